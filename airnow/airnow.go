@@ -0,0 +1,109 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package airnow implements a client for the EPA AirNow API, which provides
+// current and forecast Air Quality Index (AQI) values by pollutant.
+package airnow
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultAPIURLString = "https://www.airnowapi.org/"
+
+	// maxRespBodyBytes limits the size of response bodies. Responses list a
+	// handful of pollutants for a few reporting areas.
+	maxRespBodyBytes = 1 << 20
+
+	// defaultDistance is the distance in miles used to find a reporting area
+	// when none is given.
+	defaultDistance = 25
+)
+
+// A Client is used to retrieve data from the AirNow API.
+type Client struct {
+	httpClient          *http.Client
+	httpUserAgentString string
+	apiKey              string
+	apiURLString        string
+}
+
+// NewClient returns a new Client. An API key from AirNow is required, as is an
+// httpUserAgentString identifying your application.
+func NewClient(httpClient *http.Client, httpUserAgentString string, apiKey string) (*Client, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if httpUserAgentString == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	if apiKey == "" {
+		return nil, errors.New("apiKey must not be empty")
+	}
+	return &Client{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		apiKey:              apiKey,
+		apiURLString:        defaultAPIURLString,
+	}, nil
+}
+
+// SetAPIURLString sets the base URL of the API. It must end with a slash.
+func (c *Client) SetAPIURLString(urlString string) error {
+	if !strings.HasSuffix(urlString, "/") {
+		return errors.New("API URL must end with a slash")
+	}
+	c.apiURLString = urlString
+	return nil
+}
+
+// doAPIRequest makes a GET request to an endpoint and returns the body of a
+// 200 response. The API key is sent as a query parameter, as the API
+// requires.
+func doAPIRequest(httpClient *http.Client, httpUserAgentString string, apiKey string, apiURLString string, endpoint string, query url.Values) ([]byte, error) {
+	req, err := http.NewRequest("GET", apiURLString+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	query.Set("format", "application/json")
+	query.Set("API_KEY", apiKey)
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("User-Agent", httpUserAgentString)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody) > maxRespBodyBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxRespBodyBytes)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, respBody)
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package airnow
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	currentByPointEndpoint  = "aq/observation/latLong/current/"
+	currentByZipEndpoint    = "aq/observation/zipCode/current/"
+	forecastByPointEndpoint = "aq/forecast/latLong/"
+	forecastByZipEndpoint   = "aq/forecast/zipCode/"
+
+	dateLayout = "2006-01-02"
+)
+
+// Parameters (pollutants)
+const (
+	ParameterOzone = "O3"
+	ParameterPM25  = "PM2.5"
+	ParameterPM10  = "PM10"
+)
+
+// A Category is an AQI category, from 1 ("Good") to 6 ("Hazardous"), or 7
+// ("Unavailable").
+type Category struct {
+	Number int
+	Name   string
+}
+
+// An Observation is the current AQI for a single pollutant in a reporting
+// area.
+type Observation struct {
+	Observed      time.Time // start of the hour; zero if the time zone is unknown
+	ReportingArea string
+	StateCode     string
+	Lat           float64
+	Lon           float64
+	Parameter     string // one of the Parameter constants
+	AQI           int
+	Category      Category
+}
+
+// A Forecast is the forecast AQI for a single pollutant in a reporting area
+// on a single day.
+type Forecast struct {
+	DateIssued    time.Time // date only, in UTC
+	Date          time.Time // date only, in UTC
+	ReportingArea string
+	StateCode     string
+	Lat           float64
+	Lon           float64
+	Parameter     string // one of the Parameter constants
+	AQI           int    // -1 if only a category is forecast
+	Category      Category
+	ActionDay     bool
+	Discussion    string
+}
+
+// timeZoneOffsets maps the time zone abbreviations used by AirNow to UTC
+// offsets in hours.
+var timeZoneOffsets = map[string]int{
+	"EST": -5, "EDT": -4,
+	"CST": -6, "CDT": -5,
+	"MST": -7, "MDT": -6,
+	"PST": -8, "PDT": -7,
+	"AKST": -9, "AKDT": -8,
+	"HST": -10,
+	"AST": -4,
+}
+
+// CurrentByPoint retrieves the current AQI for each pollutant in the
+// reporting area nearest a point. distance is the maximum distance to the
+// reporting area in miles; 25 is used if it is zero.
+func (c *Client) CurrentByPoint(lat, lon float64, distance int) ([]Observation, error) {
+	q := pointQuery(lat, lon, distance)
+	respBody, err := doAPIRequest(c.httpClient, c.httpUserAgentString, c.apiKey, c.apiURLString, currentByPointEndpoint, q)
+	if err != nil {
+		return nil, err
+	}
+	return newObservationsFromRespBody(respBody)
+}
+
+// CurrentByZip retrieves the current AQI for each pollutant in the reporting
+// area for a ZIP code. distance is as for CurrentByPoint.
+func (c *Client) CurrentByZip(zip string, distance int) ([]Observation, error) {
+	q, err := zipQuery(zip, distance)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := doAPIRequest(c.httpClient, c.httpUserAgentString, c.apiKey, c.apiURLString, currentByZipEndpoint, q)
+	if err != nil {
+		return nil, err
+	}
+	return newObservationsFromRespBody(respBody)
+}
+
+// ForecastByPoint retrieves the AQI forecast for each pollutant in the
+// reporting area nearest a point, beginning on date (today if zero).
+// distance is as for CurrentByPoint.
+func (c *Client) ForecastByPoint(lat, lon float64, date time.Time, distance int) ([]Forecast, error) {
+	q := pointQuery(lat, lon, distance)
+	if !date.IsZero() {
+		q.Set("date", date.Format(dateLayout))
+	}
+	respBody, err := doAPIRequest(c.httpClient, c.httpUserAgentString, c.apiKey, c.apiURLString, forecastByPointEndpoint, q)
+	if err != nil {
+		return nil, err
+	}
+	return newForecastsFromRespBody(respBody)
+}
+
+// ForecastByZip retrieves the AQI forecast for each pollutant in the
+// reporting area for a ZIP code. date and distance are as for
+// ForecastByPoint.
+func (c *Client) ForecastByZip(zip string, date time.Time, distance int) ([]Forecast, error) {
+	q, err := zipQuery(zip, distance)
+	if err != nil {
+		return nil, err
+	}
+	if !date.IsZero() {
+		q.Set("date", date.Format(dateLayout))
+	}
+	respBody, err := doAPIRequest(c.httpClient, c.httpUserAgentString, c.apiKey, c.apiURLString, forecastByZipEndpoint, q)
+	if err != nil {
+		return nil, err
+	}
+	return newForecastsFromRespBody(respBody)
+}
+
+// HighestObservation returns the observation with the highest AQI, which is
+// the overall AQI for the reporting area. false is returned if there are no
+// observations.
+func HighestObservation(obs []Observation) (Observation, bool) {
+	var highest Observation
+	found := false
+	for _, o := range obs {
+		if !found || o.AQI > highest.AQI {
+			highest = o
+			found = true
+		}
+	}
+	return highest, found
+}
+
+// ObservationFor returns the observation for a parameter, such as
+// ParameterPM25. false is returned if there is none.
+func ObservationFor(obs []Observation, parameter string) (Observation, bool) {
+	for _, o := range obs {
+		if o.Parameter == parameter {
+			return o, true
+		}
+	}
+	return Observation{}, false
+}
+
+// ForecastFor returns the forecast for a parameter on a date. false is
+// returned if there is none.
+func ForecastFor(fcsts []Forecast, parameter string, date time.Time) (Forecast, bool) {
+	y, m, d := date.Date()
+	for _, f := range fcsts {
+		fy, fm, fd := f.Date.Date()
+		if f.Parameter == parameter && fy == y && fm == m && fd == d {
+			return f, true
+		}
+	}
+	return Forecast{}, false
+}
+
+// pointQuery returns the query parameters for a point.
+func pointQuery(lat, lon float64, distance int) url.Values {
+	q := url.Values{}
+	q.Set("latitude", strconv.FormatFloat(lat, 'f', 4, 64))
+	q.Set("longitude", strconv.FormatFloat(lon, 'f', 4, 64))
+	q.Set("distance", strconv.Itoa(distanceOrDefault(distance)))
+	return q
+}
+
+// zipQuery validates a ZIP code and returns the query parameters for it.
+func zipQuery(zip string, distance int) (url.Values, error) {
+	zip = strings.TrimSpace(zip)
+	if len(zip) != 5 {
+		return nil, errors.New("zip must be five digits")
+	}
+	if _, err := strconv.Atoi(zip); err != nil {
+		return nil, errors.New("zip must be five digits")
+	}
+	q := url.Values{}
+	q.Set("zipCode", zip)
+	q.Set("distance", strconv.Itoa(distanceOrDefault(distance)))
+	return q, nil
+}
+
+func distanceOrDefault(distance int) int {
+	if distance <= 0 {
+		return defaultDistance
+	}
+	return distance
+}
+
+// newObservationsFromRespBody returns a slice of observations, given a
+// response body from the API.
+func newObservationsFromRespBody(respBody []byte) ([]Observation, error) {
+	obsRaw := []struct {
+		DateObserved  string // "2019-09-14 " (note the space)
+		HourObserved  int
+		LocalTimeZone string
+		ReportingArea string
+		StateCode     string
+		Latitude      float64
+		Longitude     float64
+		ParameterName string
+		AQI           int
+		Category      Category
+	}{}
+	if err := json.Unmarshal(respBody, &obsRaw); err != nil {
+		return nil, err
+	}
+
+	var obs []Observation
+	for _, oRaw := range obsRaw {
+		if oRaw.ParameterName == "" {
+			continue // skip if no pollutant
+		}
+		o := Observation{
+			ReportingArea: oRaw.ReportingArea,
+			StateCode:     oRaw.StateCode,
+			Lat:           oRaw.Latitude,
+			Lon:           oRaw.Longitude,
+			Parameter:     oRaw.ParameterName,
+			AQI:           oRaw.AQI,
+			Category:      oRaw.Category,
+		}
+		if offset, ok := timeZoneOffsets[strings.TrimSpace(oRaw.LocalTimeZone)]; ok {
+			loc := time.FixedZone(strings.TrimSpace(oRaw.LocalTimeZone), offset*60*60)
+			if d, err := time.ParseInLocation(dateLayout, strings.TrimSpace(oRaw.DateObserved), loc); err == nil {
+				o.Observed = d.Add(time.Duration(oRaw.HourObserved) * time.Hour)
+			}
+		}
+		obs = append(obs, o)
+	}
+
+	return obs, nil
+}
+
+// newForecastsFromRespBody returns a slice of forecasts, given a response
+// body from the API.
+func newForecastsFromRespBody(respBody []byte) ([]Forecast, error) {
+	fcstsRaw := []struct {
+		DateIssue     string
+		DateForecast  string
+		ReportingArea string
+		StateCode     string
+		Latitude      float64
+		Longitude     float64
+		ParameterName string
+		AQI           int
+		Category      Category
+		ActionDay     bool
+		Discussion    string
+	}{}
+	if err := json.Unmarshal(respBody, &fcstsRaw); err != nil {
+		return nil, err
+	}
+
+	var fcsts []Forecast
+	for _, fRaw := range fcstsRaw {
+		d, err := time.Parse(dateLayout, strings.TrimSpace(fRaw.DateForecast))
+		if err != nil || fRaw.ParameterName == "" {
+			continue // skip if bad date or no pollutant
+		}
+		issued, _ := time.Parse(dateLayout, strings.TrimSpace(fRaw.DateIssue))
+		fcsts = append(fcsts, Forecast{
+			DateIssued:    issued,
+			Date:          d,
+			ReportingArea: fRaw.ReportingArea,
+			StateCode:     fRaw.StateCode,
+			Lat:           fRaw.Latitude,
+			Lon:           fRaw.Longitude,
+			Parameter:     fRaw.ParameterName,
+			AQI:           fRaw.AQI,
+			Category:      fRaw.Category,
+			ActionDay:     fRaw.ActionDay,
+			Discussion:    fRaw.Discussion,
+		})
+	}
+
+	return fcsts, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astro_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mikecamilleri/our-data/astro"
+)
+
+func ExampleSun() {
+	pdt := time.FixedZone("PDT", -7*60*60)
+	st := astro.Sun(time.Date(2019, 8, 30, 0, 0, 0, 0, pdt), 45.458, -122.6636)
+
+	fmt.Println("civil dawn:", st.CivilDawn.Format("15:04"))
+	fmt.Println("sunrise:   ", st.Sunrise.Format("15:04"))
+	fmt.Println("sunset:    ", st.Sunset.Format("15:04"))
+	fmt.Println("civil dusk:", st.CivilDusk.Format("15:04"))
+	// Output:
+	// civil dawn: 05:58
+	// sunrise:    06:29
+	// sunset:     19:52
+	// civil dusk: 20:22
+}
+
+func ExampleMoon() {
+	m := astro.Moon(time.Date(2019, 9, 14, 4, 33, 0, 0, time.UTC))
+	fmt.Println(m.Name)
+	// Output:
+	// Full Moon
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// synodicMonth is the mean length of a lunar cycle in days.
+	synodicMonth = 29.530588853
+
+	// referenceNewMoon is the Julian day of the new moon of January 6, 2000.
+	referenceNewMoon = 2451550.1
+)
+
+// Moon phase names.
+const (
+	NewMoon        = "New Moon"
+	WaxingCrescent = "Waxing Crescent"
+	FirstQuarter   = "First Quarter"
+	WaxingGibbous  = "Waxing Gibbous"
+	FullMoon       = "Full Moon"
+	WaningGibbous  = "Waning Gibbous"
+	LastQuarter    = "Last Quarter"
+	WaningCrescent = "Waning Crescent"
+)
+
+// phaseNames are the names of the eight phases, each centered on an eighth of
+// the cycle starting with the new moon.
+var phaseNames = [8]string{NewMoon, WaxingCrescent, FirstQuarter, WaxingGibbous, FullMoon, WaningGibbous, LastQuarter, WaningCrescent}
+
+// A MoonPhase describes the phase of the moon at a time.
+type MoonPhase struct {
+	Age          float64 // days since the new moon
+	Illumination float64 // fraction of the visible disk illuminated, 0 to 1
+	Name         string  // one of the moon phase name constants
+}
+
+// Moon returns the phase of the moon at time t. The phase is computed from the
+// mean lunar cycle, so ages may be off by up to about a day.
+func Moon(t time.Time) MoonPhase {
+	age := math.Mod(julianDay(t)-referenceNewMoon, synodicMonth)
+	if age < 0 {
+		age += synodicMonth
+	}
+	fraction := age / synodicMonth
+	return MoonPhase{
+		Age:          age,
+		Illumination: (1 - math.Cos(2*math.Pi*fraction)) / 2,
+		Name:         phaseNames[int(math.Floor(fraction*8+0.5))%8],
+	}
+}
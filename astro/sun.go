@@ -0,0 +1,211 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package astro computes the times of sunrise, sunset, and twilight, the
+// position of the sun, and the phase of the moon for a location on Earth.
+//
+// Latitudes and longitudes are WGS 84 decimal degrees, with west and south
+// negative.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Zenith angles, in degrees, of the sun's center at the beginning and end of
+// each kind of day and twilight.
+const (
+	ZenithOfficial     = 90.833 // sunrise and sunset, accounting for refraction and the sun's radius
+	ZenithCivil        = 96
+	ZenithNautical     = 102
+	ZenithAstronomical = 108
+)
+
+const rad = math.Pi / 180
+
+// SunTimes are the times of the sun's daily events on a date at a location.
+// Events that don't occur on the date (e.g. sunrise during polar night) are
+// zero.
+type SunTimes struct {
+	Date      time.Time // local midnight at the start of the date
+	SolarNoon time.Time
+
+	Sunrise time.Time
+	Sunset  time.Time
+
+	CivilDawn        time.Time
+	CivilDusk        time.Time
+	NauticalDawn     time.Time
+	NauticalDusk     time.Time
+	AstronomicalDawn time.Time
+	AstronomicalDusk time.Time
+}
+
+// DayLength returns the time between sunrise and sunset, or zero if either
+// doesn't occur.
+func (st SunTimes) DayLength() time.Duration {
+	if st.Sunrise.IsZero() || st.Sunset.IsZero() {
+		return 0
+	}
+	return st.Sunset.Sub(st.Sunrise)
+}
+
+// Sun returns the times of the sun's events on the local date of t (in t's
+// location) at a location.
+func Sun(t time.Time, lat float64, lon float64) SunTimes {
+	y, m, d := t.Date()
+	st := SunTimes{Date: time.Date(y, m, d, 0, 0, 0, 0, t.Location())}
+	st.SolarNoon = SolarNoon(t, lon)
+	st.Sunrise, _ = Rise(t, lat, lon, ZenithOfficial)
+	st.Sunset, _ = Set(t, lat, lon, ZenithOfficial)
+	st.CivilDawn, _ = Rise(t, lat, lon, ZenithCivil)
+	st.CivilDusk, _ = Set(t, lat, lon, ZenithCivil)
+	st.NauticalDawn, _ = Rise(t, lat, lon, ZenithNautical)
+	st.NauticalDusk, _ = Set(t, lat, lon, ZenithNautical)
+	st.AstronomicalDawn, _ = Rise(t, lat, lon, ZenithAstronomical)
+	st.AstronomicalDusk, _ = Set(t, lat, lon, ZenithAstronomical)
+	return st
+}
+
+// Sunrise returns the time of sunrise on the local date of t at a location.
+// The second return value is false if the sun doesn't rise that day.
+func Sunrise(t time.Time, lat float64, lon float64) (time.Time, bool) {
+	return Rise(t, lat, lon, ZenithOfficial)
+}
+
+// Sunset returns the time of sunset on the local date of t at a location. The
+// second return value is false if the sun doesn't set that day.
+func Sunset(t time.Time, lat float64, lon float64) (time.Time, bool) {
+	return Set(t, lat, lon, ZenithOfficial)
+}
+
+// Rise returns the time in the morning of the local date of t that the sun
+// reaches a zenith angle (e.g. ZenithCivil for civil dawn). The second return
+// value is false if the sun doesn't reach that angle that day.
+func Rise(t time.Time, lat float64, lon float64, zenith float64) (time.Time, bool) {
+	return sunEvent(t, lat, lon, zenith, -1)
+}
+
+// Set returns the time in the evening of the local date of t that the sun
+// reaches a zenith angle (e.g. ZenithCivil for civil dusk). The second return
+// value is false if the sun doesn't reach that angle that day.
+func Set(t time.Time, lat float64, lon float64, zenith float64) (time.Time, bool) {
+	return sunEvent(t, lat, lon, zenith, 1)
+}
+
+// SolarNoon returns the time that the sun crosses the meridian on the local
+// date of t at a longitude.
+func SolarNoon(t time.Time, lon float64) time.Time {
+	base := utcDate(t)
+	_, eqTime := solarParams(julianDay(base.Add(12*time.Hour)) - lon/360)
+	return in(base, 720-4*lon-eqTime, t.Location())
+}
+
+// SolarPosition returns the elevation of the sun above the horizon and its
+// azimuth (degrees clockwise from true north) at time t at a location. The
+// elevation is geometric; it does not account for refraction.
+func SolarPosition(t time.Time, lat float64, lon float64) (elevation float64, azimuth float64) {
+	decl, eqTime := solarParams(julianDay(t))
+
+	u := t.UTC()
+	minutes := float64(u.Hour()*60+u.Minute()) + float64(u.Second())/60
+	trueSolarTime := math.Mod(minutes+eqTime+4*lon+1440, 1440)
+	hourAngle := trueSolarTime/4 - 180
+
+	cosZenith := math.Sin(lat*rad)*math.Sin(decl*rad) + math.Cos(lat*rad)*math.Cos(decl*rad)*math.Cos(hourAngle*rad)
+	zenith := math.Acos(math.Max(-1, math.Min(1, cosZenith))) / rad
+
+	azimuth = math.Atan2(
+		math.Sin(hourAngle*rad),
+		math.Cos(hourAngle*rad)*math.Sin(lat*rad)-math.Tan(decl*rad)*math.Cos(lat*rad),
+	)/rad + 180
+
+	return 90 - zenith, math.Mod(azimuth, 360)
+}
+
+// IsDaylight reports whether the sun is above the horizon at time t at a
+// location.
+func IsDaylight(t time.Time, lat float64, lon float64) bool {
+	elevation, _ := SolarPosition(t, lat, lon)
+	return elevation > 90-ZenithOfficial
+}
+
+// sunEvent returns the time on the local date of t that the sun reaches a
+// zenith angle before (direction -1) or after (direction 1) solar noon.
+//
+// The sun's declination and the equation of time are computed for the
+// approximate time of the event, which is refined once.
+func sunEvent(t time.Time, lat float64, lon float64, zenith float64, direction float64) (time.Time, bool) {
+	base := utcDate(t)
+
+	minutes := 720 - 4*lon // approximate solar noon in minutes after midnight UTC
+	for i := 0; i < 2; i++ {
+		decl, eqTime := solarParams(julianDay(base) + minutes/1440)
+		cosHA := math.Cos(zenith*rad)/(math.Cos(lat*rad)*math.Cos(decl*rad)) - math.Tan(lat*rad)*math.Tan(decl*rad)
+		if cosHA > 1 || cosHA < -1 {
+			return time.Time{}, false
+		}
+		hourAngle := math.Acos(cosHA) / rad
+		minutes = 720 - 4*(lon-direction*hourAngle) - eqTime
+	}
+
+	return in(base, minutes, t.Location()), true
+}
+
+// solarParams returns the sun's declination in degrees and the equation of
+// time in minutes at a Julian day, using the NOAA solar calculator equations.
+func solarParams(jd float64) (declination float64, eqTime float64) {
+	jc := (jd - 2451545) / 36525 // Julian centuries since J2000.0
+
+	meanLon := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	meanAnomaly := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	eccentricity := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+	center := math.Sin(meanAnomaly*rad)*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(2*meanAnomaly*rad)*(0.019993-0.000101*jc) +
+		math.Sin(3*meanAnomaly*rad)*0.000289
+	omega := 125.04 - 1934.136*jc
+	apparentLon := meanLon + center - 0.00569 - 0.00478*math.Sin(omega*rad)
+	meanObliquity := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliquity := meanObliquity + 0.00256*math.Cos(omega*rad)
+
+	declination = math.Asin(math.Sin(obliquity*rad)*math.Sin(apparentLon*rad)) / rad
+
+	y := math.Pow(math.Tan(obliquity/2*rad), 2)
+	eqTime = 4 / rad * (y*math.Sin(2*meanLon*rad) -
+		2*eccentricity*math.Sin(meanAnomaly*rad) +
+		4*eccentricity*y*math.Sin(meanAnomaly*rad)*math.Cos(2*meanLon*rad) -
+		0.5*y*y*math.Sin(4*meanLon*rad) -
+		1.25*eccentricity*eccentricity*math.Sin(2*meanAnomaly*rad))
+
+	return declination, eqTime
+}
+
+// julianDay returns the Julian day of a time.
+func julianDay(t time.Time) float64 {
+	return float64(t.UTC().UnixNano())/float64(24*time.Hour) + 2440587.5
+}
+
+// utcDate returns midnight UTC on the local date of t. Events are computed as
+// offsets from it, so that an event on the local date may fall on the previous
+// or next UTC date.
+func utcDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// in returns the time a number of minutes after base, in a location.
+func in(base time.Time, minutes float64, loc *time.Location) time.Time {
+	return base.Add(time.Duration(minutes * float64(time.Minute))).Round(time.Second).In(loc)
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// runAnonymize scrubs location information from a recorded API response read
+// from stdin (or a file) and writes the result to stdout. Offsets are random
+// unless given.
+//
+//   ourwx anonymize point.json > point-anon.json
+func runAnonymize(args []string) error {
+	var opts nws.AnonymizeOptions
+	fs := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	fs.Float64Var(&opts.LatOffset, "lat-offset", 0, "degrees added to latitudes (random if 0)")
+	fs.Float64Var(&opts.LonOffset, "lon-offset", 0, "degrees added to longitudes (random if 0)")
+	fs.IntVar(&opts.GridOffset, "grid-offset", 0, "added to grid indices (random if 0)")
+	fs.Parse(args)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	if opts.LatOffset == 0 {
+		opts.LatOffset = randomOffset(r, 1, 3)
+	}
+	if opts.LonOffset == 0 {
+		opts.LonOffset = randomOffset(r, 1, 3)
+	}
+	if opts.GridOffset == 0 {
+		opts.GridOffset = int(randomOffset(r, 10, 40))
+	}
+
+	in := os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	body, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := nws.Anonymize(body, opts)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// randomOffset returns a random value with a magnitude between min and max
+// and a random sign.
+func randomOffset(r *rand.Rand, min float64, max float64) float64 {
+	v := min + r.Float64()*(max-min)
+	if r.Intn(2) == 0 {
+		v = -v
+	}
+	return v
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mikecamilleri/our-data/daemon"
+)
+
+// runDaemon polls the locations in a config file and publishes to its sinks
+// until interrupted.
+//
+//   ourwx daemon --config ourwx.json
+//   ourwx daemon --config ourwx.toml
+func runDaemon(args []string) error {
+	var config string
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	fs.StringVar(&config, "config", "", "path to a JSON or TOML config file (see package daemon)")
+	fs.Parse(args)
+	if config == "" {
+		return errors.New("--config is required")
+	}
+
+	cfg, err := daemon.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+	d, err := daemon.New(*cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := d.Run(ctx); err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A snapshot is the forecast and alerts for a location at a point in time.
+type snapshot struct {
+	Forecast nws.Forecast
+	Alerts   []nws.Alert
+}
+
+// runSnapshot writes a snapshot of live data to stdout.
+//
+//   ourwx snapshot --lat 45.458 --lon -122.6636 > before.json
+func runSnapshot(args []string) error {
+	var lf locationFlags
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	lf.register(fs)
+	fs.Parse(args)
+
+	s, err := liveSnapshot(&lf)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// runDiff prints the changes between two snapshots, or between a snapshot and
+// live data if only one file and a location are given.
+//
+//   ourwx diff [--exact] before.json after.json
+//   ourwx diff --lat 45.458 --lon -122.6636 [--exact] before.json
+func runDiff(args []string) error {
+	var lf locationFlags
+	var ef exactFlag
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	lf.register(fs)
+	ef.register(fs)
+	fs.Parse(args)
+
+	var older, newer *snapshot
+	var err error
+	switch {
+	case fs.NArg() == 2:
+		if older, err = readSnapshot(fs.Arg(0)); err != nil {
+			return err
+		}
+		if newer, err = readSnapshot(fs.Arg(1)); err != nil {
+			return err
+		}
+	case fs.NArg() == 1 && lf.isSet():
+		if older, err = readSnapshot(fs.Arg(0)); err != nil {
+			return err
+		}
+		if newer, err = liveSnapshot(&lf); err != nil {
+			return err
+		}
+	default:
+		return errors.New("requires two snapshot files, or one snapshot file and a location")
+	}
+
+	pcs := nws.DiffForecasts(older.Forecast, newer.Forecast)
+	acs := nws.DiffAlerts(older.Alerts, newer.Alerts)
+	if len(pcs) == 0 && len(acs) == 0 {
+		fmt.Println("no changes")
+		return nil
+	}
+	if len(pcs) > 0 {
+		fmt.Printf("Forecast (%s -> %s)\n", older.Forecast.TimeForecast.Format("Jan 2 3:04PM"), newer.Forecast.TimeForecast.Format("Jan 2 3:04PM"))
+		dp := ef.policy()
+		for _, c := range pcs {
+			fmt.Printf("  %s\n", c.Format(dp))
+		}
+	}
+	if len(acs) > 0 {
+		fmt.Println("Alerts")
+		for _, c := range acs {
+			fmt.Printf("  %s\n", c)
+		}
+	}
+	return nil
+}
+
+// readSnapshot reads a snapshot from a JSON file.
+func readSnapshot(name string) (*snapshot, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var s snapshot
+	if err = json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return &s, nil
+}
+
+// liveSnapshot retrieves a snapshot for a location.
+func liveSnapshot(lf *locationFlags) (*snapshot, error) {
+	c, err := lf.newClient()
+	if err != nil {
+		return nil, err
+	}
+	var s snapshot
+	if s.Forecast, err = c.Forecast(); err != nil {
+		return nil, err
+	}
+	if s.Alerts, err = c.ActiveAlerts(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// outputFlag is the flag shared by commands that print data as a table or as
+// JSON.
+type outputFlag string
+
+// register adds the output flag to a flag set.
+func (of *outputFlag) register(fs *flag.FlagSet) {
+	fs.StringVar((*string)(of), "output", "table", "output format: table or json")
+}
+
+// validate returns an error if the output format is unknown.
+func (of outputFlag) validate() error {
+	if of != "table" && of != "json" {
+		return fmt.Errorf("unknown output format: \"%s\"", of)
+	}
+	return nil
+}
+
+// exactFlag is the flag shared by commands that print values, which are
+// rounded with nws.DefaultDisplayPolicy unless it is set.
+type exactFlag bool
+
+// register adds the exact flag to a flag set.
+func (ef *exactFlag) register(fs *flag.FlagSet) {
+	fs.BoolVar((*bool)(ef), "exact", false, "print values without rounding")
+}
+
+// policy returns the display policy selected by the flag.
+func (ef exactFlag) policy() nws.DisplayPolicy {
+	if ef {
+		return nws.DisplayPolicy{}
+	}
+	return nws.DefaultDisplayPolicy
+}
+
+// runForecast prints the semi-daily forecast.
+//
+//   ourwx forecast --lat 45.458 --lon -122.6636 [--output json] [--exact]
+func runForecast(args []string) error {
+	return printForecast("forecast", args, false)
+}
+
+// runHourly prints the hourly forecast.
+//
+//   ourwx hourly --lat 45.458 --lon -122.6636 [--output json] [--exact]
+func runHourly(args []string) error {
+	return printForecast("hourly", args, true)
+}
+
+// printForecast prints the semi-daily or hourly forecast.
+func printForecast(name string, args []string, hourly bool) error {
+	var lf locationFlags
+	var of outputFlag
+	var ef exactFlag
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	lf.register(fs)
+	of.register(fs)
+	ef.register(fs)
+	fs.Parse(args)
+	if err := of.validate(); err != nil {
+		return err
+	}
+
+	c, err := lf.newClient()
+	if err != nil {
+		return err
+	}
+	var f nws.Forecast
+	if hourly {
+		f, err = c.HourlyForecast()
+	} else {
+		f, err = c.Forecast()
+	}
+	if err != nil {
+		return err
+	}
+
+	if of == "json" {
+		return nws.ExportForecastJSON(os.Stdout, f)
+	}
+	dp := ef.policy()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PERIOD\tTEMP\tWIND\tPRECIP\tFORECAST")
+	for _, p := range f.Periods {
+		period := p.Name
+		if hourly || period == "" {
+			period = p.TimeStart.Format("Mon 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", period, formatValueUnit(dp, p.Temperature), formatWind(dp, p), formatPercent(p.PrecipitationProbability), p.ForecastShort)
+	}
+	return tw.Flush()
+}
+
+// runObs prints the latest observation from a station, or from the station
+// nearest a location.
+//
+//   ourwx obs --station KPDX [--output json] [--exact]
+//   ourwx obs --lat 45.458 --lon -122.6636 [--output json] [--exact]
+func runObs(args []string) error {
+	var lf locationFlags
+	var of outputFlag
+	var ef exactFlag
+	var station string
+	fs := flag.NewFlagSet("obs", flag.ExitOnError)
+	lf.register(fs)
+	of.register(fs)
+	ef.register(fs)
+	fs.StringVar(&station, "station", "", "station ID (--lat and --lon are required without it)")
+	fs.Parse(args)
+	if err := of.validate(); err != nil {
+		return err
+	}
+
+	var o nws.Observation
+	if station == "" {
+		c, err := lf.newClient()
+		if err != nil {
+			return err
+		}
+		if o, err = c.CurrentConditions(); err != nil {
+			return err
+		}
+	} else {
+		c, err := lf.newNWSClient()
+		if err != nil {
+			return err
+		}
+		station = strings.ToUpper(station)
+		if err := c.UpdateLatestOservationForStation(station); err != nil {
+			return err
+		}
+		o = c.LatestObservationForStation(station)
+	}
+
+	if of == "json" {
+		return nws.ExportObservationsJSON(os.Stdout, []nws.Observation{o})
+	}
+	dp := ef.policy()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Station\t%s\n", o.StationID)
+	fmt.Fprintf(tw, "Observed\t%s\n", o.TimeObserved.Local().Format("Mon Jan 2 15:04 MST"))
+	for _, row := range []struct {
+		name string
+		vu   nws.ValueUnit
+	}{
+		{"Temperature", o.Temperature},
+		{"Dew point", o.Dewpoint},
+		{"Humidity", o.RelativeHumidity},
+		{"Wind direction", o.WindDirection},
+		{"Wind speed", o.WindSpeed},
+		{"Wind gust", o.WindGust},
+		{"Pressure", o.BarometricPressure},
+		{"Visibility", o.Visibility},
+	} {
+		if row.vu.Unit != "" {
+			fmt.Fprintf(tw, "%s\t%s\n", row.name, formatValueUnit(dp, row.vu))
+		}
+	}
+	return tw.Flush()
+}
+
+// runAlerts prints the active alerts for a zone, or for a location.
+//
+//   ourwx alerts --zone ORZ006 [--output json]
+//   ourwx alerts --lat 45.458 --lon -122.6636 [--output json]
+func runAlerts(args []string) error {
+	var lf locationFlags
+	var of outputFlag
+	var zone string
+	fs := flag.NewFlagSet("alerts", flag.ExitOnError)
+	lf.register(fs)
+	of.register(fs)
+	fs.StringVar(&zone, "zone", "", "forecast or county zone ID (--lat and --lon are required without it)")
+	fs.Parse(args)
+	if err := of.validate(); err != nil {
+		return err
+	}
+
+	var alerts []nws.Alert
+	if zone == "" {
+		c, err := lf.newClient()
+		if err != nil {
+			return err
+		}
+		if alerts, err = c.ActiveAlerts(); err != nil {
+			return err
+		}
+	} else {
+		c, err := lf.newNWSClient()
+		if err != nil {
+			return err
+		}
+		if alerts, err = c.ActiveAlertsForZone(zone); err != nil {
+			return err
+		}
+	}
+
+	if of == "json" {
+		return nws.ExportAlertsJSON(os.Stdout, alerts)
+	}
+	if len(alerts) == 0 {
+		fmt.Println("no active alerts")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "EVENT\tSEVERITY\tEXPIRES\tHEADLINE")
+	for _, a := range alerts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Event, a.Severity, a.TimeExpires.Local().Format("Mon Jan 2 15:04"), a.Headline)
+	}
+	return tw.Flush()
+}
+
+// formatValueUnit returns a value formatted for display using dp, or "-" if
+// it is missing.
+func formatValueUnit(dp nws.DisplayPolicy, vu nws.ValueUnit) string {
+	if vu.Unit == "" {
+		return "-"
+	}
+	return dp.Format(vu)
+}
+
+// formatWind returns the wind forecast for a period formatted for display
+// using dp (e.g. "NW 5 to 10 mph").
+func formatWind(dp nws.DisplayPolicy, p nws.Period) string {
+	if p.WindSpeedMax.Unit == "" {
+		return "-"
+	}
+	s := formatSpeed(dp, p.WindSpeedMax)
+	if p.WindSpeedMin.Unit != "" && p.WindSpeedMin.Value != p.WindSpeedMax.Value {
+		s = strconv.FormatFloat(dp.Round(p.WindSpeedMin).Value, 'f', -1, 64) + " to " + s
+	}
+	if p.WindDirection != "" {
+		s = p.WindDirection + " " + s
+	}
+	return s
+}
+
+// formatPercent returns a percentage formatted for display, or "-" if it is
+// missing.
+func formatPercent(vu nws.ValueUnit) string {
+	if vu.Unit == "" {
+		return "-"
+	}
+	return strconv.FormatFloat(vu.Value, 'f', 0, 64) + "%"
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// runLulls prints the windows in the hourly forecast with light wind, for
+// scheduling drone flights and the like.
+//
+//   ourwx lulls --lat 45.458 --lon -122.6636 --max-wind 10 --max-gust 15 --min-duration 2h [--exact]
+func runLulls(args []string) error {
+	var lf locationFlags
+	var ef exactFlag
+	var maxWind, maxGust float64
+	var unit string
+	var minDuration time.Duration
+	fs := flag.NewFlagSet("lulls", flag.ExitOnError)
+	lf.register(fs)
+	ef.register(fs)
+	fs.Float64Var(&maxWind, "max-wind", 10, "sustained wind must be below this")
+	fs.Float64Var(&maxGust, "max-gust", 0, "gusts must be below this (0 to ignore gusts)")
+	fs.StringVar(&unit, "unit", "mph", "unit for --max-wind and --max-gust: mph, kt, km/h, or m/s")
+	fs.DurationVar(&minDuration, "min-duration", time.Hour, "shortest window to report")
+	fs.Parse(args)
+
+	c, err := lf.newClient()
+	if err != nil {
+		return err
+	}
+	f, err := c.HourlyForecast()
+	if err != nil {
+		return err
+	}
+
+	criteria := nws.LullCriteria{
+		WindSpeedMax: nws.ValueUnit{Value: maxWind, Unit: unit},
+		MinDuration:  minDuration,
+	}
+	if maxGust > 0 {
+		criteria.WindGustMax = nws.ValueUnit{Value: maxGust, Unit: unit}
+	}
+	lulls, err := f.Lulls(criteria)
+	if err != nil {
+		return err
+	}
+
+	dp := ef.policy()
+	for _, l := range lulls {
+		fmt.Printf("%s - %s  wind up to %s", l.TimeStart.Format("Mon Jan 2 15:04"), l.TimeEnd.Format("15:04"), formatSpeed(dp, l.WindSpeedMax))
+		if l.WindGustMax.Unit != "" {
+			fmt.Printf(", gusts to %s", formatSpeed(dp, l.WindGustMax))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// formatSpeed returns a speed formatted for display using dp.
+func formatSpeed(dp nws.DisplayPolicy, vu nws.ValueUnit) string {
+	return dp.Format(vu)
+}
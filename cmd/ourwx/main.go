@@ -0,0 +1,134 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ourwx retrieves and compares weather data for a location.
+//
+// Usage:
+//   ourwx <command> [flags]
+//
+// Commands:
+//   forecast  print the forecast for a location
+//   hourly    print the hourly forecast for a location
+//   obs       print the latest observation from a station
+//   alerts    print the active alerts for a location or zone
+//   snapshot  write the current forecast and alerts for a location as JSON
+//   diff      compare two snapshots, or a snapshot and live data
+//   lulls     list light-wind windows in the hourly forecast
+//   anonymize scrub location information from a recorded API response
+//   weekend   compare this weekend's forecast with next weekend's
+//   record    write recent observations and alerts for a location as JSON
+//   replay    replay a recording at a chosen speed
+//   daemon    poll locations and publish to sinks until interrupted
+//
+// Live data requires --user-agent (or the OURWX_USER_AGENT environment
+// variable) and, except for obs with --station and alerts with --zone, --lat
+// and --lon. The forecast, hourly, obs, and alerts commands print a table, or
+// JSON with --output json.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/mikecamilleri/our-data/nws"
+	"github.com/mikecamilleri/our-data/ourwx"
+)
+
+// commands maps each subcommand name to its function. Each function is given
+// the arguments following the subcommand name.
+var commands = map[string]func(args []string) error{
+	"forecast":  runForecast,
+	"hourly":    runHourly,
+	"obs":       runObs,
+	"alerts":    runAlerts,
+	"snapshot":  runSnapshot,
+	"diff":      runDiff,
+	"lulls":     runLulls,
+	"anonymize": runAnonymize,
+	"weekend":   runWeekend,
+	"record":    runRecord,
+	"replay":    runReplay,
+	"daemon":    runDaemon,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "ourwx %s: %s\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the list of commands to stderr.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ourwx <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  forecast  print the forecast for a location")
+	fmt.Fprintln(os.Stderr, "  hourly    print the hourly forecast for a location")
+	fmt.Fprintln(os.Stderr, "  obs       print the latest observation from a station")
+	fmt.Fprintln(os.Stderr, "  alerts    print the active alerts for a location or zone")
+	fmt.Fprintln(os.Stderr, "  snapshot  write the current forecast and alerts for a location as JSON")
+	fmt.Fprintln(os.Stderr, "  diff      compare two snapshots, or a snapshot and live data")
+	fmt.Fprintln(os.Stderr, "  lulls     list light-wind windows in the hourly forecast")
+	fmt.Fprintln(os.Stderr, "  anonymize scrub location information from a recorded API response")
+	fmt.Fprintln(os.Stderr, "  weekend   compare this weekend's forecast with next weekend's")
+	fmt.Fprintln(os.Stderr, "  record    write recent observations and alerts for a location as JSON")
+	fmt.Fprintln(os.Stderr, "  replay    replay a recording at a chosen speed")
+	fmt.Fprintln(os.Stderr, "  daemon    poll locations and publish to sinks until interrupted")
+}
+
+// locationFlags are the flags shared by commands that retrieve live data.
+type locationFlags struct {
+	lat       float64
+	lon       float64
+	userAgent string
+}
+
+// register adds the location flags to a flag set.
+func (lf *locationFlags) register(fs *flag.FlagSet) {
+	fs.Float64Var(&lf.lat, "lat", 0, "latitude (WGS 84)")
+	fs.Float64Var(&lf.lon, "lon", 0, "longitude (WGS 84)")
+	fs.StringVar(&lf.userAgent, "user-agent", os.Getenv("OURWX_USER_AGENT"), "User-Agent identifying your application and contact email")
+}
+
+// isSet reports whether a location was given.
+func (lf *locationFlags) isSet() bool {
+	return lf.lat != 0 || lf.lon != 0
+}
+
+// newClient returns a client for the location.
+func (lf *locationFlags) newClient() (*ourwx.Client, error) {
+	if !lf.isSet() {
+		return nil, errors.New("--lat and --lon are required")
+	}
+	return ourwx.NewClient(&http.Client{}, lf.userAgent, lf.lat, lf.lon)
+}
+
+// newNWSClient returns a client that isn't bound to a location, for commands
+// given a station or zone. Any location is ignored.
+func (lf *locationFlags) newNWSClient() (*nws.Client, error) {
+	return nws.NewClient(&http.Client{}, lf.userAgent)
+}
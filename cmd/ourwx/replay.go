@@ -0,0 +1,86 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/mikecamilleri/our-data/ourwx"
+)
+
+// runRecord writes a recording of the observations and alerts for a location
+// over a recent period to stdout.
+//
+//   ourwx record --lat 45.458 --lon -122.6636 --since 24h > day.json
+func runRecord(args []string) error {
+	var lf locationFlags
+	var since time.Duration
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	lf.register(fs)
+	fs.DurationVar(&since, "since", 24*time.Hour, "how far back to record")
+	fs.Parse(args)
+
+	c, err := lf.newClient()
+	if err != nil {
+		return err
+	}
+	rec, err := c.Record(time.Now().Add(-since))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rec)
+}
+
+// runReplay replays a recording, printing each event as it is delivered.
+//
+//   ourwx replay --speed 3600 day.json
+func runReplay(args []string) error {
+	var speed float64
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.Float64Var(&speed, "speed", 60, "times faster than real time (0 for no delay)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("a recording file is required")
+	}
+
+	b, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var rec ourwx.Recording
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return err
+	}
+
+	return ourwx.Replay(context.Background(), rec, ourwx.ReplayOptions{Speed: speed}, func(e ourwx.ReplayEvent) error {
+		ts := e.Time.Format("Jan 2 15:04")
+		if e.Observation != nil {
+			fmt.Printf("%s  observation: %s\n", ts, e.Observation.METAR)
+		}
+		for _, ch := range e.AlertChanges {
+			fmt.Printf("%s  alert: %s\n", ts, ch)
+		}
+		return nil
+	})
+}
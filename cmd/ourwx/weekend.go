@@ -0,0 +1,71 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// runWeekend compares this weekend's forecast with next weekend's.
+//
+//   ourwx weekend --lat 45.458 --lon -122.6636 --min-temp 65 --max-temp 80
+func runWeekend(args []string) error {
+	var lf locationFlags
+	criteria := nws.DefaultComfortCriteria
+	fs := flag.NewFlagSet("weekend", flag.ExitOnError)
+	lf.register(fs)
+	fs.Float64Var(&criteria.TemperatureMin.Value, "min-temp", criteria.TemperatureMin.Value, "lowest comfortable temperature (F)")
+	fs.Float64Var(&criteria.TemperatureMax.Value, "max-temp", criteria.TemperatureMax.Value, "highest comfortable temperature (F)")
+	fs.Float64Var(&criteria.WindSpeedMax.Value, "max-wind", criteria.WindSpeedMax.Value, "highest comfortable wind speed (mph)")
+	fs.IntVar(&criteria.PrecipitationProbabilityMax, "max-pop", criteria.PrecipitationProbabilityMax, "highest acceptable chance of precipitation (percent)")
+	fs.Parse(args)
+
+	c, err := lf.newClient()
+	if err != nil {
+		return err
+	}
+	// the semidaily forecast reaches further ahead than the hourly forecast
+	f, err := c.Forecast()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	aStart, aEnd := nws.WeekendWindow(now, 0)
+	bStart, bEnd := nws.WeekendWindow(now, 1)
+	cmp, err := f.CompareWindows(aStart, aEnd, bStart, bEnd, criteria)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("this weekend (%s): %.0f%% comfortable over %.0f forecast hours\n", aStart.Format("Jan 2"), cmp.A.Score, cmp.A.Hours)
+	fmt.Printf("next weekend (%s): %.0f%% comfortable over %.0f forecast hours\n", bStart.Format("Jan 2"), cmp.B.Score, cmp.B.Hours)
+	switch cmp.Better {
+	case nws.WindowA:
+		fmt.Println("this weekend looks better")
+	case nws.WindowB:
+		fmt.Println("next weekend looks better")
+	default:
+		fmt.Println("the weekends look about the same")
+	}
+	for _, fc := range cmp.Factors {
+		fmt.Printf("  %-13s %3.0f%% vs. %3.0f%%\n", fc.Name, fc.A, fc.B)
+	}
+	return nil
+}
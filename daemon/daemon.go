@@ -0,0 +1,385 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package daemon runs a long-lived poller for one or more locations, recording
+// the data it retrieves in a history store and publishing them to sinks such
+// as Prometheus, webhooks, alert notifications, and MQTT.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikecamilleri/our-data/exporter"
+	"github.com/mikecamilleri/our-data/nws"
+	"github.com/mikecamilleri/our-data/ourwx"
+)
+
+const (
+	defaultInterval = 5 * time.Minute
+
+	// defaultCacheTTL is how long responses without a Cache-Control max-age
+	// are cached.
+	defaultCacheTTL = time.Minute
+)
+
+// A Config configures a Daemon. It is read from a JSON or TOML file by
+// LoadConfig. Durations are strings such as "5m".
+//
+//   {
+//     "userAgent": "(myweatherapp.com, contact@myweatherapp.com)",
+//     "interval": "5m",
+//     "locations": [{"name": "home", "lat": 45.458, "lon": -122.6636}],
+//     "store": {"dir": "/var/lib/ourwx"},
+//     "prometheus": {"listen": ":9120"},
+//     "webhooks": [{"url": "https://example.com/hook"}],
+//     "notifications": [{"url": "https://example.com/alert", "secret": "s3cret", "zones": ["ORZ006"], "minSeverity": "Severe"}],
+//     "mqtt": {"broker": "localhost:1883", "topicPrefix": "ourwx"}
+//   }
+type Config struct {
+	UserAgent string           `json:"userAgent"`
+	Interval  Duration         `json:"interval"`
+	Locations []LocationConfig `json:"locations"`
+	Store     *StoreConfig     `json:"store"`
+
+	Prometheus    *PrometheusConfig    `json:"prometheus"`
+	Webhooks      []WebhookConfig      `json:"webhooks"`
+	Notifications []NotificationConfig `json:"notifications"`
+	MQTT          *MQTTConfig          `json:"mqtt"`
+}
+
+// A LocationConfig is a named location to poll.
+type LocationConfig struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// A StoreConfig configures recording the data retrieved for each location in
+// an ourwx.FileStore, in a subdirectory of Dir named for the location.
+type StoreConfig struct {
+	Dir string `json:"dir"`
+}
+
+// A PrometheusConfig configures serving metrics. See package exporter.
+type PrometheusConfig struct {
+	Listen string `json:"listen"` // e.g. ":9120"
+	Path   string `json:"path"`   // "/metrics" if empty
+}
+
+// A Duration is a time.Duration that is a string such as "5m" in JSON.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads a Config from a JSON file, or from a TOML file if its name
+// ends in ".toml", and validates it. TOML files use the same keys as JSON:
+//
+//   userAgent = "(myweatherapp.com, contact@myweatherapp.com)"
+//   interval = "5m"
+//
+//   [[locations]]
+//   name = "home"
+//   lat = 45.458
+//   lon = -122.6636
+//
+//   [mqtt]
+//   broker = "localhost:1883"
+//
+// Only the parts of TOML needed for a Config are supported (see parseTOML),
+// so no TOML library is required.
+func LoadConfig(name string) (*Config, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(name), ".toml") {
+		m, err := parseTOML(string(b))
+		if err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(m); err != nil {
+			return nil, err
+		}
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate returns an error if the Config is incomplete.
+func (cfg *Config) validate() error {
+	if cfg.UserAgent == "" {
+		return errors.New("userAgent must not be empty")
+	}
+	if len(cfg.Locations) == 0 {
+		return errors.New("at least one location is required")
+	}
+	seen := make(map[string]bool)
+	for _, l := range cfg.Locations {
+		if l.Name == "" {
+			return errors.New("each location must have a name")
+		}
+		if seen[l.Name] {
+			return fmt.Errorf("duplicate location name: \"%s\"", l.Name)
+		}
+		seen[l.Name] = true
+	}
+	if cfg.Store != nil && cfg.Store.Dir == "" {
+		return errors.New("store.dir must not be empty")
+	}
+	if cfg.Prometheus != nil && cfg.Prometheus.Listen == "" {
+		return errors.New("prometheus.listen must not be empty")
+	}
+	for _, w := range cfg.Webhooks {
+		if w.URL == "" {
+			return errors.New("each webhook must have a url")
+		}
+	}
+	for _, n := range cfg.Notifications {
+		if n.URL == "" {
+			return errors.New("each notification must have a url")
+		}
+	}
+	if cfg.MQTT != nil && cfg.MQTT.Broker == "" {
+		return errors.New("mqtt.broker must not be empty")
+	}
+	return nil
+}
+
+// An Update is published to sinks after each poll of a location.
+type Update struct {
+	Location          string
+	Time              time.Time
+	CurrentConditions *nws.Observation
+	ActiveAlerts      []nws.Alert
+	AlertChanges      []nws.AlertChange // since the previous poll; none on the first
+}
+
+// A Sink receives updates.
+type Sink interface {
+	Publish(ctx context.Context, u Update) error
+}
+
+// A Daemon polls its locations on a schedule and publishes updates to its
+// sinks.
+type Daemon struct {
+	cfg       Config
+	locations []exporter.Location
+	sinks     []Sink
+	exporter  *exporter.Exporter
+
+	// Logger receives errors from polls and sinks. The standard logger is
+	// used if it is nil.
+	Logger *log.Logger
+
+	mu     sync.Mutex
+	alerts map[string][]nws.Alert // the active alerts as of the last poll; see alertChanges
+}
+
+// New returns a Daemon for a Config, creating a client for each location.
+// Responses are shared between the locations' clients and cached according
+// to the API's Cache-Control headers, so polling more often than the data
+// change doesn't make more requests.
+func New(cfg Config) (*Daemon, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = Duration(defaultInterval)
+	}
+
+	d := &Daemon{cfg: cfg, alerts: make(map[string][]nws.Alert)}
+	cache := nws.NewMemoryCache()
+	for _, l := range cfg.Locations {
+		// each client gets its own http.Client since nws.Client.Use
+		// modifies its transport
+		httpClient := &http.Client{Transport: nws.CacheMiddleware(cache, defaultCacheTTL)(http.DefaultTransport)}
+		c, err := ourwx.NewClient(httpClient, cfg.UserAgent, l.Lat, l.Lon)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", l.Name, err)
+		}
+		if err := c.SetPollingProfile(ourwx.PollingProfile{
+			Interval: time.Duration(cfg.Interval),
+			Sources:  []string{ourwx.CapabilityCurrentConditions, ourwx.CapabilityActiveAlerts},
+		}); err != nil {
+			return nil, err
+		}
+		if cfg.Store != nil {
+			s, err := ourwx.NewFileStore(filepath.Join(cfg.Store.Dir, l.Name))
+			if err != nil {
+				return nil, err
+			}
+			c.SetStore(s)
+		}
+		d.locations = append(d.locations, exporter.Location{Name: l.Name, Client: c})
+	}
+
+	if cfg.Prometheus != nil {
+		e, err := exporter.New(d.locations, time.Duration(cfg.Interval))
+		if err != nil {
+			return nil, err
+		}
+		d.exporter = e
+	}
+	for _, w := range cfg.Webhooks {
+		d.sinks = append(d.sinks, NewWebhookSink(w))
+	}
+	for _, n := range cfg.Notifications {
+		s, err := NewAlertNotifier(n)
+		if err != nil {
+			return nil, err
+		}
+		d.sinks = append(d.sinks, s)
+	}
+	if cfg.MQTT != nil {
+		d.sinks = append(d.sinks, NewMQTTSink(*cfg.MQTT))
+	}
+	return d, nil
+}
+
+// Run polls every location immediately and then once per interval, serving
+// metrics if configured, until the context is done (e.g. on a signal). It
+// returns the context's error, or an error if metrics can't be served.
+func (d *Daemon) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errc := make(chan error, 1)
+
+	if d.exporter != nil {
+		path := d.cfg.Prometheus.Path
+		if path == "" {
+			path = "/metrics"
+		}
+		mux := http.NewServeMux()
+		mux.Handle(path, d.exporter)
+		srv := &http.Server{Addr: d.cfg.Prometheus.Listen, Handler: mux}
+		go func() {
+			if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+				errc <- err
+				cancel()
+			}
+		}()
+		defer srv.Close()
+	}
+
+	tick := time.NewTicker(time.Duration(d.cfg.Interval))
+	defer tick.Stop()
+	for {
+		d.poll(ctx)
+		if d.exporter != nil {
+			d.exporter.Refresh(ctx) // from the clients' caches
+		}
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			select {
+			case err := <-errc:
+				return err
+			default:
+			}
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
+
+// poll polls every location and publishes the updates.
+func (d *Daemon) poll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, l := range d.locations {
+		wg.Add(1)
+		go func(l exporter.Location) {
+			defer wg.Done()
+			b, err := l.Client.Bundle(ctx, ourwx.BundleOptions{
+				Sources:  []string{ourwx.CapabilityCurrentConditions, ourwx.CapabilityActiveAlerts},
+				Deadline: time.Duration(d.cfg.Interval),
+			})
+			if err != nil {
+				d.logf("%s: %s", l.Name, err)
+			}
+			now := time.Now()
+			u := Update{Location: l.Name, Time: now, CurrentConditions: b.CurrentConditions, ActiveAlerts: b.ActiveAlerts}
+
+			var me *ourwx.MultiError
+			if err == nil || (errors.As(err, &me) && me.Failed(ourwx.CapabilityActiveAlerts) == nil) {
+				u.AlertChanges = d.alertChanges(l.Name, b.ActiveAlerts, now)
+			}
+
+			for _, s := range d.sinks {
+				if err := s.Publish(ctx, u); err != nil {
+					d.logf("%s: %s", l.Name, err)
+				}
+			}
+		}(l)
+	}
+	wg.Wait()
+}
+
+// alertChanges records the active alerts for a location and returns how they
+// changed since the previous poll. The first successful poll of a location
+// only records them: the daemon doesn't know what was active before it
+// started, and reporting every alert as new would notify them again after
+// each restart.
+func (d *Daemon) alertChanges(location string, alerts []nws.Alert, now time.Time) []nws.AlertChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	prev, ok := d.alerts[location]
+	d.alerts[location] = alerts
+	if !ok {
+		return nil
+	}
+	return nws.DiffAlertsAt(prev, alerts, now)
+}
+
+// logf logs an error.
+func (d *Daemon) logf(format string, args ...interface{}) {
+	if d.Logger != nil {
+		d.Logger.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+func TestLoadConfigTOML(t *testing.T) {
+	dir := t.TempDir()
+	jsonName := filepath.Join(dir, "ourwx.json")
+	tomlName := filepath.Join(dir, "ourwx.toml")
+	if err := os.WriteFile(jsonName, []byte(`{
+		"userAgent": "(example.com, contact@example.com)",
+		"interval": "5m",
+		"locations": [{"name": "home", "lat": 45.458, "lon": -122.6636}, {"name": "work", "lat": 45.52, "lon": -122.68}],
+		"notifications": [{"url": "https://example.com/alert", "zones": ["ORZ006"], "minSeverity": "Severe"}],
+		"mqtt": {"broker": "localhost:1883"}
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tomlName, []byte(`userAgent = "(example.com, contact@example.com)"
+interval = "5m"
+
+[[locations]]
+name = "home"
+lat = 45.458
+lon = -122.6636
+
+[[locations]]
+name = "work"
+lat = 45.52
+lon = -122.68
+
+[[notifications]]
+url = "https://example.com/alert"
+zones = ["ORZ006"]
+minSeverity = "Severe"
+
+[mqtt]
+broker = "localhost:1883"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := LoadConfig(jsonName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadConfig(tomlName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestDaemonAlertChanges(t *testing.T) {
+	d := &Daemon{alerts: make(map[string][]nws.Alert)}
+	now := time.Date(2019, 8, 30, 12, 0, 0, 0, time.UTC)
+	heat := nws.Alert{ID: "1", Event: "Heat Advisory", TimeExpires: now.Add(time.Hour)}
+	wind := nws.Alert{ID: "2", Event: "Wind Advisory", TimeExpires: now.Add(time.Hour)}
+
+	if changes := d.alertChanges("home", []nws.Alert{heat}, now); len(changes) != 0 {
+		t.Errorf("first poll: got %d changes; want none", len(changes))
+	}
+	changes := d.alertChanges("home", []nws.Alert{heat, wind}, now)
+	if len(changes) != 1 || changes[0].Alert.ID != "2" {
+		t.Errorf("second poll: got %+v; want the wind advisory added", changes)
+	}
+	if changes := d.alertChanges("work", nil, now); len(changes) != 0 {
+		t.Errorf("first poll of another location: got %d changes; want none", len(changes))
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+const (
+	defaultMQTTTopicPrefix = "ourwx"
+	mqttTimeout            = 10 * time.Second
+	mqttKeepAliveSeconds   = 60
+)
+
+// An MQTTConfig configures an MQTT sink.
+type MQTTConfig struct {
+	Broker      string `json:"broker"`      // host:port
+	ClientID    string `json:"clientID"`    // "ourwx" if empty; see MQTTSink
+	Username    string `json:"username"`    // optional
+	Password    string `json:"password"`    // optional
+	TopicPrefix string `json:"topicPrefix"` // "ourwx" if empty
+}
+
+// An MQTTSink publishes each update to an MQTT 3.1.1 broker as retained
+// messages with QoS 0:
+//
+//   <prefix>/<location>/conditions  the current conditions as JSON
+//   <prefix>/<location>/alerts      the active alerts as a JSON array
+//
+// A connection is made for each update, since updates are minutes apart.
+// Locations are polled at the same time, and a broker drops a session when
+// another connects with the same client ID, so the location's name is
+// appended to ClientID (e.g. "ourwx-home"). Only the parts of the protocol
+// needed to publish are implemented, so no MQTT library is required.
+type MQTTSink struct {
+	cfg MQTTConfig
+}
+
+// NewMQTTSink returns an MQTTSink.
+func NewMQTTSink(cfg MQTTConfig) *MQTTSink {
+	if cfg.ClientID == "" {
+		cfg.ClientID = "ourwx"
+	}
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = defaultMQTTTopicPrefix
+	}
+	return &MQTTSink{cfg: cfg}
+}
+
+// Publish implements Sink.
+func (s *MQTTSink) Publish(ctx context.Context, u Update) error {
+	type message struct {
+		topic   string
+		payload interface{}
+	}
+	var msgs []message
+	prefix := strings.TrimSuffix(s.cfg.TopicPrefix, "/") + "/" + u.Location
+	if u.CurrentConditions != nil {
+		msgs = append(msgs, message{prefix + "/conditions", u.CurrentConditions})
+	}
+	if u.ActiveAlerts != nil || u.AlertChanges != nil {
+		alerts := u.ActiveAlerts
+		if alerts == nil {
+			alerts = []nws.Alert{} // publish an empty array, not null
+		}
+		msgs = append(msgs, message{prefix + "/alerts", alerts})
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.cfg.Broker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(mqttTimeout))
+	w := bufio.NewWriter(conn)
+
+	if err := s.connect(w, conn, s.cfg.ClientID+"-"+u.Location); err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		payload, err := json.Marshal(m.payload)
+		if err != nil {
+			return err
+		}
+		// PUBLISH, QoS 0, retained
+		var p []byte
+		p = appendMQTTString(p, m.topic)
+		p = append(p, payload...)
+		writeMQTTPacket(w, 0x31, p)
+	}
+	writeMQTTPacket(w, 0xe0, nil) // DISCONNECT
+	return w.Flush()
+}
+
+// connect sends CONNECT with a client ID and waits for a successful CONNACK.
+func (s *MQTTSink) connect(w *bufio.Writer, r io.Reader, clientID string) error {
+	var p []byte
+	p = appendMQTTString(p, "MQTT")
+	flags := byte(0x02) // clean session
+	if s.cfg.Username != "" {
+		flags |= 0x80
+		if s.cfg.Password != "" {
+			flags |= 0x40
+		}
+	}
+	p = append(p, 4, flags, 0, mqttKeepAliveSeconds) // protocol level 4 (3.1.1)
+	p = appendMQTTString(p, clientID)
+	if s.cfg.Username != "" {
+		p = appendMQTTString(p, s.cfg.Username)
+		if s.cfg.Password != "" {
+			p = appendMQTTString(p, s.cfg.Password)
+		}
+	}
+	writeMQTTPacket(w, 0x10, p)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	connack := make([]byte, 4)
+	if _, err := io.ReadFull(r, connack); err != nil {
+		return err
+	}
+	if connack[0] != 0x20 || connack[1] != 2 {
+		return errors.New("mqtt: malformed CONNACK")
+	}
+	if connack[3] != 0 {
+		return fmt.Errorf("mqtt: connection refused: code %d", connack[3])
+	}
+	return nil
+}
+
+// writeMQTTPacket writes a packet with a fixed header. Errors are reported by
+// the writer's Flush.
+func writeMQTTPacket(w *bufio.Writer, header byte, body []byte) {
+	w.WriteByte(header)
+	// remaining length, seven bits at a time
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		w.WriteByte(b)
+		if n == 0 {
+			break
+		}
+	}
+	w.Write(body)
+}
+
+// appendMQTTString appends a length-prefixed UTF-8 string.
+func appendMQTTString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)>>8), byte(len(s)))
+	return append(b, s...)
+}
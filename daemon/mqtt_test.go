@@ -0,0 +1,108 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// TestMQTTSinkClientIDs publishes updates for two locations at once and checks
+// that they connect with different client IDs, so a broker doesn't drop one
+// session for the other.
+func TestMQTTSinkClientIDs(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var mu sync.Mutex
+	var clientIDs []string
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				body, err := readMQTTPacket(r)
+				if err != nil {
+					return
+				}
+				// protocol name (6), level, flags, keep alive (2), client ID
+				n := int(body[10])<<8 | int(body[11])
+				mu.Lock()
+				clientIDs = append(clientIDs, string(body[12:12+n]))
+				mu.Unlock()
+				conn.Write([]byte{0x20, 2, 0, 0}) // CONNACK
+				io.Copy(io.Discard, r)
+			}(conn)
+		}
+	}()
+
+	s := NewMQTTSink(MQTTConfig{Broker: ln.Addr().String()})
+	var wg sync.WaitGroup
+	for _, location := range []string{"home", "work"} {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+			u := Update{Location: location, Time: time.Now(), ActiveAlerts: []nws.Alert{}}
+			if err := s.Publish(context.Background(), u); err != nil {
+				t.Error(err)
+			}
+		}(location)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(clientIDs)
+	if len(clientIDs) != 2 || clientIDs[0] != "ourwx-home" || clientIDs[1] != "ourwx-work" {
+		t.Errorf("got client IDs %q; want [ourwx-home ourwx-work]", clientIDs)
+	}
+}
+
+// readMQTTPacket reads a packet and returns its body.
+func readMQTTPacket(r *bufio.Reader) ([]byte, error) {
+	if _, err := r.ReadByte(); err != nil {
+		return nil, err
+	}
+	n, mult := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		n += int(b&0x7f) * mult
+		if b&0x80 == 0 {
+			break
+		}
+		mult *= 128
+	}
+	body := make([]byte, n)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A NotificationConfig configures an alert notifier.
+type NotificationConfig struct {
+	URL     string   `json:"url"`
+	Timeout Duration `json:"timeout"` // 10s if zero
+
+	// Secret, if set, is used to sign each request body. The signature is
+	// sent in the X-Ourwx-Signature header.
+	Secret string `json:"secret"`
+
+	// Zones limits notifications to alerts covering at least one of these
+	// forecast or county zones, such as "ORZ006". All alerts for the
+	// location are notified if it is empty.
+	Zones []string `json:"zones"`
+
+	// MinSeverity is the least severe alert notified, one of the keys of
+	// nws.AlertSeverities. All alerts are notified if it is empty.
+	MinSeverity string `json:"minSeverity"`
+
+	// Urgencies and Events limit notifications to alerts with one of these
+	// urgencies (keys of nws.AlertUrgencies) or events (e.g. "Winter Storm
+	// Warning"). All alerts are notified if they are empty.
+	Urgencies []string `json:"urgencies"`
+	Events    []string `json:"events"`
+}
+
+// A Notification is posted for each new or updated alert.
+type Notification struct {
+	Location string
+	Time     time.Time
+	Type     nws.AlertChangeType // nws.AlertAdded or nws.AlertUpdated
+	Alert    nws.Alert
+}
+
+// An AlertNotifier posts a Notification as JSON to a URL when an alert is
+// issued or updated, such as when a watch is upgraded to a warning. Alerts
+// that are cancelled, expire, or are removed are not notified.
+type AlertNotifier struct {
+	cfg        NotificationConfig
+	filter     nws.AlertFilter
+	httpClient *http.Client
+}
+
+// NewAlertNotifier returns an AlertNotifier.
+func NewAlertNotifier(cfg NotificationConfig) (*AlertNotifier, error) {
+	filter := nws.AlertFilter{
+		MinSeverity: cfg.MinSeverity,
+		Urgencies:   cfg.Urgencies,
+		Events:      cfg.Events,
+		Zones:       cfg.Zones,
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &AlertNotifier{cfg: cfg, filter: filter, httpClient: &http.Client{Timeout: timeout}}, nil
+}
+
+// Publish implements Sink. Each matching alert change is posted separately;
+// the first error stops the rest.
+func (n *AlertNotifier) Publish(ctx context.Context, u Update) error {
+	for _, c := range u.AlertChanges {
+		if !n.matches(c) {
+			continue
+		}
+		notification := Notification{Location: u.Location, Time: u.Time, Type: c.Type, Alert: c.Alert}
+		if err := postJSON(ctx, n.httpClient, n.cfg.URL, n.cfg.Secret, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether an alert change should be notified.
+func (n *AlertNotifier) matches(c nws.AlertChange) bool {
+	if c.Type != nws.AlertAdded && c.Type != nws.AlertUpdated {
+		return false
+	}
+	return n.filter.Matches(c.Alert)
+}
@@ -0,0 +1,338 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML parses the subset of TOML used by configuration files into maps,
+// slices, strings, int64s, float64s, and bools, so that it can be re-encoded
+// as JSON and decoded into a Config. Tables, arrays of tables, bare and quoted
+// keys, basic and literal strings, integers, floats, booleans, and arrays
+// (which may span lines) are supported. Dotted keys, inline tables,
+// multi-line strings, and dates are not.
+func parseTOML(s string) (map[string]interface{}, error) {
+	p := &tomlParser{s: s, line: 1}
+	root := make(map[string]interface{})
+	cur := root
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			cur, err = p.parseTableHeader(root)
+		} else {
+			err = p.parseKeyValue(cur)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+			return nil, p.errorf("unexpected %q at end of line", p.peek())
+		}
+	}
+}
+
+// tomlParser holds the position of a parseTOML call.
+type tomlParser struct {
+	s    string
+	i    int
+	line int
+}
+
+func (p *tomlParser) eof() bool  { return p.i >= len(p.s) }
+func (p *tomlParser) peek() byte { return p.s[p.i] }
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips spaces, tabs, and comments, and newlines too if newlines is
+// set.
+func (p *tomlParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t':
+			p.i++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.i++
+			}
+		case newlines && (c == '\n' || c == '\r'):
+			if c == '\n' {
+				p.line++
+			}
+			p.i++
+		default:
+			return
+		}
+	}
+}
+
+// parseTableHeader parses a [table] or [[array of tables]] header and returns
+// the table that the following keys belong to.
+func (p *tomlParser) parseTableHeader(root map[string]interface{}) (map[string]interface{}, error) {
+	p.i++ // [
+	array := !p.eof() && p.peek() == '['
+	if array {
+		p.i++
+	}
+	var path []string
+	for {
+		p.skipSpace(false)
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		p.skipSpace(false)
+		if p.eof() {
+			return nil, p.errorf("unterminated table header")
+		}
+		if p.peek() == '.' {
+			p.i++
+			continue
+		}
+		break
+	}
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.i:], closing) {
+		return nil, p.errorf("expected %q", closing)
+	}
+	p.i += len(closing)
+
+	t := root
+	for i, key := range path {
+		last := i == len(path)-1
+		switch v := t[key].(type) {
+		case nil:
+			if last && array {
+				next := make(map[string]interface{})
+				t[key] = []interface{}{next}
+				return next, nil
+			}
+			next := make(map[string]interface{})
+			t[key] = next
+			t = next
+		case map[string]interface{}:
+			if last && array {
+				return nil, p.errorf("%q is a table, not an array of tables", key)
+			}
+			t = v
+		case []interface{}:
+			tables, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("%q is not a table", key)
+			}
+			if last && array {
+				next := make(map[string]interface{})
+				t[key] = append(v, next)
+				return next, nil
+			}
+			t = tables
+		default:
+			return nil, p.errorf("%q is not a table", key)
+		}
+	}
+	return t, nil
+}
+
+// parseKeyValue parses a key = value pair into t.
+func (p *tomlParser) parseKeyValue(t map[string]interface{}) error {
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if p.eof() || p.peek() != '=' {
+		return p.errorf("expected \"=\" after %q", key)
+	}
+	p.i++
+	p.skipSpace(false)
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if _, ok := t[key]; ok {
+		return p.errorf("duplicate key %q", key)
+	}
+	t[key] = v
+	return nil
+}
+
+// parseKey parses a bare or quoted key.
+func (p *tomlParser) parseKey() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected a key")
+	}
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.parseString()
+	}
+	start := p.i
+	for !p.eof() {
+		c := p.peek()
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			break
+		}
+		p.i++
+	}
+	if p.i == start {
+		return "", p.errorf("expected a key, found %q", p.peek())
+	}
+	return p.s[start:p.i], nil
+}
+
+// parseValue parses a string, number, boolean, or array.
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case strings.HasPrefix(p.s[p.i:], "true"):
+		p.i += len("true")
+		return true, nil
+	case strings.HasPrefix(p.s[p.i:], "false"):
+		p.i += len("false")
+		return false, nil
+	}
+
+	start := p.i
+	for !p.eof() && strings.IndexByte("+-0123456789._eE", p.peek()) >= 0 {
+		p.i++
+	}
+	num := strings.ReplaceAll(p.s[start:p.i], "_", "")
+	if num == "" {
+		return nil, p.errorf("unsupported value starting with %q", p.peek())
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", num)
+	}
+	return f, nil
+}
+
+// parseArray parses an array, which may span lines and contain comments.
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.i++ // [
+	a := []interface{}{}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.i++
+			return a, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.i++
+		case ']':
+		default:
+			return nil, p.errorf("expected \",\" or \"]\" in array, found %q", p.peek())
+		}
+	}
+}
+
+// parseString parses a basic ("...") or literal ('...') string on one line.
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.peek()
+	p.i++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		p.i++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && quote == '"':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// parseEscape parses the escape sequence following a backslash in a basic
+// string.
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+	c := p.peek()
+	p.i++
+	switch c {
+	case '"', '\\':
+		b.WriteByte(c)
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.i+n > len(p.s) {
+			return p.errorf("invalid unicode escape")
+		}
+		r, err := strconv.ParseUint(p.s[p.i:p.i+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.errorf("invalid unicode escape")
+		}
+		p.i += n
+		b.WriteRune(rune(r))
+	default:
+		return p.errorf("invalid escape \"\\%c\"", c)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "scalars",
+			in: `# a comment
+s = "x\ty\u00e9" # a trailing comment
+'l' = 'C:\path'
+i = -1_000
+f = 45.458
+b = true
+`,
+			want: map[string]interface{}{"s": "x\ty\u00e9", "l": `C:\path`, "i": int64(-1000), "f": 45.458, "b": true},
+		},
+		{
+			name: "tables and arrays",
+			in: `
+zones = [
+  "ORZ006", # Portland
+  "ORZ007",
+]
+
+[mqtt]
+broker = "localhost:1883"
+
+[[locations]]
+name = "home"
+
+[[locations]]
+name = "work"
+`,
+			want: map[string]interface{}{
+				"zones": []interface{}{"ORZ006", "ORZ007"},
+				"mqtt":  map[string]interface{}{"broker": "localhost:1883"},
+				"locations": []interface{}{
+					map[string]interface{}{"name": "home"},
+					map[string]interface{}{"name": "work"},
+				},
+			},
+		},
+		{name: "duplicate key", in: "a = 1\na = 2\n", wantErr: true},
+		{name: "unterminated string", in: "a = \"x\n", wantErr: true},
+		{name: "trailing garbage", in: "a = 1 2\n", wantErr: true},
+		{name: "table redefined as array", in: "[a]\n[[a]]\n", wantErr: true},
+		{name: "inline table", in: "a = {b = 1}\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v; want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v; want %#v", got, tt.want)
+			}
+		})
+	}
+}
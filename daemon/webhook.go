@@ -0,0 +1,110 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+
+	// webhookSignatureHeader carries the HMAC-SHA256 of the request body,
+	// keyed with the webhook's secret, as "sha256=" followed by lowercase hex.
+	webhookSignatureHeader = "X-Ourwx-Signature"
+)
+
+// A WebhookConfig configures a webhook sink.
+type WebhookConfig struct {
+	URL     string   `json:"url"`
+	Timeout Duration `json:"timeout"` // 10s if zero
+
+	// Secret, if set, is used to sign each request body. The signature is
+	// sent in the X-Ourwx-Signature header.
+	Secret string `json:"secret"`
+
+	// AlertChangesOnly skips updates without alert changes, so that the
+	// webhook is only called when alerts are issued, updated, or end.
+	AlertChangesOnly bool `json:"alertChangesOnly"`
+}
+
+// A WebhookSink posts each update as JSON to a URL.
+type WebhookSink struct {
+	cfg        WebhookConfig
+	httpClient *http.Client
+}
+
+// NewWebhookSink returns a WebhookSink.
+func NewWebhookSink(cfg WebhookConfig) *WebhookSink {
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookSink{cfg: cfg, httpClient: &http.Client{Timeout: timeout}}
+}
+
+// Publish implements Sink. Any non-2xx response is an error.
+func (s *WebhookSink) Publish(ctx context.Context, u Update) error {
+	if s.cfg.AlertChangesOnly && len(u.AlertChanges) == 0 {
+		return nil
+	}
+	return postJSON(ctx, s.httpClient, s.cfg.URL, s.cfg.Secret, u)
+}
+
+// postJSON posts v as JSON to url, signing the body if secret is not empty.
+// Any non-2xx response is an error.
+func postJSON(ctx context.Context, httpClient *http.Client, url string, secret string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(webhookSignatureHeader, "sha256="+signBody(secret, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// signBody returns the hex encoded HMAC-SHA256 of body keyed with secret.
+func signBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
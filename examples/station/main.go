@@ -0,0 +1,190 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command station is a reference program that wires together the packages in
+// this repository for one or more locations, from a single config file:
+//
+//   - an ourwx.Client per location (package ourwx), whose NWS requests share
+//     a response cache (package nws)
+//   - a history store per location (ourwx.FileStore)
+//   - Prometheus metrics (package exporter)
+//   - alert notifications, webhooks, and MQTT (package daemon's sinks)
+//
+// It does by hand what daemon.New and Daemon.Run do, so that each step is
+// visible. The config file is a daemon.Config; see station.json.
+//
+//   go run ./examples/station --config examples/station/station.json
+//
+// To run the same configuration in production, use `ourwx daemon`.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/mikecamilleri/our-data/daemon"
+	"github.com/mikecamilleri/our-data/exporter"
+	"github.com/mikecamilleri/our-data/nws"
+	"github.com/mikecamilleri/our-data/ourwx"
+)
+
+// sources are the data polled for each location.
+var sources = []string{ourwx.CapabilityCurrentConditions, ourwx.CapabilityActiveAlerts}
+
+func main() {
+	config := flag.String("config", "", "path to a JSON config file (see package daemon)")
+	flag.Parse()
+	if err := run(*config); err != nil {
+		fmt.Fprintln(os.Stderr, "station:", err)
+		os.Exit(1)
+	}
+}
+
+// run wires the subsystems together and polls until interrupted.
+func run(config string) error {
+	if config == "" {
+		return errors.New("--config is required")
+	}
+
+	// 1. Read and validate the config.
+	cfg, err := daemon.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(cfg.Interval)
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	// 2. Create a client for each location. Each gets its own http.Client,
+	// since nws.Client.Use modifies its transport, but they share a cache so
+	// that nearby locations don't request the same data twice.
+	cache := nws.NewMemoryCache()
+	var locations []exporter.Location
+	for _, l := range cfg.Locations {
+		httpClient := &http.Client{Transport: nws.CacheMiddleware(cache, time.Minute)(http.DefaultTransport)}
+		c, err := ourwx.NewClient(httpClient, cfg.UserAgent, l.Lat, l.Lon)
+		if err != nil {
+			return fmt.Errorf("%s: %s", l.Name, err)
+		}
+
+		// 3. Record everything the client retrieves.
+		if cfg.Store != nil {
+			s, err := ourwx.NewFileStore(filepath.Join(cfg.Store.Dir, l.Name))
+			if err != nil {
+				return err
+			}
+			c.SetStore(s)
+		}
+
+		log.Printf("%s: gridpoint %s, station %s", l.Name, c.NWS().Gridpoint(), c.NWS().DefaultStationID())
+		locations = append(locations, exporter.Location{Name: l.Name, Client: c})
+	}
+
+	// 4. Create the sinks that receive each poll's update.
+	var sinks []daemon.Sink
+	for _, w := range cfg.Webhooks {
+		sinks = append(sinks, daemon.NewWebhookSink(w))
+	}
+	for _, n := range cfg.Notifications {
+		s, err := daemon.NewAlertNotifier(n)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, s)
+	}
+	if cfg.MQTT != nil {
+		sinks = append(sinks, daemon.NewMQTTSink(*cfg.MQTT))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 5. Serve metrics. The exporter reads from the clients, which are
+	// refreshed by the polls below rather than by the exporter itself.
+	var exp *exporter.Exporter
+	if cfg.Prometheus != nil {
+		if exp, err = exporter.New(locations, interval); err != nil {
+			return err
+		}
+		path := cfg.Prometheus.Path
+		if path == "" {
+			path = "/metrics"
+		}
+		mux := http.NewServeMux()
+		mux.Handle(path, exp)
+		srv := &http.Server{Addr: cfg.Prometheus.Listen, Handler: mux}
+		go func() {
+			if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+				log.Print(err)
+				stop()
+			}
+		}()
+		defer srv.Close()
+	}
+
+	// 6. Poll each location once per interval, publishing an update with the
+	// alerts that changed since the previous poll.
+	alerts := make(map[string][]nws.Alert)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		for _, l := range locations {
+			poll(ctx, l, sinks, alerts)
+		}
+		if exp != nil {
+			exp.Refresh(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+	}
+}
+
+// poll retrieves the sources for a location and publishes the update to each
+// sink. alerts holds the active alerts for each location as of its previous
+// poll.
+func poll(ctx context.Context, l exporter.Location, sinks []daemon.Sink, alerts map[string][]nws.Alert) {
+	b, err := l.Client.Bundle(ctx, ourwx.BundleOptions{Sources: sources})
+	if err != nil {
+		log.Printf("%s: %s", l.Name, err)
+	}
+	now := time.Now()
+	u := daemon.Update{Location: l.Name, Time: now, CurrentConditions: b.CurrentConditions, ActiveAlerts: b.ActiveAlerts}
+
+	// only diff the alerts if they were retrieved, so that a failed request
+	// isn't reported as every alert having been cancelled
+	var me *ourwx.MultiError
+	if err == nil || (errors.As(err, &me) && me.Failed(ourwx.CapabilityActiveAlerts) == nil) {
+		u.AlertChanges = nws.DiffAlertsAt(alerts[l.Name], b.ActiveAlerts, now)
+		alerts[l.Name] = b.ActiveAlerts
+	}
+
+	for _, s := range sinks {
+		if err := s.Publish(ctx, u); err != nil {
+			log.Printf("%s: %s", l.Name, err)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exporter serves current weather data for one or more locations as
+// Prometheus metrics.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+	"github.com/mikecamilleri/our-data/ourwx"
+)
+
+// contentType is the content type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// A Location is a named location to export metrics for. The name is used as
+// the value of the "location" label.
+type Location struct {
+	Name   string
+	Client *ourwx.Client
+}
+
+// A gauge describes one exported metric.
+type gauge struct {
+	name string
+	help string
+	// value returns the metric's value for a location, or false if it is
+	// missing
+	value func(s *state) (float64, bool)
+}
+
+// gauges are the exported metrics, in the order they are written.
+var gauges = []gauge{
+	{"ourwx_up", "Whether the last refresh of the location succeeded (1) or not (0).", func(s *state) (float64, bool) {
+		if s.err != nil {
+			return 0, true
+		}
+		return 1, true
+	}},
+	{"ourwx_temperature_celsius", "Observed air temperature.", func(s *state) (float64, bool) {
+		return obsValue(s, func(o nws.Observation) nws.ValueUnit { return o.Temperature }, "C")
+	}},
+	{"ourwx_dewpoint_celsius", "Observed dew point.", func(s *state) (float64, bool) {
+		return obsValue(s, func(o nws.Observation) nws.ValueUnit { return o.Dewpoint }, "C")
+	}},
+	{"ourwx_relative_humidity_percent", "Observed relative humidity.", func(s *state) (float64, bool) {
+		return obsValue(s, func(o nws.Observation) nws.ValueUnit { return o.RelativeHumidity }, "percent")
+	}},
+	{"ourwx_wind_speed_meters_per_second", "Observed sustained wind speed.", func(s *state) (float64, bool) {
+		return obsValue(s, func(o nws.Observation) nws.ValueUnit { return o.WindSpeed }, "m/s")
+	}},
+	{"ourwx_wind_gust_meters_per_second", "Observed wind gust speed.", func(s *state) (float64, bool) {
+		return obsValue(s, func(o nws.Observation) nws.ValueUnit { return o.WindGust }, "m/s")
+	}},
+	{"ourwx_barometric_pressure_pascals", "Observed station pressure.", func(s *state) (float64, bool) {
+		return obsValue(s, func(o nws.Observation) nws.ValueUnit { return o.BarometricPressure }, "Pa")
+	}},
+	{"ourwx_observation_timestamp_seconds", "Time of the observation as a Unix timestamp.", func(s *state) (float64, bool) {
+		if s.obs == nil || s.obs.TimeObserved.IsZero() {
+			return 0, false
+		}
+		return float64(s.obs.TimeObserved.Unix()), true
+	}},
+	{"ourwx_active_alerts", "Number of active alerts.", func(s *state) (float64, bool) {
+		if !s.alertsOK {
+			return 0, false
+		}
+		return float64(s.alerts), true
+	}},
+}
+
+// state is the most recently retrieved data for a location.
+type state struct {
+	obs      *nws.Observation
+	alerts   int
+	alertsOK bool
+	err      error
+}
+
+// An Exporter refreshes weather data for its locations and serves it as
+// Prometheus metrics. It implements http.Handler.
+type Exporter struct {
+	locations []Location
+	interval  time.Duration
+
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+// New returns an Exporter for a set of locations, each of which must have a
+// unique, non-empty name. Data are refreshed every interval by Run.
+func New(locations []Location, interval time.Duration) (*Exporter, error) {
+	if len(locations) == 0 {
+		return nil, errors.New("at least one location is required")
+	}
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	seen := make(map[string]bool)
+	for _, l := range locations {
+		if l.Name == "" || l.Client == nil {
+			return nil, errors.New("each location must have a name and a client")
+		}
+		if seen[l.Name] {
+			return nil, fmt.Errorf("duplicate location name: \"%s\"", l.Name)
+		}
+		seen[l.Name] = true
+	}
+	return &Exporter{
+		locations: locations,
+		interval:  interval,
+		states:    make(map[string]*state),
+	}, nil
+}
+
+// Run refreshes the data immediately and then every interval until the
+// context is done, returning the context's error.
+func (e *Exporter) Run(ctx context.Context) error {
+	tick := time.NewTicker(e.interval)
+	defer tick.Stop()
+	for {
+		e.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
+
+// Refresh retrieves the current conditions and active alerts for every
+// location. Data that can't be retrieved are reported as missing and the
+// location's ourwx_up gauge is 0.
+func (e *Exporter) Refresh(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, l := range e.locations {
+		wg.Add(1)
+		go func(l Location) {
+			defer wg.Done()
+			b, err := l.Client.Bundle(ctx, ourwx.BundleOptions{
+				Sources:  []string{ourwx.CapabilityCurrentConditions, ourwx.CapabilityActiveAlerts},
+				Deadline: e.interval,
+			})
+			s := &state{obs: b.CurrentConditions, alerts: len(b.ActiveAlerts), err: err}
+			var me *ourwx.MultiError
+			s.alertsOK = err == nil || (errors.As(err, &me) && me.Failed(ourwx.CapabilityActiveAlerts) == nil)
+			e.mu.Lock()
+			e.states[l.Name] = s
+			e.mu.Unlock()
+		}(l)
+	}
+	wg.Wait()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if err := e.WriteMetrics(w); err != nil {
+		// the status has been sent, so the error can only be logged
+		log.Printf("exporter: writing metrics: %s", err)
+	}
+}
+
+// WriteMetrics writes the metrics in the Prometheus text exposition format.
+// Locations that have not been refreshed yet are omitted.
+func (e *Exporter) WriteMetrics(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var names []string
+	for name := range e.states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, g := range gauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+		for _, name := range names {
+			v, ok := g.value(e.states[name])
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "%s{location=\"%s\"} %s\n", g.name, escapeLabelValue(name), strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// obsValue returns a value from the observation of a location converted to a
+// unit, or false if it is missing or can't be converted.
+func obsValue(s *state, field func(nws.Observation) nws.ValueUnit, unit string) (float64, bool) {
+	if s.obs == nil {
+		return 0, false
+	}
+	vu, err := field(*s.obs).Convert(unit)
+	if err != nil {
+		return 0, false
+	}
+	return vu.Value, !math.IsNaN(vu.Value)
+}
+
+// escapeLabelValue escapes a label value as required by the exposition
+// format.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+func TestWriteMetricsConvertsUnits(t *testing.T) {
+	e := &Exporter{states: map[string]*state{
+		"home": {obs: &nws.Observation{
+			Temperature: nws.ValueUnit{Value: 50, Unit: "F"},
+			WindSpeed:   nws.ValueUnit{Value: 10, Unit: "kt"},
+			WindGust:    nws.ValueUnit{Value: 20, Unit: "furlongs"},
+		}},
+	}}
+	var b strings.Builder
+	if err := e.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`ourwx_temperature_celsius{location="home"} 10` + "\n",
+		`ourwx_wind_speed_meters_per_second{location="home"} 5.144444444444445` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `ourwx_wind_gust_meters_per_second{`) {
+		t.Errorf("unconvertible wind gust exported:\n%s", b.String())
+	}
+}
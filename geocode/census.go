@@ -0,0 +1,97 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geocode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultCensusURLString = "https://geocoding.geo.census.gov/geocoder/locations/onelineaddress"
+
+// Census geocodes street addresses using the U.S. Census Bureau geocoder. It
+// doesn't require an API key, but it only matches addresses (e.g. "1600
+// Pennsylvania Ave NW, Washington, DC"), not bare city names; use Nominatim
+// for those.
+type Census struct {
+	httpClient          *http.Client
+	httpUserAgentString string
+	urlString           string
+}
+
+// NewCensus returns a Census geocoder.
+func NewCensus(httpClient *http.Client, httpUserAgentString string) (*Census, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if strings.TrimSpace(httpUserAgentString) == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	return &Census{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		urlString:           defaultCensusURLString,
+	}, nil
+}
+
+// SetURLString sets the URL of the one line address endpoint.
+func (c *Census) SetURLString(urlString string) {
+	c.urlString = urlString
+}
+
+// Geocode implements Geocoder.
+func (c *Census) Geocode(query string) ([]Place, error) {
+	q := url.Values{}
+	q.Set("address", query)
+	q.Set("benchmark", "Public_AR_Current")
+	q.Set("format", "json")
+	respBody, err := doRequest(c.httpClient, c.httpUserAgentString, c.urlString, q)
+	if err != nil {
+		return nil, err
+	}
+	return newPlacesFromCensusRespBody(respBody)
+}
+
+// newPlacesFromCensusRespBody returns a slice of Places, given a response body
+// from the Census geocoder. The coordinates are given as x (longitude) and y
+// (latitude).
+func newPlacesFromCensusRespBody(respBody []byte) ([]Place, error) {
+	resultRaw := struct {
+		Result struct {
+			AddressMatches []struct {
+				MatchedAddress string
+				Coordinates    struct {
+					X float64
+					Y float64
+				}
+			}
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &resultRaw); err != nil {
+		return nil, err
+	}
+
+	var places []Place
+	for _, m := range resultRaw.Result.AddressMatches {
+		places = append(places, Place{Name: m.MatchedAddress, Lat: m.Coordinates.Y, Lon: m.Coordinates.X})
+	}
+	if len(places) == 0 {
+		return nil, ErrNoMatch
+	}
+	return places, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geocode resolves free-text place names, such as "Portland, OR", to
+// coordinates, so that weather data can be configured by place name rather
+// than by latitude and longitude.
+package geocode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// maxRespBodyBytes limits the size of response bodies. Responses list a
+// handful of candidate places.
+const maxRespBodyBytes = 1 << 20
+
+// ErrNoMatch is returned when a geocoder finds no place matching a query.
+var ErrNoMatch = errors.New("no matching place")
+
+// A Place is a location returned by a geocoder. Lat and Lon are decimal WGS 84
+// (EPSG:4326) values.
+type Place struct {
+	Name string // the geocoder's name for the place, such as a matched address
+	Lat  float64
+	Lon  float64
+}
+
+// A Geocoder resolves a free-text query to candidate places, best match first.
+// ErrNoMatch is returned if there are none.
+type Geocoder interface {
+	Geocode(query string) ([]Place, error)
+}
+
+// First returns the best match for a query.
+func First(g Geocoder, query string) (Place, error) {
+	places, err := g.Geocode(query)
+	if err != nil {
+		return Place{}, err
+	}
+	if len(places) == 0 {
+		return Place{}, ErrNoMatch
+	}
+	return places[0], nil
+}
+
+// doRequest makes a GET request and returns the body of a 200 response.
+func doRequest(httpClient *http.Client, httpUserAgentString string, urlString string, query url.Values) ([]byte, error) {
+	req, err := http.NewRequest("GET", urlString, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("User-Agent", httpUserAgentString)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody) > maxRespBodyBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxRespBodyBytes)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, respBody)
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geocode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const defaultNominatimURLString = "https://nominatim.openstreetmap.org/search"
+
+// Nominatim geocodes place names using the OpenStreetMap Nominatim service.
+// Results are limited to the United States.
+//
+// The public service requires a User-Agent identifying your application and
+// allows at most one request per second; see
+// https://operations.osmfoundation.org/policies/nominatim/. Geocode place names
+// once, when configuring, rather than on every poll.
+type Nominatim struct {
+	// Limit is the maximum number of places returned. Five if zero.
+	Limit int
+
+	httpClient          *http.Client
+	httpUserAgentString string
+	urlString           string
+}
+
+// NewNominatim returns a Nominatim geocoder using the public service.
+func NewNominatim(httpClient *http.Client, httpUserAgentString string) (*Nominatim, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if strings.TrimSpace(httpUserAgentString) == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	return &Nominatim{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		urlString:           defaultNominatimURLString,
+	}, nil
+}
+
+// SetURLString sets the URL of the search endpoint, such as for a self-hosted
+// instance.
+func (n *Nominatim) SetURLString(urlString string) {
+	n.urlString = urlString
+}
+
+// Geocode implements Geocoder.
+func (n *Nominatim) Geocode(query string) ([]Place, error) {
+	limit := n.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	q := url.Values{}
+	q.Set("q", query)
+	q.Set("format", "json")
+	q.Set("countrycodes", "us")
+	q.Set("limit", strconv.Itoa(limit))
+	respBody, err := doRequest(n.httpClient, n.httpUserAgentString, n.urlString, q)
+	if err != nil {
+		return nil, err
+	}
+	return newPlacesFromNominatimRespBody(respBody)
+}
+
+// newPlacesFromNominatimRespBody returns a slice of Places, given a response
+// body from Nominatim. Results without valid coordinates are skipped.
+func newPlacesFromNominatimRespBody(respBody []byte) ([]Place, error) {
+	var resultsRaw []struct {
+		DisplayName string `json:"display_name"`
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+	}
+	if err := json.Unmarshal(respBody, &resultsRaw); err != nil {
+		return nil, err
+	}
+
+	var places []Place
+	for _, r := range resultsRaw {
+		lat, err := strconv.ParseFloat(r.Lat, 64)
+		if err != nil {
+			continue
+		}
+		lon, err := strconv.ParseFloat(r.Lon, 64)
+		if err != nil {
+			continue
+		}
+		places = append(places, Place{Name: r.DisplayName, Lat: lat, Lon: lon})
+	}
+	if len(places) == 0 {
+		return nil, ErrNoMatch
+	}
+	return places, nil
+}
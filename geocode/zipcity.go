@@ -0,0 +1,151 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geocode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultZipCityURLString = "https://forecast.weather.gov/zipcity.php"
+
+// zipCodeRegexp matches a five digit zip code, optionally with the ZIP+4
+// suffix.
+var zipCodeRegexp = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+
+// ZipCity geocodes zip codes (e.g. "97202") and "City, ST" strings (e.g.
+// "Portland, OR") using the search box of forecast.weather.gov, which
+// redirects a match to the forecast page for its coordinates. It doesn't
+// require an API key and, unlike Census, matches bare zip codes and city
+// names. Only one place is returned.
+type ZipCity struct {
+	httpClient          *http.Client
+	httpUserAgentString string
+	urlString           string
+}
+
+// NewZipCity returns a ZipCity geocoder.
+func NewZipCity(httpClient *http.Client, httpUserAgentString string) (*ZipCity, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if strings.TrimSpace(httpUserAgentString) == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	return &ZipCity{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		urlString:           defaultZipCityURLString,
+	}, nil
+}
+
+// SetURLString sets the URL of the zipcity.php endpoint.
+func (z *ZipCity) SetURLString(urlString string) {
+	z.urlString = urlString
+}
+
+// Geocode implements Geocoder. The query must be a zip code or a city and
+// two letter state abbreviation separated by a comma.
+func (z *ZipCity) Geocode(query string) ([]Place, error) {
+	query = strings.TrimSpace(query)
+	if !IsZipCode(query) && !isCityState(query) {
+		return nil, fmt.Errorf("query must be a zip code or \"City, ST\": \"%s\"", query)
+	}
+
+	req, err := http.NewRequest("GET", z.urlString, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = url.Values{"inputstring": {query}}.Encode()
+	req.Header.Set("User-Agent", z.httpUserAgentString)
+
+	// the match is in the redirect, so don't follow it
+	httpClient := *z.httpClient
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode > 399 {
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("%s: %s", resp.Status, z.urlString)
+		}
+		return nil, ErrNoMatch // a search results or error page
+	}
+	p, ok := newPlaceFromZipCityLocation(resp.Header.Get("Location"))
+	if !ok {
+		return nil, ErrNoMatch
+	}
+	if p.Name == "" {
+		p.Name = query
+	}
+	return []Place{p}, nil
+}
+
+// newPlaceFromZipCityLocation returns the place in the Location of a zipcity
+// redirect. The coordinates are given either as lat and lon or as textField1
+// and textField2, and the place name, if any, as CityName and state.
+func newPlaceFromZipCityLocation(location string) (Place, bool) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return Place{}, false
+	}
+	q := u.Query()
+	latString, lonString := q.Get("lat"), q.Get("lon")
+	if latString == "" || lonString == "" {
+		latString, lonString = q.Get("textField1"), q.Get("textField2")
+	}
+	lat, err := strconv.ParseFloat(latString, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return Place{}, false
+	}
+	lon, err := strconv.ParseFloat(lonString, 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return Place{}, false
+	}
+
+	p := Place{Lat: lat, Lon: lon}
+	if city := strings.TrimSpace(q.Get("CityName")); city != "" {
+		p.Name = city
+		if state := strings.TrimSpace(q.Get("state")); state != "" {
+			p.Name += ", " + state
+		}
+	}
+	return p, true
+}
+
+// IsZipCode reports whether s is a US zip code (e.g. "97202" or "97202-1234").
+func IsZipCode(s string) bool {
+	return zipCodeRegexp.MatchString(strings.TrimSpace(s))
+}
+
+// isCityState reports whether s looks like "City, ST".
+func isCityState(s string) bool {
+	i := strings.LastIndex(s, ",")
+	if i < 1 {
+		return false
+	}
+	state := strings.TrimSpace(s[i+1:])
+	return strings.TrimSpace(s[:i]) != "" && len(state) == 2
+}
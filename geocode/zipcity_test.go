@@ -0,0 +1,57 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geocode
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// ExampleZipCity shows both forms of the redirect from zipcity.php: a zip code
+// redirects with lat and lon, and a city with textField1, textField2, and the
+// city's name.
+func ExampleZipCity() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("inputstring") {
+		case "97202":
+			http.Redirect(w, r, "https://forecast.weather.gov/MapClick.php?lat=45.4793&lon=-122.6439", http.StatusFound)
+		case "portland, or":
+			http.Redirect(w, r, "https://forecast.weather.gov/MapClick.php?CityName=Portland&state=OR&site=PQR&textField1=45.5118&textField2=-122.6756", http.StatusFound)
+		default:
+			fmt.Fprint(w, "<html><body>Location not found</body></html>")
+		}
+	}))
+	defer srv.Close()
+
+	z, err := NewZipCity(srv.Client(), "example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	z.SetURLString(srv.URL + "/zipcity.php")
+	for _, query := range []string{"97202", "portland, or", "Nowhere, ZZ"} {
+		p, err := First(z, query)
+		if err != nil {
+			fmt.Printf("%s: %s\n", query, err)
+			continue
+		}
+		fmt.Printf("%s: %s (%.4f, %.4f)\n", query, p.Name, p.Lat, p.Lon)
+	}
+	// Output:
+	// 97202: 97202 (45.4793, -122.6439)
+	// portland, or: Portland, OR (45.5118, -122.6756)
+	// Nowhere, ZZ: no matching place
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mikecamilleri/our-data/mock"
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+func ExampleServer() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	c, err := nws.NewClientFromCoordinates(srv.Client(), "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := c.UpdateSemidailyForecast(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, p := range c.SemidailyForecast().Periods {
+		fmt.Printf("%s: %s\n", p.Name, p.ForecastShort)
+	}
+	fmt.Println("points requests:", srv.RequestCount("/points/45.458000,-122.663600"))
+	// Output:
+	// This Afternoon: Sunny
+	// Tonight: Mostly Clear
+	// points requests: 1
+}
+
+func ExampleServer_Handle() {
+	srv := mock.NewServer()
+	defer srv.Close()
+
+	// fail with a server error and then a truncated body, then succeed
+	srv.Handle("/products/types/AFD",
+		mock.ServerError(http.StatusServiceUnavailable),
+		mock.MalformedJSON(),
+		mock.JSON(`{"@graph": []}`),
+	)
+	srv.Handle("/slow", mock.Slow(mock.JSON(`{}`), time.Second))
+
+	for i := 0; i < 3; i++ {
+		resp, err := srv.Client().Get("https://api.weather.gov/products/types/AFD")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		resp.Body.Close()
+		fmt.Println(resp.Status)
+	}
+
+	httpClient := srv.Client()
+	httpClient.Timeout = 10 * time.Millisecond
+	if _, err := httpClient.Get(srv.URLString() + "slow"); err != nil {
+		fmt.Println("slow response timed out")
+	}
+	// Output:
+	// 503 Service Unavailable
+	// 200 OK
+	// 200 OK
+	// slow response timed out
+}
@@ -0,0 +1,318 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock implements a programmable test server that serves fixture
+// responses in place of the NWS API and the other services used by this
+// module, so that integrations can be tested offline and against failure
+// modes such as server errors, slow responses, and malformed JSON.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultContentType is the Content-Type of responses that don't set one,
+// which is that of most NWS API responses.
+const defaultContentType = "application/geo+json"
+
+// contentTypes are the Content-Types of fixture files by extension.
+var contentTypes = map[string]string{
+	".json":    "application/geo+json",
+	".geojson": "application/geo+json",
+	".jsonld":  "application/ld+json",
+	".xml":     "application/xml",
+	".cap":     "application/cap+xml",
+	".atom":    "application/atom+xml",
+	".html":    "text/html; charset=utf-8",
+	".txt":     "text/plain; charset=utf-8",
+}
+
+// A Response is a programmed response. The zero value is an empty 200
+// response.
+type Response struct {
+	Status      int    // defaults to 200
+	ContentType string // defaults to "application/geo+json"
+	Header      http.Header
+	Body        []byte
+
+	// Delay is how long to wait before responding, for simulating slow
+	// responses and client timeouts. The wait ends early if the client
+	// cancels the request.
+	Delay time.Duration
+}
+
+// JSON returns a 200 response with a JSON body.
+func JSON(body string) Response {
+	return Response{Body: []byte(body)}
+}
+
+// ServerError returns a response with a 5xx status (e.g. 503) and a problem
+// document like those returned by the NWS API.
+func ServerError(status int) Response {
+	return Problem(status, http.StatusText(status))
+}
+
+// Problem returns a response with a status and a problem document
+// (application/problem+json) like those returned by the NWS API.
+func Problem(status int, detail string) Response {
+	title := http.StatusText(status)
+	return Response{
+		Status:      status,
+		ContentType: "application/problem+json",
+		Body: []byte(fmt.Sprintf(
+			`{"correlationId": "mock", "title": %q, "type": "https://api.weather.gov/problems/%s", "status": %d, "detail": %q, "instance": "https://api.weather.gov/requests/mock"}`,
+			title, strings.ReplaceAll(title, " ", ""), status, detail,
+		)),
+	}
+}
+
+// MalformedJSON returns a 200 response whose body is JSON truncated partway
+// through, as from a dropped connection.
+func MalformedJSON() Response {
+	return JSON(`{"type": "Feature", "properties": {"updated": "2019-`)
+}
+
+// HTMLErrorPage returns a response with a status and an HTML body, like the
+// outage pages served by the NWS's content delivery network in place of JSON.
+func HTMLErrorPage(status int) Response {
+	title := http.StatusText(status)
+	return Response{
+		Status:      status,
+		ContentType: "text/html; charset=utf-8",
+		Body:        []byte(fmt.Sprintf("<html><head><title>%s</title></head><body><h1>%s</h1></body></html>", title, title)),
+	}
+}
+
+// Slow returns r with a delay before responding.
+func Slow(r Response, delay time.Duration) Response {
+	r.Delay = delay
+	return r
+}
+
+// A Server is an HTTP test server that serves programmed responses by path.
+// Paths without responses are answered with a 404 problem document.
+//
+// Requests to any host may be routed to the server with the client returned
+// by Client, which is needed for clients that make requests to the real
+// services before their URLs can be changed (e.g. nws.NewClientFromCoordinates).
+// Otherwise, set a client's URL to URLString.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string][]Response // key is a path, or a path and query
+	requests  []string              // paths and queries, in order received
+}
+
+// NewServer starts and returns a new Server. Call Close when finished.
+func NewServer() *Server {
+	s := &Server{responses: make(map[string][]Response)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URLString returns the server's URL with a trailing slash, suitable for
+// SetAPIURLString and similar methods.
+func (s *Server) URLString() string {
+	return s.URL + "/"
+}
+
+// Client returns an HTTP client that sends requests to every host to the
+// server, keeping their paths and queries.
+func (s *Server) Client() *http.Client {
+	addr := s.Listener.Addr().String()
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+	return &http.Client{Transport: rewriteScheme{transport}}
+}
+
+// rewriteScheme is a RoundTripper that sends HTTPS requests as HTTP, since the
+// server doesn't use TLS.
+type rewriteScheme struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rs rewriteScheme) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "http"
+	}
+	return rs.next.RoundTrip(req)
+}
+
+// Handle programs the responses to requests for a path (e.g.
+// "/points/45.5,-122.6"), optionally with a query (e.g.
+// "/alerts/active?point=45.5,-122.6"). Requests matching a path and query are
+// answered before those matching only the path. Responses are served in order
+// and the last is repeated, so a failure followed by a success can be
+// simulated. Handle replaces any responses already programmed for the path.
+func (s *Server) Handle(path string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[normalizePath(path)] = responses
+}
+
+// HandleFile programs the response to requests for a path to be the contents
+// of a file. The Content-Type is determined by the file's extension.
+func (s *Server) HandleFile(path string, filename string) error {
+	body, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	s.Handle(path, Response{ContentType: contentTypeForFilename(filename), Body: body})
+	return nil
+}
+
+// LoadDir programs responses from the files in a directory (e.g. "testdata"),
+// each served for the path of the file relative to the directory without its
+// extension. For example, "testdata/points/45.5,-122.6.json" is served for
+// "/points/45.5,-122.6". Use Handle for paths with queries.
+func (s *Server) LoadDir(dir string) error {
+	return filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+		path := "/" + filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+		return s.HandleFile(path, filename)
+	})
+}
+
+// Requests returns the paths and queries of the requests received, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// RequestCount returns the number of requests received for a path, with any
+// query.
+func (s *Server) RequestCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path = normalizePath(path)
+	n := 0
+	for _, r := range s.requests {
+		if r == path || strings.HasPrefix(r, path+"?") {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset removes all programmed responses and recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = make(map[string][]Response)
+	s.requests = nil
+}
+
+// serveHTTP answers a request with its next programmed response.
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	resp, ok := s.nextResponse(req)
+	if !ok {
+		resp = Problem(http.StatusNotFound, fmt.Sprintf("No fixture for %s", req.URL.RequestURI()))
+	}
+
+	if resp.Delay > 0 {
+		t := time.NewTimer(resp.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		ct := resp.ContentType
+		if ct == "" {
+			ct = defaultContentType
+		}
+		w.Header().Set("Content-Type", ct)
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(resp.Body)
+}
+
+// nextResponse records a request and returns its next programmed response.
+func (s *Server) nextResponse(req *http.Request) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := normalizePath(req.URL.Path)
+	key := path
+	if req.URL.RawQuery != "" {
+		key += "?" + req.URL.RawQuery
+	}
+	s.requests = append(s.requests, key)
+
+	if _, ok := s.responses[key]; !ok {
+		key = path
+	}
+	rs := s.responses[key]
+	if len(rs) < 1 {
+		return Response{}, false
+	}
+	if len(rs) > 1 {
+		s.responses[key] = rs[1:]
+	}
+	return rs[0], true
+}
+
+// normalizePath returns a path with a leading slash and without a trailing
+// one. A query, if any, is kept.
+func normalizePath(path string) string {
+	query := ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i:]
+	}
+	path = "/" + strings.Trim(path, "/")
+	return path + query
+}
+
+// contentTypeForFilename returns the Content-Type of a fixture file.
+func contentTypeForFilename(filename string) string {
+	if ct, ok := contentTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		return ct
+	}
+	return defaultContentType
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ncei
+
+import (
+	"sort"
+	"time"
+)
+
+// Data type IDs used for daily summaries and normals.
+const (
+	dataTypeTMAX = "TMAX"
+	dataTypeTMIN = "TMIN"
+	dataTypePRCP = "PRCP"
+	dataTypeSNOW = "SNOW"
+	dataTypeSNWD = "SNWD"
+
+	dataTypeNormalTMAX = "DLY-TMAX-NORMAL"
+	dataTypeNormalTMIN = "DLY-TMIN-NORMAL"
+	dataTypeNormalTAVG = "DLY-TAVG-NORMAL"
+
+	// normalsYear is the year used for dates in the daily normals dataset.
+	normalsYear = 2010
+)
+
+// A DailySummary represents the observations at a station for one day from
+// the GHCND dataset. Values are nil if they were not reported.
+type DailySummary struct {
+	Date           time.Time
+	TemperatureMax *float64
+	TemperatureMin *float64
+	Precipitation  *float64
+	Snowfall       *float64
+	SnowDepth      *float64
+}
+
+// A DailyNormal represents the 30-year normal temperatures at a station for a
+// day of the year. Values are nil if the station has no normal.
+type DailyNormal struct {
+	Month          time.Month
+	Day            int
+	TemperatureMax *float64
+	TemperatureMin *float64
+	TemperatureAvg *float64
+}
+
+// DailySummaries retrieves the daily summaries for a GHCND station between two
+// dates, inclusive, in chronological order.
+func (c *Client) DailySummaries(stationID string, start time.Time, end time.Time, units string) ([]DailySummary, error) {
+	data, err := c.Data(DataRequest{
+		DatasetID:   "GHCND",
+		StationID:   stationID,
+		DataTypeIDs: []string{dataTypeTMAX, dataTypeTMIN, dataTypePRCP, dataTypeSNOW, dataTypeSNWD},
+		Units:       units,
+		Start:       start,
+		End:         end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]*DailySummary)
+	for _, d := range data {
+		s, ok := byDate[d.Date]
+		if !ok {
+			s = &DailySummary{Date: d.Date}
+			byDate[d.Date] = s
+		}
+		v := d.Value
+		switch d.DataTypeID {
+		case dataTypeTMAX:
+			s.TemperatureMax = &v
+		case dataTypeTMIN:
+			s.TemperatureMin = &v
+		case dataTypePRCP:
+			s.Precipitation = &v
+		case dataTypeSNOW:
+			s.Snowfall = &v
+		case dataTypeSNWD:
+			s.SnowDepth = &v
+		}
+	}
+
+	ss := make([]DailySummary, 0, len(byDate))
+	for _, s := range byDate {
+		ss = append(ss, *s)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].Date.Before(ss[j].Date) })
+	return ss, nil
+}
+
+// DailyNormals retrieves the daily normal temperatures for every day of the
+// year for a station, in order from January 1.
+func (c *Client) DailyNormals(stationID string, units string) ([]DailyNormal, error) {
+	data, err := c.Data(DataRequest{
+		DatasetID:   "NORMAL_DLY",
+		StationID:   stationID,
+		DataTypeIDs: []string{dataTypeNormalTMAX, dataTypeNormalTMIN, dataTypeNormalTAVG},
+		Units:       units,
+		Start:       time.Date(normalsYear, time.January, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(normalsYear, time.December, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]*DailyNormal)
+	for _, d := range data {
+		n, ok := byDate[d.Date]
+		if !ok {
+			n = &DailyNormal{Month: d.Date.Month(), Day: d.Date.Day()}
+			byDate[d.Date] = n
+		}
+		v := d.Value
+		switch d.DataTypeID {
+		case dataTypeNormalTMAX:
+			n.TemperatureMax = &v
+		case dataTypeNormalTMIN:
+			n.TemperatureMin = &v
+		case dataTypeNormalTAVG:
+			n.TemperatureAvg = &v
+		}
+	}
+
+	ns := make([]DailyNormal, 0, len(byDate))
+	for _, n := range byDate {
+		ns = append(ns, *n)
+	}
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].Month != ns[j].Month {
+			return ns[i].Month < ns[j].Month
+		}
+		return ns[i].Day < ns[j].Day
+	})
+	return ns, nil
+}
+
+// NormalFor returns the normal for the date of t (in t's location). The
+// normals have no entry for February 29, so the normal for February 28 is
+// used instead. The second return value is false if there is no normal.
+func NormalFor(normals []DailyNormal, t time.Time) (DailyNormal, bool) {
+	month, day := t.Month(), t.Day()
+	if month == time.February && day == 29 {
+		day = 28
+	}
+	for _, n := range normals {
+		if n.Month == month && n.Day == day {
+			return n, true
+		}
+	}
+	return DailyNormal{}, false
+}
@@ -0,0 +1,243 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ncei implements a client for the NCEI Climate Data Online web
+// services, which provide historical daily summaries and climate normals.
+package ncei
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAPIURLString = "https://www.ncdc.noaa.gov/cdo-web/api/v2/"
+	dataEndpoint        = "data"
+
+	// pageLimit is the maximum number of results the API returns at once.
+	pageLimit = 1000
+
+	// maxPages limits the number of pages retrieved for a single request.
+	maxPages = 50
+
+	// maxRespBodyBytes limits the size of response bodies. A full page of
+	// results is a few hundred kilobytes.
+	maxRespBodyBytes = 4 << 20
+
+	dateLayout     = "2006-01-02"
+	respTimeLayout = "2006-01-02T15:04:05"
+)
+
+// Units
+const (
+	UnitsStandard = "standard" // F, inches
+	UnitsMetric   = "metric"   // C, millimeters
+)
+
+// A Client is used to retrieve data from Climate Data Online.
+type Client struct {
+	httpClient          *http.Client
+	httpUserAgentString string
+	token               string
+	apiURLString        string
+}
+
+// A DataRequest describes data to retrieve from a dataset.
+type DataRequest struct {
+	DatasetID   string   // e.g. "GHCND" or "NORMAL_DLY"
+	StationID   string   // e.g. "GHCND:USW00024229"
+	DataTypeIDs []string // e.g. "TMAX", "PRCP"; all data types if empty
+	Units       string   // one of the Units constants; UnitsStandard if empty
+	Start       time.Time
+	End         time.Time // inclusive; at most one year after Start
+}
+
+// A Datum is a single value returned from the API.
+type Datum struct {
+	Date       time.Time
+	DataTypeID string
+	StationID  string
+	Value      float64
+	Attributes string // measurement, quality, and source flags
+}
+
+// NewClient returns a new Client. A token from NCEI is required, as is an
+// httpUserAgentString identifying your application.
+func NewClient(httpClient *http.Client, httpUserAgentString string, token string) (*Client, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if httpUserAgentString == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	if token == "" {
+		return nil, errors.New("token must not be empty")
+	}
+	return &Client{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		token:               token,
+		apiURLString:        defaultAPIURLString,
+	}, nil
+}
+
+// SetAPIURLString sets the base URL of the API. It must end with a slash.
+func (c *Client) SetAPIURLString(urlString string) error {
+	if !strings.HasSuffix(urlString, "/") {
+		return errors.New("API URL must end with a slash")
+	}
+	c.apiURLString = urlString
+	return nil
+}
+
+// Data retrieves all data matching a DataRequest, following pagination.
+func (c *Client) Data(r DataRequest) ([]Datum, error) {
+	query, err := r.query()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []Datum
+	for page, offset := 0, 1; page < maxPages; page++ {
+		query.Set("offset", strconv.Itoa(offset))
+		respBody, err := doAPIRequest(c.httpClient, c.httpUserAgentString, c.token, c.apiURLString, dataEndpoint, query)
+		if err != nil {
+			return nil, err
+		}
+		ds, count, err := newDataFromDataRespBody(respBody)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, ds...)
+		offset += pageLimit
+		if len(ds) == 0 || offset > count {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("more than %d pages of results", maxPages)
+}
+
+// query validates a DataRequest and returns its query parameters.
+func (r DataRequest) query() (url.Values, error) {
+	if r.DatasetID == "" {
+		return nil, errors.New("dataset ID must not be empty")
+	}
+	if r.StationID == "" {
+		return nil, errors.New("station ID must not be empty")
+	}
+	if r.Start.IsZero() || r.End.Before(r.Start) {
+		return nil, errors.New("end must not be before start")
+	}
+	if r.End.After(r.Start.AddDate(1, 0, 0)) {
+		return nil, errors.New("range must not exceed one year")
+	}
+	units := r.Units
+	if units == "" {
+		units = UnitsStandard
+	}
+	if units != UnitsStandard && units != UnitsMetric {
+		return nil, fmt.Errorf("units must be \"%s\" or \"%s\": \"%s\"", UnitsStandard, UnitsMetric, units)
+	}
+
+	q := url.Values{}
+	q.Set("datasetid", r.DatasetID)
+	q.Set("stationid", r.StationID)
+	for _, dt := range r.DataTypeIDs {
+		q.Add("datatypeid", dt)
+	}
+	q.Set("units", units)
+	q.Set("startdate", r.Start.Format(dateLayout))
+	q.Set("enddate", r.End.Format(dateLayout))
+	q.Set("limit", strconv.Itoa(pageLimit))
+	return q, nil
+}
+
+// newDataFromDataRespBody returns the data in a page of results and the total
+// number of results, given a response body from the API. The API returns an
+// empty object when there are no results.
+func newDataFromDataRespBody(respBody []byte) ([]Datum, int, error) {
+	dRaw := struct {
+		Metadata struct {
+			ResultSet struct {
+				Count int
+			}
+		}
+		Results []struct {
+			Date       string
+			DataType   string
+			Station    string
+			Attributes string
+			Value      float64
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &dRaw); err != nil {
+		return nil, 0, err
+	}
+
+	var ds []Datum
+	for _, rRaw := range dRaw.Results {
+		t, err := time.Parse(respTimeLayout, rRaw.Date)
+		if err != nil {
+			continue // skip if bad date
+		}
+		ds = append(ds, Datum{
+			Date:       t,
+			DataTypeID: rRaw.DataType,
+			StationID:  rRaw.Station,
+			Value:      rRaw.Value,
+			Attributes: rRaw.Attributes,
+		})
+	}
+
+	return ds, dRaw.Metadata.ResultSet.Count, nil
+}
+
+// doAPIRequest makes a GET request to an endpoint and returns the body of a
+// 200 response.
+func doAPIRequest(httpClient *http.Client, httpUserAgentString string, token string, apiURLString string, endpoint string, query url.Values) ([]byte, error) {
+	req, err := http.NewRequest("GET", apiURLString+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("User-Agent", httpUserAgentString)
+	req.Header.Set("token", token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody) > maxRespBodyBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxRespBodyBytes)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, respBody)
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// accountingDays is the number of days of counts kept by a
+	// RequestAccount.
+	accountingDays = 31
+
+	accountingDayLayout = "2006-01-02"
+
+	// otherEndpointName is the endpoint name under which requests that were
+	// not made to the API, such as alert resources, are counted.
+	otherEndpointName = "other"
+)
+
+// A QuotaWarning is passed to a RequestAccount's warn function the first time
+// the number of requests in a day reaches the soft quota.
+type QuotaWarning struct {
+	UserAgent string
+	Day       time.Time // midnight UTC
+	Requests  int
+	SoftQuota int
+}
+
+// A RequestAccount counts the requests made by a Client per endpoint per day
+// (UTC), so that users can keep to polite usage of the API. Counts are kept
+// for 31 days. It is safe for concurrent use.
+type RequestAccount struct {
+	userAgent string
+	softQuota int
+	warn      func(QuotaWarning)
+
+	mu     sync.Mutex
+	days   map[string]map[string]int // day, endpoint name, count
+	warned map[string]bool           // day
+}
+
+// TrackRequests starts counting the requests made by the Client and returns
+// the account. If softQuota is positive, warn is called the first time the
+// requests in a day reach it; requests are never blocked. Requests are
+// counted under the names returned by EndpointFromRequest.
+//
+// As with Use, requests made while the Client was constructed are not
+// counted.
+func (c *Client) TrackRequests(softQuota int, warn func(QuotaWarning)) *RequestAccount {
+	a := &RequestAccount{
+		userAgent: c.httpUserAgentString,
+		softQuota: softQuota,
+		warn:      warn,
+		days:      make(map[string]map[string]int),
+		warned:    make(map[string]bool),
+	}
+	c.Use(a.middleware)
+	return a
+}
+
+// middleware counts each request before passing it on.
+func (a *RequestAccount) middleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		name := EndpointFromRequest(req)
+		if name == "" {
+			name = otherEndpointName
+		}
+		a.count(time.Now(), name)
+		return next.RoundTrip(req)
+	})
+}
+
+// count counts a request and warns if the soft quota is reached.
+func (a *RequestAccount) count(t time.Time, name string) {
+	day := t.UTC().Format(accountingDayLayout)
+
+	a.mu.Lock()
+	if a.days[day] == nil {
+		a.days[day] = make(map[string]int)
+		a.prune()
+	}
+	a.days[day][name]++
+	total := 0
+	for _, n := range a.days[day] {
+		total += n
+	}
+	var w *QuotaWarning
+	if a.softQuota > 0 && total >= a.softQuota && !a.warned[day] {
+		a.warned[day] = true
+		d, _ := time.Parse(accountingDayLayout, day)
+		w = &QuotaWarning{UserAgent: a.userAgent, Day: d, Requests: total, SoftQuota: a.softQuota}
+	}
+	a.mu.Unlock()
+
+	// call warn without holding the lock, in case it makes requests
+	if w != nil && a.warn != nil {
+		a.warn(*w)
+	}
+}
+
+// prune removes the oldest days beyond accountingDays.
+func (a *RequestAccount) prune() {
+	if len(a.days) <= accountingDays {
+		return
+	}
+	var days []string
+	for d := range a.days {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	for _, d := range days[:len(days)-accountingDays] {
+		delete(a.days, d)
+		delete(a.warned, d)
+	}
+}
+
+// Counts returns the number of requests made to each endpoint on the UTC day
+// containing t.
+func (a *RequestAccount) Counts(t time.Time) map[string]int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	counts := make(map[string]int)
+	for name, n := range a.days[t.UTC().Format(accountingDayLayout)] {
+		counts[name] = n
+	}
+	return counts
+}
+
+// Total returns the number of requests made on the UTC day containing t.
+func (a *RequestAccount) Total(t time.Time) int {
+	total := 0
+	for _, n := range a.Counts(t) {
+		total += n
+	}
+	return total
+}
+
+// Remaining returns the number of requests left before the soft quota is
+// reached on the UTC day containing t, or -1 if there is no soft quota.
+func (a *RequestAccount) Remaining(t time.Time) int {
+	if a.softQuota <= 0 {
+		return -1
+	}
+	if r := a.softQuota - a.Total(t); r > 0 {
+		return r
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import "time"
+
+// An AccumulationForecast holds the raw gridpoint forecast of precipitation
+// amounts for the Client's point. Each layer is a time series of amounts
+// expected during each value's time range, usually six hours.
+type AccumulationForecast struct {
+	QuantitativePrecipitation GridpointLayer // liquid equivalent
+	SnowfallAmount            GridpointLayer
+	IceAccumulation           GridpointLayer
+}
+
+// AccumulationForecast retrieves the raw gridpoint forecast of precipitation
+// amounts for the Client's point. Layers missing from the forecast have no
+// values.
+func (c *Client) AccumulationForecast() (*AccumulationForecast, error) {
+	layers, err := getGridpointLayersForGridpoint(
+		c.httpClient,
+		c.httpUserAgentString,
+		c.apiURLString,
+		c.gridpoint,
+		[]string{"quantitativePrecipitation", "snowfallAmount", "iceAccumulation"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &AccumulationForecast{
+		QuantitativePrecipitation: layers["quantitativePrecipitation"],
+		SnowfallAmount:            layers["snowfallAmount"],
+		IceAccumulation:           layers["iceAccumulation"],
+	}, nil
+}
+
+// Precipitation returns the liquid precipitation in inches forecast between
+// start and end.
+func (a AccumulationForecast) Precipitation(start time.Time, end time.Time) (ValueUnit, error) {
+	return a.QuantitativePrecipitation.Total(start, end)
+}
+
+// Snowfall returns the snowfall in inches forecast between start and end.
+func (a AccumulationForecast) Snowfall(start time.Time, end time.Time) (ValueUnit, error) {
+	return a.SnowfallAmount.Total(start, end)
+}
+
+// Ice returns the ice accumulation in inches forecast between start and end.
+func (a AccumulationForecast) Ice(start time.Time, end time.Time) (ValueUnit, error) {
+	return a.IceAccumulation.Total(start, end)
+}
+
+// SnowfallNext returns the snowfall in inches forecast for the duration d
+// following now (e.g. the next 48 hours).
+func (a AccumulationForecast) SnowfallNext(now time.Time, d time.Duration) (ValueUnit, error) {
+	return a.Snowfall(now, now.Add(d))
+}
+
+// PrecipitationNext returns the liquid precipitation in inches forecast for
+// the duration d following now.
+func (a AccumulationForecast) PrecipitationNext(now time.Time, d time.Duration) (ValueUnit, error) {
+	return a.Precipitation(now, now.Add(d))
+}
@@ -19,10 +19,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
+	"unicode"
 )
 
-const getActiveAlertsForPointEndpointURLStringFmt = "alerts/active"
+const (
+	getActiveAlertsForPointEndpointURLStringFmt = "alerts/active"
+	getAlertsForPointEndpointURLStringFmt       = "alerts"
+	getActiveAlertsForZoneEndpointURLStringFmt  = "alerts/active/zone/%s" // zone ID
+)
 
 var (
 	// AlertStatuses are defined in
@@ -121,41 +127,200 @@ type Alert struct {
 	SenderID   string // appears to usually be an email address
 	SenderName string
 
-	Status      string   // must be a key in AlertStatuses
-	MessageType string   // must be a key in AlertMessageTypes
-	References  []string // IDs of alerts that this alert affects based on MessageType
-
-	Category        string // must be a key in AlertCategories
-	Severity        string // must be a key in AlertSeverities
-	Certainty       string // must be a key in AlertCertainties
-	Urgency         string // must be a key in Alert Urgencies
-	Event           string
-	AreaDescription string
-	Headline        string
-	Description     string
-	Instruction     string
-	Response        string // must be a key in AlerResponses
+	Status           string           // must be a key in AlertStatuses
+	MessageType      string           // must be a key in AlertMessageTypes
+	References       []string         // IDs of alerts that this alert affects based on MessageType
+	ReferenceDetails []AlertReference // the References with their senders and times, if provided
+
+	Category          string // must be a key in AlertCategories
+	Severity          string // must be a key in AlertSeverities
+	Certainty         string // must be a key in AlertCertainties
+	Urgency           string // must be a key in Alert Urgencies
+	Event             string
+	EventCode         string // SAME event code (e.g. "SVR"); see LookupSAMEEvent
+	VTEC              []VTEC // the events this alert is about, if provided
+	AreaDescription   string
+	Polygons          [][]Point // outer rings of the affected area, if provided
+	PolygonsFromZones bool      // Polygons were resolved from zone geometry; see Client.ResolveAlertPolygonsFromZones
+	Circles           []Circle  // circular areas, from the CAP document; only populated if Client.FetchAlertResources
+	UGCCodes          []string  // UGC zone and county codes (e.g. "ORZ006")
+	SAMECodes         []string  // SAME (FIPS) county codes (e.g. "041051")
+	AffectedZones     []string  // zone IDs (e.g. "ORZ006")
+	Headline          string
+	Description       string
+	Instruction       string
+	Response          string // must be a key in AlerResponses
+
+	Resources []AlertResource // only populated if Client.FetchAlertResources
+	Infos     []AlertInfo     // one per CAP info block; only populated if Client.FetchAlertResources
+
+	ResponseMeta *ResponseMeta // of the API response the alert was parsed from, if any
+}
+
+// An AlertReference identifies an earlier alert, as in the CAP references
+// element.
+type AlertReference struct {
+	Sender   string
+	ID       string
+	TimeSent time.Time
+}
+
+// CoversPoint reports whether the alert's area contains a point, that is,
+// whether any of its polygons or circles contains the point. Alerts without
+// either never contain a point; use CoversZone for those, or resolve their
+// polygons with Client.ResolveAlertPolygonsFromZones.
+func (a Alert) CoversPoint(lat float64, lon float64) bool {
+	p := Point{Lat: lat, Lon: lon}
+	for _, poly := range a.Polygons {
+		if polygonContainsPoint(poly, p) {
+			return true
+		}
+	}
+	for _, c := range a.Circles {
+		if c.ContainsPoint(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// CoversZone reports whether the alert applies to a UGC zone or county code
+// (e.g. "ORZ006" or "ORC051") or to a county FIPS code, either as a five digit
+// FIPS code (e.g. "41051") or a six digit SAME code (e.g. "041051"). A SAME
+// code for part of a county (e.g. "141051") covers the county.
+func (a Alert) CoversZone(id string) bool {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if isFIPSOrSAMECode(id) {
+		for _, same := range a.SAMECodes {
+			if isFIPSOrSAMECode(same) && same[len(same)-5:] == id[len(id)-5:] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ugc := range a.UGCCodes {
+		if ugc == id {
+			return true
+		}
+	}
+	for _, z := range a.AffectedZones {
+		if z == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isFIPSOrSAMECode reports whether s is a five digit county FIPS code or a six
+// digit SAME code.
+func isFIPSOrSAMECode(s string) bool {
+	if len(s) != 5 && len(s) != 6 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // getActiveAlertsForPoint retrieves from the NWS API active alerts for a given
-// point.
-func getActiveAlertsForPoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, point Point) ([]Alert, error) {
+// point, following pagination up to maxAlertFeedPages pages. The request for
+// the first page is conditional on v; if the alerts haven't changed,
+// notModified is true and no alerts are returned. The validators of the first
+// page are returned for use in the next request.
+func getActiveAlertsForPoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, point Point, v validators) (alerts []Alert, newV validators, notModified bool, err error) {
 	// It may be more efficient to use "zone" or "area", but it isn't clear from
 	// the limited documentation whish is most appropriate. "Point" seems like it
 	// has the best chance of returning appropriate/relevent alerts.
-	var query url.Values
+	query := url.Values{}
 	query.Add("point", fmt.Sprintf("%f,%f", point.Lat, point.Lon))
-	respBody, err := doAPIRequest(
-		httpClient,
-		apiURLString,
-		httpUserAgentString,
-		fmt.Sprintf(getActiveAlertsForPointEndpointURLStringFmt),
-		query,
+	endpoint := getActiveAlertsForPointEndpointURLStringFmt
+	respBody, header, notModified, err := doAPIRequestWithHeader(httpClient, httpUserAgentString, apiURLString, endpoint, query, "", v)
+	if err != nil {
+		return nil, validators{}, false, err
+	}
+	if notModified {
+		return nil, v, true, nil
+	}
+	newV = newValidators(header)
+	for page := 1; ; page++ {
+		pageAlerts, err := newAlertsWithMetaFromAlertsRespBody(respBody, newResponseMeta(header, respBody))
+		if err != nil {
+			return nil, validators{}, false, err
+		}
+		alerts = append(alerts, pageAlerts...)
+		next := nextEndpointFromRespBody(respBody, apiURLString)
+		if next == "" || next == endpoint || page >= maxAlertFeedPages {
+			break
+		}
+		endpoint = next
+		respBody, header, _, err = doAPIRequestWithHeader(httpClient, httpUserAgentString, apiURLString, endpoint, nil, "", validators{})
+		if err != nil {
+			return nil, validators{}, false, err
+		}
+	}
+	return alerts, newV, false, nil
+}
+
+// ActiveAlertsForZone retrieves the alerts currently active for a forecast or
+// county zone (e.g. "ORZ006"), which need not contain the Client's point. The
+// alerts are not cached.
+func (c *Client) ActiveAlertsForZone(id string) ([]Alert, error) {
+	if err := ValidateZoneID(id); err != nil {
+		return nil, err
+	}
+	respBody, meta, err := doAPIRequestWithMeta(
+		c.httpClient,
+		c.httpUserAgentString,
+		c.apiURLString,
+		fmt.Sprintf(getActiveAlertsForZoneEndpointURLStringFmt, strings.ToUpper(id)),
+		nil,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return newAlertsFromAlertsRespBody(respBody)
+	return newAlertsWithMetaFromAlertsRespBody(respBody, meta)
+}
+
+// getAlertsForPoint retrieves from the NWS API all alerts, including those no
+// longer active, sent for a given point between two times.
+//
+// The response may be paginated; the returned string is the endpoint of the
+// next page, or empty if this is the last page. Pass it as endpoint to retrieve
+// that page, or pass an empty endpoint for the first page.
+func getAlertsForPoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, point Point, start time.Time, end time.Time, endpoint string) ([]Alert, string, error) {
+	var query url.Values
+	if endpoint == "" {
+		endpoint = getAlertsForPointEndpointURLStringFmt
+		query = url.Values{}
+		query.Add("point", fmt.Sprintf("%f,%f", point.Lat, point.Lon))
+		query.Add("start", start.UTC().Format(time.RFC3339))
+		query.Add("end", end.UTC().Format(time.RFC3339))
+	}
+	respBody, meta, err := doAPIRequestWithMeta(httpClient, httpUserAgentString, apiURLString, endpoint, query)
+	if err != nil {
+		return nil, "", err
+	}
+	alerts, err := newAlertsWithMetaFromAlertsRespBody(respBody, meta)
+	if err != nil {
+		return nil, "", err
+	}
+	return alerts, nextEndpointFromRespBody(respBody, apiURLString), nil
+}
+
+// newAlertsWithMetaFromAlertsRespBody is the same as
+// newAlertsFromAlertsRespBody, but also sets the ResponseMeta of each alert.
+func newAlertsWithMetaFromAlertsRespBody(respBody []byte, meta *ResponseMeta) ([]Alert, error) {
+	alerts, err := newAlertsFromAlertsRespBody(respBody)
+	if err != nil {
+		return nil, err
+	}
+	for i := range alerts {
+		alerts[i].ResponseMeta = meta
+	}
+	return alerts, nil
 }
 
 // newAlertsFromAlertsRespBody returns a slice of Alerts, given a response body
@@ -164,11 +329,28 @@ func newAlertsFromAlertsRespBody(respBody []byte) ([]Alert, error) {
 	// unmarshal the body into a temporary struct
 	alertsRaw := struct {
 		Features []struct {
+			Geometry *struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
 			Properties struct {
-				ID         string
-				AreaDesc   string
-				References []struct {
+				ID       string
+				AreaDesc string
+				Geocode  struct {
+					UGC  []string
+					SAME []string
+				}
+				EventCode struct {
+					SAME []string
+				}
+				Parameters struct {
+					VTEC []string
+				}
+				AffectedZones []string // URLs
+				References    []struct {
 					Identifier string
+					Sender     string
+					Sent       string
 				}
 				Sent        string
 				Effective   string
@@ -224,6 +406,9 @@ func newAlertsFromAlertsRespBody(respBody []byte) ([]Alert, error) {
 		for _, ref := range aRaw.Properties.References {
 			if ref.Identifier != "" {
 				a.References = append(a.References, ref.Identifier)
+				if t, err := time.Parse(time.RFC3339, ref.Sent); err == nil && ref.Sender != "" {
+					a.ReferenceDetails = append(a.ReferenceDetails, AlertReference{Sender: ref.Sender, ID: ref.Identifier, TimeSent: t})
+				}
 			}
 		}
 
@@ -240,7 +425,25 @@ func newAlertsFromAlertsRespBody(respBody []byte) ([]Alert, error) {
 			a.Urgency = aRaw.Properties.Urgency
 		}
 		a.Event = aRaw.Properties.Event
+		if len(aRaw.Properties.EventCode.SAME) > 0 {
+			a.EventCode = strings.ToUpper(aRaw.Properties.EventCode.SAME[0])
+		}
+		for _, vs := range aRaw.Properties.Parameters.VTEC {
+			if v, err := ParseVTEC(vs); err == nil {
+				a.VTEC = append(a.VTEC, v)
+			}
+		}
 		a.AreaDescription = aRaw.Properties.AreaDesc
+		if aRaw.Geometry != nil {
+			a.Polygons, _ = newPolygonsFromGeoJSONGeometry(aRaw.Geometry.Type, aRaw.Geometry.Coordinates)
+		}
+		a.UGCCodes = expandUGCGeocodes(aRaw.Properties.Geocode.UGC)
+		a.SAMECodes = splitGeocodes(aRaw.Properties.Geocode.SAME)
+		for _, z := range aRaw.Properties.AffectedZones {
+			if id := zoneIDFromZoneURLString(z); id != "" {
+				a.AffectedZones = append(a.AffectedZones, id)
+			}
+		}
 		a.Headline = aRaw.Properties.Headline
 		a.Description = aRaw.Properties.Description
 		a.Instruction = aRaw.Properties.Instruction
@@ -253,3 +456,21 @@ func newAlertsFromAlertsRespBody(respBody []byte) ([]Alert, error) {
 
 	return alerts, nil
 }
+
+// splitGeocodes returns geocode values split into individual, uppercase codes.
+// Legacy CAP feeds sometimes pack several codes into one value (e.g. "041059
+// 041061 041063"), separated by spaces or commas. Duplicates are removed.
+func splitGeocodes(values []string) []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		for _, code := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || unicode.IsSpace(r) }) {
+			code = strings.ToUpper(code)
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes
+}
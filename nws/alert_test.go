@@ -155,3 +155,48 @@ package nws
 //     "title": "current watches, warnings, and advisories for 45.458 N, 122.6636 W",
 //     "updated": "2019-08-28T17:36:22+00:00"
 // }
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientUpdateAlerts(t *testing.T) {
+	var requests, notModified int
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/geo+json")
+		if r.URL.Query().Get("cursor") != "" {
+			fmt.Fprint(w, `{"features": [{"properties": {"id": "urn:oid:2.49.0.1.840.0.2", "event": "Wind Advisory"}}]}`)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"1"`)
+		fmt.Fprintf(w, `{
+			"features": [{"properties": {"id": "urn:oid:2.49.0.1.840.0.1", "event": "Heat Advisory"}}],
+			"pagination": {"next": "%s/alerts/active?cursor=2"}
+		}`, srv.URL)
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client(), httpUserAgentString: "test", apiURLString: srv.URL + "/", point: Point{Lat: 45.458, Lon: -122.6636}}
+	for i := 0; i < 2; i++ {
+		if err := c.UpdateAlerts(); err != nil {
+			t.Fatal(err)
+		}
+		alerts := c.Alerts("")
+		if len(alerts) != 2 || alerts[0].Event != "Heat Advisory" || alerts[1].Event != "Wind Advisory" {
+			t.Fatalf("update %d: got %+v; want both pages", i+1, alerts)
+		}
+	}
+	if requests != 3 || notModified != 1 {
+		t.Errorf("got %d requests, %d not modified; want 3, 1", requests, notModified)
+	}
+}
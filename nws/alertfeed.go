@@ -0,0 +1,230 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const atomMIMEType = "application/atom+xml"
+
+// maxAlertFeedPages limits the number of pages of an alert feed that are
+// followed, in case a server returns a cycle of next links.
+const maxAlertFeedPages = 10
+
+// alertFeedRaw is an Atom feed of alerts as returned by the NWS API when
+// requested as "application/atom+xml". Each entry carries the headline-level
+// CAP elements as extension elements in the CAP namespace; elements are
+// matched by local name only.
+type alertFeedRaw struct {
+	XMLName xml.Name `xml:"feed"`
+	Links   []struct {
+		Rel  string `xml:"rel,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+	Entries []struct {
+		ID          string `xml:"id"`
+		Title       string `xml:"title"`
+		Event       string `xml:"event"`
+		Sent        string `xml:"sent"`
+		Effective   string `xml:"effective"`
+		Onset       string `xml:"onset"`
+		Expires     string `xml:"expires"`
+		Status      string `xml:"status"`
+		MessageType string `xml:"msgType"`
+		Category    string `xml:"category"`
+		Urgency     string `xml:"urgency"`
+		Severity    string `xml:"severity"`
+		Certainty   string `xml:"certainty"`
+		AreaDesc    string `xml:"areaDesc"`
+		Geocodes    []struct {
+			ValueNames []string `xml:"valueName"`
+			Values     []string `xml:"value"`
+		} `xml:"geocode"`
+	} `xml:"entry"`
+}
+
+// ActiveAlertSummaries retrieves the alerts currently active for the Client's
+// point from the NWS API's Atom feed, without retrieving the full alert
+// documents. It is intended for pollers with limited bandwidth that only need
+// to know which alerts are in effect.
+//
+// Only the ID, times, status, message type, category, severity, certainty,
+// urgency, event, area description, geocodes, and headline are populated. The
+// feed doesn't include references, so DiffAlerts reports an updated alert as
+// an addition and a removal.
+//
+// Requests are conditional: the ETag and Last-Modified of the previous
+// response for the same point are sent, and if the feed hasn't changed the
+// previously retrieved alerts are returned without downloading it again. If
+// the feed is paginated, up to 10 pages are followed.
+func (c *Client) ActiveAlertSummaries() ([]Alert, error) {
+	query := url.Values{}
+	query.Add("point", fmt.Sprintf("%f,%f", c.point.Lat, c.point.Lon))
+	return c.alertSummaries(getActiveAlertsForPointEndpointURLStringFmt, query)
+}
+
+// ActiveAlertSummariesForZone is the same as ActiveAlertSummaries, but for a
+// forecast or county zone (e.g. "ORZ006").
+func (c *Client) ActiveAlertSummariesForZone(id string) ([]Alert, error) {
+	if err := ValidateZoneID(id); err != nil {
+		return nil, err
+	}
+	return c.alertSummaries(fmt.Sprintf(getActiveAlertsForZoneEndpointURLStringFmt, strings.ToUpper(id)), nil)
+}
+
+// alertFeedState holds the validators and alerts of the last response for an
+// alert feed, used to make conditional requests.
+type alertFeedState struct {
+	validators validators
+	alerts     []Alert
+}
+
+// alertSummaries retrieves the Atom feed of alerts for an endpoint, using and
+// updating the Client's state for that feed.
+func (c *Client) alertSummaries(endpoint string, query url.Values) ([]Alert, error) {
+	key := endpoint
+	if query != nil {
+		key += "?" + query.Encode()
+	}
+	c.mu.Lock()
+	prev := c.alertFeeds[key]
+	c.mu.Unlock()
+
+	state, err := getAlertSummaries(c.httpClient, c.httpUserAgentString, c.apiURLString, endpoint, query, prev)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.alertFeeds == nil {
+		c.alertFeeds = make(map[string]alertFeedState)
+	}
+	c.alertFeeds[key] = state
+	return append([]Alert(nil), state.alerts...), nil
+}
+
+// getAlertSummaries retrieves from the NWS API the Atom feed of alerts for an
+// endpoint, following next links. The request for the first page is
+// conditional on prev; if the feed hasn't changed prev is returned.
+func getAlertSummaries(httpClient *http.Client, httpUserAgentString string, apiURLString string, endpoint string, query url.Values, prev alertFeedState) (alertFeedState, error) {
+	respBody, v, notModified, err := doConditionalAPIRequest(httpClient, httpUserAgentString, apiURLString, endpoint, query, atomMIMEType, prev.validators)
+	if err != nil {
+		return alertFeedState{}, err
+	}
+	if notModified {
+		return prev, nil
+	}
+	state := alertFeedState{validators: v}
+	for page := 1; ; page++ {
+		alerts, next, err := newAlertsFromAlertFeedRespBody(respBody, apiURLString)
+		if err != nil {
+			return alertFeedState{}, err
+		}
+		state.alerts = append(state.alerts, alerts...)
+		if next == "" || next == endpoint || page >= maxAlertFeedPages {
+			break
+		}
+		endpoint = next
+		respBody, err = doAPIRequestAccepting(httpClient, httpUserAgentString, apiURLString, endpoint, nil, atomMIMEType)
+		if err != nil {
+			return alertFeedState{}, err
+		}
+	}
+	return state, nil
+}
+
+// newAlertsFromAlertFeedRespBody returns a slice of Alerts and the endpoint
+// of the next page, given an Atom response body from the NWS API. As with
+// nextEndpointFromRespBody, the endpoint is relative to apiURLString and is
+// empty if there is no next page or if it is not on the API.
+func newAlertsFromAlertFeedRespBody(respBody []byte, apiURLString string) ([]Alert, string, error) {
+	var feed alertFeedRaw
+	d := xml.NewDecoder(bytes.NewReader(respBody))
+	d.Strict = true
+	if err := d.Decode(&feed); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	for _, l := range feed.Links {
+		href := strings.TrimSpace(l.Href)
+		if l.Rel == "next" && strings.HasPrefix(href, apiURLString) {
+			next = strings.TrimPrefix(href, apiURLString)
+		}
+	}
+
+	var alerts []Alert
+	for _, e := range feed.Entries {
+		// entry IDs are alert URLs; the alert ID is the last path segment
+		id := strings.TrimSpace(e.ID)
+		if i := strings.LastIndex(id, "/"); i >= 0 {
+			id = id[i+1:]
+		}
+		if id == "" {
+			continue // skip if no ID
+		}
+
+		// as with the JSON representation, ignore bad data
+		a := Alert{ID: id, TimeRetrieved: time.Now()}
+		a.TimeSent, _ = time.Parse(time.RFC3339, strings.TrimSpace(e.Sent))
+		a.TimeEffective, _ = time.Parse(time.RFC3339, strings.TrimSpace(e.Effective))
+		a.TimeOnset, _ = time.Parse(time.RFC3339, strings.TrimSpace(e.Onset))
+		a.TimeExpires, _ = time.Parse(time.RFC3339, strings.TrimSpace(e.Expires))
+		a.Status = strings.TrimSpace(e.Status)
+		a.MessageType = strings.TrimSpace(e.MessageType)
+		if _, ok := AlertCategories[e.Category]; ok {
+			a.Category = e.Category
+		}
+		if _, ok := AlertSeverities[e.Severity]; ok {
+			a.Severity = e.Severity
+		}
+		if _, ok := AlertCertainties[e.Certainty]; ok {
+			a.Certainty = e.Certainty
+		}
+		if _, ok := AlertUrgencies[e.Urgency]; ok {
+			a.Urgency = e.Urgency
+		}
+		a.Event = strings.TrimSpace(e.Event)
+		a.AreaDescription = strings.TrimSpace(e.AreaDesc)
+		a.Headline = strings.TrimSpace(e.Title)
+
+		// value names and values alternate within each geocode element
+		var ugc, same []string
+		for _, g := range e.Geocodes {
+			for i := 0; i < len(g.ValueNames) && i < len(g.Values); i++ {
+				switch strings.ToUpper(strings.TrimSpace(g.ValueNames[i])) {
+				case "UGC":
+					ugc = append(ugc, g.Values[i])
+				case "SAME", "FIPS6":
+					same = append(same, g.Values[i])
+				}
+			}
+		}
+		a.UGCCodes = expandUGCGeocodes(ugc)
+		a.SAMECodes = splitGeocodes(same)
+
+		alerts = append(alerts, a)
+	}
+
+	return alerts, next, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// alertSeverityRanks orders the keys of AlertSeverities from least to most
+// severe.
+var alertSeverityRanks = map[string]int{
+	"Unknown":  0,
+	"Minor":    1,
+	"Moderate": 2,
+	"Severe":   3,
+	"Extreme":  4,
+}
+
+// An AlertFilter selects alerts, for example to decide which alerts to notify.
+// An alert matches if it matches every field that is set; a filter with no
+// fields set matches every alert.
+type AlertFilter struct {
+	// MinSeverity is the least severe alert matched, a key of
+	// AlertSeverities.
+	MinSeverity string
+
+	// Urgencies and Events match alerts with any of the urgencies (keys of
+	// AlertUrgencies) or events (e.g. "Winter Storm Warning", compared
+	// without regard to case) listed.
+	Urgencies []string
+	Events    []string
+
+	// Start and End match alerts in effect at some time between them (see
+	// Alert.EffectiveRange). A zero Start or End is unbounded.
+	Start time.Time
+	End   time.Time
+
+	// Zones and Points match alerts covering any of the UGC zones (see
+	// Alert.CoversZone) or points (see Alert.CoversPoint) listed.
+	Zones  []string
+	Points []Point
+}
+
+// Validate returns an error if the filter has an unknown severity or urgency
+// or a malformed zone, so that mistakes in notification rules surface before
+// they silently match nothing.
+func (f AlertFilter) Validate() error {
+	if _, ok := alertSeverityRanks[f.MinSeverity]; f.MinSeverity != "" && !ok {
+		return fmt.Errorf("invalid severity: \"%s\"", f.MinSeverity)
+	}
+	for _, u := range f.Urgencies {
+		if _, ok := AlertUrgencies[u]; !ok {
+			return fmt.Errorf("invalid urgency: \"%s\"", u)
+		}
+	}
+	for _, z := range f.Zones {
+		if err := ValidateZoneID(z); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an alert matches the filter.
+func (f AlertFilter) Matches(a Alert) bool {
+	if f.MinSeverity != "" && alertSeverityRanks[a.Severity] < alertSeverityRanks[f.MinSeverity] {
+		return false
+	}
+	if len(f.Urgencies) > 0 && !containsString(f.Urgencies, a.Urgency) {
+		return false
+	}
+	if len(f.Events) > 0 {
+		var ok bool
+		for _, e := range f.Events {
+			ok = ok || strings.EqualFold(e, a.Event)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !f.Start.IsZero() || !f.End.IsZero() {
+		start, end := a.EffectiveRange()
+		if (!f.End.IsZero() && start.After(f.End)) || (!f.Start.IsZero() && !end.IsZero() && end.Before(f.Start)) {
+			return false
+		}
+	}
+	if len(f.Zones) > 0 || len(f.Points) > 0 {
+		var ok bool
+		for _, z := range f.Zones {
+			ok = ok || a.CoversZone(z)
+		}
+		for _, p := range f.Points {
+			ok = ok || a.CoversPoint(p.Lat, p.Lon)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterAlerts returns the alerts that match a filter, in their original
+// order.
+func FilterAlerts(alerts []Alert, f AlertFilter) []Alert {
+	var matched []Alert
+	for _, a := range alerts {
+		if f.Matches(a) {
+			matched = append(matched, a)
+		}
+	}
+	return matched
+}
+
+// EffectiveRange returns when an alert takes and stops taking effect: from
+// its effective time (or sent time) until its end time (or expiration time).
+// The end is zero if the alert has neither.
+func (a Alert) EffectiveRange() (time.Time, time.Time) {
+	start := a.TimeEffective
+	if start.IsZero() {
+		start = a.TimeSent
+	}
+	end := a.TimeEnds
+	if end.IsZero() {
+		end = a.TimeExpires
+	}
+	return start, end
+}
+
+// containsString reports whether ss contains s.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import "strings"
+
+// DefaultCAPLanguage is the language of a CAP info block that doesn't specify
+// one, per the CAP 1.2 specification.
+const DefaultCAPLanguage = "en-US"
+
+// An AlertInfo represents the language-specific content of an alert: one CAP
+// info block. Alerts may carry the same information in several languages
+// (e.g. "en-US" and "es-US").
+type AlertInfo struct {
+	Language    string // RFC 3066 language code, never empty
+	Event       string
+	SenderName  string
+	Headline    string
+	Description string
+	Instruction string
+	Web         string // URL with additional information, if provided
+}
+
+// newAlertInfosFromCAP returns the info blocks of a CAP document.
+func newAlertInfosFromCAP(doc *capAlertRaw) []AlertInfo {
+	var infos []AlertInfo
+	for _, iRaw := range doc.Info {
+		infos = append(infos, AlertInfo{
+			Language:    languageOrDefault(iRaw.Language),
+			Event:       strings.TrimSpace(iRaw.Event),
+			SenderName:  strings.TrimSpace(iRaw.SenderName),
+			Headline:    strings.TrimSpace(iRaw.Headline),
+			Description: strings.TrimSpace(iRaw.Description),
+			Instruction: strings.TrimSpace(iRaw.Instruction),
+			Web:         strings.TrimSpace(iRaw.Web),
+		})
+	}
+	return infos
+}
+
+// languageOrDefault returns a trimmed language code, or DefaultCAPLanguage if
+// it is empty.
+func languageOrDefault(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return DefaultCAPLanguage
+	}
+	return lang
+}
+
+// infos returns the alert's info blocks. If they weren't retrieved, the
+// alert's own fields are returned as a single info block in the default
+// language, since the GeoJSON representation carries only that one.
+func (a Alert) infos() []AlertInfo {
+	if len(a.Infos) > 0 {
+		return a.Infos
+	}
+	return []AlertInfo{{
+		Language:    DefaultCAPLanguage,
+		Event:       a.Event,
+		SenderName:  a.SenderName,
+		Headline:    a.Headline,
+		Description: a.Description,
+		Instruction: a.Instruction,
+	}}
+}
+
+// DefaultInfo returns the alert's info block in DefaultCAPLanguage, or its
+// first info block if none is in that language.
+func (a Alert) DefaultInfo() AlertInfo {
+	infos := a.infos()
+	for _, info := range infos {
+		if strings.EqualFold(info.Language, DefaultCAPLanguage) {
+			return info
+		}
+	}
+	return infos[0]
+}
+
+// InfoForLanguage returns the alert's info block in a language (e.g. "es-US").
+// Languages are compared case-insensitively. If there is no exact match, an
+// info block with the same primary language is returned (e.g. "es-MX" for
+// "es-US", or "es-US" for "es"). An empty language returns DefaultInfo. false
+// is returned if there is no info block in the language.
+func (a Alert) InfoForLanguage(lang string) (AlertInfo, bool) {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return a.DefaultInfo(), true
+	}
+	infos := a.infos()
+	for _, info := range infos {
+		if strings.EqualFold(info.Language, lang) {
+			return info, true
+		}
+	}
+	primary := primaryLanguage(lang)
+	for _, info := range infos {
+		if primaryLanguage(info.Language) == primary {
+			return info, true
+		}
+	}
+	return AlertInfo{}, false
+}
+
+// Languages returns the languages of the alert's info blocks, in order.
+func (a Alert) Languages() []string {
+	var langs []string
+	for _, info := range a.infos() {
+		langs = append(langs, info.Language)
+	}
+	return langs
+}
+
+// primaryLanguage returns the lower case primary subtag of a language code
+// (e.g. "es" for "es-US").
+func primaryLanguage(lang string) string {
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.ToLower(lang)
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const getAlertEndpointURLStringFmt = "alerts/%s" // id
+
+// AlertState is the state of an alert lifecycle. See AlertLifecycles.
+type AlertState string
+
+// AlertStates
+const (
+	AlertStateActive    AlertState = "active"
+	AlertStateCancelled AlertState = "cancelled" // terminal
+	AlertStateExpired   AlertState = "expired"   // terminal
+)
+
+// AlertLifecycleState returns the state of an alert lifecycle at a time, as
+// determined by its last alert.
+func AlertLifecycleState(lifecycle []Alert, now time.Time) AlertState {
+	if len(lifecycle) == 0 {
+		return AlertStateExpired
+	}
+	last := lifecycle[len(lifecycle)-1]
+	switch {
+	case last.MessageType == "Cancel":
+		return AlertStateCancelled
+	case !last.TimeExpires.IsZero() && !last.TimeExpires.After(now):
+		return AlertStateExpired
+	}
+	return AlertStateActive
+}
+
+// RefreshAlertLifecycleState retrieves the last alert of a lifecycle by ID and
+// returns the lifecycle's current state. If the API responds that the alert
+// has expired, the lifecycle is closed as AlertStateExpired rather than
+// returning an error.
+func (c *Client) RefreshAlertLifecycleState(lifecycle []Alert) (AlertState, error) {
+	if len(lifecycle) == 0 {
+		return AlertStateExpired, nil
+	}
+	a, err := getAlert(c.httpClient, c.httpUserAgentString, c.apiURLString, lifecycle[len(lifecycle)-1].ID)
+	if errors.Is(err, ErrExpiredAlert) {
+		return AlertStateExpired, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	refreshed := append(append([]Alert(nil), lifecycle[:len(lifecycle)-1]...), *a)
+	return AlertLifecycleState(refreshed, time.Now()), nil
+}
+
+// getAlert retrieves from the NWS API a single alert by ID. ErrExpiredAlert is
+// returned (wrapped) if the API responds that the alert has expired.
+func getAlert(httpClient *http.Client, httpUserAgentString string, apiURLString string, id string) (*Alert, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getAlertEndpointURLStringFmt, id),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// a single alert is a GeoJSON feature rather than a feature collection
+	wrapped := make([]byte, 0, len(respBody)+16)
+	wrapped = append(wrapped, `{"features":[`...)
+	wrapped = append(wrapped, respBody...)
+	wrapped = append(wrapped, `]}`...)
+	alerts, err := newAlertsFromAlertsRespBody(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) != 1 {
+		return nil, fmt.Errorf("%w: %s", ErrEmptyResponse, id)
+	}
+	return &alerts[0], nil
+}
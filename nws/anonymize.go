@@ -0,0 +1,214 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	anonPointRegexp     = regexp.MustCompile(`(-?\d+\.\d+),(-?\d+\.\d+)`)
+	anonGridpointRegexp = regexp.MustCompile(`gridpoints/([A-Z]{3})/(\d+),(\d+)`)
+	anonStationRegexp   = regexp.MustCompile(`/stations/([A-Z0-9]{3,5})\b`)
+)
+
+// AnonymizeOptions control how Anonymize disguises a location. Offsets are
+// added to every latitude, longitude, and grid index so that geometry keeps
+// its shape. Choose offsets large enough to move the location well away from
+// the original (e.g. a degree or more) and don't publish them.
+type AnonymizeOptions struct {
+	LatOffset  float64
+	LonOffset  float64
+	GridOffset int
+}
+
+// Anonymize returns a copy of an NWS API response body (e.g. one recorded for
+// a bug report) with identifying location information scrubbed while
+// preserving its structure:
+//   - GeoJSON coordinates, and points in URLs, are shifted by the offsets
+//   - gridX, gridY, and gridpoint URLs are shifted by GridOffset
+//   - station identifiers are replaced everywhere they appear, including in
+//     URLs and raw METARs, and station names are replaced
+//   - city names are replaced
+// Office and zone identifiers are left as is, so the region remains apparent.
+//
+// The result is indented JSON with object keys sorted.
+func Anonymize(respBody []byte, opts AnonymizeOptions) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(respBody))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	a := anonymizer{opts: opts, stations: make(map[string]string)}
+	a.collectStations(doc)
+	doc = a.anonymize(doc)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// anonymizer holds the state of a single call to Anonymize.
+type anonymizer struct {
+	opts     AnonymizeOptions
+	stations map[string]string // original station identifier to replacement
+
+	stationRegexps []*regexp.Regexp // match each original identifier as a word
+}
+
+// collectStations finds the station identifiers in a document and assigns
+// each a replacement. Replacements are assigned in sorted order so that the
+// result doesn't depend on map iteration order.
+func (a *anonymizer) collectStations(doc interface{}) {
+	found := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if id, ok := v["stationIdentifier"].(string); ok && id != "" {
+				found[id] = true
+			}
+			for _, vv := range v {
+				walk(vv)
+			}
+		case []interface{}:
+			for _, vv := range v {
+				walk(vv)
+			}
+		case string:
+			for _, m := range anonStationRegexp.FindAllStringSubmatch(v, -1) {
+				found[m[1]] = true
+			}
+		}
+	}
+	walk(doc)
+
+	ids := make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		a.stations[id] = fmt.Sprintf("X%03d", i+1)
+		a.stationRegexps = append(a.stationRegexps, regexp.MustCompile(`\b`+regexp.QuoteMeta(id)+`\b`))
+	}
+}
+
+// anonymize returns an anonymized copy of a value.
+func (a *anonymizer) anonymize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		stationID, isStation := v["stationIdentifier"].(string)
+		for k, vv := range v {
+			switch {
+			case k == "coordinates":
+				v[k] = a.shiftCoordinates(vv)
+			case k == "gridX" || k == "gridY":
+				v[k] = shiftNumber(vv, float64(a.opts.GridOffset))
+			case k == "city":
+				v[k] = "Anytown"
+			case k == "name" && isStation:
+				v[k] = "Station " + a.stations[stationID]
+			default:
+				v[k] = a.anonymize(vv)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = a.anonymize(vv)
+		}
+		return v
+	case string:
+		return a.anonymizeString(v)
+	}
+	return v
+}
+
+// shiftCoordinates shifts GeoJSON coordinates, which may be a position or
+// nested arrays of positions. Positions are longitude first.
+func (a *anonymizer) shiftCoordinates(v interface{}) interface{} {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	if len(arr) >= 2 {
+		if _, ok := arr[0].(json.Number); ok {
+			arr[0] = shiftNumber(arr[0], a.opts.LonOffset)
+			arr[1] = shiftNumber(arr[1], a.opts.LatOffset)
+			return arr
+		}
+	}
+	for i, vv := range arr {
+		arr[i] = a.shiftCoordinates(vv)
+	}
+	return arr
+}
+
+// anonymizeString shifts points and gridpoints in a string (usually a URL) and
+// replaces station identifiers.
+func (a *anonymizer) anonymizeString(s string) string {
+	s = anonGridpointRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sm := anonGridpointRegexp.FindStringSubmatch(m)
+		x, _ := strconv.Atoi(sm[2])
+		y, _ := strconv.Atoi(sm[3])
+		return fmt.Sprintf("gridpoints/%s/%d,%d", sm[1], x+a.opts.GridOffset, y+a.opts.GridOffset)
+	})
+	s = anonPointRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sm := anonPointRegexp.FindStringSubmatch(m)
+		return shiftDecimalString(sm[1], a.opts.LatOffset) + "," + shiftDecimalString(sm[2], a.opts.LonOffset)
+	})
+	for _, re := range a.stationRegexps {
+		s = re.ReplaceAllStringFunc(s, func(id string) string { return a.stations[id] })
+	}
+	return s
+}
+
+// shiftNumber adds an offset to a json.Number, keeping the original number of
+// decimal places. Other values are returned unchanged.
+func shiftNumber(v interface{}, offset float64) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	return json.Number(shiftDecimalString(string(n), offset))
+}
+
+// shiftDecimalString adds an offset to a decimal number string, keeping the
+// original number of decimal places.
+func shiftDecimalString(s string, offset float64) string {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	places := 0
+	if i := strings.Index(s, "."); i >= 0 {
+		places = len(s) - i - 1
+	}
+	return strconv.FormatFloat(f+offset, 'f', places, 64)
+}
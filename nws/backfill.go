@@ -0,0 +1,132 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// defaultBackfillThrottle is the minimum time between requests made by
+	// Backfill, to avoid spiking the API after a long outage.
+	defaultBackfillThrottle = 2 * time.Second
+
+	// maxBackfillAlertPages limits the number of pages of alert history that
+	// Backfill will retrieve.
+	maxBackfillAlertPages = 10
+)
+
+// A Backfill holds the data that was missed while a Client (or the program
+// using it) was not running.
+type Backfill struct {
+	Since time.Time
+
+	// Observations from the default station, oldest first.
+	Observations []Observation
+
+	// AlertLifecycles contains every alert sent for the point since Since,
+	// grouped into lifecycles. See AlertLifecycles.
+	AlertLifecycles [][]Alert
+}
+
+// Backfill retrieves the observations from the default station and the alerts
+// for the point that were sent since a time, such as when a program last ran.
+// The latest observation and active alerts are not updated.
+//
+// Requests are made at most once per BackfillThrottle (or every two seconds if
+// it is zero).
+func (c *Client) Backfill(since time.Time) (*Backfill, error) {
+	throttle := c.BackfillThrottle
+	if throttle <= 0 {
+		throttle = defaultBackfillThrottle
+	}
+	tick := time.NewTicker(throttle)
+	defer tick.Stop()
+
+	now := time.Now()
+	b := &Backfill{Since: since}
+
+	obs, err := getObservationsForStation(c.httpClient, c.httpUserAgentString, c.apiURLString, c.defaultStationID, since, now)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(obs, func(i, j int) bool { return obs[i].TimeObserved.Before(obs[j].TimeObserved) })
+	b.Observations = obs
+
+	var alerts []Alert
+	var endpoint string
+	for page := 0; page < maxBackfillAlertPages; page++ {
+		<-tick.C
+		var as []Alert
+		as, endpoint, err = getAlertsForPoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.point, since, now, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, as...)
+		if endpoint == "" {
+			break
+		}
+	}
+	b.AlertLifecycles = AlertLifecycles(alerts)
+
+	return b, nil
+}
+
+// AlertLifecycles groups alerts into lifecycles. A lifecycle is an initial
+// alert followed by the updates and cancellations that reference it, directly
+// or indirectly. Alerts within each lifecycle are ordered by the time they were
+// sent, and lifecycles are ordered by the time their first alert was sent.
+//
+// The last alert of a lifecycle is its current state. A lifecycle whose last
+// alert is a cancellation or has expired is closed; see AlertLifecycleState.
+func AlertLifecycles(alerts []Alert) [][]Alert {
+	// union-find over alert IDs
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		if p, ok := parent[id]; ok && p != id {
+			parent[id] = find(p)
+			return parent[id]
+		}
+		parent[id] = id
+		return id
+	}
+	for _, a := range alerts {
+		for _, ref := range a.References {
+			parent[find(a.ID)] = find(ref)
+		}
+	}
+
+	groups := make(map[string][]Alert)
+	seen := make(map[string]bool)
+	for _, a := range alerts {
+		if seen[a.ID] {
+			continue // pages may overlap
+		}
+		seen[a.ID] = true
+		root := find(a.ID)
+		groups[root] = append(groups[root], a)
+	}
+
+	var lcs [][]Alert
+	for _, g := range groups {
+		sort.Slice(g, func(i, j int) bool { return g[i].TimeSent.Before(g[j].TimeSent) })
+		lcs = append(lcs, g)
+	}
+	sort.Slice(lcs, func(i, j int) bool { return lcs[i][0].TimeSent.Before(lcs[j][0].TimeSent) })
+
+	return lcs
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// binaryFormatVersion is the first byte of every binary encoding. It changes
+// only if a field is removed or changes type; gob ignores fields that are
+// added, so older and newer versions of this package may share a cache.
+const binaryFormatVersion = 1
+
+// The binary types have the same fields as the types they encode, but none of
+// their methods, so that gob doesn't call MarshalBinary recursively. Fields
+// added to Forecast must be added to forecastBinary too.
+type (
+	periodBinary      Period
+	observationBinary Observation
+	alertBinary       Alert
+	forecastBinary    struct {
+		Gridpoint     Gridpoint
+		Source        string
+		TimeRetrieved time.Time
+		TimeForecast  time.Time
+		TimeValid     time.Time
+		ValidDuration time.Duration
+		Elevation     ValueUnit
+		Geometry      ForecastGeometry
+		Periods       []periodBinary
+		ResponseMeta  *ResponseMeta
+	}
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the forecast
+// compactly for caches and message queues. The encoding is gob, preceded by a
+// version byte.
+func (f Forecast) MarshalBinary() ([]byte, error) {
+	fb := forecastBinary{Gridpoint: f.Gridpoint, Source: f.Source, TimeRetrieved: f.TimeRetrieved, TimeForecast: f.TimeForecast, TimeValid: f.TimeValid, ValidDuration: f.ValidDuration, Elevation: f.Elevation, Geometry: f.Geometry, ResponseMeta: f.ResponseMeta}
+	for _, p := range f.Periods {
+		fb.Periods = append(fb.Periods, periodBinary(p))
+	}
+	return marshalBinary(fb)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *Forecast) UnmarshalBinary(data []byte) error {
+	var fb forecastBinary
+	if err := unmarshalBinary(data, &fb); err != nil {
+		return err
+	}
+	*f = Forecast{Gridpoint: fb.Gridpoint, Source: fb.Source, TimeRetrieved: fb.TimeRetrieved, TimeForecast: fb.TimeForecast, TimeValid: fb.TimeValid, ValidDuration: fb.ValidDuration, Elevation: fb.Elevation, Geometry: fb.Geometry, ResponseMeta: fb.ResponseMeta}
+	for _, p := range fb.Periods {
+		f.Periods = append(f.Periods, Period(p))
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Periods are usually
+// encoded as part of a Forecast, which is more compact than encoding each.
+func (p Period) MarshalBinary() ([]byte, error) {
+	return marshalBinary(periodBinary(p))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *Period) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, (*periodBinary)(p))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (o Observation) MarshalBinary() ([]byte, error) {
+	return marshalBinary(observationBinary(o))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (o *Observation) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, (*observationBinary)(o))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (a Alert) MarshalBinary() ([]byte, error) {
+	return marshalBinary(alertBinary(a))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (a *Alert) UnmarshalBinary(data []byte) error {
+	return unmarshalBinary(data, (*alertBinary)(a))
+}
+
+// marshalBinary returns the version byte followed by the gob encoding of v.
+func marshalBinary(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(binaryFormatVersion)
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalBinary decodes data encoded by marshalBinary into v.
+func unmarshalBinary(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return errors.New("binary data is empty")
+	}
+	if data[0] != binaryFormatVersion {
+		return fmt.Errorf("unsupported binary format version: %d", data[0])
+	}
+	return gob.NewDecoder(bytes.NewReader(data[1:])).Decode(v)
+}
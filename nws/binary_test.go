@@ -0,0 +1,60 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestForecastBinaryFields(t *testing.T) {
+	ft := reflect.TypeOf(Forecast{})
+	bt := reflect.TypeOf(forecastBinary{})
+	if ft.NumField() != bt.NumField() {
+		t.Fatalf("Forecast has %d fields, forecastBinary %d", ft.NumField(), bt.NumField())
+	}
+	for i := 0; i < ft.NumField(); i++ {
+		if _, ok := bt.FieldByName(ft.Field(i).Name); !ok {
+			t.Errorf("forecastBinary lacks %s", ft.Field(i).Name)
+		}
+	}
+}
+
+func TestForecastMarshalBinary(t *testing.T) {
+	made := time.Date(2019, 8, 30, 21, 4, 11, 0, time.UTC)
+	f := Forecast{
+		Gridpoint:     Gridpoint{WFO: "PQR", GridX: 112, GridY: 103},
+		Source:        SourceAPI,
+		TimeForecast:  made,
+		TimeValid:     made,
+		ValidDuration: 7 * 24 * time.Hour,
+		Periods: []Period{
+			{ID: "1", Name: "This Afternoon", Temperature: ValueUnit{Value: 84, Unit: "F"}},
+		},
+		ResponseMeta: &ResponseMeta{CorrelationID: "1b2c3d", ETag: `"abc"`, Date: made},
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Forecast
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, f) {
+		t.Errorf("got %+v; want %+v", got, f)
+	}
+}
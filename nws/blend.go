@@ -0,0 +1,76 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import "time"
+
+// BlendForecasts returns an hourly forecast covering the full range of a
+// semidaily forecast. The periods of the hourly forecast are used as is.
+// Beyond the end of the hourly forecast, each semidaily period is split into
+// one hour periods that repeat its conditions, with Derived set to true.
+//
+// Derived periods carry the semidaily period's temperature (the high or low),
+// wind, and chance of precipitation for every hour, so they are coarse. Their
+// precipitation amounts are cleared since those apply to the whole semidaily
+// period. If the semidaily period is split, each hour takes the icon
+// condition of the half containing it.
+func BlendForecasts(hourly Forecast, semidaily Forecast) Forecast {
+	blended := hourly
+	blended.Periods = append([]Period(nil), hourly.Periods...)
+
+	var horizon time.Time
+	number := 0
+	if n := len(hourly.Periods); n > 0 {
+		horizon = hourly.Periods[n-1].TimeEnd
+		number = hourly.Periods[n-1].Number
+	}
+
+	for _, sp := range semidaily.Periods {
+		if !sp.TimeEnd.After(horizon) {
+			continue
+		}
+		start := sp.TimeStart
+		if start.Before(horizon) {
+			start = horizon
+		}
+		for t := start; t.Before(sp.TimeEnd); t = t.Add(time.Hour) {
+			number++
+			p := sp
+			p.Number = number
+			p.TimeStart = t
+			p.TimeEnd = t.Add(time.Hour)
+			if p.TimeEnd.After(sp.TimeEnd) {
+				p.TimeEnd = sp.TimeEnd
+			}
+			p.RainfallAmountMin, p.RainfallAmountMax = ValueUnit{}, ValueUnit{}
+			p.SnowAmountMin, p.SnowAmountMax = ValueUnit{}, ValueUnit{}
+			p.IceAmountMin, p.IceAmountMax = ValueUnit{}, ValueUnit{}
+			if sp.IsSplit() {
+				half := sp.FirstHalf
+				if !t.Before(sp.SecondHalf.TimeStart) {
+					half = sp.SecondHalf
+				}
+				p.Icon.Conditions = []IconCondition{half.Condition}
+			}
+			p.FirstHalf, p.SecondHalf = nil, nil
+			p.Derived = true
+			p.ID = PeriodID(semidaily, p)
+			blended.Periods = append(blended.Periods, p)
+		}
+		horizon = sp.TimeEnd
+	}
+
+	return blended
+}
@@ -0,0 +1,406 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// kinds of xmlNode
+const (
+	xmlNodeElement = iota
+	xmlNodeText
+	xmlNodeComment
+	xmlNodeProcInst
+)
+
+// An xmlDocument is a parsed XML document that, unlike the structs decoded by
+// encoding/xml, keeps everything needed to canonicalize it: name prefixes,
+// namespace declarations, comments, and processing instructions.
+type xmlDocument struct {
+	prolog []xmlNode // comments and processing instructions before the root
+	root   *xmlElement
+	epilog []xmlNode // comments and processing instructions after the root
+}
+
+// An xmlElement is an element of an xmlDocument. The Space of names holds the
+// prefix rather than the namespace, and namespace declarations are kept as
+// attributes.
+type xmlElement struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []xmlNode
+	parent   *xmlElement
+}
+
+// An xmlNode is a child of an xmlElement, or a node outside the root element.
+type xmlNode struct {
+	kind   int         // one of the xmlNode* constants
+	elem   *xmlElement // if an element
+	data   string      // character data, comment, or processing instruction
+	target string      // processing instruction target
+}
+
+// parseXMLDocument parses an XML document. The XML declaration, directives,
+// and whitespace outside the root element are dropped.
+func parseXMLDocument(data []byte) (*xmlDocument, error) {
+	doc := &xmlDocument{}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = true
+	var cur *xmlElement
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var n xmlNode
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &xmlElement{name: t.Name, attrs: append([]xml.Attr(nil), t.Attr...), parent: cur}
+			if cur != nil {
+				cur.children = append(cur.children, xmlNode{kind: xmlNodeElement, elem: e})
+			} else if doc.root == nil {
+				doc.root = e
+			} else {
+				return nil, errors.New("xml: more than one root element")
+			}
+			cur = e
+			continue
+		case xml.EndElement:
+			if cur == nil || t.Name != cur.name {
+				return nil, errors.New("xml: unexpected end element")
+			}
+			cur = cur.parent
+			continue
+		case xml.CharData:
+			if cur == nil {
+				continue
+			}
+			n = xmlNode{kind: xmlNodeText, data: string(t)}
+		case xml.Comment:
+			n = xmlNode{kind: xmlNodeComment, data: string(t)}
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				continue
+			}
+			n = xmlNode{kind: xmlNodeProcInst, target: t.Target, data: strings.TrimLeft(string(t.Inst), " \t\r\n")}
+		default:
+			continue
+		}
+
+		switch {
+		case cur != nil:
+			cur.children = append(cur.children, n)
+		case doc.root == nil:
+			doc.prolog = append(doc.prolog, n)
+		default:
+			doc.epilog = append(doc.epilog, n)
+		}
+	}
+	if doc.root == nil || cur != nil {
+		return nil, errors.New("xml: incomplete document")
+	}
+	return doc, nil
+}
+
+// namespace returns the namespace of the element.
+func (e *xmlElement) namespace() string {
+	ns, _ := e.lookupNamespace(e.name.Space)
+	return ns
+}
+
+// lookupNamespace returns the namespace bound to a prefix ("" for the default
+// namespace) in the scope of the element. false is returned if the prefix is
+// not bound.
+func (e *xmlElement) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+	for ; e != nil; e = e.parent {
+		for _, a := range e.attrs {
+			if isNamespaceDecl(a) && namespaceDeclPrefix(a) == prefix {
+				return a.Value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+// inScopePrefixes returns the prefixes bound in the scope of the element,
+// including "" if a default namespace is declared.
+func (e *xmlElement) inScopePrefixes() []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for ; e != nil; e = e.parent {
+		for _, a := range e.attrs {
+			if p := namespaceDeclPrefix(a); isNamespaceDecl(a) && !seen[p] {
+				seen[p] = true
+				prefixes = append(prefixes, p)
+			}
+		}
+	}
+	return prefixes
+}
+
+// child returns the first child element with a namespace and local name, or
+// nil if there is none.
+func (e *xmlElement) child(ns string, local string) *xmlElement {
+	for _, n := range e.children {
+		if n.kind == xmlNodeElement && n.elem.name.Local == local && n.elem.namespace() == ns {
+			return n.elem
+		}
+	}
+	return nil
+}
+
+// childElements returns the child elements with a namespace and local name.
+func (e *xmlElement) childElements(ns string, local string) []*xmlElement {
+	var es []*xmlElement
+	for _, n := range e.children {
+		if n.kind == xmlNodeElement && n.elem.name.Local == local && n.elem.namespace() == ns {
+			es = append(es, n.elem)
+		}
+	}
+	return es
+}
+
+// attr returns the value of an unprefixed attribute, or an empty string.
+func (e *xmlElement) attr(local string) string {
+	for _, a := range e.attrs {
+		if a.Name.Space == "" && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// text returns the concatenated character data of the element's children.
+func (e *xmlElement) text() string {
+	var b strings.Builder
+	for _, n := range e.children {
+		if n.kind == xmlNodeText {
+			b.WriteString(n.data)
+		}
+	}
+	return b.String()
+}
+
+// findByID returns the element with an ID, Id, or id attribute equal to id,
+// searching e and its descendants, or nil if there is none.
+func (e *xmlElement) findByID(id string) *xmlElement {
+	for _, local := range []string{"ID", "Id", "id"} {
+		if e.attr(local) == id {
+			return e
+		}
+	}
+	for _, n := range e.children {
+		if n.kind == xmlNodeElement {
+			if found := n.elem.findByID(id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// isNamespaceDecl reports whether an attribute declares a namespace.
+func isNamespaceDecl(a xml.Attr) bool {
+	return a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns")
+}
+
+// namespaceDeclPrefix returns the prefix declared by a namespace declaration,
+// or "" for the default namespace.
+func namespaceDeclPrefix(a xml.Attr) string {
+	if a.Name.Space == "xmlns" {
+		return a.Name.Local
+	}
+	return ""
+}
+
+// A c14nMethod canonicalizes XML per Canonical XML 1.0 or Exclusive XML
+// Canonicalization 1.0, as used by XML signatures.
+//
+// https://www.w3.org/TR/xml-c14n
+// https://www.w3.org/TR/xml-exc-c14n/
+type c14nMethod struct {
+	exclusive         bool
+	comments          bool
+	inclusivePrefixes []string // exclusive only; "" is the default namespace
+}
+
+// canonicalizeDocument returns the canonical form of a document, omitting the
+// element exclude (if not nil) and its descendants.
+func (m c14nMethod) canonicalizeDocument(doc *xmlDocument, exclude *xmlElement) []byte {
+	var b bytes.Buffer
+	for _, n := range doc.prolog {
+		if m.writeNode(&b, n, exclude, nil) {
+			b.WriteByte('\n')
+		}
+	}
+	m.writeElement(&b, doc.root, exclude, map[string]string{})
+	for _, n := range doc.epilog {
+		if n.kind != xmlNodeComment || m.comments {
+			b.WriteByte('\n')
+			m.writeNode(&b, n, exclude, nil)
+		}
+	}
+	return b.Bytes()
+}
+
+// canonicalize returns the canonical form of the subtree rooted at an
+// element, omitting the element exclude (if not nil) and its descendants.
+func (m c14nMethod) canonicalize(e *xmlElement, exclude *xmlElement) []byte {
+	var b bytes.Buffer
+	m.writeElement(&b, e, exclude, map[string]string{})
+	return b.Bytes()
+}
+
+// writeNode writes the canonical form of a node and reports whether anything
+// was written. rendered holds the namespace declarations in effect in the
+// output, keyed by prefix.
+func (m c14nMethod) writeNode(b *bytes.Buffer, n xmlNode, exclude *xmlElement, rendered map[string]string) bool {
+	switch n.kind {
+	case xmlNodeElement:
+		if n.elem == exclude {
+			return false
+		}
+		m.writeElement(b, n.elem, exclude, rendered)
+	case xmlNodeText:
+		b.WriteString(c14nTextReplacer.Replace(n.data))
+	case xmlNodeComment:
+		if !m.comments {
+			return false
+		}
+		b.WriteString("<!--" + n.data + "-->")
+	case xmlNodeProcInst:
+		b.WriteString("<?" + n.target)
+		if n.data != "" {
+			b.WriteString(" " + n.data)
+		}
+		b.WriteString("?>")
+	}
+	return true
+}
+
+// writeElement writes the canonical form of an element.
+func (m c14nMethod) writeElement(b *bytes.Buffer, e *xmlElement, exclude *xmlElement, rendered map[string]string) {
+	// namespace declarations: all those in scope for inclusive
+	// canonicalization, only those visibly utilized for exclusive
+	var prefixes []string
+	if m.exclusive {
+		prefixes = append(prefixes, e.name.Space)
+		for _, a := range e.attrs {
+			if !isNamespaceDecl(a) && a.Name.Space != "" {
+				prefixes = append(prefixes, a.Name.Space)
+			}
+		}
+		prefixes = append(prefixes, m.inclusivePrefixes...)
+	} else {
+		prefixes = e.inScopePrefixes()
+	}
+	var decls []xml.Attr
+	seen := make(map[string]bool)
+	for _, p := range prefixes {
+		if seen[p] || p == "xml" {
+			continue
+		}
+		seen[p] = true
+		ns, ok := e.lookupNamespace(p)
+		if !ok {
+			continue
+		}
+		prev, had := rendered[p]
+		if (had && prev == ns) || (p == "" && ns == "" && !had) {
+			continue // already in effect
+		}
+		decls = append(decls, xml.Attr{Name: xml.Name{Local: p}, Value: ns})
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].Name.Local < decls[j].Name.Local })
+	if len(decls) > 0 {
+		r := make(map[string]string, len(rendered)+len(decls))
+		for p, ns := range rendered {
+			r[p] = ns
+		}
+		for _, d := range decls {
+			r[d.Name.Local] = d.Value
+		}
+		rendered = r
+	}
+
+	// other attributes, ordered by namespace then local name
+	type nsAttr struct {
+		ns string
+		a  xml.Attr
+	}
+	var attrs []nsAttr
+	for _, a := range e.attrs {
+		if isNamespaceDecl(a) {
+			continue
+		}
+		ns := ""
+		if a.Name.Space != "" {
+			ns, _ = e.lookupNamespace(a.Name.Space)
+		}
+		attrs = append(attrs, nsAttr{ns: ns, a: a})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].ns != attrs[j].ns {
+			return attrs[i].ns < attrs[j].ns
+		}
+		return attrs[i].a.Name.Local < attrs[j].a.Name.Local
+	})
+
+	b.WriteString("<" + qualifiedName(e.name))
+	for _, d := range decls {
+		if d.Name.Local == "" {
+			b.WriteString(` xmlns="`)
+		} else {
+			b.WriteString(` xmlns:` + d.Name.Local + `="`)
+		}
+		b.WriteString(c14nAttrReplacer.Replace(d.Value) + `"`)
+	}
+	for _, a := range attrs {
+		b.WriteString(" " + qualifiedName(a.a.Name) + `="` + c14nAttrReplacer.Replace(a.a.Value) + `"`)
+	}
+	b.WriteString(">")
+	for _, n := range e.children {
+		m.writeNode(b, n, exclude, rendered)
+	}
+	b.WriteString("</" + qualifiedName(e.name) + ">")
+}
+
+// qualifiedName returns a name with its prefix, as parsed by RawToken.
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+var (
+	c14nTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	c14nAttrReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
@@ -0,0 +1,209 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"testing"
+)
+
+// The documents below are the examples from section 3 of Canonical XML 1.0
+// and section 2.2 of Exclusive XML Canonicalization 1.0. DTDs aren't processed,
+// so the examples that depend on one have been trimmed.
+//
+// https://www.w3.org/TR/xml-c14n#Examples
+// https://www.w3.org/TR/xml-exc-c14n/#sec-Enveloping
+
+const c14nPIsCommentsAndOutsideInput = `<?xml version="1.0"?>
+
+<?xml-stylesheet   href="doc.xsl"
+   type="text/xsl"   ?>
+
+<!DOCTYPE doc SYSTEM "doc.dtd">
+
+<doc>Hello, world!<!-- Comment 1 --></doc>
+
+<?pi-without-data     ?>
+
+<!-- Comment 2 -->
+
+<!-- Comment 3 -->`
+
+const c14nWhitespaceInput = `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`
+
+const c14nStartAndEndTagsInput = `<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e4   name="elem4"   id="elem4"   ></e4>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`
+
+const c14nCharacterModificationsInput = `<doc>
+   <text>First line&#x0d;&#10;Second line</text>
+   <value>&#x32;</value>
+   <compute><![CDATA[value>"0" && value<"10" ?"valid":"error"]]></compute>
+   <compute expr='value>"0" &amp;&amp; value&lt;"10" ?"valid":"error"'>valid</compute>
+   <norm attr=' &apos;   &#x20;&#13;&#xa;&#9;   &apos; '/>
+</doc>`
+
+const c14nExclusiveInput = `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+  <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n0:local>`
+
+func TestC14NMethodCanonicalizeDocument(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     c14nMethod
+		input string
+		want  string
+	}{
+		{
+			name:  "PIs, comments, and outside of document element",
+			m:     c14nMethod{},
+			input: c14nPIsCommentsAndOutsideInput,
+			want: `<?xml-stylesheet href="doc.xsl"
+   type="text/xsl"   ?>
+<doc>Hello, world!</doc>
+<?pi-without-data?>`,
+		},
+		{
+			name:  "PIs, comments, and outside of document element with comments",
+			m:     c14nMethod{comments: true},
+			input: c14nPIsCommentsAndOutsideInput,
+			want: `<?xml-stylesheet href="doc.xsl"
+   type="text/xsl"   ?>
+<doc>Hello, world!<!-- Comment 1 --></doc>
+<?pi-without-data?>
+<!-- Comment 2 -->
+<!-- Comment 3 -->`,
+		},
+		{
+			name:  "whitespace in document content",
+			m:     c14nMethod{},
+			input: c14nWhitespaceInput,
+			want:  c14nWhitespaceInput,
+		},
+		{
+			name:  "start and end tags",
+			m:     c14nMethod{},
+			input: c14nStartAndEndTagsInput,
+			want: `<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e4 id="elem4" name="elem4"></e4>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6 xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+		},
+		{
+			name:  "character modifications and character references",
+			m:     c14nMethod{},
+			input: c14nCharacterModificationsInput,
+			want: `<doc>
+   <text>First line&#xD;
+Second line</text>
+   <value>2</value>
+   <compute>value&gt;"0" &amp;&amp; value&lt;"10" ?"valid":"error"</compute>
+   <compute expr="value>&quot;0&quot; &amp;&amp; value&lt;&quot;10&quot; ?&quot;valid&quot;:&quot;error&quot;">valid</compute>
+   <norm attr=" '    &#xD;&#xA;&#x9;   ' "></norm>
+</doc>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseXMLDocument([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("parseXMLDocument: %v", err)
+			}
+			if got := string(tt.m.canonicalizeDocument(doc, nil)); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestC14NMethodCanonicalizeSubtree(t *testing.T) {
+	tests := []struct {
+		name string
+		m    c14nMethod
+		want string
+	}{
+		{
+			name: "inclusive",
+			m:    c14nMethod{},
+			want: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+    <n3:stuff></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			name: "exclusive",
+			m:    c14nMethod{exclusive: true},
+			want: `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+		},
+		{
+			name: "exclusive with inclusive namespaces",
+			m:    c14nMethod{exclusive: true, inclusivePrefixes: []string{"n0"}},
+			want: `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`,
+		},
+	}
+	doc, err := parseXMLDocument([]byte(c14nExclusiveInput))
+	if err != nil {
+		t.Fatalf("parseXMLDocument: %v", err)
+	}
+	elem2 := doc.root.child("http://example.net", "elem2")
+	if elem2 == nil {
+		t.Fatal("elem2 not found")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.m.canonicalize(elem2, nil)); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A ResponseCache stores API response bodies so that several processes (or
+// Clients) can share them instead of each making the same requests.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the value stored under key, or false if there is none or
+	// it has expired.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores a value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// CacheMiddleware returns Middleware that answers GET requests from a
+// ResponseCache when possible and stores successful responses in it. Entries
+// are kept for the max-age given by the API's Cache-Control header, or for
+// defaultTTL if there is none. Cache errors are ignored, so a broken cache
+// only means more requests.
+//
+// The headers in cachedResponseHeaders are stored with each response, so
+// that ResponseMeta and conditional requests work for cached responses. A
+// conditional request whose If-None-Match matches the cached ETag is answered
+// 304 Not Modified. Responses larger than the limit on response bodies are
+// passed through without being stored.
+func CacheMiddleware(cache ResponseCache, defaultTTL time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != "GET" {
+				return next.RoundTrip(req)
+			}
+			key := cacheKey(req)
+			if v, ok, err := cache.Get(key); err == nil && ok {
+				if resp, ok := decodeCachedResponse(req, v); ok {
+					if inm := req.Header.Get("If-None-Match"); inm != "" && inm == resp.Header.Get("ETag") {
+						resp.Status, resp.StatusCode = "304 Not Modified", http.StatusNotModified
+						resp.Body, resp.ContentLength = http.NoBody, 0
+					}
+					return resp, nil
+				}
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil || resp.StatusCode != 200 {
+				return resp, err
+			}
+			ttl := cacheTTL(resp.Header.Get("Cache-Control"), defaultTTL)
+			if ttl <= 0 {
+				return resp, nil
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			if len(body) > maxRespBodyBytes {
+				// too large to store; the caller will reject it
+				resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+				return resp, nil
+			}
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			_ = cache.Set(key, encodeCachedResponse(resp.Header, body), ttl)
+			return resp, nil
+		})
+	}
+}
+
+// readCloser combines a Reader with the Closer of another.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// cacheKey returns the cache key for a request. The Accept header is included
+// since the same URL may be requested in several formats. The "v2" marks the
+// encoding of encodeCachedResponse, so that entries stored in shared caches by
+// older versions of this package, which lack headers, aren't used.
+func cacheKey(req *http.Request) string {
+	return "nws:v2:" + req.Header.Get("Accept") + ":" + req.URL.String()
+}
+
+// cachedResponseHeaders are the response headers stored with cached
+// responses: those used to build ResponseMeta and to make conditional
+// requests.
+var cachedResponseHeaders = []string{
+	"Cache-Control",
+	"Content-Type",
+	"Date",
+	"ETag",
+	"Expires",
+	"Last-Modified",
+	"Server",
+	"X-Correlation-Id",
+	"X-Request-Id",
+	"X-Server-Id",
+}
+
+// cacheTTL returns the max-age of a Cache-Control header, or defaultTTL if
+// there is none. Zero is returned if the response must not be stored.
+func cacheTTL(cacheControl string, defaultTTL time.Duration) time.Duration {
+	for _, d := range strings.Split(cacheControl, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		switch {
+		case d == "no-store" || d == "no-cache" || d == "private":
+			return 0
+		case strings.HasPrefix(d, "max-age="):
+			if s, err := strconv.Atoi(strings.TrimPrefix(d, "max-age=")); err == nil {
+				return time.Duration(s) * time.Second
+			}
+		}
+	}
+	return defaultTTL
+}
+
+// encodeCachedResponse encodes a response as the headers in
+// cachedResponseHeaders, one "Name: value" per line, a blank line, and its
+// body.
+func encodeCachedResponse(header http.Header, body []byte) []byte {
+	var b bytes.Buffer
+	for _, name := range cachedResponseHeaders {
+		v := header.Get(name)
+		if v == "" || strings.ContainsAny(v, "\r\n") {
+			continue
+		}
+		b.WriteString(name + ": " + v + "\n")
+	}
+	b.WriteByte('\n')
+	b.Write(body)
+	return b.Bytes()
+}
+
+// decodeCachedResponse returns a 200 response for req from an encoded cached
+// response.
+func decodeCachedResponse(req *http.Request, v []byte) (*http.Response, bool) {
+	header := http.Header{}
+	for {
+		i := bytes.IndexByte(v, '\n')
+		if i < 0 {
+			return nil, false
+		}
+		line := string(v[:i])
+		v = v[i+1:]
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, false
+		}
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    200,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(v)),
+		ContentLength: int64(len(v)),
+		Request:       req,
+	}, true
+}
+
+// A MemoryCache is a ResponseCache held in memory, for sharing responses
+// between Clients in one process.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get implements ResponseCache.
+func (c *MemoryCache) Get(key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !time.Now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set implements ResponseCache. Expired entries are removed as new ones are
+// added.
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoryCacheEntry{value: value, expires: now.Add(ttl)}
+	return nil
+}
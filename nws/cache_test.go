@@ -0,0 +1,104 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	var requests int
+	bodies := map[string]string{
+		"/small": `{"ok": true}`,
+		"/large": strings.Repeat(" ", maxRespBodyBytes+1),
+	}
+	next := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		body := bodies[req.URL.Path]
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: 200,
+			Header: http.Header{
+				"Content-Type":     {"application/geo+json"},
+				"Cache-Control":    {"public, max-age=60"},
+				"Etag":             {`"abc"`},
+				"Last-Modified":    {"Fri, 30 Aug 2019 21:04:11 GMT"},
+				"X-Correlation-Id": {"1b2c3d"},
+				"Set-Cookie":       {"session=1"},
+			},
+			Body:    ioutil.NopCloser(strings.NewReader(body)),
+			Request: req,
+		}, nil
+	})
+	rt := CacheMiddleware(NewMemoryCache(), time.Minute)(next)
+
+	get := func(path string, etag string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "https://api.weather.gov"+path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// the second request is answered from the cache with the stored headers
+	for i := 0; i < 2; i++ {
+		resp := get("/small", "")
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != 200 || string(body) != bodies["/small"] {
+			t.Fatalf("request %d: got %d %q", i+1, resp.StatusCode, body)
+		}
+		for _, name := range []string{"Content-Type", "ETag", "Last-Modified", "X-Correlation-Id"} {
+			if resp.Header.Get(name) == "" {
+				t.Errorf("request %d: no %s header", i+1, name)
+			}
+		}
+		if i > 0 && resp.Header.Get("Set-Cookie") != "" {
+			t.Errorf("request %d: Set-Cookie replayed from cache", i+1)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests; want 1", requests)
+	}
+
+	// a matching conditional request is not modified
+	if resp := get("/small", `"abc"`); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional request: got %d; want 304", resp.StatusCode)
+	}
+	if resp := get("/small", `"xyz"`); resp.StatusCode != 200 {
+		t.Errorf("conditional request with other ETag: got %d; want 200", resp.StatusCode)
+	}
+
+	// an oversized response is passed on whole and not stored
+	requests = 0
+	for i := 0; i < 2; i++ {
+		body, _ := ioutil.ReadAll(get("/large", "").Body)
+		if !bytes.Equal(body, []byte(bodies["/large"])) {
+			t.Fatalf("large request %d: got %d bytes; want %d", i+1, len(body), len(bodies["/large"]))
+		}
+	}
+	if requests != 2 {
+		t.Errorf("large: got %d requests; want 2", requests)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	getCAPForAlertEndpointURLStringFmt = "alerts/%s" // id
+	capMIMEType                        = "application/cap+xml"
+)
+
+// capAlertRaw is a CAP 1.2 alert document as returned by the NWS API when
+// requested as "application/cap+xml". Only the elements that are not present
+// in the GeoJSON representation are included.
+//
+// http://docs.oasis-open.org/emergency/cap/v1.2/CAP-v1.2.html
+type capAlertRaw struct {
+	XMLName    xml.Name `xml:"alert"`
+	Identifier string   `xml:"identifier"`
+	Info       []struct {
+		Language    string `xml:"language"`
+		Event       string `xml:"event"`
+		SenderName  string `xml:"senderName"`
+		Headline    string `xml:"headline"`
+		Description string `xml:"description"`
+		Instruction string `xml:"instruction"`
+		Web         string `xml:"web"`
+		Resources   []struct {
+			ResourceDesc string `xml:"resourceDesc"`
+			MIMEType     string `xml:"mimeType"`
+			Size         string `xml:"size"`
+			URI          string `xml:"uri"`
+			DerefURI     string `xml:"derefUri"`
+			Digest       string `xml:"digest"`
+		} `xml:"resource"`
+		Areas []struct {
+			Circles []string `xml:"circle"`
+		} `xml:"area"`
+	} `xml:"info"`
+}
+
+// defaultInfoIndex returns the index of the info block in the document's
+// default language, or of the first info block if none is in the default
+// language. -1 is returned if the document has no info blocks.
+func (doc *capAlertRaw) defaultInfoIndex() int {
+	if len(doc.Info) < 1 {
+		return -1
+	}
+	for i, info := range doc.Info {
+		if strings.EqualFold(languageOrDefault(info.Language), DefaultCAPLanguage) {
+			return i
+		}
+	}
+	return 0
+}
+
+// newCirclesFromCAP returns the circles of the areas of a CAP document's
+// default info block. Malformed circles are skipped.
+func newCirclesFromCAP(doc *capAlertRaw) []Circle {
+	i := doc.defaultInfoIndex()
+	if i < 0 {
+		return nil
+	}
+	var circles []Circle
+	for _, area := range doc.Info[i].Areas {
+		for _, s := range area.Circles {
+			if c, err := parseCAPCircle(s); err == nil {
+				circles = append(circles, c)
+			}
+		}
+	}
+	return circles
+}
+
+// getCAPForAlert retrieves from the NWS API the CAP document for an alert.
+func getCAPForAlert(httpClient *http.Client, httpUserAgentString string, apiURLString string, id string) (*capAlertRaw, error) {
+	respBody, err := doAPIRequestAccepting(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getCAPForAlertEndpointURLStringFmt, id),
+		nil,
+		capMIMEType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newCAPFromCAPRespBody(respBody)
+}
+
+// newCAPFromCAPRespBody returns a capAlertRaw pointer, given a response body
+// from the NWS API. ErrEmptyResponse is returned if the document has no
+// identifier, as an alert without one can't be referenced.
+func newCAPFromCAPRespBody(respBody []byte) (*capAlertRaw, error) {
+	// encoding/xml only expands the five predefined entities and never fetches
+	// external entities, so there is no need to guard against entity expansion
+	// beyond the response body size limit.
+	var doc capAlertRaw
+	d := xml.NewDecoder(bytes.NewReader(respBody))
+	d.Strict = true
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(doc.Identifier) == "" {
+		return nil, ErrEmptyResponse
+	}
+	return &doc, nil
+}
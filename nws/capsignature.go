@@ -0,0 +1,342 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1" // registers crypto.SHA1
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCAPSignature is returned when a CAP document's signature can't be
+// verified.
+var ErrInvalidCAPSignature = errors.New("invalid CAP signature")
+
+// XML signature namespaces and algorithm identifiers
+//
+// https://www.w3.org/TR/xmldsig-core1/
+const (
+	xmldsigNamespace             = "http://www.w3.org/2000/09/xmldsig#"
+	xmldsigMoreNamespace         = "http://www.w3.org/2001/04/xmldsig-more#"
+	xmlencNamespace              = "http://www.w3.org/2001/04/xmlenc#"
+	c14nAlgorithm                = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	c14nWithCommentsAlgorithm    = c14nAlgorithm + "#WithComments"
+	excC14NAlgorithm             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	excC14NWithCommentsAlgorithm = excC14NAlgorithm + "WithComments"
+	envelopedSignatureAlgorithm  = xmldsigNamespace + "enveloped-signature"
+)
+
+// xmldsigDigestAlgorithms maps digest algorithm identifiers to hashes.
+var xmldsigDigestAlgorithms = map[string]crypto.Hash{
+	xmldsigNamespace + "sha1":       crypto.SHA1,
+	xmlencNamespace + "sha256":      crypto.SHA256,
+	xmldsigMoreNamespace + "sha384": crypto.SHA384,
+	xmlencNamespace + "sha512":      crypto.SHA512,
+}
+
+// xmldsigSignatureAlgorithms maps signature algorithm identifiers to hashes.
+// The key type is given by the identifier's "rsa-" or "ecdsa-" prefix.
+var xmldsigSignatureAlgorithms = map[string]crypto.Hash{
+	xmldsigNamespace + "rsa-sha1":         crypto.SHA1,
+	xmldsigMoreNamespace + "rsa-sha256":   crypto.SHA256,
+	xmldsigMoreNamespace + "rsa-sha384":   crypto.SHA384,
+	xmldsigMoreNamespace + "rsa-sha512":   crypto.SHA512,
+	xmldsigMoreNamespace + "ecdsa-sha1":   crypto.SHA1,
+	xmldsigMoreNamespace + "ecdsa-sha256": crypto.SHA256,
+	xmldsigMoreNamespace + "ecdsa-sha384": crypto.SHA384,
+	xmldsigMoreNamespace + "ecdsa-sha512": crypto.SHA512,
+}
+
+// A CAPSignatureVerifier verifies the enveloped XML signatures that CAP 1.2
+// documents may carry. A signature is valid if it covers the whole alert, it
+// was made with the key of the first certificate in its KeyInfo, and that
+// certificate chains to a root in the trust store.
+//
+// Canonical XML 1.0 and Exclusive XML Canonicalization 1.0 are supported, with
+// RSA and ECDSA signatures and SHA-1 and SHA-2 digests.
+type CAPSignatureVerifier struct {
+	// Roots is the trust store. If nil, the system's roots are used.
+	Roots *x509.CertPool
+
+	// Time is the time at which certificates must be valid. If zero, the
+	// current time is used.
+	Time time.Time
+}
+
+// A CAPSignature is the result of verifying a CAP document's signature.
+type CAPSignature struct {
+	Present     bool              // the document is signed
+	Valid       bool              // the signature is valid and trusted
+	Certificate *x509.Certificate // the signer's certificate, if present
+}
+
+// AlertSignature retrieves the CAP document for an alert and verifies its
+// signature. The alerts served by the NWS API are usually not signed, in which
+// case Present is false and no error is returned.
+func (c *Client) AlertSignature(id string, v CAPSignatureVerifier) (CAPSignature, error) {
+	respBody, err := doAPIRequestAccepting(
+		c.httpClient,
+		c.httpUserAgentString,
+		c.apiURLString,
+		fmt.Sprintf(getCAPForAlertEndpointURLStringFmt, id),
+		nil,
+		capMIMEType,
+	)
+	if err != nil {
+		return CAPSignature{}, err
+	}
+	return v.Verify(respBody)
+}
+
+// Verify verifies the signature of a CAP document. If the document isn't
+// signed, Present is false and no error is returned. If it is signed but the
+// signature is not valid or not trusted, Valid is false and an error wrapping
+// ErrInvalidCAPSignature describes why.
+func (v CAPSignatureVerifier) Verify(capDoc []byte) (CAPSignature, error) {
+	doc, err := parseXMLDocument(capDoc)
+	if err != nil {
+		return CAPSignature{}, err
+	}
+	sig := doc.root.child(xmldsigNamespace, "Signature")
+	if sig == nil {
+		return CAPSignature{}, nil
+	}
+	result := CAPSignature{Present: true}
+
+	// the signer's certificate, and any intermediates
+	var certs []*x509.Certificate
+	if keyInfo := sig.child(xmldsigNamespace, "KeyInfo"); keyInfo != nil {
+		for _, x509Data := range keyInfo.childElements(xmldsigNamespace, "X509Data") {
+			for _, certElem := range x509Data.childElements(xmldsigNamespace, "X509Certificate") {
+				der, err := decodeXMLBase64(certElem.text())
+				if err != nil {
+					return result, fmt.Errorf("%w: certificate: %s", ErrInvalidCAPSignature, err)
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					return result, fmt.Errorf("%w: certificate: %s", ErrInvalidCAPSignature, err)
+				}
+				certs = append(certs, cert)
+			}
+		}
+	}
+	if len(certs) < 1 {
+		return result, fmt.Errorf("%w: no certificate", ErrInvalidCAPSignature)
+	}
+	result.Certificate = certs[0]
+
+	signedInfo := sig.child(xmldsigNamespace, "SignedInfo")
+	if signedInfo == nil {
+		return result, fmt.Errorf("%w: no SignedInfo", ErrInvalidCAPSignature)
+	}
+	if err := verifyXMLDSigReferences(doc, sig, signedInfo); err != nil {
+		return result, err
+	}
+	if err := verifyXMLDSigSignedInfo(sig, signedInfo, certs[0]); err != nil {
+		return result, err
+	}
+
+	// the certificate must be trusted
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         v.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return result, fmt.Errorf("%w: %s", ErrInvalidCAPSignature, err)
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+// verifyXMLDSigReferences checks the digests of the references in a signature's
+// SignedInfo. At least one reference must cover the root element.
+func verifyXMLDSigReferences(doc *xmlDocument, sig *xmlElement, signedInfo *xmlElement) error {
+	refs := signedInfo.childElements(xmldsigNamespace, "Reference")
+	if len(refs) < 1 {
+		return fmt.Errorf("%w: no references", ErrInvalidCAPSignature)
+	}
+	coversRoot := false
+	for _, ref := range refs {
+		uri := ref.attr("URI")
+
+		// same document references only; comments are always removed
+		var target *xmlElement
+		switch {
+		case uri == "":
+			target = doc.root
+		case strings.HasPrefix(uri, "#"):
+			target = doc.root.findByID(uri[1:])
+		}
+		if target == nil {
+			return fmt.Errorf("%w: unsupported reference: \"%s\"", ErrInvalidCAPSignature, uri)
+		}
+
+		// transforms
+		var exclude *xmlElement
+		m := c14nMethod{}
+		if transforms := ref.child(xmldsigNamespace, "Transforms"); transforms != nil {
+			for _, t := range transforms.childElements(xmldsigNamespace, "Transform") {
+				if t.attr("Algorithm") == envelopedSignatureAlgorithm {
+					exclude = sig
+					continue
+				}
+				var err error
+				if m, err = newC14NMethod(t); err != nil {
+					return err
+				}
+			}
+		}
+		m.comments = false
+
+		// digest
+		digestAlgorithm := ""
+		if dm := ref.child(xmldsigNamespace, "DigestMethod"); dm != nil {
+			digestAlgorithm = dm.attr("Algorithm")
+		}
+		hash, ok := xmldsigDigestAlgorithms[digestAlgorithm]
+		if !ok {
+			return fmt.Errorf("%w: unsupported digest algorithm: \"%s\"", ErrInvalidCAPSignature, digestAlgorithm)
+		}
+		var digestValue []byte
+		if dv := ref.child(xmldsigNamespace, "DigestValue"); dv != nil {
+			var err error
+			if digestValue, err = decodeXMLBase64(dv.text()); err != nil {
+				return fmt.Errorf("%w: digest: %s", ErrInvalidCAPSignature, err)
+			}
+		}
+		var data []byte
+		if uri == "" {
+			data = m.canonicalizeDocument(doc, exclude)
+		} else {
+			data = m.canonicalize(target, exclude)
+		}
+		h := hash.New()
+		h.Write(data)
+		if !bytes.Equal(h.Sum(nil), digestValue) {
+			return fmt.Errorf("%w: digest mismatch for reference \"%s\"", ErrInvalidCAPSignature, uri)
+		}
+
+		if target == doc.root {
+			coversRoot = true
+		}
+	}
+	if !coversRoot {
+		return fmt.Errorf("%w: signature does not cover the alert", ErrInvalidCAPSignature)
+	}
+	return nil
+}
+
+// verifyXMLDSigSignedInfo checks the signature value of a signature's
+// SignedInfo with a certificate's public key.
+func verifyXMLDSigSignedInfo(sig *xmlElement, signedInfo *xmlElement, cert *x509.Certificate) error {
+	cm := signedInfo.child(xmldsigNamespace, "CanonicalizationMethod")
+	if cm == nil {
+		return fmt.Errorf("%w: no CanonicalizationMethod", ErrInvalidCAPSignature)
+	}
+	m, err := newC14NMethod(cm)
+	if err != nil {
+		return err
+	}
+	signatureAlgorithm := ""
+	if sm := signedInfo.child(xmldsigNamespace, "SignatureMethod"); sm != nil {
+		signatureAlgorithm = sm.attr("Algorithm")
+	}
+	hash, ok := xmldsigSignatureAlgorithms[signatureAlgorithm]
+	if !ok {
+		return fmt.Errorf("%w: unsupported signature algorithm: \"%s\"", ErrInvalidCAPSignature, signatureAlgorithm)
+	}
+	var sigValue []byte
+	if sv := sig.child(xmldsigNamespace, "SignatureValue"); sv != nil {
+		if sigValue, err = decodeXMLBase64(sv.text()); err != nil {
+			return fmt.Errorf("%w: signature value: %s", ErrInvalidCAPSignature, err)
+		}
+	}
+
+	h := hash.New()
+	h.Write(m.canonicalize(signedInfo, nil))
+	digest := h.Sum(nil)
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(signatureAlgorithm[strings.LastIndex(signatureAlgorithm, "#")+1:], "rsa-") {
+			break
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, sigValue); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidCAPSignature, err)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(signatureAlgorithm[strings.LastIndex(signatureAlgorithm, "#")+1:], "ecdsa-") {
+			break
+		}
+		// XML signatures concatenate r and s rather than using ASN.1
+		if len(sigValue) == 0 || len(sigValue)%2 != 0 {
+			return fmt.Errorf("%w: malformed ECDSA signature", ErrInvalidCAPSignature)
+		}
+		r := new(big.Int).SetBytes(sigValue[:len(sigValue)/2])
+		s := new(big.Int).SetBytes(sigValue[len(sigValue)/2:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("%w: ECDSA verification failed", ErrInvalidCAPSignature)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: key type does not match signature algorithm \"%s\"", ErrInvalidCAPSignature, signatureAlgorithm)
+}
+
+// newC14NMethod returns the canonicalization method identified by the
+// Algorithm attribute of a CanonicalizationMethod or Transform element.
+func newC14NMethod(e *xmlElement) (c14nMethod, error) {
+	algorithm := e.attr("Algorithm")
+	switch algorithm {
+	case c14nAlgorithm:
+		return c14nMethod{}, nil
+	case c14nWithCommentsAlgorithm:
+		return c14nMethod{comments: true}, nil
+	case excC14NAlgorithm, excC14NWithCommentsAlgorithm:
+		m := c14nMethod{exclusive: true, comments: algorithm == excC14NWithCommentsAlgorithm}
+		if in := e.child(excC14NAlgorithm, "InclusiveNamespaces"); in != nil {
+			for _, p := range strings.Fields(in.attr("PrefixList")) {
+				if p == "#default" {
+					p = ""
+				}
+				m.inclusivePrefixes = append(m.inclusivePrefixes, p)
+			}
+		}
+		return m, nil
+	}
+	return c14nMethod{}, fmt.Errorf("%w: unsupported algorithm: \"%s\"", ErrInvalidCAPSignature, algorithm)
+}
+
+// decodeXMLBase64 decodes base64 that may be wrapped over several lines.
+func decodeXMLBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
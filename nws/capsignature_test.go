@@ -0,0 +1,220 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The signed documents in testdata/cap were signed outside of this package,
+// with xmllint canonicalizing and OpenSSL signing, by the key for
+// testdata/cap/signer.pem.
+
+// capSignatureTestTime is within the validity of the test certificates.
+var capSignatureTestTime = time.Date(2019, 6, 1, 19, 0, 0, 0, time.UTC)
+
+func readCAPSignatureTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/cap/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func readCAPSignatureTestCert(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(readCAPSignatureTestFile(t, name))
+	if block == nil {
+		t.Fatalf("%s: no PEM block", name)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// newCAPSignatureTestVerifier returns a verifier that trusts both test
+// certificates.
+func newCAPSignatureTestVerifier(t *testing.T) CAPSignatureVerifier {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(readCAPSignatureTestCert(t, "signer.pem"))
+	roots.AddCert(readCAPSignatureTestCert(t, "other.pem"))
+	return CAPSignatureVerifier{Roots: roots, Time: capSignatureTestTime}
+}
+
+// replaceElementText replaces the character data of the first element named
+// local in doc.
+func replaceElementText(t *testing.T, doc []byte, local string, text string) []byte {
+	t.Helper()
+	start := bytes.Index(doc, []byte("<"+local+">"))
+	end := bytes.Index(doc, []byte("</"+local+">"))
+	if start < 0 || end < start {
+		t.Fatalf("no %s element", local)
+	}
+	start += len(local) + 2
+	return append(append(append([]byte{}, doc[:start]...), text...), doc[end:]...)
+}
+
+func TestCAPSignatureVerifierVerify(t *testing.T) {
+	v := newCAPSignatureTestVerifier(t)
+	for _, name := range []string{"signed.xml", "signed-id.xml"} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := v.Verify(readCAPSignatureTestFile(t, name))
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !sig.Present || !sig.Valid {
+				t.Errorf("got Present %v, Valid %v; want both true", sig.Present, sig.Valid)
+			}
+			if sig.Certificate == nil || sig.Certificate.Subject.CommonName != "CAP Test Signer" {
+				t.Errorf("got certificate %v; want the test signer's", sig.Certificate)
+			}
+		})
+	}
+}
+
+func TestCAPSignatureVerifierVerifyUnsigned(t *testing.T) {
+	doc := readCAPSignatureTestFile(t, "signed.xml")
+	doc = append(doc[:bytes.Index(doc, []byte("<Signature"))], "</alert>\n"...)
+	sig, err := newCAPSignatureTestVerifier(t).Verify(doc)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if sig.Present || sig.Valid {
+		t.Errorf("got Present %v, Valid %v; want both false", sig.Present, sig.Valid)
+	}
+}
+
+func TestCAPSignatureVerifierVerifyUntrusted(t *testing.T) {
+	roots := x509.NewCertPool()
+	roots.AddCert(readCAPSignatureTestCert(t, "other.pem"))
+	v := CAPSignatureVerifier{Roots: roots, Time: capSignatureTestTime}
+	sig, err := v.Verify(readCAPSignatureTestFile(t, "signed.xml"))
+	if !errors.Is(err, ErrInvalidCAPSignature) {
+		t.Fatalf("got error %v; want ErrInvalidCAPSignature", err)
+	}
+	if !sig.Present || sig.Valid {
+		t.Errorf("got Present %v, Valid %v; want Present only", sig.Present, sig.Valid)
+	}
+}
+
+func TestCAPSignatureVerifierVerifyTampered(t *testing.T) {
+	signed := readCAPSignatureTestFile(t, "signed.xml")
+	signedID := readCAPSignatureTestFile(t, "signed-id.xml")
+
+	// the other certificate, as it would appear in KeyInfo
+	otherCert := readCAPSignatureTestCert(t, "other.pem")
+	otherCertText := "\n" + pemBase64(otherCert.Raw) + "\n"
+
+	// the signature from signed-id.xml, which references the alert by ID
+	sigStart := bytes.Index(signedID, []byte("<Signature"))
+	sigEnd := bytes.Index(signedID, []byte("</Signature>")) + len("</Signature>")
+	signature := string(signedID[sigStart:sigEnd])
+	original := string(signedID[:sigStart]) + string(signedID[sigEnd:])
+	original = original[strings.Index(original, "<alert"):]
+
+	tests := []struct {
+		name    string
+		doc     []byte
+		wantErr string
+	}{
+		{
+			name:    "modified body",
+			doc:     bytes.Replace(signed, []byte("up to 100"), []byte("up to 110"), 1),
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "modified attribute",
+			doc:     bytes.Replace(signedID, []byte(`id="alert-1"`), []byte(`id="alert-1" status="Test"`), 1),
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "wrong digest",
+			doc:     replaceElementText(t, signed, "DigestValue", "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="),
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "modified SignedInfo",
+			doc:     bytes.Replace(signed, []byte(`<Reference URI="">`), []byte(`<Reference URI="" Id="r">`), 1),
+			wantErr: "verification error",
+		},
+		{
+			name:    "swapped certificate",
+			doc:     replaceElementText(t, signed, "X509Certificate", otherCertText),
+			wantErr: "verification error",
+		},
+		{
+			// the signed alert is moved into the signature and a forged
+			// alert takes its place as the root
+			name: "wrapped reference",
+			doc: []byte(`<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2" id="alert-2">` +
+				`<identifier>forged</identifier>` +
+				strings.Replace(signature, "</Signature>", "<Object>"+original+"</Object></Signature>", 1) +
+				`</alert>`),
+			wantErr: "does not cover the alert",
+		},
+		{
+			// as above, but the forged alert claims the signed alert's ID
+			name: "wrapped reference with duplicate ID",
+			doc: []byte(`<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2" id="alert-1">` +
+				`<identifier>forged</identifier>` +
+				strings.Replace(signature, "</Signature>", "<Object>"+original+"</Object></Signature>", 1) +
+				`</alert>`),
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "unsupported reference",
+			doc:     bytes.Replace(signed, []byte(`<Reference URI="">`), []byte(`<Reference URI="http://example.com/alert.xml">`), 1),
+			wantErr: "unsupported reference",
+		},
+		{
+			name:    "no certificate",
+			doc:     bytes.ReplaceAll(signed, []byte("X509Certificate>"), []byte("X509SubjectName>")),
+			wantErr: "no certificate",
+		},
+	}
+	v := newCAPSignatureTestVerifier(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := v.Verify(tt.doc)
+			if !errors.Is(err, ErrInvalidCAPSignature) {
+				t.Fatalf("got error %v; want ErrInvalidCAPSignature", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %q; want one containing %q", err, tt.wantErr)
+			}
+			if !sig.Present || sig.Valid {
+				t.Errorf("got Present %v, Valid %v; want Present only", sig.Present, sig.Valid)
+			}
+		})
+	}
+}
+
+// pemBase64 returns b base64 encoded in lines of 64 characters.
+func pemBase64(b []byte) string {
+	s := string(pem.EncodeToMemory(&pem.Block{Type: "X", Bytes: b}))
+	s = strings.TrimPrefix(s, "-----BEGIN X-----\n")
+	return strings.TrimSuffix(s, "\n-----END X-----\n")
+}
@@ -0,0 +1,326 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCAPAlert is returned when an alert can't be marshaled to a valid
+// CAP document.
+var ErrInvalidCAPAlert = errors.New("invalid CAP alert")
+
+// capTimeFormat is the CAP time format, which doesn't allow "Z" for UTC.
+const capTimeFormat = "2006-01-02T15:04:05-07:00"
+
+// capAlertOut is a CAP 1.2 alert document as written by MarshalCAP. Elements
+// are in the order required by the schema.
+type capAlertOut struct {
+	XMLName    xml.Name     `xml:"urn:oasis:names:tc:emergency:cap:1.2 alert"`
+	Identifier string       `xml:"identifier"`
+	Sender     string       `xml:"sender"`
+	Sent       string       `xml:"sent"`
+	Status     string       `xml:"status"`
+	MsgType    string       `xml:"msgType"`
+	Scope      string       `xml:"scope"`
+	References string       `xml:"references,omitempty"`
+	Info       []capInfoOut `xml:"info"`
+}
+
+type capInfoOut struct {
+	Language     string            `xml:"language,omitempty"`
+	Category     string            `xml:"category"`
+	Event        string            `xml:"event"`
+	ResponseType string            `xml:"responseType,omitempty"`
+	Urgency      string            `xml:"urgency"`
+	Severity     string            `xml:"severity"`
+	Certainty    string            `xml:"certainty"`
+	EventCodes   []capValuePairOut `xml:"eventCode"`
+	Effective    string            `xml:"effective,omitempty"`
+	Onset        string            `xml:"onset,omitempty"`
+	Expires      string            `xml:"expires,omitempty"`
+	SenderName   string            `xml:"senderName,omitempty"`
+	Headline     string            `xml:"headline,omitempty"`
+	Description  string            `xml:"description,omitempty"`
+	Instruction  string            `xml:"instruction,omitempty"`
+	Web          string            `xml:"web,omitempty"`
+	Parameters   []capValuePairOut `xml:"parameter"`
+	Resources    []capResourceOut  `xml:"resource"`
+	Areas        []capAreaOut      `xml:"area"`
+}
+
+type capValuePairOut struct {
+	ValueName string `xml:"valueName"`
+	Value     string `xml:"value"`
+}
+
+type capResourceOut struct {
+	ResourceDesc string `xml:"resourceDesc"`
+	MIMEType     string `xml:"mimeType"`
+	Size         string `xml:"size,omitempty"`
+	URI          string `xml:"uri,omitempty"`
+	DerefURI     string `xml:"derefUri,omitempty"`
+	Digest       string `xml:"digest,omitempty"`
+}
+
+type capAreaOut struct {
+	AreaDesc string            `xml:"areaDesc"`
+	Polygons []string          `xml:"polygon"`
+	Circles  []string          `xml:"circle"`
+	Geocodes []capValuePairOut `xml:"geocode"`
+}
+
+// ValidateCAP returns an error wrapping ErrInvalidCAPAlert if the alert lacks
+// an element required by CAP 1.2 or has a value that CAP doesn't allow.
+func (a Alert) ValidateCAP() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidCAPAlert, fmt.Sprintf(format, args...))
+	}
+
+	// alert
+	if !isCAPIdentifier(a.ID) {
+		return invalid("identifier: \"%s\"", a.ID)
+	}
+	if !isCAPIdentifier(a.SenderID) {
+		return invalid("sender: \"%s\"", a.SenderID)
+	}
+	if a.TimeSent.IsZero() {
+		return invalid("no sent time")
+	}
+	if _, ok := AlertStatuses[a.Status]; !ok {
+		return invalid("status: \"%s\"", a.Status)
+	}
+	if _, ok := AlertMessageTypes[a.MessageType]; !ok {
+		return invalid("message type: \"%s\"", a.MessageType)
+	}
+	if a.MessageType != "Alert" && len(a.References) < 1 {
+		return invalid("%s message has no references", a.MessageType)
+	}
+	if _, err := a.capReferences(); err != nil {
+		return err
+	}
+
+	// info
+	if _, ok := AlertCategories[a.Category]; !ok {
+		return invalid("category: \"%s\"", a.Category)
+	}
+	if strings.TrimSpace(a.Event) == "" {
+		return invalid("no event")
+	}
+	if _, ok := AlertUrgencies[a.Urgency]; !ok {
+		return invalid("urgency: \"%s\"", a.Urgency)
+	}
+	if _, ok := AlertSeverities[a.Severity]; !ok {
+		return invalid("severity: \"%s\"", a.Severity)
+	}
+	if _, ok := AlertCertainties[a.Certainty]; !ok {
+		return invalid("certainty: \"%s\"", a.Certainty)
+	}
+	if _, ok := AlertResponses[a.Response]; a.Response != "" && !ok {
+		return invalid("response: \"%s\"", a.Response)
+	}
+	for _, info := range a.Infos {
+		if strings.TrimSpace(info.Event) == "" {
+			return invalid("no event for language \"%s\"", info.Language)
+		}
+	}
+	for _, r := range a.Resources {
+		if strings.TrimSpace(r.Description) == "" || strings.TrimSpace(r.MIMEType) == "" {
+			return invalid("resource lacks a description or MIME type")
+		}
+	}
+
+	// area
+	if a.hasCAPArea() && strings.TrimSpace(a.AreaDescription) == "" {
+		return invalid("no area description")
+	}
+	for _, poly := range a.capPolygons() {
+		if len(closeRing(poly)) < 4 {
+			return invalid("polygon has fewer than three points")
+		}
+	}
+
+	return nil
+}
+
+// MarshalCAP returns the alert as a CAP 1.2 XML document, after validating it
+// with ValidateCAP. The document has one info block for each of the alert's
+// Infos, or a single info block in the default language if it has none. The
+// SAME event code, VTEC strings, resources, polygons, and UGC and SAME codes
+// are included; the scope is always "Public".
+func (a Alert) MarshalCAP() ([]byte, error) {
+	if err := a.ValidateCAP(); err != nil {
+		return nil, err
+	}
+	references, _ := a.capReferences()
+	out := capAlertOut{
+		Identifier: a.ID,
+		Sender:     a.SenderID,
+		Sent:       formatCAPTime(a.TimeSent),
+		Status:     a.Status,
+		MsgType:    a.MessageType,
+		Scope:      "Public",
+		References: references,
+	}
+
+	// values shared by all languages
+	shared := capInfoOut{
+		Category:     a.Category,
+		ResponseType: a.Response,
+		Urgency:      a.Urgency,
+		Severity:     a.Severity,
+		Certainty:    a.Certainty,
+		Effective:    formatCAPTime(a.TimeEffective),
+		Onset:        formatCAPTime(a.TimeOnset),
+		Expires:      formatCAPTime(a.TimeExpires),
+	}
+	if a.EventCode != "" {
+		shared.EventCodes = []capValuePairOut{{ValueName: "SAME", Value: a.EventCode}}
+	}
+	for _, v := range a.VTEC {
+		shared.Parameters = append(shared.Parameters, capValuePairOut{ValueName: "VTEC", Value: v.String()})
+	}
+	for _, r := range a.Resources {
+		rOut := capResourceOut{
+			ResourceDesc: r.Description,
+			MIMEType:     r.MIMEType,
+			URI:          r.URI,
+			Digest:       r.Digest,
+		}
+		if r.Size > 0 {
+			rOut.Size = strconv.Itoa(r.Size)
+		}
+		if r.URI == "" && r.Data != nil {
+			rOut.DerefURI = base64.StdEncoding.EncodeToString(r.Data)
+		}
+		shared.Resources = append(shared.Resources, rOut)
+	}
+	if a.hasCAPArea() {
+		area := capAreaOut{AreaDesc: a.AreaDescription}
+		for _, poly := range a.capPolygons() {
+			var pairs []string
+			for _, p := range closeRing(poly) {
+				pairs = append(pairs, strconv.FormatFloat(p.Lat, 'f', -1, 64)+","+strconv.FormatFloat(p.Lon, 'f', -1, 64))
+			}
+			area.Polygons = append(area.Polygons, strings.Join(pairs, " "))
+		}
+		for _, c := range a.Circles {
+			area.Circles = append(area.Circles, strconv.FormatFloat(c.Center.Lat, 'f', -1, 64)+","+strconv.FormatFloat(c.Center.Lon, 'f', -1, 64)+" "+strconv.FormatFloat(c.RadiusMeters/1000, 'f', -1, 64))
+		}
+		for _, ugc := range a.UGCCodes {
+			area.Geocodes = append(area.Geocodes, capValuePairOut{ValueName: "UGC", Value: ugc})
+		}
+		for _, same := range a.SAMECodes {
+			area.Geocodes = append(area.Geocodes, capValuePairOut{ValueName: "SAME", Value: same})
+		}
+		shared.Areas = []capAreaOut{area}
+	}
+
+	for _, info := range a.Infos {
+		infoOut := shared
+		infoOut.Language = info.Language
+		infoOut.Event = info.Event
+		infoOut.SenderName = info.SenderName
+		infoOut.Headline = info.Headline
+		infoOut.Description = info.Description
+		infoOut.Instruction = info.Instruction
+		infoOut.Web = info.Web
+		out.Info = append(out.Info, infoOut)
+	}
+	if len(a.Infos) < 1 {
+		infoOut := shared
+		infoOut.Event = a.Event
+		infoOut.SenderName = a.SenderName
+		infoOut.Headline = a.Headline
+		infoOut.Description = a.Description
+		infoOut.Instruction = a.Instruction
+		out.Info = []capInfoOut{infoOut}
+	}
+
+	b, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// capReferences returns the alert's references in the CAP format: space
+// separated "sender,identifier,sent" triplets. Each reference must have
+// details.
+func (a Alert) capReferences() (string, error) {
+	var triplets []string
+	for _, id := range a.References {
+		found := false
+		for _, ref := range a.ReferenceDetails {
+			if ref.ID == id {
+				if !isCAPIdentifier(ref.Sender) || ref.TimeSent.IsZero() {
+					break
+				}
+				triplets = append(triplets, ref.Sender+","+ref.ID+","+formatCAPTime(ref.TimeSent))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%w: reference lacks a sender or sent time: \"%s\"", ErrInvalidCAPAlert, id)
+		}
+	}
+	return strings.Join(triplets, " "), nil
+}
+
+// hasCAPArea reports whether the alert has anything to put in a CAP area.
+func (a Alert) hasCAPArea() bool {
+	return a.AreaDescription != "" || len(a.capPolygons()) > 0 || len(a.Circles) > 0 || len(a.UGCCodes) > 0 || len(a.SAMECodes) > 0
+}
+
+// capPolygons returns the polygons to put in a CAP area. Polygons resolved
+// from zone geometry are left out, since the geocodes already describe them.
+func (a Alert) capPolygons() [][]Point {
+	if a.PolygonsFromZones {
+		return nil
+	}
+	return a.Polygons
+}
+
+// isCAPIdentifier reports whether s may be used as a CAP identifier or
+// sender: it must be non-empty and may not contain spaces, commas, or
+// restricted characters.
+func isCAPIdentifier(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\r\n,<&")
+}
+
+// formatCAPTime formats a time as CAP requires, or returns an empty string if
+// it is zero.
+func formatCAPTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(capTimeFormat)
+}
+
+// closeRing returns a polygon ring with its first point repeated at the end,
+// as CAP requires, if it isn't already.
+func closeRing(ring []Point) []Point {
+	if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+		ring = append(append([]Point(nil), ring...), ring[0])
+	}
+	return ring
+}
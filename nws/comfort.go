@@ -0,0 +1,221 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Window comparison results.
+const (
+	WindowA   = "A"
+	WindowB   = "B"
+	WindowTie = "tie"
+)
+
+// DefaultComfortCriteria are pleasant conditions for spending time outdoors.
+var DefaultComfortCriteria = ComfortCriteria{
+	TemperatureMin:              ValueUnit{Value: 60, Unit: "F"},
+	TemperatureMax:              ValueUnit{Value: 85, Unit: "F"},
+	WindSpeedMax:                ValueUnit{Value: 15, Unit: "mph"},
+	PrecipitationProbabilityMax: 30,
+}
+
+// ComfortCriteria describe comfortable conditions. An hour is comfortable if
+// the temperature is within the range, the wind is below WindSpeedMax, and the
+// chance of precipitation is below PrecipitationProbabilityMax percent.
+type ComfortCriteria struct {
+	TemperatureMin              ValueUnit // "F" or "C"
+	TemperatureMax              ValueUnit // "F" or "C"
+	WindSpeedMax                ValueUnit // "mph", "kt", "km/h", or "m/s"
+	PrecipitationProbabilityMax int
+}
+
+// A WindowScore summarizes the forecast for a window of time against a set of
+// ComfortCriteria. Hours are the hours of the window covered by the forecast.
+type WindowScore struct {
+	TimeStart time.Time
+	TimeEnd   time.Time
+	Hours     float64
+
+	TemperatureHours float64 // hours with a comfortable temperature
+	DryHours         float64 // hours with a low chance of precipitation
+	CalmHours        float64 // hours with light wind
+	ComfortableHours float64 // hours meeting all criteria
+
+	Score float64 // percentage of hours meeting all criteria
+}
+
+// A ComparisonFactor is one of the factors contributing to a WindowComparison,
+// given as the percentage of hours in each window meeting that criterion.
+type ComparisonFactor struct {
+	Name   string // "temperature", "precipitation", or "wind"
+	A      float64
+	B      float64
+	Better string // WindowA, WindowB, or WindowTie
+}
+
+// A WindowComparison is the verdict of comparing two windows of time, such as
+// this weekend and next weekend.
+type WindowComparison struct {
+	A       WindowScore
+	B       WindowScore
+	Better  string // WindowA, WindowB, or WindowTie
+	Factors []ComparisonFactor
+}
+
+// String returns a human readable verdict.
+func (c WindowComparison) String() string {
+	switch c.Better {
+	case WindowA:
+		return fmt.Sprintf("A is better: %.0f%% comfortable vs. %.0f%%", c.A.Score, c.B.Score)
+	case WindowB:
+		return fmt.Sprintf("B is better: %.0f%% comfortable vs. %.0f%%", c.B.Score, c.A.Score)
+	}
+	return fmt.Sprintf("about the same: %.0f%% comfortable", c.A.Score)
+}
+
+// WeekendWindow returns the start and end of a weekend (Saturday and Sunday)
+// in ref's location. weeksAhead 0 is the weekend in progress, or the next one
+// if ref is a weekday; 1 is the weekend after that, and so on.
+func WeekendWindow(ref time.Time, weeksAhead int) (time.Time, time.Time) {
+	day := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, ref.Location())
+	switch ref.Weekday() {
+	case time.Sunday:
+		day = day.AddDate(0, 0, -1)
+	default:
+		day = day.AddDate(0, 0, int(time.Saturday-ref.Weekday()))
+	}
+	start := day.AddDate(0, 0, 7*weeksAhead)
+	return start, start.AddDate(0, 0, 2)
+}
+
+// ScoreWindow scores the forecast between start and end. Periods are weighted
+// by their overlap with the window, so both hourly and semidaily forecasts may
+// be used.
+func (f Forecast) ScoreWindow(start time.Time, end time.Time, criteria ComfortCriteria) (WindowScore, error) {
+	s := WindowScore{TimeStart: start, TimeEnd: end}
+
+	for _, p := range f.PeriodsBetween(start, end) {
+		ps, pe := p.TimeStart, p.TimeEnd
+		if ps.Before(start) {
+			ps = start
+		}
+		if pe.After(end) {
+			pe = end
+		}
+		h := pe.Sub(ps).Hours()
+		s.Hours += h
+
+		var temp, dry, calm bool
+		if p.Temperature.Unit != "" {
+			t, err := convertTemperature(p.Temperature, criteria.TemperatureMin.Unit)
+			if err != nil {
+				return WindowScore{}, err
+			}
+			tMax, err := convertTemperature(criteria.TemperatureMax, criteria.TemperatureMin.Unit)
+			if err != nil {
+				return WindowScore{}, err
+			}
+			temp = t.Value >= criteria.TemperatureMin.Value && t.Value <= tMax.Value
+		}
+		dry = p.precipitationProbability() < criteria.PrecipitationProbabilityMax
+		if p.WindSpeedMax.Unit != "" {
+			c := compareSpeeds(p.WindSpeedMax, criteria.WindSpeedMax)
+			if c == 2 {
+				return WindowScore{}, fmt.Errorf("unsupported speed unit: \"%s\" or \"%s\"", p.WindSpeedMax.Unit, criteria.WindSpeedMax.Unit)
+			}
+			calm = c == -1
+		}
+
+		if temp {
+			s.TemperatureHours += h
+		}
+		if dry {
+			s.DryHours += h
+		}
+		if calm {
+			s.CalmHours += h
+		}
+		if temp && dry && calm {
+			s.ComfortableHours += h
+		}
+	}
+
+	if s.Hours == 0 {
+		return WindowScore{}, errors.New("forecast does not cover window")
+	}
+	s.Score = 100 * s.ComfortableHours / s.Hours
+	return s, nil
+}
+
+// CompareWindows scores two windows of the forecast and reports which is more
+// comfortable, along with the factors contributing to the verdict. Windows
+// that are only partly covered by the forecast are judged on the hours that
+// are covered.
+func (f Forecast) CompareWindows(aStart time.Time, aEnd time.Time, bStart time.Time, bEnd time.Time, criteria ComfortCriteria) (WindowComparison, error) {
+	var c WindowComparison
+	var err error
+	if c.A, err = f.ScoreWindow(aStart, aEnd, criteria); err != nil {
+		return WindowComparison{}, fmt.Errorf("window A: %s", err)
+	}
+	if c.B, err = f.ScoreWindow(bStart, bEnd, criteria); err != nil {
+		return WindowComparison{}, fmt.Errorf("window B: %s", err)
+	}
+
+	c.Better = betterWindow(c.A.Score, c.B.Score)
+	for _, fc := range []struct {
+		name string
+		a, b float64
+	}{
+		{"temperature", c.A.TemperatureHours, c.B.TemperatureHours},
+		{"precipitation", c.A.DryHours, c.B.DryHours},
+		{"wind", c.A.CalmHours, c.B.CalmHours},
+	} {
+		a, b := 100*fc.a/c.A.Hours, 100*fc.b/c.B.Hours
+		c.Factors = append(c.Factors, ComparisonFactor{Name: fc.name, A: a, B: b, Better: betterWindow(a, b)})
+	}
+
+	return c, nil
+}
+
+// betterWindow returns the window with the higher percentage. Percentages
+// within one point of each other are a tie.
+func betterWindow(a float64, b float64) string {
+	switch {
+	case math.Abs(a-b) < 1:
+		return WindowTie
+	case a > b:
+		return WindowA
+	}
+	return WindowB
+}
+
+// convertTemperature returns a temperature converted to another unit, "F" or
+// "C".
+func convertTemperature(vu ValueUnit, unit string) (ValueUnit, error) {
+	switch {
+	case vu.Unit == unit && (unit == "F" || unit == "C"):
+		return vu, nil
+	case vu.Unit == "F" && unit == "C":
+		return ValueUnit{Value: (vu.Value - 32) * 5 / 9, Unit: unit}, nil
+	case vu.Unit == "C" && unit == "F":
+		return ValueUnit{Value: vu.Value*9/5 + 32, Unit: unit}, nil
+	}
+	return ValueUnit{}, fmt.Errorf("unsupported temperature conversion: \"%s\" to \"%s\"", vu.Unit, unit)
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Kinds of significant forecast change.
+const (
+	ChangeTemperature   = "temperature"
+	ChangePrecipitation = "precipitation"
+	ChangeWind          = "wind"
+)
+
+// precipitationWords are words in a short forecast that mention
+// precipitation.
+var precipitationWords = []string{"rain", "shower", "drizzle", "snow", "sleet", "hail", "thunderstorm", "flurries", "wintry mix"}
+
+// DefaultChangeThresholds are suitable for "forecast changed" notifications.
+var DefaultChangeThresholds = ChangeThresholds{
+	Temperature:              ValueUnit{Value: 5, Unit: "F"},
+	PrecipitationProbability: 30,
+	WindSpeed:                ValueUnit{Value: 10, Unit: "mph"},
+}
+
+// ChangeThresholds determine which differences between two forecasts are
+// significant enough to report.
+type ChangeThresholds struct {
+	// Temperature is the smallest rise or fall reported. "F" or "C".
+	Temperature ValueUnit
+
+	// PrecipitationProbability is the chance of precipitation, in percent,
+	// at or above which a period is considered to mention precipitation.
+	// Periods without a probability are judged by their short forecast.
+	PrecipitationProbability int
+
+	// WindSpeed is the smallest increase in the maximum sustained wind
+	// reported. "mph", "kt", "km/h", or "m/s".
+	WindSpeed ValueUnit
+}
+
+// A ForecastChange is a significant difference between the same period in two
+// successive forecasts. Periods are matched by their start time.
+type ForecastChange struct {
+	TimeStart time.Time
+	Name      string // name of the period in the newer forecast
+	Kind      string // ChangeTemperature, ChangePrecipitation, or ChangeWind
+	Old       Period
+	New       Period
+}
+
+// String returns a human readable description of the change, with values
+// rounded using DefaultDisplayPolicy.
+func (c ForecastChange) String() string {
+	return c.Format(DefaultDisplayPolicy)
+}
+
+// Format returns a human readable description of the change, with values
+// rounded using p.
+func (c ForecastChange) Format(p DisplayPolicy) string {
+	switch c.Kind {
+	case ChangeTemperature:
+		return fmt.Sprintf("%s: temperature changed from %s to %s", c.Name, p.Format(c.Old.Temperature), p.Format(c.New.Temperature))
+	case ChangePrecipitation:
+		return fmt.Sprintf("%s: precipitation now forecast: %s", c.Name, c.New.ForecastShort)
+	case ChangeWind:
+		return fmt.Sprintf("%s: wind increased from %s to %s", c.Name, p.Format(c.Old.WindSpeedMax), p.Format(c.New.WindSpeedMax))
+	}
+	return fmt.Sprintf("%s: %s changed", c.Name, c.Kind)
+}
+
+// Compare reports the significant changes between two successive forecasts
+// for the same place, in the order of the newer forecast's periods. Unlike
+// DiffForecasts, which reports every difference, only the changes a person
+// would want to be told about are reported: a temperature rise or fall beyond
+// the threshold, precipitation newly mentioned, and a wind increase beyond the
+// threshold. Periods present in only one forecast are ignored.
+//
+// No changes are reported if either forecast is nil.
+func Compare(older *Forecast, newer *Forecast, thresholds ChangeThresholds) ([]ForecastChange, error) {
+	if _, err := convertTemperature(thresholds.Temperature, "F"); err != nil {
+		return nil, err
+	}
+	if _, err := convertSpeed(thresholds.WindSpeed, "mph"); err != nil {
+		return nil, err
+	}
+	if older == nil || newer == nil {
+		return nil, nil
+	}
+
+	olderPeriods := make(map[int64]Period)
+	for _, p := range older.Periods {
+		olderPeriods[p.TimeStart.Unix()] = p
+	}
+
+	var changes []ForecastChange
+	for _, np := range newer.Periods {
+		op, ok := olderPeriods[np.TimeStart.Unix()]
+		if !ok {
+			continue
+		}
+		change := func(kind string) {
+			changes = append(changes, ForecastChange{TimeStart: np.TimeStart, Name: np.Name, Kind: kind, Old: op, New: np})
+		}
+
+		if op.Temperature.Unit != "" && np.Temperature.Unit != "" {
+			ot, err := convertTemperature(op.Temperature, thresholds.Temperature.Unit)
+			if err != nil {
+				return nil, err
+			}
+			nt, err := convertTemperature(np.Temperature, thresholds.Temperature.Unit)
+			if err != nil {
+				return nil, err
+			}
+			if math.Abs(nt.Value-ot.Value) >= thresholds.Temperature.Value {
+				change(ChangeTemperature)
+			}
+		}
+
+		if !precipitationMentioned(op, thresholds.PrecipitationProbability) && precipitationMentioned(np, thresholds.PrecipitationProbability) {
+			change(ChangePrecipitation)
+		}
+
+		if op.WindSpeedMax.Unit != "" && np.WindSpeedMax.Unit != "" {
+			ow, err := convertSpeed(op.WindSpeedMax, thresholds.WindSpeed.Unit)
+			if err != nil {
+				return nil, err
+			}
+			nw, err := convertSpeed(np.WindSpeedMax, thresholds.WindSpeed.Unit)
+			if err != nil {
+				return nil, err
+			}
+			if nw.Value-ow.Value >= thresholds.WindSpeed.Value {
+				change(ChangeWind)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// precipitationMentioned reports whether a period forecasts precipitation: its
+// chance of precipitation is at least threshold percent or, if it has no
+// chance of precipitation, its short forecast mentions precipitation.
+func precipitationMentioned(p Period, threshold int) bool {
+	if pop := p.precipitationProbability(); pop > 0 {
+		return pop >= threshold
+	}
+	short := strings.ToLower(p.ForecastShort)
+	for _, w := range precipitationWords {
+		if strings.Contains(short, w) {
+			return true
+		}
+	}
+	return false
+}
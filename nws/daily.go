@@ -0,0 +1,103 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"math"
+	"time"
+)
+
+// A DailySummary summarizes the periods of a forecast that fall within a
+// single day. It is most useful with the hourly forecast, which may cover
+// days that the semi-daily forecast does not.
+type DailySummary struct {
+	Date time.Time // the start of the day according to a DayBoundary
+
+	High         ValueUnit
+	Low          ValueUnit
+	WindSpeedMax ValueUnit
+	Condition    string // the most common short forecast, by duration
+
+	// PrecipitationProbabilityMax is the highest probability of precipitation
+	// as a percentage. PrecipitationStart and PrecipitationEnd bound the
+	// periods with a nonzero probability and are zero if there are none.
+	PrecipitationProbabilityMax int
+	PrecipitationStart          time.Time
+	PrecipitationEnd            time.Time
+}
+
+// DailySummaries rolls up the forecast into one DailySummary per day, with days
+// beginning according to a DayBoundary. Periods are assigned to the day on
+// which they start.
+func (f Forecast) DailySummaries(boundary DayBoundary) []DailySummary {
+	var dss []DailySummary
+	var durations map[string]time.Duration // condition durations for current day
+
+	for _, p := range f.Periods {
+		date := boundary(p.TimeStart)
+		if len(dss) == 0 || !dss[len(dss)-1].Date.Equal(date) {
+			dss = append(dss, DailySummary{Date: date})
+			durations = make(map[string]time.Duration)
+		}
+		ds := &dss[len(dss)-1]
+
+		if p.Temperature.Unit != "" {
+			if ds.High.Unit == "" || p.Temperature.Value > ds.High.Value {
+				ds.High = p.Temperature
+			}
+			if ds.Low.Unit == "" || p.Temperature.Value < ds.Low.Value {
+				ds.Low = p.Temperature
+			}
+		}
+		if p.WindSpeedMax.Unit != "" && (ds.WindSpeedMax.Unit == "" || p.WindSpeedMax.Value > ds.WindSpeedMax.Value) {
+			ds.WindSpeedMax = p.WindSpeedMax
+		}
+
+		if p.ForecastShort != "" {
+			durations[p.ForecastShort] += p.TimeEnd.Sub(p.TimeStart)
+			if durations[p.ForecastShort] > durations[ds.Condition] {
+				ds.Condition = p.ForecastShort
+			}
+		}
+
+		if pop := p.precipitationProbability(); pop > 0 {
+			if pop > ds.PrecipitationProbabilityMax {
+				ds.PrecipitationProbabilityMax = pop
+			}
+			if ds.PrecipitationStart.IsZero() {
+				ds.PrecipitationStart = p.TimeStart
+			}
+			ds.PrecipitationEnd = p.TimeEnd
+		}
+	}
+
+	return dss
+}
+
+// precipitationProbability returns the probability of precipitation for the
+// period as a percentage. The icon probabilities are used if the API did not
+// provide one.
+func (p Period) precipitationProbability() int {
+	if p.PrecipitationProbability.Unit != "" {
+		return int(math.Round(p.PrecipitationProbability.Value))
+	}
+	var pop int
+	for _, c := range p.Icon.Conditions {
+		if c.Probability > pop {
+			pop = c.Probability
+		}
+	}
+	return pop
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"time"
+
+	"github.com/mikecamilleri/our-data/astro"
+)
+
+// A DayBoundary returns the start of the "day" that contains t. Daily
+// aggregation uses a DayBoundary to decide which day each period belongs to.
+//
+// Midnight is the default, but people tend to think of "tomorrow's weather" as
+// running from morning to morning. See HourDayBoundary and SunriseDayBoundary.
+// Days are in the location of the times given; use DayBoundaryIn to fix the
+// location.
+type DayBoundary func(t time.Time) time.Time
+
+// MidnightDayBoundary returns local midnight at the start of t's day.
+func MidnightDayBoundary(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// HourDayBoundary returns a DayBoundary where each day begins at the given
+// local hour (e.g. 6 for 6am to 6am).
+func HourDayBoundary(hour int) DayBoundary {
+	return func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		start := time.Date(y, m, d, hour, 0, 0, 0, t.Location())
+		if t.Before(start) {
+			start = time.Date(y, m, d-1, hour, 0, 0, 0, t.Location())
+		}
+		return start
+	}
+}
+
+// SunriseDayBoundary returns a DayBoundary where each day begins at sunrise at
+// a point. On days without a sunrise (polar regions) the day begins at local
+// midnight.
+func SunriseDayBoundary(p Point) DayBoundary {
+	return func(t time.Time) time.Time {
+		start, ok := astro.Sunrise(t, p.Lat, p.Lon)
+		if !ok {
+			return MidnightDayBoundary(t)
+		}
+		if t.Before(start) {
+			y, m, d := t.Date()
+			prev := time.Date(y, m, d-1, 12, 0, 0, 0, t.Location())
+			if start, ok = astro.Sunrise(prev, p.Lat, p.Lon); !ok {
+				return MidnightDayBoundary(prev)
+			}
+		}
+		return start
+	}
+}
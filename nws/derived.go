@@ -0,0 +1,176 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"math"
+)
+
+// HeatIndex returns the heat index for a temperature ("F" or "C") and relative
+// humidity ("percent"), in the temperature's unit. It uses the NWS algorithm:
+// the Rothfusz regression with adjustments for low and high humidity, and
+// Steadman's simpler formula below 80 F.
+func HeatIndex(temperature ValueUnit, relativeHumidity ValueUnit) (ValueUnit, error) {
+	t, err := convertTemperature(temperature, "F")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	rh, err := percentValue(relativeHumidity)
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	T := t.Value
+
+	hi := 0.5 * (T + 61 + (T-68)*1.2 + rh*0.094)
+	if (hi+T)/2 >= 80 {
+		hi = -42.379 + 2.04901523*T + 10.14333127*rh - 0.22475541*T*rh -
+			0.00683783*T*T - 0.05481717*rh*rh + 0.00122874*T*T*rh +
+			0.00085282*T*rh*rh - 0.00000199*T*T*rh*rh
+		switch {
+		case rh < 13 && T >= 80 && T <= 112:
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(T-95))/17)
+		case rh > 85 && T >= 80 && T <= 87:
+			hi += (rh - 85) / 10 * (87 - T) / 5
+		}
+	}
+
+	return convertTemperature(ValueUnit{Value: hi, Unit: "F"}, temperature.Unit)
+}
+
+// WindChill returns the wind chill for a temperature ("F" or "C") and wind
+// speed ("mph", "kt", "km/h", or "m/s"), in the temperature's unit, using the
+// NWS formula adopted in 2001. The formula is only defined at or below 50 F
+// with wind of at least 3 mph; otherwise the temperature is returned.
+func WindChill(temperature ValueUnit, windSpeed ValueUnit) (ValueUnit, error) {
+	t, err := convertTemperature(temperature, "F")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	v, err := convertSpeed(windSpeed, "mph")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	if t.Value > 50 || v.Value < 3 {
+		return temperature, nil
+	}
+
+	vp := math.Pow(v.Value, 0.16)
+	wc := 35.74 + 0.6215*t.Value - 35.75*vp + 0.4275*t.Value*vp
+
+	return convertTemperature(ValueUnit{Value: wc, Unit: "F"}, temperature.Unit)
+}
+
+// DewPointFromRH returns the dew point for a temperature ("F" or "C") and
+// relative humidity ("percent"), in the temperature's unit, using the Magnus
+// formula.
+func DewPointFromRH(temperature ValueUnit, relativeHumidity ValueUnit) (ValueUnit, error) {
+	const b, c = 17.625, 243.04
+
+	t, err := convertTemperature(temperature, "C")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	rh, err := percentValue(relativeHumidity)
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	if rh <= 0 {
+		return ValueUnit{}, fmt.Errorf("relative humidity must be greater than 0: %g", rh)
+	}
+
+	gamma := math.Log(rh/100) + b*t.Value/(c+t.Value)
+	return convertTemperature(ValueUnit{Value: c * gamma / (b - gamma), Unit: "C"}, temperature.Unit)
+}
+
+// RHFromDewPoint returns the relative humidity ("percent") for a temperature
+// and dew point ("F" or "C"), using the Magnus formula.
+func RHFromDewPoint(temperature ValueUnit, dewPoint ValueUnit) (ValueUnit, error) {
+	const b, c = 17.625, 243.04
+
+	t, err := convertTemperature(temperature, "C")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	td, err := convertTemperature(dewPoint, "C")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+
+	rh := 100 * math.Exp(b*td.Value/(c+td.Value)-b*t.Value/(c+t.Value))
+	return ValueUnit{Value: math.Min(rh, 100), Unit: "percent"}, nil
+}
+
+// ApparentTemperature returns what the temperature feels like, in the
+// temperature's unit, as weather.gov presents it: the heat index at or above
+// 80 F, the wind chill at or below 50 F, and the temperature otherwise.
+// relativeHumidity and windSpeed may be empty if unknown, in which case the
+// corresponding adjustment is not made.
+func ApparentTemperature(temperature ValueUnit, relativeHumidity ValueUnit, windSpeed ValueUnit) (ValueUnit, error) {
+	t, err := convertTemperature(temperature, "F")
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	switch {
+	case t.Value >= 80 && relativeHumidity.Unit != "":
+		return HeatIndex(temperature, relativeHumidity)
+	case t.Value <= 50 && windSpeed.Unit != "":
+		return WindChill(temperature, windSpeed)
+	}
+	return temperature, nil
+}
+
+// WithDerivedValues returns a copy of the observation with the dew point,
+// relative humidity, heat index, and wind chill computed from the other values
+// wherever the station didn't report them. Values that don't apply (e.g. heat
+// index in cold weather) are left empty.
+func (o Observation) WithDerivedValues() Observation {
+	if o.Temperature.Unit == "" {
+		return o
+	}
+	if o.Dewpoint.Unit == "" && o.RelativeHumidity.Unit != "" {
+		if dp, err := DewPointFromRH(o.Temperature, o.RelativeHumidity); err == nil {
+			o.Dewpoint = dp
+		}
+	}
+	if o.RelativeHumidity.Unit == "" && o.Dewpoint.Unit != "" {
+		if rh, err := RHFromDewPoint(o.Temperature, o.Dewpoint); err == nil {
+			o.RelativeHumidity = rh
+		}
+	}
+	t, err := convertTemperature(o.Temperature, "F")
+	if err != nil {
+		return o
+	}
+	if o.HeatIndex.Unit == "" && o.RelativeHumidity.Unit != "" && t.Value >= 80 {
+		if hi, err := HeatIndex(o.Temperature, o.RelativeHumidity); err == nil {
+			o.HeatIndex = hi
+		}
+	}
+	if o.WindChill.Unit == "" && o.WindSpeed.Unit != "" && t.Value <= 50 {
+		if wc, err := WindChill(o.Temperature, o.WindSpeed); err == nil && wc != o.Temperature {
+			o.WindChill = wc
+		}
+	}
+	return o
+}
+
+// percentValue returns the value of a percentage.
+func percentValue(vu ValueUnit) (float64, error) {
+	if vu.Unit != "percent" {
+		return 0, fmt.Errorf("unit must be \"percent\": \"%s\"", vu.Unit)
+	}
+	return vu.Value, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// diffTolerance is the smallest difference between two values that
+// DiffForecasts reports, to ignore floating point noise from unit
+// conversions.
+const diffTolerance = 1e-6
+
+// A PeriodChange represents a single difference between the same period in two
+// forecasts. Periods are matched by their start time.
+type PeriodChange struct {
+	ID        string // ID of the period in the newer forecast, or the older if removed
+	TimeStart time.Time
+	Name      string // name of the period in the newer forecast
+	Field     string // e.g. "Temperature", or "Period" if added or removed
+	Old       string // empty if the period was added; unrounded for values
+	New       string // empty if the period was removed; unrounded for values
+
+	// OldValue and NewValue are set for the fields that are values
+	// (Temperature, WindSpeedMin, WindSpeedMax, and PrecipitationProbability).
+	OldValue ValueUnit
+	NewValue ValueUnit
+}
+
+// String returns a human readable description of the change, with values
+// rounded using DefaultDisplayPolicy. See Format.
+func (c PeriodChange) String() string {
+	return c.Format(DefaultDisplayPolicy)
+}
+
+// Format returns a human readable description of the change. Values are
+// rounded using p, unless rounding would hide the change.
+func (c PeriodChange) Format(p DisplayPolicy) string {
+	old, new := c.Old, c.New
+	if c.OldValue.Unit != "" && c.NewValue.Unit != "" {
+		if o, n := p.Format(c.OldValue), p.Format(c.NewValue); o != n {
+			old, new = o, n
+		}
+	}
+	switch {
+	case old == "":
+		return fmt.Sprintf("%s: %s added: %s", c.Name, c.Field, new)
+	case new == "":
+		return fmt.Sprintf("%s: %s removed: %s", c.Name, c.Field, old)
+	default:
+		return fmt.Sprintf("%s: %s changed from %s to %s", c.Name, c.Field, old, new)
+	}
+}
+
+// DiffForecasts returns the differences between two forecasts for the same
+// place, in the order of the newer forecast's periods.
+//
+// Periods in the older forecast that start before the first period of the
+// newer forecast have simply expired and are not reported as removed.
+func DiffForecasts(older Forecast, newer Forecast) []PeriodChange {
+	var changes []PeriodChange
+
+	olderPeriods := make(map[int64]Period)
+	for _, p := range older.Periods {
+		olderPeriods[p.TimeStart.Unix()] = p
+	}
+
+	for _, np := range newer.Periods {
+		op, ok := olderPeriods[np.TimeStart.Unix()]
+		if !ok {
+			changes = append(changes, PeriodChange{ID: np.ID, TimeStart: np.TimeStart, Name: np.Name, Field: "Period", New: np.Name})
+			continue
+		}
+		delete(olderPeriods, np.TimeStart.Unix())
+
+		for _, f := range []struct {
+			name     string
+			old, new ValueUnit
+		}{
+			{"Temperature", op.Temperature, np.Temperature},
+			{"WindSpeedMin", op.WindSpeedMin, np.WindSpeedMin},
+			{"WindSpeedMax", op.WindSpeedMax, np.WindSpeedMax},
+			{"PrecipitationProbability", ValueUnit{Value: float64(op.precipitationProbability()), Unit: "percent"}, ValueUnit{Value: float64(np.precipitationProbability()), Unit: "percent"}},
+		} {
+			if valueUnitsDiffer(f.old, f.new) {
+				changes = append(changes, PeriodChange{ID: np.ID, TimeStart: np.TimeStart, Name: np.Name, Field: f.name, Old: formatRawValueUnit(f.old), New: formatRawValueUnit(f.new), OldValue: f.old, NewValue: f.new})
+			}
+		}
+		for _, f := range []struct {
+			name     string
+			old, new string
+		}{
+			{"WindDirection", op.WindDirection, np.WindDirection},
+			{"ForecastShort", op.ForecastShort, np.ForecastShort},
+		} {
+			if f.old != f.new {
+				changes = append(changes, PeriodChange{ID: np.ID, TimeStart: np.TimeStart, Name: np.Name, Field: f.name, Old: f.old, New: f.new})
+			}
+		}
+	}
+
+	var firstStart time.Time
+	if len(newer.Periods) > 0 {
+		firstStart = newer.Periods[0].TimeStart
+	}
+	for _, op := range older.Periods {
+		if _, ok := olderPeriods[op.TimeStart.Unix()]; !ok || op.TimeStart.Before(firstStart) {
+			continue
+		}
+		changes = append(changes, PeriodChange{ID: op.ID, TimeStart: op.TimeStart, Name: op.Name, Field: "Period", Old: op.Name})
+	}
+
+	return changes
+}
+
+// AlertChangeType describes how an alert changed between two sets of alerts.
+type AlertChangeType string
+
+// AlertChangeTypes
+const (
+	AlertAdded   AlertChangeType = "added"
+	AlertUpdated AlertChangeType = "updated"   // replaced by an alert referencing it
+	AlertRemoved AlertChangeType = "removed"   // no longer active
+	AlertCleared AlertChangeType = "cancelled" // replaced by a cancellation
+	AlertExpired AlertChangeType = "expired"   // no longer active because it expired
+)
+
+// An AlertChange represents a single difference between two sets of alerts.
+type AlertChange struct {
+	Type  AlertChangeType
+	Alert Alert // the newer alert, or the older alert if removed
+}
+
+// String returns a human readable description of the change.
+func (c AlertChange) String() string {
+	return fmt.Sprintf("%s %s: %s", c.Alert.Event, c.Type, c.Alert.Headline)
+}
+
+// DiffAlerts returns the differences between two sets of active alerts for the
+// same place. An alert in newer that references an alert in older is reported
+// as an update (or cancellation) rather than as an addition and removal.
+func DiffAlerts(older []Alert, newer []Alert) []AlertChange {
+	return DiffAlertsAt(older, newer, time.Time{})
+}
+
+// DiffAlertsAt is the same as DiffAlerts, except that an alert in older that
+// is no longer active and that expired at or before now is reported as
+// AlertExpired rather than AlertRemoved, closing out its lifecycle.
+func DiffAlertsAt(older []Alert, newer []Alert, now time.Time) []AlertChange {
+	var changes []AlertChange
+
+	olderAlerts := make(map[string]bool)
+	for _, a := range older {
+		olderAlerts[a.ID] = true
+	}
+	referenced := make(map[string]bool)
+
+	for _, a := range newer {
+		if olderAlerts[a.ID] {
+			referenced[a.ID] = true
+			continue
+		}
+		t := AlertAdded
+		for _, ref := range a.References {
+			if olderAlerts[ref] {
+				referenced[ref] = true
+				t = AlertUpdated
+				if a.MessageType == "Cancel" {
+					t = AlertCleared
+				}
+			}
+		}
+		changes = append(changes, AlertChange{Type: t, Alert: a})
+	}
+
+	for _, a := range older {
+		if referenced[a.ID] {
+			continue
+		}
+		t := AlertRemoved
+		if !now.IsZero() && !a.TimeExpires.IsZero() && !a.TimeExpires.After(now) {
+			t = AlertExpired
+		}
+		changes = append(changes, AlertChange{Type: t, Alert: a})
+	}
+
+	return changes
+}
+
+// formatRawValueUnit returns a ValueUnit formatted without rounding, or an
+// empty string if it has no unit.
+func formatRawValueUnit(vu ValueUnit) string {
+	if vu.Unit == "" {
+		return ""
+	}
+	return strconv.FormatFloat(vu.Value, 'f', -1, 64) + " " + vu.Unit
+}
+
+// valueUnitsDiffer reports whether two values differ by more than
+// diffTolerance, converting b to the unit of a if they differ. A value without
+// a unit differs from one with a unit.
+func valueUnitsDiffer(a ValueUnit, b ValueUnit) bool {
+	if a.Unit == "" || b.Unit == "" {
+		return a.Unit != b.Unit
+	}
+	b, err := convertValueUnit(b, a.Unit)
+	if err != nil {
+		return true
+	}
+	return math.Abs(a.Value-b.Value) > diffTolerance
+}
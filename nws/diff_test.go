@@ -0,0 +1,50 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"testing"
+)
+
+func TestPeriodChangeFormat(t *testing.T) {
+	c := PeriodChange{
+		Name:     "Tonight",
+		Field:    "WindSpeedMax",
+		Old:      "11 mph",
+		New:      "14 mph",
+		OldValue: ValueUnit{Value: 11, Unit: "mph"},
+		NewValue: ValueUnit{Value: 14, Unit: "mph"},
+	}
+	tests := []struct {
+		name   string
+		policy DisplayPolicy
+		want   string
+	}{
+		{"default", DefaultDisplayPolicy, "Tonight: WindSpeedMax changed from 10 mph to 15 mph"},
+		{"exact", DisplayPolicy{}, "Tonight: WindSpeedMax changed from 11 mph to 14 mph"},
+		// rounding to 10 mph would hide the change, so the raw values are used
+		{"coarse", DisplayPolicy{Increments: map[string]float64{"mph": 10}}, "Tonight: WindSpeedMax changed from 11 mph to 14 mph"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Format(tt.policy); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+	if got := c.String(); got != tests[0].want {
+		t.Errorf("String: got %q; want %q", got, tests[0].want)
+	}
+}
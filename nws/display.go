@@ -0,0 +1,85 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultDisplayPolicy rounds values the way weather.gov presents them:
+// temperatures to whole degrees, wind to 5 mph (or kt, or km/h), and pressure
+// to 0.01 inHg.
+var DefaultDisplayPolicy = DisplayPolicy{
+	Increments: map[string]float64{
+		"F":            1,
+		"C":            1,
+		"mph":          5,
+		"kt":           5,
+		"km/h":         5,
+		"m/s":          1,
+		"inHg":         0.01,
+		"mb":           1,
+		"Pa":           100,
+		"percent":      1,
+		"degrees true": 10,
+		"in":           0.01,
+		"mi":           0.25,
+	},
+}
+
+// A DisplayPolicy determines how values are rounded for presentation. It is
+// applied by formatters only; values stored in Forecasts, Observations, etc.
+// always keep their full precision.
+type DisplayPolicy struct {
+	// Increments maps a unit to the increment values in that unit are rounded
+	// to (e.g. 5 to round wind speeds in mph to the nearest 5). Values in
+	// units without an entry are not rounded.
+	Increments map[string]float64
+}
+
+// Round returns vu rounded to the increment for its unit.
+func (p DisplayPolicy) Round(vu ValueUnit) ValueUnit {
+	inc, ok := p.Increments[vu.Unit]
+	if !ok || inc <= 0 {
+		return vu
+	}
+	vu.Value = math.Round(vu.Value/inc) * inc
+	return vu
+}
+
+// Format returns vu rounded and formatted for display (e.g. "72 F" or
+// "30.12 inHg"), or an empty string if it has no unit.
+func (p DisplayPolicy) Format(vu ValueUnit) string {
+	if vu.Unit == "" {
+		return ""
+	}
+	inc, ok := p.Increments[vu.Unit]
+	if !ok || inc <= 0 {
+		return strconv.FormatFloat(vu.Value, 'f', -1, 64) + " " + vu.Unit
+	}
+	return strconv.FormatFloat(p.Round(vu).Value, 'f', decimalPlaces(inc), 64) + " " + vu.Unit
+}
+
+// decimalPlaces returns the number of decimal places needed to display
+// multiples of an increment (e.g. 2 for 0.01 and 0 for 5).
+func decimalPlaces(inc float64) int {
+	s := strconv.FormatFloat(inc, 'f', -1, 64)
+	if i := strings.Index(s, "."); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,359 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mikecamilleri/our-data/mock"
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+func ExampleNewClientFromConfig() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	httpClient := srv.Client()
+	config := nws.ClientConfig{
+		AppName:      "example",
+		Version:      "1.0",
+		ContactEmail: "you@example.com",
+	}
+
+	c, err := nws.NewClientFromConfig(httpClient, config, 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	gp := c.Gridpoint()
+	fmt.Printf("%s %d,%d (%s, %s)\n", gp.WFO, gp.GridX, gp.GridY, gp.City, gp.State)
+	fmt.Println("default station:", c.DefaultStationID())
+	// Output:
+	// PQR 112,103 (Portland, OR)
+	// default station: KPDX
+}
+
+func ExampleClient_UpdateSemidailyForecast() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	httpClient := srv.Client()
+	c, err := nws.NewClientFromCoordinates(httpClient, "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := c.UpdateSemidailyForecast(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, p := range c.SemidailyForecast().Periods {
+		fmt.Printf("%s: %s, %s\n", p.Name, p.ForecastShort, nws.DefaultDisplayPolicy.Format(p.Temperature))
+	}
+	// Output:
+	// This Afternoon: Sunny, 84 F
+	// Tonight: Mostly Clear, 59 F
+}
+
+func ExampleParseForecastHTML() {
+	// a trimmed forecast.weather.gov page; note the unclosed and unescaped
+	// markup typical of HTML
+	page := `<!DOCTYPE html>
+<html><head><script>if (a < b && c) {}</script></head><body>
+<div class="panel-heading"><b>Last Update: </b>2:43 pm PDT Aug 30, 2019</div>
+<div id="seven-day-forecast-body"><ul id="seven-day-forecast-list">
+<li class="forecast-tombstone"><div class="tombstone-container">
+<p class="period-name">This<br>Afternoon</p>
+<p><img src="newimages/medium/few.png" alt="This Afternoon: Sunny, with a high near 84." class="forecast-icon"></p>
+<p class="short-desc">Sunny</p><p class="temp temp-high">High: 84 &deg;F</p></div>
+<li class="forecast-tombstone"><div class="tombstone-container">
+<p class="period-name">Tonight<br><br></p>
+<p><img src="DualImage.php?i=nsct&j=nra&jp=30" alt="Tonight: A chance of rain." class="forecast-icon"></p>
+<p class="short-desc">Chance Rain</p><p class="temp temp-low">Low: 59 &deg;F</p></div>
+</ul></div>
+<div id="detailed-forecast-body">
+<div class="row row-odd row-forecast"><div class="col-sm-2 forecast-label"><b>This Afternoon</b></div><div class="col-sm-10 forecast-text">Sunny, with a high near 84.</div></div>
+<div class="row row-even row-forecast"><div class="col-sm-2 forecast-label"><b>Tonight</b></div><div class="col-sm-10 forecast-text">A 30 percent chance of rain after 11pm. Low around 59.</div></div>
+<div class="row row-odd row-forecast"><div class="col-sm-2 forecast-label"><b>Saturday</b></div><div class="col-sm-10 forecast-text">Sunny, with a high near 86.</div></div>
+</div></body></html>`
+
+	f, err := nws.ParseForecastHTML([]byte(page))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(f.TimeForecast.UTC().Format(time.RFC3339))
+	for _, p := range f.Periods {
+		fmt.Printf("%s: %q, %s, %.0f%% precipitation\n", p.Name, p.ForecastShort, nws.DefaultDisplayPolicy.Format(p.Temperature), p.PrecipitationProbability.Value)
+	}
+	// Output:
+	// 2019-08-30T21:43:00Z
+	// This Afternoon: "Sunny", 84 F, 0% precipitation
+	// Tonight: "Chance Rain", 59 F, 30% precipitation
+	// Saturday: "", 86 F, 0% precipitation
+}
+
+func ExampleParseTabularForecastHTML() {
+	// a trimmed FcstType=digital page; the date is given only for the first
+	// hour of each day, so the rollover at midnight is inferred from the hours
+	page := `<!DOCTYPE html>
+<html><body>
+<div class="panel-heading"><b>Last Update: </b>7:05 pm PDT Aug 30, 2019</div>
+<table>
+<tr><td><b>Date</b></td><td><b>08/30</b></td><td></td><td></td><td></td><td></td><td></td>
+<tr><td><b>Hour (PDT)</b></td><td>21</td><td>22</td><td>23</td><td>00</td><td>01</td><td>02</td>
+<tr><td><b>Temperature (&deg;F)</b></td><td>72</td><td>69</td><td>66</td><td>64</td><td>62</td><td>61</td>
+<tr><td><b>Surface Wind (mph)</b></td><td>8</td><td>7</td><td>6</td><td>5</td><td>5</td><td>3</td>
+<tr><td><b>Wind Dir</b></td><td>NW</td><td>NW</td><td>NNW</td><td>N</td><td>N</td><td>N</td>
+<tr><td><b>Precipitation Potential (%)</b></td><td>0</td><td>0</td><td>5</td><td>10</td><td>10</td><td>15</td>
+</table></body></html>`
+
+	ref := time.Date(2019, 8, 31, 2, 5, 0, 0, time.UTC)
+	f, err := nws.ParseTabularForecastHTML([]byte(page), ref)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, p := range f.Periods {
+		fmt.Printf("%s %s %s %s, %.0f%% precipitation\n", p.TimeStart.Format("Jan 2 15:04 MST"),
+			nws.DefaultDisplayPolicy.Format(p.Temperature), p.WindDirection, nws.DefaultDisplayPolicy.Format(p.WindSpeedMax), p.PrecipitationProbability.Value)
+	}
+	// Output:
+	// Aug 30 21:00 PDT 72 F NW 10 mph, 0% precipitation
+	// Aug 30 22:00 PDT 69 F NW 5 mph, 0% precipitation
+	// Aug 30 23:00 PDT 66 F NNW 5 mph, 5% precipitation
+	// Aug 31 00:00 PDT 64 F N 5 mph, 10% precipitation
+	// Aug 31 01:00 PDT 62 F N 5 mph, 10% precipitation
+	// Aug 31 02:00 PDT 61 F N 5 mph, 15% precipitation
+}
+
+func ExampleSummarize() {
+	start := time.Now().Add(time.Hour)
+	f := &nws.Forecast{Periods: []nws.Period{
+		{Name: "Today", TimeStart: start, TimeEnd: start.Add(6 * time.Hour), ForecastShort: "Sunny", Temperature: nws.ValueUnit{Value: 86, Unit: "F"}},
+		{Name: "Tonight", TimeStart: start.Add(6 * time.Hour), TimeEnd: start.Add(18 * time.Hour), ForecastShort: "Clear", Temperature: nws.ValueUnit{Value: 59.6, Unit: "F"}},
+		{Name: "Saturday", TimeStart: start.Add(18 * time.Hour), TimeEnd: start.Add(30 * time.Hour), ForecastShort: "Sunny", Temperature: nws.ValueUnit{Value: 88, Unit: "F"}},
+	}}
+	fmt.Println(nws.Summarize(f, 2))
+
+	tmpl, err := nws.NewSummaryTemplate(`{{label .}} {{degrees .Temperature}}`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	s, err := nws.Summarizer{Template: tmpl, Separator: ", ", MaxLength: 30}.Summarize(f, 3)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s)
+	// Output:
+	// Today: Sunny 86°F; Tonight: Clear 60°F
+	// Today 86°F, Tonight 60°F
+}
+
+func ExampleFireWeatherAlerts() {
+	alerts := []nws.Alert{
+		{Event: "Heat Advisory", Headline: "Heat Advisory until 8 PM"},
+		{Event: "Red Flag Warning", Headline: "Red Flag Warning until 11 PM"},
+	}
+	for _, a := range nws.FireWeatherAlerts(alerts) {
+		fmt.Println(a.Headline)
+	}
+	// Output:
+	// Red Flag Warning until 11 PM
+}
+
+func ExampleAlert_CoversPoint() {
+	a := nws.Alert{
+		Event: "Tornado Warning",
+		Polygons: [][]nws.Point{{
+			{Lat: 45.4, Lon: -122.7},
+			{Lat: 45.4, Lon: -122.6},
+			{Lat: 45.5, Lon: -122.6},
+			{Lat: 45.5, Lon: -122.7},
+		}},
+	}
+	fmt.Println(a.CoversPoint(45.458, -122.6636))
+	fmt.Println(a.CoversPoint(45.6, -122.6636))
+	// Output:
+	// true
+	// false
+}
+
+func ExampleAlertFilter() {
+	alerts := []nws.Alert{
+		{Event: "Wind Advisory", Severity: "Moderate", Urgency: "Expected", UGCCodes: []string{"ORZ006"}},
+		{Event: "Winter Storm Warning", Severity: "Severe", Urgency: "Expected", UGCCodes: []string{"ORZ006", "ORZ007"}},
+		{Event: "Blizzard Warning", Severity: "Extreme", Urgency: "Immediate", UGCCodes: []string{"WAZ211"}},
+	}
+
+	// notify of severe alerts for Portland, OR
+	f := nws.AlertFilter{
+		MinSeverity: "Severe",
+		Zones:       []string{"ORZ006"},
+	}
+	if err := f.Validate(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, a := range nws.FilterAlerts(alerts, f) {
+		fmt.Println(a.Event)
+	}
+
+	// a typo in a rule is reported rather than matching nothing
+	fmt.Println(nws.AlertFilter{MinSeverity: "Sever"}.Validate())
+	// Output:
+	// Winter Storm Warning
+	// invalid severity: "Sever"
+}
+
+func ExampleParseIconURL() {
+	icon, err := nws.ParseIconURL("https://api.weather.gov/icons/land/day/sct/rain,40?size=medium")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, c := range icon.Conditions {
+		fmt.Println(c.Code, c.Probability)
+	}
+	// Output:
+	// sct 0
+	// rain 40
+}
+
+func ExampleParseTAF() {
+	ref := time.Date(2019, 8, 31, 18, 0, 0, 0, time.UTC)
+	taf, err := nws.ParseTAF("TAF KPDX 311720Z 3118/0118 31008KT P6SM SCT050 FM010300 VRB04KT 3SM -RA BKN015", ref)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, g := range taf.Groups {
+		fmt.Println(g.Change, g.TimeStart.Format("02 15:04"), g.Visibility.Value, g.Weather)
+	}
+	// Output:
+	//  31 18:00 6 []
+	// FM 01 03:00 3 [-RA]
+}
+
+func ExampleClient_TAFs() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	httpClient := srv.Client()
+	c, err := nws.NewClientFromCoordinates(httpClient, "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	tafs, err := c.TAFs()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, taf := range tafs {
+		fmt.Println(taf.StationID, taf.TimeIssued.Format(time.RFC3339), len(taf.Groups), "groups")
+	}
+	// Output:
+	// KPDX 2019-08-31T17:20:00Z 2 groups
+}
+
+func ExampleHeatIndex() {
+	hi, err := nws.HeatIndex(nws.ValueUnit{Value: 95, Unit: "F"}, nws.ValueUnit{Value: 50, Unit: "percent"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(nws.DefaultDisplayPolicy.Format(hi))
+	// Output:
+	// 105 F
+}
+
+func ExampleDiffForecasts() {
+	start := time.Date(2019, 8, 31, 6, 0, 0, 0, time.UTC)
+	older := nws.Forecast{Periods: []nws.Period{
+		{Name: "Saturday", TimeStart: start, Temperature: nws.ValueUnit{Value: 84, Unit: "F"}, ForecastShort: "Sunny"},
+	}}
+	newer := nws.Forecast{Periods: []nws.Period{
+		{Name: "Saturday", TimeStart: start, Temperature: nws.ValueUnit{Value: 79, Unit: "F"}, ForecastShort: "Sunny"},
+	}}
+	for _, c := range nws.DiffForecasts(older, newer) {
+		fmt.Println(c)
+	}
+	// Output:
+	// Saturday: Temperature changed from 84 F to 79 F
+}
+
+func ExampleTimeSeries_Resample() {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// hourly precipitation from Saturday evening through Sunday, when
+	// daylight saving time begins
+	var samples []nws.Sample[float64]
+	for t := time.Date(2020, 3, 7, 18, 0, 0, 0, loc); t.Before(time.Date(2020, 3, 9, 0, 0, 0, 0, loc)); t = t.Add(time.Hour) {
+		samples = append(samples, nws.Sample[float64]{Time: t, Duration: time.Hour, Value: 0.1})
+	}
+	ts := nws.NewTimeSeries(samples)
+
+	for _, s := range ts.Resample(24*time.Hour, nws.AggregateSum).Samples {
+		fmt.Printf("%s %v %.1f\n", s.Time.Format("Mon Jan 2"), s.Duration, s.Value)
+	}
+	// Output:
+	// Sat Mar 7 24h0m0s 0.6
+	// Sun Mar 8 23h0m0s 2.3
+}
+
+func ExampleTimeSeries_FillGaps() {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// gridpoint values of irregular length with a gap between 06:00 and
+	// 09:00
+	layer := nws.GridpointLayer{Unit: "mm", Values: []nws.GridpointValue{
+		{TimeStart: start, Duration: 6 * time.Hour, Value: 6},
+		{TimeStart: start.Add(9 * time.Hour), Duration: 3 * time.Hour, Value: 1.5},
+	}}
+
+	buckets, err := layer.Series().Resample(3*time.Hour, nws.AggregateSum).FillGaps(nws.GapFillZero)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, s := range buckets.Samples {
+		fmt.Printf("%s %.1f\n", s.Time.Format("15:04"), s.Value)
+	}
+	// Output:
+	// 00:00 3.0
+	// 03:00 3.0
+	// 06:00 0.0
+	// 09:00 1.5
+}
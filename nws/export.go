@@ -0,0 +1,324 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An exportField is a single named value in an exported record. Values are
+// nil (missing), string, int, float64, bool, or time.Time.
+type exportField struct {
+	name  string
+	value interface{}
+}
+
+// ExportForecastCSV writes the periods of a forecast as CSV with a header row.
+// The columns are:
+//
+//   forecast_time, id, number, name, start, end, is_daytime,
+//   temperature, temperature_unit, temperature_trend,
+//   wind_speed_min, wind_speed_max, wind_speed_unit, wind_gust,
+//   wind_direction, precipitation_probability,
+//   short_forecast, detailed_forecast
+//
+// Times are ISO 8601 (RFC 3339) and missing values are empty. Wind speeds are
+// in wind_speed_unit.
+func ExportForecastCSV(w io.Writer, f Forecast) error {
+	return writeExportCSV(w, exportHeader(forecastExportRecords(Forecast{Periods: []Period{{}}})[0]), forecastExportRecords(f))
+}
+
+// ExportForecastJSON writes the periods of a forecast as a JSON array of
+// objects with the same fields as ExportForecastCSV. Missing values are null.
+func ExportForecastJSON(w io.Writer, f Forecast) error {
+	return writeExportJSON(w, forecastExportRecords(f))
+}
+
+// ExportForecastMsgPack writes the periods of a forecast as a MessagePack
+// array of maps with the same fields as ExportForecastCSV, for consumers such
+// as microcontrollers for which JSON is too heavy. Missing values are nil.
+func ExportForecastMsgPack(w io.Writer, f Forecast) error {
+	return writeExportMsgPack(w, forecastExportRecords(f))
+}
+
+// ExportObservationsCSV writes observations as CSV with a header row. The
+// columns are:
+//
+//   station, time, temperature, dewpoint, relative_humidity, wind_direction,
+//   wind_speed, wind_gust, barometric_pressure, sea_level_pressure,
+//   visibility, precipitation_last_hour, wind_chill, heat_index, metar
+//
+// followed by a unit column for each value (e.g. temperature_unit). Times are
+// ISO 8601 (RFC 3339) and missing values are empty.
+func ExportObservationsCSV(w io.Writer, obs []Observation) error {
+	return writeExportCSV(w, exportHeader(observationExportRecords([]Observation{{}})[0]), observationExportRecords(obs))
+}
+
+// ExportObservationsJSON writes observations as a JSON array of objects with
+// the same fields as ExportObservationsCSV. Missing values are null.
+func ExportObservationsJSON(w io.Writer, obs []Observation) error {
+	return writeExportJSON(w, observationExportRecords(obs))
+}
+
+// ExportObservationsMsgPack writes observations as a MessagePack array of maps
+// with the same fields as ExportObservationsCSV. Missing values are nil.
+func ExportObservationsMsgPack(w io.Writer, obs []Observation) error {
+	return writeExportMsgPack(w, observationExportRecords(obs))
+}
+
+// ExportAlertsCSV writes alerts as CSV with a header row. The columns are:
+//
+//   id, sent, effective, onset, expires, ends, status, message_type,
+//   category, severity, certainty, urgency, event, sender_name, headline,
+//   area_description, ugc_codes, same_codes, description, instruction,
+//   response
+//
+// Codes are separated by spaces. Times are ISO 8601 (RFC 3339) and missing
+// values are empty.
+func ExportAlertsCSV(w io.Writer, alerts []Alert) error {
+	return writeExportCSV(w, exportHeader(alertExportRecords([]Alert{{}})[0]), alertExportRecords(alerts))
+}
+
+// ExportAlertsJSON writes alerts as a JSON array of objects with the same
+// fields as ExportAlertsCSV. Missing values are null.
+func ExportAlertsJSON(w io.Writer, alerts []Alert) error {
+	return writeExportJSON(w, alertExportRecords(alerts))
+}
+
+// ExportAlertsMsgPack writes alerts as a MessagePack array of maps with the
+// same fields as ExportAlertsCSV. Missing values are nil.
+func ExportAlertsMsgPack(w io.Writer, alerts []Alert) error {
+	return writeExportMsgPack(w, alertExportRecords(alerts))
+}
+
+// forecastExportRecords returns the export records for a forecast.
+func forecastExportRecords(f Forecast) [][]exportField {
+	var recs [][]exportField
+	for _, p := range f.Periods {
+		windUnit := p.WindSpeedMax.Unit
+		for _, vu := range []ValueUnit{p.WindSpeedMin, p.WindGust} {
+			if windUnit == "" {
+				windUnit = vu.Unit
+			}
+		}
+		var pop interface{}
+		if p.PrecipitationProbability.Unit != "" {
+			pop = p.PrecipitationProbability.Value
+		}
+		recs = append(recs, []exportField{
+			{"forecast_time", f.TimeForecast},
+			{"id", p.ID},
+			{"number", p.Number},
+			{"name", p.Name},
+			{"start", p.TimeStart},
+			{"end", p.TimeEnd},
+			{"is_daytime", p.IsDaytime},
+			{"temperature", exportValue(p.Temperature)},
+			{"temperature_unit", p.Temperature.Unit},
+			{"temperature_trend", p.TemperatureTrend},
+			{"wind_speed_min", exportSpeedValue(p.WindSpeedMin, windUnit)},
+			{"wind_speed_max", exportSpeedValue(p.WindSpeedMax, windUnit)},
+			{"wind_speed_unit", windUnit},
+			{"wind_gust", exportSpeedValue(p.WindGust, windUnit)},
+			{"wind_direction", p.WindDirection},
+			{"precipitation_probability", pop},
+			{"short_forecast", p.ForecastShort},
+			{"detailed_forecast", p.ForecastDetailed},
+		})
+	}
+	return recs
+}
+
+// observationExportRecords returns the export records for observations.
+func observationExportRecords(obs []Observation) [][]exportField {
+	var recs [][]exportField
+	for _, o := range obs {
+		rec := []exportField{
+			{"station", o.StationID},
+			{"time", o.TimeObserved},
+		}
+		values := []struct {
+			name string
+			vu   ValueUnit
+		}{
+			{"temperature", o.Temperature},
+			{"dewpoint", o.Dewpoint},
+			{"relative_humidity", o.RelativeHumidity},
+			{"wind_direction", o.WindDirection},
+			{"wind_speed", o.WindSpeed},
+			{"wind_gust", o.WindGust},
+			{"barometric_pressure", o.BarometricPressure},
+			{"sea_level_pressure", o.SeaLevelPressure},
+			{"visibility", o.Visibility},
+			{"precipitation_last_hour", o.PrecipitationLastHour},
+			{"wind_chill", o.WindChill},
+			{"heat_index", o.HeatIndex},
+		}
+		for _, v := range values {
+			rec = append(rec, exportField{v.name, exportValue(v.vu)})
+		}
+		rec = append(rec, exportField{"metar", o.METAR})
+		for _, v := range values {
+			rec = append(rec, exportField{v.name + "_unit", v.vu.Unit})
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// alertExportRecords returns the export records for alerts.
+func alertExportRecords(alerts []Alert) [][]exportField {
+	var recs [][]exportField
+	for _, a := range alerts {
+		recs = append(recs, []exportField{
+			{"id", a.ID},
+			{"sent", a.TimeSent},
+			{"effective", a.TimeEffective},
+			{"onset", a.TimeOnset},
+			{"expires", a.TimeExpires},
+			{"ends", a.TimeEnds},
+			{"status", a.Status},
+			{"message_type", a.MessageType},
+			{"category", a.Category},
+			{"severity", a.Severity},
+			{"certainty", a.Certainty},
+			{"urgency", a.Urgency},
+			{"event", a.Event},
+			{"sender_name", a.SenderName},
+			{"headline", a.Headline},
+			{"area_description", a.AreaDescription},
+			{"ugc_codes", strings.Join(a.UGCCodes, " ")},
+			{"same_codes", strings.Join(a.SAMECodes, " ")},
+			{"description", a.Description},
+			{"instruction", a.Instruction},
+			{"response", a.Response},
+		})
+	}
+	return recs
+}
+
+// exportValue returns the value of a ValueUnit, or nil if it has no unit.
+func exportValue(vu ValueUnit) interface{} {
+	if vu.Unit == "" {
+		return nil
+	}
+	return vu.Value
+}
+
+// exportSpeedValue returns the value of a speed in a unit, or nil if it has no
+// unit or can't be converted.
+func exportSpeedValue(vu ValueUnit, unit string) interface{} {
+	cvu, err := convertSpeed(vu, unit)
+	if vu.Unit == "" || err != nil {
+		return nil
+	}
+	return cvu.Value
+}
+
+// exportNormalize returns a value with zero times and empty strings replaced
+// by nil and times formatted as RFC 3339.
+func exportNormalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Format(time.RFC3339)
+	case string:
+		if v == "" {
+			return nil
+		}
+	}
+	return v
+}
+
+// exportHeader returns the field names of a template record, for use as a
+// header row.
+func exportHeader(template []exportField) []string {
+	header := make([]string, len(template))
+	for i, f := range template {
+		header[i] = f.name
+	}
+	return header
+}
+
+// writeExportCSV writes records as CSV with a header row, which is written
+// even if there are no records.
+func writeExportCSV(w io.Writer, header []string, recs [][]exportField) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		row := make([]string, len(rec))
+		for i, f := range rec {
+			switch v := exportNormalize(f.value).(type) {
+			case nil:
+			case string:
+				row[i] = v
+			case int:
+				row[i] = strconv.Itoa(v)
+			case float64:
+				row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				row[i] = strconv.FormatBool(v)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeExportJSON writes records as a JSON array of objects, keeping the
+// order of the fields.
+func writeExportJSON(w io.Writer, recs [][]exportField) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, rec := range recs {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j, f := range rec {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			name, _ := json.Marshal(f.name)
+			value, err := json.Marshal(exportNormalize(f.value))
+			if err != nil {
+				return err
+			}
+			b.WriteString("\n    ")
+			b.Write(name)
+			b.WriteString(": ")
+			b.Write(value)
+		}
+		b.WriteString("\n  }")
+	}
+	if len(recs) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
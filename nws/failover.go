@@ -0,0 +1,132 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Station failover modes
+const (
+	// FailoverFirst uses the first station, in order, with a fresh
+	// observation.
+	FailoverFirst = "first"
+
+	// FailoverLatest retrieves every station and uses the most recent fresh
+	// observation.
+	FailoverLatest = "latest"
+)
+
+// A FailoverPolicy controls UpdateLatestObservationWithFailover.
+type FailoverPolicy struct {
+	// StationIDs are the candidate stations in order of preference. If it is
+	// empty, the default station is tried first and then the Client's other
+	// stations, nearest first, up to MaxStations in total.
+	StationIDs  []string
+	MaxStations int
+
+	// MaxAge is the age beyond which an observation is stale.
+	MaxAge time.Duration
+
+	// Mode is FailoverFirst or FailoverLatest. FailoverFirst is used if it
+	// is empty.
+	Mode string
+}
+
+// DefaultFailoverPolicy tries up to three stations, in order, for an
+// observation less than two hours old.
+var DefaultFailoverPolicy = FailoverPolicy{
+	MaxStations: 3,
+	MaxAge:      2 * time.Hour,
+	Mode:        FailoverFirst,
+}
+
+// UpdateLatestObservationWithFailover updates the latest observation for each
+// candidate station in a FailoverPolicy until a fresh one is found, and
+// returns it. Observations are cached as by UpdateLatestOservationForStation.
+//
+// If no station has a fresh observation, the most recent stale observation is
+// returned along with ErrStaleObservation. If no observation could be
+// retrieved at all, an error describing each station's failure is returned.
+func (c *Client) UpdateLatestObservationWithFailover(policy FailoverPolicy) (Observation, error) {
+	if policy.MaxStations <= 0 {
+		policy.MaxStations = DefaultFailoverPolicy.MaxStations
+	}
+	if policy.MaxAge <= 0 {
+		policy.MaxAge = DefaultFailoverPolicy.MaxAge
+	}
+	switch policy.Mode {
+	case "":
+		policy.Mode = FailoverFirst
+	case FailoverFirst, FailoverLatest:
+	default:
+		return Observation{}, fmt.Errorf("unknown failover mode: \"%s\"", policy.Mode)
+	}
+
+	var fresh, stale Observation
+	var errs []string
+	for _, id := range c.failoverStationIDs(policy) {
+		if err := c.UpdateLatestOservationForStation(id); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", id, err))
+			continue
+		}
+		o := c.obsTime(id).observation
+		if time.Since(o.TimeObserved) > policy.MaxAge {
+			if o.TimeObserved.After(stale.TimeObserved) {
+				stale = o
+			}
+			continue
+		}
+		if policy.Mode == FailoverFirst {
+			return o, nil
+		}
+		if o.TimeObserved.After(fresh.TimeObserved) {
+			fresh = o
+		}
+	}
+
+	switch {
+	case !fresh.TimeObserved.IsZero():
+		return fresh, nil
+	case !stale.TimeObserved.IsZero():
+		return stale, ErrStaleObservation
+	case len(errs) > 0:
+		return Observation{}, errors.New(strings.Join(errs, "; "))
+	}
+	return Observation{}, errors.New("no stations to try")
+}
+
+// failoverStationIDs returns the candidate stations for a FailoverPolicy.
+func (c *Client) failoverStationIDs(policy FailoverPolicy) []string {
+	if len(policy.StationIDs) > 0 {
+		return policy.StationIDs
+	}
+	ids := []string{}
+	if c.defaultStationID != "" {
+		ids = append(ids, c.defaultStationID)
+	}
+	for _, s := range c.stations {
+		if len(ids) >= policy.MaxStations {
+			break
+		}
+		if s.ID != c.defaultStationID {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
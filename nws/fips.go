@@ -0,0 +1,55 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stateFIPSCodes maps the two-letter postal abbreviations used in UGC codes to
+// state FIPS codes, including DC and the territories with NWS coverage.
+var stateFIPSCodes = map[string]string{
+	"AL": "01", "AK": "02", "AZ": "04", "AR": "05", "CA": "06", "CO": "08",
+	"CT": "09", "DE": "10", "DC": "11", "FL": "12", "GA": "13", "HI": "15",
+	"ID": "16", "IL": "17", "IN": "18", "IA": "19", "KS": "20", "KY": "21",
+	"LA": "22", "ME": "23", "MD": "24", "MA": "25", "MI": "26", "MN": "27",
+	"MS": "28", "MO": "29", "MT": "30", "NE": "31", "NV": "32", "NH": "33",
+	"NJ": "34", "NM": "35", "NY": "36", "NC": "37", "ND": "38", "OH": "39",
+	"OK": "40", "OR": "41", "PA": "42", "RI": "44", "SC": "45", "SD": "46",
+	"TN": "47", "TX": "48", "UT": "49", "VT": "50", "VA": "51", "WA": "53",
+	"WV": "54", "WI": "55", "WY": "56", "AS": "60", "GU": "66", "MP": "69",
+	"PR": "72", "VI": "78",
+}
+
+// CountyFIPSFromUGC returns the five digit county FIPS code for a UGC county
+// code (e.g. "ORC051" returns "41051"). The SAME code for a whole county is
+// the FIPS code with a leading zero.
+func CountyFIPSFromUGC(ugc string) (string, error) {
+	ugc = strings.ToUpper(strings.TrimSpace(ugc))
+	if len(ugc) != 6 || ugc[2] != 'C' {
+		return "", fmt.Errorf("not a UGC county code: \"%s\"", ugc)
+	}
+	state, ok := stateFIPSCodes[ugc[:2]]
+	if !ok {
+		return "", fmt.Errorf("unknown state in UGC code: \"%s\"", ugc)
+	}
+	for _, r := range ugc[3:] {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("not a UGC county code: \"%s\"", ugc)
+		}
+	}
+	return state + ugc[3:], nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const getZoneForecastForZoneEndpointURLStringFmt = "zones/%s/%s/forecast" // type, id
+
+// FireWeatherAlertEvents are the alert events that concern fire weather.
+var FireWeatherAlertEvents = []string{
+	"Fire Weather Watch",
+	"Red Flag Warning",
+}
+
+// A ZoneForecast represents the text forecast for a forecast zone, such as a
+// public or fire weather zone.
+type ZoneForecast struct {
+	ZoneID        string
+	TimeRetrieved time.Time
+	TimeForecast  time.Time
+	Periods       []ZonePeriod
+}
+
+// A ZonePeriod represents a single period of a zone forecast.
+type ZonePeriod struct {
+	Number           int
+	Name             string
+	ForecastDetailed string
+}
+
+// FireWeather represents fire weather information for a point. Gridpoint
+// layers are empty if they are not published for the gridpoint.
+type FireWeather struct {
+	ZoneForecast *ZoneForecast // the fire weather zone forecast
+
+	HainesIndex      GridpointLayer
+	RelativeHumidity GridpointLayer
+	WindSpeed        GridpointLayer
+	WindGust         GridpointLayer
+
+	Alerts []Alert // active Fire Weather Watches and Red Flag Warnings
+}
+
+// FireWeather retrieves fire weather information for the Client's point: the
+// fire weather zone forecast, gridpoint layers relevant to fire behavior, and
+// any active fire weather alerts among the last retrieved alerts.
+func (c *Client) FireWeather() (*FireWeather, error) {
+	if c.gridpoint.FireWeatherZone == "" {
+		return nil, errors.New("gridpoint has no fire weather zone")
+	}
+
+	zf, err := getZoneForecastForZone(c.httpClient, c.httpUserAgentString, c.apiURLString, "fire", c.gridpoint.FireWeatherZone)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := getGridpointLayersForGridpoint(
+		c.httpClient,
+		c.httpUserAgentString,
+		c.apiURLString,
+		c.gridpoint,
+		[]string{"hainesIndex", "relativeHumidity", "windSpeed", "windGust"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FireWeather{
+		ZoneForecast:     zf,
+		HainesIndex:      layers["hainesIndex"],
+		RelativeHumidity: layers["relativeHumidity"],
+		WindSpeed:        layers["windSpeed"],
+		WindGust:         layers["windGust"],
+		Alerts:           FireWeatherAlerts(c.alerts),
+	}, nil
+}
+
+// FireWeatherAlerts returns the alerts whose event is one of
+// FireWeatherAlertEvents.
+func FireWeatherAlerts(alerts []Alert) []Alert {
+	var fwas []Alert
+	for _, a := range alerts {
+		for _, e := range FireWeatherAlertEvents {
+			if a.Event == e {
+				fwas = append(fwas, a)
+				break
+			}
+		}
+	}
+	return fwas
+}
+
+// getZoneForecastForZone retrieves from the NWS API the text forecast for a
+// zone of a given type ("forecast", "fire", etc.).
+func getZoneForecastForZone(httpClient *http.Client, httpUserAgentString string, apiURLString string, zoneType string, zoneID string) (*ZoneForecast, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getZoneForecastForZoneEndpointURLStringFmt, zoneType, zoneID),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	zf, err := newZoneForecastFromZoneForecastRespBody(respBody)
+	if err != nil {
+		return nil, err
+	}
+	zf.ZoneID = zoneID
+	return zf, nil
+}
+
+// newZoneForecastFromZoneForecastRespBody returns a ZoneForecast pointer, given
+// a response body from the NWS API.
+func newZoneForecastFromZoneForecastRespBody(respBody []byte) (*ZoneForecast, error) {
+	// unmarshal the body into a temporary struct
+	zfRaw := struct {
+		Properties struct {
+			Updated string
+			Periods []struct {
+				Number           json.Number
+				Name             string
+				DetailedForecast string
+			}
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &zfRaw); err != nil {
+		return nil, err
+	}
+
+	var zf ZoneForecast
+	zf.TimeRetrieved = time.Now()
+	zf.TimeForecast, _ = time.Parse(time.RFC3339, zfRaw.Properties.Updated)
+
+	for _, pRaw := range zfRaw.Properties.Periods {
+		n, err := strconv.Atoi(string(pRaw.Number))
+		if err != nil {
+			continue // skip if no number
+		}
+		zf.Periods = append(zf.Periods, ZonePeriod{
+			Number:           n,
+			Name:             pRaw.Name,
+			ForecastDetailed: pRaw.DetailedForecast,
+		})
+	}
+
+	return &zf, nil
+}
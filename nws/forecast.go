@@ -34,17 +34,47 @@ const (
 // Forecasts contain a variable number of Periods, each representing an
 // arbitrary length of time.
 type Forecast struct {
-	// Gridpoint Gridpoint
+	Gridpoint Gridpoint // the gridpoint, and therefore office, that served this
+	Source    string    // SourceAPI or SourceLegacy
 
 	TimeRetrieved time.Time
 	TimeForecast  time.Time
+	TimeValid     time.Time     // start of the time the forecast is valid for
+	ValidDuration time.Duration // length of the time the forecast is valid for
+
+	Elevation ValueUnit        // of the gridpoint cell, if provided
+	Geometry  ForecastGeometry // of the gridpoint cell, if provided
 
 	Periods []Period
+
+	ResponseMeta *ResponseMeta // of the API response the forecast was parsed from, if any
+}
+
+// IsValidAt reports whether the forecast is valid at t, that is, whether t is
+// within the forecast's valid times. If the valid times are unknown, it reports
+// whether t is within the forecast's periods.
+func (f Forecast) IsValidAt(t time.Time) bool {
+	start, end := f.TimeValid, f.TimeValid.Add(f.ValidDuration)
+	if f.TimeValid.IsZero() {
+		if len(f.Periods) == 0 {
+			return false
+		}
+		start, end = f.Periods[0].TimeStart, f.Periods[len(f.Periods)-1].TimeEnd
+	}
+	return !t.Before(start) && t.Before(end)
+}
+
+// A ForecastGeometry is the location that a forecast applies to, for rendering
+// the forecast on a map.
+type ForecastGeometry struct {
+	Point   *Point  // the point within the cell, if provided
+	Polygon []Point // the outer ring of the gridpoint cell
 }
 
 // A Period represents the forecast for a particular range of time at a
 // a particular place on Earth.
 type Period struct {
+	ID     string // stable across fetches of the same forecast; see PeriodID
 	Number int
 	Name   string
 
@@ -56,17 +86,145 @@ type Period struct {
 	TemperatureTrend string
 	WindSpeedMin     ValueUnit
 	WindSpeedMax     ValueUnit
+	WindGust         ValueUnit // hourly forecasts only, and not always
 	WindDirection    string
+	Icon             Icon
+
+	// PrecipitationProbability is only provided by newer versions of the API.
+	// See also the probabilities in Icon.
+	PrecipitationProbability ValueUnit
+
+	// RelativeHumidity ("percent") and Dewpoint ("C") are only provided by
+	// newer versions of the API, and only in hourly forecasts.
+	RelativeHumidity ValueUnit
+	Dewpoint         ValueUnit
+
+	// SkyCover ("percent") is only provided by tabular forecasts; see
+	// ParseTabularForecastHTML.
+	SkyCover ValueUnit
+
+	// Expected precipitation amounts in inches, parsed from ForecastDetailed.
+	// These have no unit if no amount is mentioned.
+	RainfallAmountMin ValueUnit
+	RainfallAmountMax ValueUnit
+	SnowAmountMin     ValueUnit
+	SnowAmountMax     ValueUnit
+	IceAmountMin      ValueUnit
+	IceAmountMax      ValueUnit
+
 	ForecastShort    string
 	ForecastDetailed string
+
+	// FirstHalf and SecondHalf are nil unless the icon indicates that
+	// conditions change partway through the period (e.g. "sct/rain,40").
+	FirstHalf  *HalfPeriod
+	SecondHalf *HalfPeriod
+
+	// Derived is true for periods synthesized by BlendForecasts rather than
+	// forecast by the NWS.
+	Derived bool
+}
+
+// A HalfPeriod represents the conditions for half of a split Period, allowing
+// "AM / PM" or "evening / overnight" breakdowns.
+type HalfPeriod struct {
+	TimeStart time.Time
+	TimeEnd   time.Time
+	Condition IconCondition
+}
+
+// IsSplit reports whether the period has different conditions in its first and
+// second halves.
+func (p Period) IsSplit() bool {
+	return p.FirstHalf != nil && p.SecondHalf != nil
+}
+
+// A HighLow represents the high and low temperatures for a single day.
+type HighLow struct {
+	Date time.Time // the start of the day according to a DayBoundary
+	High ValueUnit
+	Low  ValueUnit
+}
+
+// PeriodAt returns the period that contains t. The second return value is
+// false if no period contains t.
+func (f Forecast) PeriodAt(t time.Time) (Period, bool) {
+	for _, p := range f.Periods {
+		if !t.Before(p.TimeStart) && t.Before(p.TimeEnd) {
+			return p, true
+		}
+	}
+	return Period{}, false
+}
+
+// PeriodsBetween returns the periods that overlap the range from start to end.
+func (f Forecast) PeriodsBetween(start time.Time, end time.Time) []Period {
+	var ps []Period
+	for _, p := range f.Periods {
+		if p.TimeStart.Before(end) && p.TimeEnd.After(start) {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// DailyHighLow returns the high and low temperature for each day in the
+// forecast, in chronological order, with days beginning at local midnight.
+// Periods are assigned to the day on which they start, so the low from
+// "Thursday Night" belongs to Thursday as it does on weather.gov. Periods
+// without a temperature are ignored.
+func (f Forecast) DailyHighLow() []HighLow {
+	return f.DailyHighLowWithBoundary(MidnightDayBoundary)
+}
+
+// DailyHighLowWithBoundary is the same as DailyHighLow, but with days beginning
+// according to a DayBoundary (e.g. sunrise to sunrise).
+func (f Forecast) DailyHighLowWithBoundary(boundary DayBoundary) []HighLow {
+	var hls []HighLow
+	for _, p := range f.Periods {
+		if p.Temperature.Unit == "" {
+			continue
+		}
+		date := boundary(p.TimeStart)
+
+		if len(hls) == 0 || !hls[len(hls)-1].Date.Equal(date) {
+			hls = append(hls, HighLow{Date: date, High: p.Temperature, Low: p.Temperature})
+			continue
+		}
+		hl := &hls[len(hls)-1]
+		if p.Temperature.Value > hl.High.Value {
+			hl.High = p.Temperature
+		}
+		if p.Temperature.Value < hl.Low.Value {
+			hl.Low = p.Temperature
+		}
+	}
+	return hls
+}
+
+// MaxWind returns the period with the highest maximum wind speed. The second
+// return value is false if no period has a wind speed.
+func (f Forecast) MaxWind() (Period, bool) {
+	var max Period
+	var ok bool
+	for _, p := range f.Periods {
+		if p.WindSpeedMax.Unit == "" {
+			continue
+		}
+		if !ok || p.WindSpeedMax.Value > max.WindSpeedMax.Value {
+			max = p
+			ok = true
+		}
+	}
+	return max, ok
 }
 
 // getSemidailyForceastForGridpoint retrieves from the NWS API the latest
 // semni-daily forecast for a particular gridpoint.
 //
 // The NWS tends to refer to semni-daily forecasts simply as "forecast."
-func getSemidailyForecastForGridpoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, gridpoint Gridpoint) (*Forecast, error) {
-	respBody, err := doAPIRequest(
+func getSemidailyForecastForGridpoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, gridpoint Gridpoint, opts ParseOptions) (*Forecast, error) {
+	respBody, meta, err := doAPIRequestWithMeta(
 		httpClient,
 		httpUserAgentString,
 		apiURLString,
@@ -81,13 +239,20 @@ func getSemidailyForecastForGridpoint(httpClient *http.Client, httpUserAgentStri
 	if err != nil {
 		return nil, err
 	}
-	return newForecastFromForecastRespBody(respBody)
+	f, err := newForecastFromForecastRespBody(respBody, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.Gridpoint = gridpoint
+	f.ResponseMeta = meta
+	f.setPeriodIDs()
+	return f, nil
 }
 
 // getHourlyForecastForGridpoint retrieves from the NWS API the latest
 // hourly forecast for a particular gridpoint.
-func getHourlyForecastForGridpoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, gridpoint Gridpoint) (*Forecast, error) {
-	respBody, err := doAPIRequest(
+func getHourlyForecastForGridpoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, gridpoint Gridpoint, opts ParseOptions) (*Forecast, error) {
+	respBody, meta, err := doAPIRequestWithMeta(
 		httpClient,
 		httpUserAgentString,
 		apiURLString,
@@ -97,27 +262,56 @@ func getHourlyForecastForGridpoint(httpClient *http.Client, httpUserAgentString
 	if err != nil {
 		return nil, err
 	}
-	return newForecastFromForecastRespBody(respBody)
+	f, err := newForecastFromForecastRespBody(respBody, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.Gridpoint = gridpoint
+	f.ResponseMeta = meta
+	f.setPeriodIDs()
+	return f, nil
 }
 
 // newForecastFromForecastRespBody returns a Forecast pointer, given a response
-// body from the NWS API.
-func newForecastFromForecastRespBody(respBody []byte) (*Forecast, error) {
+// body from the NWS API. See ParseOptions.
+func newForecastFromForecastRespBody(respBody []byte, opts ParseOptions) (*Forecast, error) {
 	// unmarshal the body into a temporary struct
 	fRaw := struct {
+		Geometry   *geoJSONGeometry
 		Properties struct {
 			UpdateTime string
-			Periods    []struct {
-				Number           string
+			ValidTimes string
+			Elevation  struct {
+				Value    *float64
+				UnitCode string
+			}
+			Periods []struct {
+				Number           json.Number
 				Name             string
 				StartTime        string
 				EndTime          string
 				IsDaytime        bool
-				Temperature      string
+				Temperature      json.Number
 				TemperatureUnit  string
 				TemperatureTrend string
-				WindSpeed        string // "2 to 7 mph" or "5 mph"
+				WindSpeed        json.RawMessage // "2 to 7 mph", "5 mph", or a structured value
+				WindGust         json.RawMessage // null, "20 mph", or {"value": 32, "unitCode": "wmoUnit:km_h-1"}
 				WindDirection    string
+				Icon             string
+
+				ProbabilityOfPrecipitation struct {
+					Value    *float64
+					UnitCode string
+				}
+				RelativeHumidity struct {
+					Value    *float64
+					UnitCode string
+				}
+				Dewpoint struct {
+					Value    *float64
+					UnitCode string
+				}
+
 				ShortForecast    string
 				DetailedForecast string
 			}
@@ -130,6 +324,7 @@ func newForecastFromForecastRespBody(respBody []byte) (*Forecast, error) {
 	// validate and build returned slice
 	var err error
 	var f Forecast
+	f.Source = SourceAPI
 
 	// must have valid times
 	f.TimeRetrieved = time.Now()
@@ -138,60 +333,265 @@ func newForecastFromForecastRespBody(respBody []byte) (*Forecast, error) {
 		return nil, err
 	}
 
+	// missing or invalid values are ignored unless parsing is strict
+	var perrs ParseErrors
+	if vt := fRaw.Properties.ValidTimes; vt != "" {
+		if t, d, err := parseValidTime(vt); err == nil {
+			f.TimeValid, f.ValidDuration = t, d
+		} else {
+			perrs.add("validTimes", vt, err)
+		}
+	}
+	if v := fRaw.Properties.Elevation.Value; v != nil {
+		if u := fRaw.Properties.Elevation.UnitCode; strings.HasSuffix(u, ":m") {
+			f.Elevation = ValueUnit{Value: *v, Unit: "m"}
+		} else {
+			perrs.add("elevation.unitCode", u, errUnrecognizedUnit)
+		}
+	}
+	if fRaw.Geometry != nil {
+		f.Geometry = newForecastGeometryFromGeoJSON(*fRaw.Geometry)
+	}
+
 	// iterate through periods
-	for _, pRaw := range fRaw.Properties.Periods {
+	for i, pRaw := range fRaw.Properties.Periods {
 		p := Period{}
+		path := func(field string) string {
+			return fmt.Sprintf("periods[%d].%s", i, field)
+		}
 
-		p.Number, err = strconv.Atoi(pRaw.Number)
+		// skip the period if it has no number or bad times
+		p.Number, err = strconv.Atoi(string(pRaw.Number))
 		if err != nil {
-			continue // skip if no number
+			perrs.add(path("number"), string(pRaw.Number), err)
+			continue
 		}
 		p.TimeStart, err = time.Parse(time.RFC3339, pRaw.StartTime)
 		if err != nil {
-			continue // skip if bad start time
+			perrs.add(path("startTime"), pRaw.StartTime, err)
+			continue
 		}
 		p.TimeEnd, err = time.Parse(time.RFC3339, pRaw.EndTime)
 		if err != nil {
-			continue // skip if bad end time
+			perrs.add(path("endTime"), pRaw.EndTime, err)
+			continue
 		}
 
-		// ignore any missing or invalid fields
 		p.Name = pRaw.Name
 		p.IsDaytime = pRaw.IsDaytime
 
-		tv, err := strconv.ParseFloat(pRaw.Temperature, 64)
-		if err == nil && (pRaw.TemperatureUnit == "F" || pRaw.TemperatureUnit == "C") {
-			p.Temperature.Value = tv
-			p.Temperature.Unit = pRaw.TemperatureUnit
+		if pRaw.Temperature != "" {
+			tv, err := strconv.ParseFloat(string(pRaw.Temperature), 64)
+			switch {
+			case err != nil:
+				perrs.add(path("temperature"), string(pRaw.Temperature), err)
+			case pRaw.TemperatureUnit != "F" && pRaw.TemperatureUnit != "C":
+				perrs.add(path("temperatureUnit"), pRaw.TemperatureUnit, errUnrecognizedUnit)
+			default:
+				p.Temperature.Value = tv
+				p.Temperature.Unit = pRaw.TemperatureUnit
+			}
 		}
 
 		p.TemperatureTrend = pRaw.TemperatureTrend
 
-		wsTokens := strings.Split(pRaw.WindSpeed, " ")
-		if len(wsTokens) == 4 {
-			p.WindSpeedMin.Value, err = strconv.ParseFloat(wsTokens[0], 64)
-			if err == nil && wsTokens[3] == "mph" {
-				p.WindSpeedMin.Unit = wsTokens[3]
-			}
-			p.WindSpeedMax.Value, err = strconv.ParseFloat(wsTokens[2], 64)
-			if err == nil && wsTokens[3] == "mph" {
-				p.WindSpeedMax.Unit = wsTokens[3]
+		p.WindSpeedMin, p.WindSpeedMax = newWindSpeedRangeFromRaw(pRaw.WindSpeed)
+		if p.WindSpeedMax.Unit == "" && !isNullJSON(pRaw.WindSpeed) {
+			perrs.add(path("windSpeed"), strings.Trim(string(pRaw.WindSpeed), `"`), errMalformedWindSpeed)
+		}
+		p.WindGust = newWindGustFromRaw(pRaw.WindGust)
+		if p.WindGust.Unit == "" && !isNullJSON(pRaw.WindGust) {
+			perrs.add(path("windGust"), strings.Trim(string(pRaw.WindGust), `"`), errMalformedWindSpeed)
+		}
+		p.WindDirection = pRaw.WindDirection
+		if pRaw.Icon != "" {
+			if p.Icon, err = ParseIconURL(pRaw.Icon); err != nil {
+				perrs.add(path("icon"), pRaw.Icon, err)
 			}
 		}
-		if len(wsTokens) == 2 {
-			p.WindSpeedMin.Value, err = strconv.ParseFloat(wsTokens[0], 64)
-			if err == nil && wsTokens[1] == "mph" {
-				p.WindSpeedMin.Unit = wsTokens[1]
+		if v := pRaw.ProbabilityOfPrecipitation.Value; v != nil {
+			p.PrecipitationProbability.Value = *v
+			p.PrecipitationProbability.Unit = "percent"
+		}
+		if v := pRaw.RelativeHumidity.Value; v != nil {
+			p.RelativeHumidity.Value = *v
+			p.RelativeHumidity.Unit = "percent"
+		}
+		if v := pRaw.Dewpoint.Value; v != nil {
+			if u := pRaw.Dewpoint.UnitCode; strings.HasSuffix(u, ":degC") {
+				p.Dewpoint.Value = *v
+				p.Dewpoint.Unit = "C"
+			} else {
+				perrs.add(path("dewpoint.unitCode"), u, errUnrecognizedUnit)
 			}
-			p.WindSpeedMax = p.WindSpeedMin
 		}
-
-		p.WindDirection = pRaw.WindDirection
+		p.FirstHalf, p.SecondHalf = newHalfPeriodsFromIcon(p.TimeStart, p.TimeEnd, p.Icon)
 		p.ForecastShort = pRaw.ShortForecast
 		p.ForecastDetailed = pRaw.DetailedForecast
+		p.RainfallAmountMin, p.RainfallAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "rain")
+		p.SnowAmountMin, p.SnowAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "snow")
+		p.IceAmountMin, p.IceAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "ice")
 
 		f.Periods = append(f.Periods, p)
 	}
+	if err := perrs.errOrNil(opts); err != nil {
+		return nil, err
+	}
 
 	return &f, nil
 }
+
+// newForecastGeometryFromGeoJSON returns a ForecastGeometry given the GeoJSON
+// geometry of a forecast, which is a Polygon, or a GeometryCollection of a
+// Point and a Polygon.
+func newForecastGeometryFromGeoJSON(g geoJSONGeometry) ForecastGeometry {
+	var fg ForecastGeometry
+	geometries := append([]geoJSONGeometry{g}, g.Geometries...)
+	for _, g := range geometries {
+		switch g.Type {
+		case "Point":
+			var c []float64
+			if err := json.Unmarshal(g.Coordinates, &c); err == nil && len(c) >= 2 {
+				fg.Point = &Point{Lat: c[1], Lon: c[0]}
+			}
+		case "Polygon":
+			if polys, err := newPolygonsFromGeoJSONGeometry(g.Type, g.Coordinates); err == nil && len(polys) > 0 {
+				fg.Polygon = polys[0]
+			}
+		}
+	}
+	return fg
+}
+
+// newHalfPeriodsFromIcon returns the first and second HalfPeriods for a period
+// given its icon. Both are nil if the icon contains fewer than two conditions.
+func newHalfPeriodsFromIcon(start time.Time, end time.Time, icon Icon) (*HalfPeriod, *HalfPeriod) {
+	if len(icon.Conditions) < 2 || !end.After(start) {
+		return nil, nil
+	}
+	mid := start.Add(end.Sub(start) / 2)
+	first := &HalfPeriod{
+		TimeStart: start,
+		TimeEnd:   mid,
+		Condition: icon.Conditions[0],
+	}
+	second := &HalfPeriod{
+		TimeStart: mid,
+		TimeEnd:   end,
+		Condition: icon.Conditions[1],
+	}
+	return first, second
+}
+
+// windSpeedUnitCodes maps the unit codes used in structured wind values to the
+// unit names used in wind speed strings.
+var windSpeedUnitCodes = map[string]string{
+	"unit:km_h-1":    "km/h",
+	"wmoUnit:km_h-1": "km/h",
+	"unit:m_s-1":     "m/s",
+	"wmoUnit:m_s-1":  "m/s",
+	"unit:kt":        "kt",
+	"wmoUnit:kt":     "kt",
+	"unit:mi_h-1":    "mph",
+	"wmoUnit:mi_h-1": "mph",
+}
+
+// windSpeedUnitNames maps the unit names that appear in wind speed strings,
+// lowercased, to the unit names used in ValueUnits.
+var windSpeedUnitNames = map[string]string{
+	"mph":   "mph",
+	"km/h":  "km/h",
+	"kmh":   "km/h",
+	"kph":   "km/h",
+	"kt":    "kt",
+	"kts":   "kt",
+	"knot":  "kt",
+	"knots": "kt",
+	"m/s":   "m/s",
+}
+
+// newWindSpeedRangeFromRaw returns the minimum and maximum wind speeds from the
+// windSpeed property of a period, which may be a string like "5 mph", "2 to 7
+// mph", or "10 to 15 km/h", or a structured value with either a value or a
+// minValue and maxValue. The minimum and maximum are equal if a single speed
+// is given, and both are empty if the speed is missing, malformed, or in an
+// unrecognized unit.
+func newWindSpeedRangeFromRaw(raw json.RawMessage) (ValueUnit, ValueUnit) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return parseWindSpeedRange(s)
+	}
+
+	var vu struct {
+		Value    *float64
+		MinValue *float64
+		MaxValue *float64
+		UnitCode string
+	}
+	if err := json.Unmarshal(raw, &vu); err != nil {
+		return ValueUnit{}, ValueUnit{}
+	}
+	u, ok := windSpeedUnitCodes[vu.UnitCode]
+	if !ok {
+		return ValueUnit{}, ValueUnit{}
+	}
+	min, max := vu.MinValue, vu.MaxValue
+	if vu.Value != nil {
+		min, max = vu.Value, vu.Value
+	}
+	if min == nil {
+		min = max
+	}
+	if max == nil {
+		max = min
+	}
+	if min == nil {
+		return ValueUnit{}, ValueUnit{}
+	}
+	return ValueUnit{Value: *min, Unit: u}, ValueUnit{Value: *max, Unit: u}
+}
+
+// parseWindSpeedRange parses a wind speed string like "5 mph", "2 to 7 mph",
+// "10-15 km/h", or "5kt". A decimal comma is accepted. See
+// newWindSpeedRangeFromRaw.
+func parseWindSpeedRange(s string) (ValueUnit, ValueUnit) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// split off the unit, which may not be separated by a space
+	i := strings.LastIndexAny(s, "0123456789")
+	if i < 0 {
+		return ValueUnit{}, ValueUnit{}
+	}
+	unit, ok := windSpeedUnitNames[strings.TrimSpace(s[i+1:])]
+	if !ok {
+		return ValueUnit{}, ValueUnit{}
+	}
+
+	var values []float64
+	for _, tok := range strings.FieldsFunc(s[:i+1], func(r rune) bool { return r == ' ' || r == '-' }) {
+		if tok == "to" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.Replace(tok, ",", ".", 1), 64)
+		if err != nil {
+			return ValueUnit{}, ValueUnit{}
+		}
+		values = append(values, v)
+	}
+	switch len(values) {
+	case 1:
+		return ValueUnit{Value: values[0], Unit: unit}, ValueUnit{Value: values[0], Unit: unit}
+	case 2:
+		return ValueUnit{Value: values[0], Unit: unit}, ValueUnit{Value: values[1], Unit: unit}
+	}
+	return ValueUnit{}, ValueUnit{}
+}
+
+// newWindGustFromRaw returns a ValueUnit from the windGust property of a
+// period, which may be null, a string like "20 mph" or "15 to 25 mph" (the
+// maximum is used), or a structured value. An empty ValueUnit is returned if
+// the gust is missing or malformed.
+func newWindGustFromRaw(raw json.RawMessage) ValueUnit {
+	_, max := newWindSpeedRangeFromRaw(raw)
+	return max
+}
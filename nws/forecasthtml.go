@@ -0,0 +1,235 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// legacyForecastLastUpdateRegexp matches the issuance time on a forecast page
+// (e.g. "Last Update: 2:43 pm PDT Aug 30, 2019").
+var legacyForecastLastUpdateRegexp = regexp.MustCompile(`Last Update:\s*(\d{1,2}:\d{2}\s*[ap]m)\s+([A-Za-z]{2,5})\s+([A-Z][a-z]{2}\s+\d{1,2},\s*\d{4})`)
+
+// These match a temperature in a tombstone (e.g. "High: 84 °F") and in
+// detailed text (e.g. "with a high near 84").
+var (
+	legacyForecastTombstoneTemperatureRegexp = regexp.MustCompile(`(-?\d+)\s*°?\s*F`)
+	legacyForecastDetailedTemperatureRegexp  = regexp.MustCompile(`(?i)\b(?:high|low)s?\s+(?:near|around|of|in the (?:lower |mid |upper )?)?\s*(-?\d+)`)
+)
+
+// usTimeZoneOffsets are the UTC offsets, in hours, of the time zone
+// abbreviations used by weather.gov.
+var usTimeZoneOffsets = map[string]int{
+	"EST":  -5,
+	"EDT":  -4,
+	"CST":  -6,
+	"CDT":  -5,
+	"MST":  -7,
+	"MDT":  -6,
+	"PST":  -8,
+	"PDT":  -7,
+	"AKST": -9,
+	"AKDT": -8,
+	"HST":  -10,
+	"AST":  -4,
+	"SST":  -11,
+	"ChST": 10,
+	"GMT":  0,
+	"UTC":  0,
+}
+
+// ParseForecastHTML returns the semi-daily forecast shown on a
+// forecast.weather.gov forecast page (MapClick.php), for use where the NWS API
+// and the JSON form of the page can't be reached.
+//
+// Parsing is tolerant of changes to the page layout. Periods are taken from
+// the detailed forecast and, failing that, from the "tombstones" of the
+// seven-day forecast; the two are merged by period name. Anything that can't
+// be found is left empty, and ErrEmptyResponse is returned only if no periods
+// are found at all.
+//
+// TimeForecast is parsed from the "Last Update" time. The page doesn't give
+// period times, so they are derived from it and the period names: daytime
+// periods end at 6 pm and nighttime periods at 6 am, and a period named for a
+// day of the week starts on that day. Without a "Last Update" time, TimeStart
+// and TimeEnd are zero.
+func ParseForecastHTML(page []byte) (*Forecast, error) {
+	doc := parseHTML(page)
+
+	f := Forecast{Source: SourceLegacy, TimeRetrieved: time.Now()}
+	f.TimeForecast = parseLegacyForecastLastUpdate(doc.text())
+
+	// the detailed forecast, if present, has every period
+	type detail struct{ name, text string }
+	var details []detail
+	for _, row := range doc.findAll(htmlClass("row-forecast")) {
+		label := row.find(htmlClass("forecast-label"))
+		text := row.find(htmlClass("forecast-text"))
+		if label != nil && text != nil && label.text() != "" {
+			details = append(details, detail{label.text(), text.text()})
+		}
+	}
+
+	// the tombstones have icons, short forecasts, and temperatures
+	tombstones := make(map[string]*htmlElement)
+	var tombstoneNames []string
+	for _, t := range doc.findAll(htmlClass("tombstone-container", "forecast-tombstone")) {
+		nameElem := t.find(htmlClass("period-name"))
+		if nameElem == nil {
+			continue
+		}
+		name := nameElem.text()
+		if _, ok := tombstones[strings.ToLower(name)]; name == "" || ok {
+			continue
+		}
+		tombstones[strings.ToLower(name)] = t
+		tombstoneNames = append(tombstoneNames, name)
+	}
+	if len(details) < 1 {
+		for _, name := range tombstoneNames {
+			details = append(details, detail{name: name})
+		}
+	}
+	if len(details) < 1 {
+		return nil, ErrEmptyResponse
+	}
+
+	for i, d := range details {
+		p := Period{
+			Number:           i + 1,
+			Name:             d.name,
+			IsDaytime:        !strings.Contains(strings.ToLower(d.name), "night"),
+			ForecastDetailed: d.text,
+		}
+		if t := tombstones[strings.ToLower(d.name)]; t != nil {
+			if sd := t.find(htmlClass("short-desc")); sd != nil {
+				p.ForecastShort = sd.text()
+			}
+			if temp := t.find(htmlClass("temp")); temp != nil {
+				if m := legacyForecastTombstoneTemperatureRegexp.FindStringSubmatch(temp.text()); m != nil {
+					v, _ := strconv.ParseFloat(m[1], 64)
+					p.Temperature = ValueUnit{Value: v, Unit: "F"}
+				}
+			}
+			if img := t.find(htmlTag("img")); img != nil {
+				p.Icon, _ = ParseIconURL(resolveLegacyURLString(img.attrs["src"]))
+				if p.ForecastDetailed == "" {
+					// the alt text repeats the detailed forecast after the name
+					p.ForecastDetailed = strings.TrimSpace(strings.TrimPrefix(img.attrs["alt"], d.name+":"))
+				}
+			}
+		}
+		if p.Temperature.Unit == "" {
+			if m := legacyForecastDetailedTemperatureRegexp.FindStringSubmatch(p.ForecastDetailed); m != nil {
+				v, _ := strconv.ParseFloat(m[1], 64)
+				p.Temperature = ValueUnit{Value: v, Unit: "F"}
+			}
+		}
+		for _, c := range p.Icon.Conditions {
+			if float64(c.Probability) > p.PrecipitationProbability.Value {
+				p.PrecipitationProbability = ValueUnit{Value: float64(c.Probability), Unit: "percent"}
+			}
+		}
+		p.RainfallAmountMin, p.RainfallAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "rain")
+		p.SnowAmountMin, p.SnowAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "snow")
+		p.IceAmountMin, p.IceAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "ice")
+		if !f.TimeForecast.IsZero() {
+			after := f.TimeForecast
+			if i > 0 {
+				after = f.Periods[i-1].TimeEnd
+			}
+			p.TimeStart, p.TimeEnd = legacyPeriodTimes(p.Name, p.IsDaytime, after)
+		}
+		f.Periods = append(f.Periods, p)
+	}
+	f.setPeriodIDs()
+
+	return &f, nil
+}
+
+// legacyPeriodTimes returns the time range of a period on a forecast page
+// that follows after, which is the issuance time for the first period and the
+// end of the previous period otherwise. A period named for a day of the week
+// (e.g. "Saturday Night") starts on that day; others (e.g. "Tonight" or
+// "Labor Day") start at after.
+func legacyPeriodTimes(name string, isDaytime bool, after time.Time) (time.Time, time.Time) {
+	startHour, endHour := 18, 6
+	if isDaytime {
+		startHour, endHour = 6, 18
+	}
+	start := after
+	if fields := strings.Fields(name); len(fields) > 0 {
+		if wd, ok := legacyWeekdays[strings.ToLower(fields[0])]; ok && start.Weekday() != wd {
+			for d := 1; d <= 7; d++ {
+				t := time.Date(after.Year(), after.Month(), after.Day()+d, startHour, 0, 0, 0, after.Location())
+				if t.Weekday() == wd {
+					start = t
+					break
+				}
+			}
+		}
+	}
+	end := time.Date(start.Year(), start.Month(), start.Day(), endHour, 0, 0, 0, start.Location())
+	for !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// legacyWeekdays maps the lowercase names of the days of the week to them.
+var legacyWeekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// parseLegacyForecastLastUpdate returns the "Last Update" time in the text of
+// a forecast page, or the zero time if it isn't found.
+func parseLegacyForecastLastUpdate(text string) time.Time {
+	m := legacyForecastLastUpdateRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}
+	}
+	offset, ok := usTimeZoneOffsets[m[2]]
+	if !ok {
+		return time.Time{}
+	}
+	loc := time.FixedZone(m[2], offset*60*60)
+	s := strings.Join(strings.Fields(m[1]+" "+m[3]), " ")
+	t, err := time.ParseInLocation("3:04 pm Jan 2, 2006", s, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// resolveLegacyURLString resolves a URL found on a forecast page, which is
+// often relative, against the default legacy forecast URL.
+func resolveLegacyURLString(urlString string) string {
+	base, _ := url.Parse(defaultLegacyForecastURLString)
+	u, err := base.Parse(strings.TrimSpace(urlString))
+	if err != nil {
+		return urlString
+	}
+	return u.String()
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestParseForecastHTMLGolden parses each page in testdata/forecasthtml and
+// compares the forecast, as JSON, with the golden file of the same name.
+func TestParseForecastHTMLGolden(t *testing.T) {
+	pages, err := filepath.Glob("testdata/forecasthtml/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) < 1 {
+		t.Fatal("no pages in testdata/forecasthtml")
+	}
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			b, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := ParseForecastHTML(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.TimeRetrieved = time.Time{}
+			got, err := json.MarshalIndent(f, "", "\t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(page, ".html") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestLegacyPeriodTimes(t *testing.T) {
+	pdt := time.FixedZone("PDT", -7*60*60)
+	issued := time.Date(2019, 8, 30, 14, 43, 0, 0, pdt) // a Friday
+	tests := []struct {
+		name      string
+		isDaytime bool
+		after     time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"This Afternoon", true, issued, issued, time.Date(2019, 8, 30, 18, 0, 0, 0, pdt)},
+		{"Tonight", false, issued, issued, time.Date(2019, 8, 31, 6, 0, 0, 0, pdt)},
+		{"Overnight", false, time.Date(2019, 8, 31, 1, 30, 0, 0, pdt), time.Date(2019, 8, 31, 1, 30, 0, 0, pdt), time.Date(2019, 8, 31, 6, 0, 0, 0, pdt)},
+		{"Saturday", true, time.Date(2019, 8, 31, 6, 0, 0, 0, pdt), time.Date(2019, 8, 31, 6, 0, 0, 0, pdt), time.Date(2019, 8, 31, 18, 0, 0, 0, pdt)},
+		{"Saturday Night", false, time.Date(2019, 8, 31, 18, 0, 0, 0, pdt), time.Date(2019, 8, 31, 18, 0, 0, 0, pdt), time.Date(2019, 9, 1, 6, 0, 0, 0, pdt)},
+		{"Sunday", true, issued, time.Date(2019, 9, 1, 6, 0, 0, 0, pdt), time.Date(2019, 9, 1, 18, 0, 0, 0, pdt)},
+		{"Labor Day", true, time.Date(2019, 9, 2, 6, 0, 0, 0, pdt), time.Date(2019, 9, 2, 6, 0, 0, 0, pdt), time.Date(2019, 9, 2, 18, 0, 0, 0, pdt)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := legacyPeriodTimes(tt.name, tt.isDaytime, tt.after)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("got %v to %v; want %v to %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrStaleObservation is returned (with the stale observation) when a new
+// observation does not appear within a FreshnessPolicy's SLA.
+var ErrStaleObservation = errors.New("no new observation within SLA")
+
+// A FreshnessPolicy controls how FreshLatestObservationForStation waits for a
+// station's next report. The observation endpoints lag unpredictably after a
+// station reports, so the prior report is often returned for several minutes.
+type FreshnessPolicy struct {
+	// ReportInterval is how often the station reports. The next report is
+	// expected ReportInterval after the previous one.
+	ReportInterval time.Duration
+
+	// InitialBackoff is the delay before the first retry. It doubles after
+	// each retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// SLA is how long after the expected report time to keep retrying
+	// before giving up.
+	SLA time.Duration
+}
+
+// DefaultFreshnessPolicy suits stations that report hourly.
+var DefaultFreshnessPolicy = FreshnessPolicy{
+	ReportInterval: time.Hour,
+	InitialBackoff: 30 * time.Second,
+	MaxBackoff:     4 * time.Minute,
+	SLA:            20 * time.Minute,
+}
+
+// FreshLatestObservationForStation retrieves the latest observation for a
+// station, retrying with exponential backoff until one newer than the
+// previously retrieved observation appears.
+//
+// If the next report is not expected yet, the latest observation is returned
+// without retrying. If no new observation appears within policy.SLA of the
+// expected report time (or of now, if reports have been missed), the latest
+// observation is returned along with ErrStaleObservation. Retrying stops
+// early if ctx is done.
+func (c *Client) FreshLatestObservationForStation(ctx context.Context, id string, policy FreshnessPolicy) (Observation, error) {
+	if policy.ReportInterval <= 0 {
+		policy.ReportInterval = DefaultFreshnessPolicy.ReportInterval
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultFreshnessPolicy.InitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+
+	prev := c.obsTime(id).observation.TimeObserved
+	expected := prev.Add(policy.ReportInterval)
+	deadline := expected.Add(policy.SLA)
+	if now := time.Now(); now.After(expected) {
+		deadline = now.Add(policy.SLA) // reports were missed; allow a full SLA
+	}
+
+	backoff := policy.InitialBackoff
+	for {
+		if err := c.UpdateLatestOservationForStation(id); err != nil {
+			return Observation{}, err
+		}
+		o := c.obsTime(id).observation
+		if prev.IsZero() || o.TimeObserved.After(prev) {
+			return o, nil
+		}
+		now := time.Now()
+		if now.Before(expected) {
+			return o, nil // nothing new expected yet
+		}
+		if !now.Add(backoff).Before(deadline) {
+			return o, ErrStaleObservation
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return o, ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// FreshLatestObservationForDefaultStation is the same as
+// FreshLatestObservationForStation, for the default station.
+func (c *Client) FreshLatestObservationForDefaultStation(ctx context.Context, policy FreshnessPolicy) (Observation, error) {
+	return c.FreshLatestObservationForStation(ctx, c.defaultStationID, policy)
+}
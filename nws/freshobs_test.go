@@ -0,0 +1,55 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFreshLatestObservationConcurrent is meant to be run with -race.
+func TestFreshLatestObservationConcurrent(t *testing.T) {
+	observed := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/geo+json")
+		fmt.Fprintf(w, `{"properties": {"station": "https://api.weather.gov/stations/KPDX", "timestamp": "%s"}}`, observed)
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client(), httpUserAgentString: "test", apiURLString: srv.URL + "/", observations: make(map[string]ObsTime)}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := c.FreshLatestObservationForStation(context.Background(), "KPDX", DefaultFreshnessPolicy); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			c.LatestObservationForStationLastRetrieved("KPDX")
+		}()
+	}
+	wg.Wait()
+	if got := c.LatestObservationForStationLastRetrieved("KPDX"); got.IsZero() {
+		t.Error("no observation was retrieved")
+	}
+}
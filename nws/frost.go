@@ -0,0 +1,156 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// latentHeatOfFusion of water in J/kg.
+	latentHeatOfFusion = 334000
+
+	// maxFrostObservationGap is the longest gap between observations that is
+	// filled by interpolation. Longer gaps are skipped.
+	maxFrostObservationGap = 6 * time.Hour
+)
+
+// DefaultSoilParameters describe a moist loam under bare ground.
+var DefaultSoilParameters = SoilParameters{
+	ThermalConductivity: 1.5,
+	DryDensity:          1600,
+	MoistureContent:     0.15,
+	SurfaceFactor:       0.8,
+}
+
+// SoilParameters describe the soil for a FrostModel.
+type SoilParameters struct {
+	ThermalConductivity float64 // of frozen soil, W/(m K); about 1 for dry sand to 2.5 for wet clay
+	DryDensity          float64 // kg/m^3
+	MoistureContent     float64 // water as a fraction of dry weight
+	SurfaceFactor       float64 // ratio of surface to air freezing index; about 0.3 under snow to 0.9 for pavement
+}
+
+// A FrostModel estimates how deep the ground has frozen from a series of
+// observed air temperatures, using the Stefan equation:
+//   depth = sqrt(2 * k * n * FI / L)
+// where k is the thermal conductivity of the frozen soil, n is the surface
+// factor, FI is the freezing index (degree-seconds below 0 C), and L is the
+// volumetric latent heat of the soil's water.
+//
+// Time above freezing reduces the freezing index, so the estimated depth
+// recedes during thaws. This is a simplification; real thawing proceeds from
+// the surface down while the ground below stays frozen. The model is meant as
+// a practical guide (e.g. for frozen pipe risk), not a substitute for
+// measurement.
+type FrostModel struct {
+	Soil SoilParameters
+
+	freezingIndex    float64 // net degree-hours below 0 C
+	subFreezingHours float64
+	lastTime         time.Time
+	lastTemperature  float64
+}
+
+// NewFrostModel returns a FrostModel for a soil with nothing frozen.
+func NewFrostModel(soil SoilParameters) *FrostModel {
+	return &FrostModel{Soil: soil}
+}
+
+// AddObservation adds an observed temperature to the model. Observations must
+// be added in chronological order; older observations and observations without
+// a temperature are ignored. Temperatures between observations are linearly
+// interpolated unless the observations are more than six hours apart.
+func (m *FrostModel) AddObservation(o Observation) {
+	t, err := convertTemperature(o.Temperature, "C")
+	if err != nil {
+		return
+	}
+	m.AddTemperature(o.TimeObserved, t.Value)
+}
+
+// AddTemperature adds an air temperature in degrees C at a time to the model.
+// See AddObservation.
+func (m *FrostModel) AddTemperature(at time.Time, celsius float64) {
+	if !m.lastTime.IsZero() && !at.After(m.lastTime) {
+		return
+	}
+	defer func() {
+		m.lastTime = at
+		m.lastTemperature = celsius
+	}()
+	if m.lastTime.IsZero() {
+		return
+	}
+	gap := at.Sub(m.lastTime)
+	if gap > maxFrostObservationGap {
+		return
+	}
+
+	// integrate the linear segment between the two temperatures, splitting it
+	// where it crosses freezing so that thawing and freezing are separated
+	hours := gap.Hours()
+	t0, t1 := m.lastTemperature, celsius
+	below, above := 0.0, 0.0 // degree-hours below and above 0 C
+	switch {
+	case t0 <= 0 && t1 <= 0:
+		below = -(t0 + t1) / 2 * hours
+		m.subFreezingHours += hours
+	case t0 >= 0 && t1 >= 0:
+		above = (t0 + t1) / 2 * hours
+	default:
+		f := t0 / (t0 - t1) // fraction of the segment before the crossing
+		if t0 < 0 {
+			below = -t0 / 2 * f * hours
+			above = t1 / 2 * (1 - f) * hours
+			m.subFreezingHours += f * hours
+		} else {
+			above = t0 / 2 * f * hours
+			below = -t1 / 2 * (1 - f) * hours
+			m.subFreezingHours += (1 - f) * hours
+		}
+	}
+
+	m.freezingIndex = math.Max(0, m.freezingIndex+below-above)
+}
+
+// FreezingIndex returns the net accumulated freezing index in degree-days C.
+func (m *FrostModel) FreezingIndex() float64 {
+	return m.freezingIndex / 24
+}
+
+// SubFreezingHours returns the total number of hours the air temperature has
+// been below freezing.
+func (m *FrostModel) SubFreezingHours() float64 {
+	return m.subFreezingHours
+}
+
+// FrostDepth returns the estimated depth of frozen ground in meters.
+func (m *FrostModel) FrostDepth() ValueUnit {
+	l := m.Soil.DryDensity * m.Soil.MoistureContent * latentHeatOfFusion
+	if l <= 0 {
+		return ValueUnit{}
+	}
+	fi := m.freezingIndex * 3600 // degree-seconds
+	return ValueUnit{Value: math.Sqrt(2 * m.Soil.ThermalConductivity * m.Soil.SurfaceFactor * fi / l), Unit: "m"}
+}
+
+// Reaches reports whether the estimated frost depth has reached a depth in
+// meters, such as the depth of a buried water line.
+func (m *FrostModel) Reaches(meters float64) bool {
+	d := m.FrostDepth()
+	return d.Unit != "" && d.Value >= meters
+}
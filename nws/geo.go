@@ -0,0 +1,183 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusMeters is the mean radius of Earth.
+const earthRadiusMeters = 6371008.8
+
+// maxGeometryVertices limits the total number of vertices accepted in a single
+// geometry. Alert polygons rarely have more than a few dozen.
+const maxGeometryVertices = 10000
+
+// A geoJSONGeometry is a GeoJSON geometry, with its coordinates unparsed.
+// Geometries is set only for a GeometryCollection.
+type geoJSONGeometry struct {
+	Type        string
+	Coordinates json.RawMessage
+	Geometries  []geoJSONGeometry
+}
+
+// newPolygonsFromGeoJSONGeometry returns the outer ring of each polygon in a
+// GeoJSON Polygon or MultiPolygon geometry. Holes are ignored.
+func newPolygonsFromGeoJSONGeometry(geometryType string, coordinates json.RawMessage) ([][]Point, error) {
+	return newPolygonsFromGeoJSONGeometryLimited(geometryType, coordinates, maxGeometryVertices)
+}
+
+// newPolygonsFromGeoJSONGeometryLimited is the same as
+// newPolygonsFromGeoJSONGeometry, but with a limit on the number of vertices.
+func newPolygonsFromGeoJSONGeometryLimited(geometryType string, coordinates json.RawMessage, maxVertices int) ([][]Point, error) {
+	// GeoJSON coordinates are lon, lat (annoying)
+	var rings [][][]float64
+
+	switch geometryType {
+	case "Polygon":
+		var poly [][][]float64
+		if err := json.Unmarshal(coordinates, &poly); err != nil {
+			return nil, err
+		}
+		if len(poly) > 0 {
+			rings = append(rings, poly[0])
+		}
+	case "MultiPolygon":
+		var multi [][][][]float64
+		if err := json.Unmarshal(coordinates, &multi); err != nil {
+			return nil, err
+		}
+		for _, poly := range multi {
+			if len(poly) > 0 {
+				rings = append(rings, poly[0])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("geometry type must be Polygon or MultiPolygon: \"%s\"", geometryType)
+	}
+
+	var n int
+	for _, ring := range rings {
+		n += len(ring)
+	}
+	if n > maxVertices {
+		return nil, fmt.Errorf("geometry has %d vertices, maximum is %d", n, maxVertices)
+	}
+
+	var polys [][]Point
+	for _, ring := range rings {
+		var poly []Point
+		for _, c := range ring {
+			if len(c) < 2 {
+				continue // skip malformed positions
+			}
+			poly = append(poly, Point{Lat: c[1], Lon: c[0]})
+		}
+		if len(poly) >= 3 {
+			polys = append(polys, poly)
+		}
+	}
+
+	return polys, nil
+}
+
+// polygonContainsPoint reports whether a point lies inside a polygon using the
+// even-odd (ray casting) rule. The polygon may or may not be closed.
+//
+// Latitude and longitude are treated as planar coordinates, which is accurate
+// enough for the small areas covered by alerts.
+func polygonContainsPoint(poly []Point, p Point) bool {
+	in := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			in = !in
+		}
+	}
+	return in
+}
+
+// A Circle is a circular area, as in the CAP circle element.
+type Circle struct {
+	Center       Point
+	RadiusMeters float64
+}
+
+// ContainsPoint reports whether a point lies within the circle, measured
+// along the surface of Earth.
+func (c Circle) ContainsPoint(p Point) bool {
+	return haversineMeters(c.Center, p) <= c.RadiusMeters
+}
+
+// parseCAPCircle parses a CAP circle: a WGS 84 latitude and longitude and a
+// radius in kilometers (e.g. "45.46,-122.66 10.5").
+func parseCAPCircle(s string) (Circle, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Circle{}, fmt.Errorf("circle must be a point and a radius: \"%s\"", s)
+	}
+	latLon := strings.Split(fields[0], ",")
+	if len(latLon) != 2 {
+		return Circle{}, fmt.Errorf("circle center must be a latitude and longitude: \"%s\"", s)
+	}
+	lat, err := strconv.ParseFloat(latLon[0], 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return Circle{}, fmt.Errorf("circle latitude is invalid: \"%s\"", s)
+	}
+	lon, err := strconv.ParseFloat(latLon[1], 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return Circle{}, fmt.Errorf("circle longitude is invalid: \"%s\"", s)
+	}
+	km, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || km < 0 {
+		return Circle{}, fmt.Errorf("circle radius is invalid: \"%s\"", s)
+	}
+	return Circle{Center: Point{Lat: lat, Lon: lon}, RadiusMeters: km * 1000}, nil
+}
+
+// haversineMeters returns the great-circle distance in meters between two
+// points.
+func haversineMeters(a Point, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// offsetPoint returns the point a distance in meters from p along a bearing in
+// degrees true. The result is rounded to four decimal places, the maximum
+// precision accepted by the API.
+func offsetPoint(p Point, bearing float64, meters float64) Point {
+	lat1 := p.Lat * math.Pi / 180
+	lon1 := p.Lon * math.Pi / 180
+	brng := bearing * math.Pi / 180
+	d := meters / earthRadiusMeters
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brng))
+	lon2 := lon1 + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	return Point{
+		Lat: math.Round(lat2*180/math.Pi*10000) / 10000,
+		Lon: math.Round(lon2*180/math.Pi*10000) / 10000,
+	}
+}
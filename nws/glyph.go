@@ -0,0 +1,148 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+// Icon sets that icon conditions may be mapped to
+const (
+	IconSetWeatherIcons = "weather-icons" // Weather Icons CSS classes (https://erikflowers.github.io/weather-icons/)
+	IconSetMaterial     = "material"      // Material Symbols names (https://fonts.google.com/icons)
+	IconSetEmoji        = "emoji"
+)
+
+// iconGlyphs holds the day and night glyphs for an icon condition in each
+// icon set.
+type iconGlyphs struct {
+	weatherIcons [2]string // day, night
+	material     [2]string
+	emoji        [2]string
+}
+
+// iconConditionGlyphs maps the icon condition codes of the NWS API to glyphs.
+// Legacy codes are mapped to these by legacyIconConditionCodes.
+var iconConditionGlyphs = map[string]iconGlyphs{
+	"skc":             {[2]string{"wi-day-sunny", "wi-night-clear"}, [2]string{"sunny", "clear_night"}, [2]string{"☀️", "🌙"}},
+	"few":             {[2]string{"wi-day-sunny-overcast", "wi-night-alt-partly-cloudy"}, [2]string{"partly_cloudy_day", "partly_cloudy_night"}, [2]string{"🌤️", "🌙"}},
+	"sct":             {[2]string{"wi-day-cloudy", "wi-night-alt-partly-cloudy"}, [2]string{"partly_cloudy_day", "partly_cloudy_night"}, [2]string{"⛅", "☁️"}},
+	"bkn":             {[2]string{"wi-day-cloudy", "wi-night-alt-cloudy"}, [2]string{"cloud", "cloud"}, [2]string{"🌥️", "☁️"}},
+	"ovc":             {[2]string{"wi-cloudy", "wi-cloudy"}, [2]string{"cloud", "cloud"}, [2]string{"☁️", "☁️"}},
+	"wind_skc":        {[2]string{"wi-day-windy", "wi-windy"}, [2]string{"air", "air"}, [2]string{"🌬️", "🌬️"}},
+	"wind_few":        {[2]string{"wi-day-windy", "wi-windy"}, [2]string{"air", "air"}, [2]string{"🌬️", "🌬️"}},
+	"wind_sct":        {[2]string{"wi-cloudy-windy", "wi-cloudy-windy"}, [2]string{"air", "air"}, [2]string{"🌬️", "🌬️"}},
+	"wind_bkn":        {[2]string{"wi-cloudy-windy", "wi-cloudy-windy"}, [2]string{"air", "air"}, [2]string{"🌬️", "🌬️"}},
+	"wind_ovc":        {[2]string{"wi-cloudy-windy", "wi-cloudy-windy"}, [2]string{"air", "air"}, [2]string{"🌬️", "🌬️"}},
+	"snow":            {[2]string{"wi-day-snow", "wi-night-alt-snow"}, [2]string{"weather_snowy", "weather_snowy"}, [2]string{"🌨️", "🌨️"}},
+	"rain_snow":       {[2]string{"wi-day-rain-mix", "wi-night-alt-rain-mix"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌨️", "🌨️"}},
+	"rain_sleet":      {[2]string{"wi-day-sleet", "wi-night-alt-sleet"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌨️", "🌨️"}},
+	"snow_sleet":      {[2]string{"wi-day-sleet", "wi-night-alt-sleet"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌨️", "🌨️"}},
+	"fzra":            {[2]string{"wi-day-rain-mix", "wi-night-alt-rain-mix"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌧️", "🌧️"}},
+	"rain_fzra":       {[2]string{"wi-day-rain-mix", "wi-night-alt-rain-mix"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌧️", "🌧️"}},
+	"snow_fzra":       {[2]string{"wi-day-rain-mix", "wi-night-alt-rain-mix"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌨️", "🌨️"}},
+	"sleet":           {[2]string{"wi-day-sleet", "wi-night-alt-sleet"}, [2]string{"weather_mix", "weather_mix"}, [2]string{"🌨️", "🌨️"}},
+	"rain":            {[2]string{"wi-day-rain", "wi-night-alt-rain"}, [2]string{"rainy", "rainy"}, [2]string{"🌧️", "🌧️"}},
+	"rain_showers":    {[2]string{"wi-day-showers", "wi-night-alt-showers"}, [2]string{"rainy", "rainy"}, [2]string{"🌦️", "🌧️"}},
+	"rain_showers_hi": {[2]string{"wi-day-showers", "wi-night-alt-showers"}, [2]string{"rainy", "rainy"}, [2]string{"🌦️", "🌧️"}},
+	"tsra":            {[2]string{"wi-day-thunderstorm", "wi-night-alt-thunderstorm"}, [2]string{"thunderstorm", "thunderstorm"}, [2]string{"⛈️", "⛈️"}},
+	"tsra_sct":        {[2]string{"wi-day-thunderstorm", "wi-night-alt-thunderstorm"}, [2]string{"thunderstorm", "thunderstorm"}, [2]string{"⛈️", "⛈️"}},
+	"tsra_hi":         {[2]string{"wi-day-thunderstorm", "wi-night-alt-thunderstorm"}, [2]string{"thunderstorm", "thunderstorm"}, [2]string{"⛈️", "⛈️"}},
+	"tornado":         {[2]string{"wi-tornado", "wi-tornado"}, [2]string{"tornado", "tornado"}, [2]string{"🌪️", "🌪️"}},
+	"hurricane":       {[2]string{"wi-hurricane", "wi-hurricane"}, [2]string{"cyclone", "cyclone"}, [2]string{"🌀", "🌀"}},
+	"tropical_storm":  {[2]string{"wi-hurricane", "wi-hurricane"}, [2]string{"cyclone", "cyclone"}, [2]string{"🌀", "🌀"}},
+	"dust":            {[2]string{"wi-dust", "wi-dust"}, [2]string{"mist", "mist"}, [2]string{"🌫️", "🌫️"}},
+	"smoke":           {[2]string{"wi-smoke", "wi-smoke"}, [2]string{"mist", "mist"}, [2]string{"🌫️", "🌫️"}},
+	"haze":            {[2]string{"wi-day-haze", "wi-fog"}, [2]string{"mist", "mist"}, [2]string{"🌫️", "🌫️"}},
+	"hot":             {[2]string{"wi-hot", "wi-hot"}, [2]string{"thermostat", "thermostat"}, [2]string{"🌡️", "🌡️"}},
+	"cold":            {[2]string{"wi-snowflake-cold", "wi-snowflake-cold"}, [2]string{"ac_unit", "ac_unit"}, [2]string{"❄️", "❄️"}},
+	"blizzard":        {[2]string{"wi-snow-wind", "wi-snow-wind"}, [2]string{"weather_snowy", "weather_snowy"}, [2]string{"🌨️", "🌨️"}},
+	"fog":             {[2]string{"wi-day-fog", "wi-night-fog"}, [2]string{"foggy", "foggy"}, [2]string{"🌫️", "🌫️"}},
+}
+
+// legacyIconConditionCodes maps the condition codes of legacy weather.gov
+// icons, without their night prefix, to those of the NWS API.
+var legacyIconConditionCodes = map[string]string{
+	"ra":        "rain",
+	"minus_ra":  "rain",
+	"shra":      "rain_showers",
+	"hi_shwrs":  "rain_showers_hi",
+	"scttsra":   "tsra_sct",
+	"hi_tsra":   "tsra_hi",
+	"sn":        "snow",
+	"ra_sn":     "rain_snow",
+	"rasn":      "rain_snow",
+	"mix":       "rain_snow",
+	"ip":        "sleet",
+	"raip":      "rain_sleet",
+	"snip":      "snow_sleet",
+	"ra_fzra":   "rain_fzra",
+	"fzra_sn":   "snow_fzra",
+	"fg":        "fog",
+	"sctfg":     "fog",
+	"fu":        "smoke",
+	"du":        "dust",
+	"hz":        "haze",
+	"tor":       "tornado",
+	"svrtsra":   "tornado", // "nsvrtsra", once the night prefix is removed
+	"hur_warn":  "hurricane",
+	"hur_watch": "hurricane",
+	"ts_warn":   "tropical_storm",
+	"ts_watch":  "tropical_storm",
+	"ts_nowarn": "tropical_storm",
+}
+
+// Glyph returns the glyph for the condition in an icon set (IconSetWeatherIcons,
+// IconSetMaterial, or IconSetEmoji), for day or night. An empty string is
+// returned if the set or the condition is unknown.
+func (c IconCondition) Glyph(set string, isDaytime bool) string {
+	code := c.Code
+	if apiCode, ok := legacyIconConditionCodes[code]; ok {
+		code = apiCode
+	}
+	g, ok := iconConditionGlyphs[code]
+	if !ok {
+		return ""
+	}
+	i := 0
+	if !isDaytime {
+		i = 1
+	}
+	switch set {
+	case IconSetWeatherIcons:
+		return g.weatherIcons[i]
+	case IconSetMaterial:
+		return g.material[i]
+	case IconSetEmoji:
+		return g.emoji[i]
+	}
+	return ""
+}
+
+// Glyph returns the glyph for the icon in an icon set. If the icon has two
+// conditions, the glyph of the first is returned; see Period.FirstHalf and
+// Period.SecondHalf for both.
+func (i Icon) Glyph(set string) string {
+	if len(i.Conditions) < 1 {
+		return ""
+	}
+	return i.Conditions[0].Glyph(set, i.IsDaytime)
+}
+
+// Glyph returns the glyph for the period's icon in an icon set.
+func (p Period) Glyph(set string) string {
+	return p.Icon.Glyph(set)
+}
+
+// Glyph returns the glyph for the observation's icon in an icon set.
+func (o Observation) Glyph(set string) string {
+	return o.Icon.Glyph(set)
+}
@@ -16,7 +16,9 @@ package nws
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -31,6 +33,87 @@ type Gridpoint struct {
 	GridY int
 	City  string
 	State string
+
+	ForecastZone    string // UGC zone ID (e.g. "ORZ006")
+	County          string // UGC county ID (e.g. "ORC051")
+	FireWeatherZone string // UGC zone ID (e.g. "ORZ604")
+	TimeZone        string // IANA time zone name (e.g. "America/Los_Angeles")
+}
+
+// NewGridpoint returns a Gridpoint given a weather forecast office (e.g.
+// "PQR") and grid coordinates. The office is validated with ValidateWFO and
+// uppercased; the coordinates must not be negative.
+func NewGridpoint(wfo string, gridX int, gridY int) (Gridpoint, error) {
+	if err := ValidateWFO(wfo); err != nil {
+		return Gridpoint{}, err
+	}
+	if gridX < 0 || gridY < 0 {
+		return Gridpoint{}, fmt.Errorf("grid coordinates must not be negative: %d,%d", gridX, gridY)
+	}
+	return Gridpoint{WFO: strings.ToUpper(wfo), GridX: gridX, GridY: gridY}, nil
+}
+
+// ParseGridpoint returns a Gridpoint given a string in the form returned by
+// Gridpoint.String (e.g. "PQR/112,100"), which is also the form used in API
+// URLs. Only the office and coordinates are set.
+func ParseGridpoint(s string) (Gridpoint, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Gridpoint{}, fmt.Errorf("gridpoint must be a WFO and coordinates separated by a slash: \"%s\"", s)
+	}
+	xy := strings.SplitN(parts[1], ",", 2)
+	if len(xy) != 2 {
+		return Gridpoint{}, fmt.Errorf("gridpoint coordinates must be separated by a comma: \"%s\"", s)
+	}
+	x, err := strconv.Atoi(strings.TrimSpace(xy[0]))
+	if err != nil {
+		return Gridpoint{}, fmt.Errorf("GridX must be an integer: \"%s\"", xy[0])
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(xy[1]))
+	if err != nil {
+		return Gridpoint{}, fmt.Errorf("GridY must be an integer: \"%s\"", xy[1])
+	}
+	return NewGridpoint(parts[0], x, y)
+}
+
+// String returns the gridpoint's office and coordinates (e.g. "PQR/112,100").
+func (g Gridpoint) String() string {
+	return fmt.Sprintf("%s/%d,%d", g.WFO, g.GridX, g.GridY)
+}
+
+// CountyFIPS returns the five digit FIPS code of the gridpoint's county (e.g.
+// "41051"), or an empty string if the county is unknown.
+func (g Gridpoint) CountyFIPS() string {
+	fips, _ := CountyFIPSFromUGC(g.County)
+	return fips
+}
+
+// GridpointForCoordinates retrieves from the NWS API the gridpoint containing
+// a WGS 84 (EPSG:4326) latitude and longitude, including its forecast zone and
+// county. It makes a single request, so it is cheaper than creating a Client
+// when only the zones are needed, such as when configuring alerts.
+//
+// apiURLString is the URL of the NWS API Web Service, as for
+// Client.SetAPIURLString, or empty for the default.
+func GridpointForCoordinates(httpClient *http.Client, httpUserAgentString string, apiURLString string, lat float64, lon float64) (Gridpoint, error) {
+	if strings.TrimSpace(httpUserAgentString) == "" {
+		return Gridpoint{}, errors.New("httpUserAgentString must not be empty")
+	}
+	if apiURLString == "" {
+		apiURLString = defaultAPIURLString
+	}
+	if err := validateAPIURLString(apiURLString); err != nil {
+		return Gridpoint{}, err
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	point := Point{Lat: math.Round(lat*10000) / 10000, Lon: math.Round(lon*10000) / 10000}
+	gp, err := getGridpointForPoint(httpClient, httpUserAgentString, apiURLString, point)
+	if err != nil {
+		return Gridpoint{}, err
+	}
+	return *gp, nil
 }
 
 // getGridpointForPoint retrieves from the NWS API the gridpoint that contains a
@@ -56,38 +139,53 @@ func newGridpointFromPointRespBody(respBody []byte) (*Gridpoint, error) {
 	gpRaw := struct {
 		Properties struct {
 			CWA              string
-			GridX            string
-			GridY            string
+			GridX            json.Number // a number, but accept strings too
+			GridY            json.Number
 			RelativeLocation struct {
 				Properties struct {
 					City  string
 					State string
 				}
 			}
+			ForecastZone    string // URL
+			County          string // URL
+			FireWeatherZone string // URL
+			TimeZone        string
 		}
 	}{}
 	if err := json.Unmarshal(respBody, &gpRaw); err != nil {
 		return nil, err
 	}
 
-	// validate and build returned value
-	var err error
-	var gp Gridpoint
-
-	// must have WFO, gridX, and gridY
-	if len(gpRaw.Properties.CWA) != 3 {
-		return nil, fmt.Errorf("WFO/CWA must be three characters: \"%s\" is %d characters", gpRaw.Properties.CWA, len(gpRaw.Properties.CWA))
-	}
-	gp.WFO = strings.ToUpper(gpRaw.Properties.CWA)
-	if gp.GridX, err = strconv.Atoi(gpRaw.Properties.GridX); err != nil {
+	// validate and build returned value, which must have WFO, gridX, and gridY
+	x, err := strconv.Atoi(string(gpRaw.Properties.GridX))
+	if err != nil {
 		return nil, fmt.Errorf("GridX must be an integer: \"%s\"", gpRaw.Properties.GridX)
 	}
-	if gp.GridY, err = strconv.Atoi(gpRaw.Properties.GridY); err != nil {
+	y, err := strconv.Atoi(string(gpRaw.Properties.GridY))
+	if err != nil {
 		return nil, fmt.Errorf("GridY must be an integer: \"%s\"", gpRaw.Properties.GridY)
 	}
+	gp, err := NewGridpoint(gpRaw.Properties.CWA, x, y)
+	if err != nil {
+		return nil, err
+	}
 
 	gp.City = gpRaw.Properties.RelativeLocation.Properties.City
 	gp.State = gpRaw.Properties.RelativeLocation.Properties.State
 
+	gp.ForecastZone = zoneIDFromZoneURLString(gpRaw.Properties.ForecastZone)
+	gp.County = zoneIDFromZoneURLString(gpRaw.Properties.County)
+	gp.FireWeatherZone = zoneIDFromZoneURLString(gpRaw.Properties.FireWeatherZone)
+	gp.TimeZone = gpRaw.Properties.TimeZone
+
 	return &gp, nil
 }
+
+// zoneIDFromZoneURLString returns the zone ID at the end of a zone URL (e.g.
+// "https://api.weather.gov/zones/forecast/ORZ006" returns "ORZ006"). An empty
+// string is returned if there is no ID.
+func zoneIDFromZoneURLString(urlString string) string {
+	i := strings.LastIndex(urlString, "/")
+	return strings.ToUpper(strings.TrimSpace(urlString[i+1:]))
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const getGridpointDataForGridpointEndpointURLStringFmt = "gridpoints/%s/%d,%d" // wfo, x, y
+
+// gridpointUnitCodes maps the unit codes used in raw gridpoint data to unit
+// names. Unknown codes are used with their prefix removed.
+var gridpointUnitCodes = map[string]string{
+	"degC":    "C",
+	"degF":    "F",
+	"percent": "percent",
+	"km_h-1":  "km/h",
+	"m_s-1":   "m/s",
+	"kt":      "kt",
+	"mm":      "mm",
+	"m":       "m",
+}
+
+// A GridpointLayer represents one layer of raw gridpoint forecast data (e.g.
+// "relativeHumidity" or "quantitativePrecipitation") as a series of values,
+// each valid for a range of time.
+type GridpointLayer struct {
+	Unit   string // empty for unitless values like the Haines index
+	Values []GridpointValue
+}
+
+// A GridpointValue is a single value in a GridpointLayer.
+type GridpointValue struct {
+	TimeStart time.Time
+	Duration  time.Duration
+	Value     float64
+}
+
+// TimeEnd returns the end of the time range for which the value is valid.
+func (v GridpointValue) TimeEnd() time.Time {
+	return v.TimeStart.Add(v.Duration)
+}
+
+// getGridpointLayersForGridpoint retrieves from the NWS API the raw forecast
+// data for a gridpoint and returns the named layers. Layers that are not
+// present are omitted from the returned map.
+func getGridpointLayersForGridpoint(httpClient *http.Client, httpUserAgentString string, apiURLString string, gridpoint Gridpoint, names []string) (map[string]GridpointLayer, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getGridpointDataForGridpointEndpointURLStringFmt, gridpoint.WFO, gridpoint.GridX, gridpoint.GridY),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newGridpointLayersFromGridpointDataRespBody(respBody, names)
+}
+
+// newGridpointLayersFromGridpointDataRespBody returns the named layers, given
+// a response body from the NWS API.
+func newGridpointLayersFromGridpointDataRespBody(respBody []byte, names []string) (map[string]GridpointLayer, error) {
+	// unmarshal the body into a temporary struct, leaving layers raw since the
+	// response contains dozens of them
+	gdRaw := struct {
+		Properties map[string]json.RawMessage
+	}{}
+	if err := json.Unmarshal(respBody, &gdRaw); err != nil {
+		return nil, err
+	}
+
+	layers := make(map[string]GridpointLayer)
+	for _, name := range names {
+		raw, ok := gdRaw.Properties[name]
+		if !ok {
+			continue
+		}
+		lRaw := struct {
+			UOM    string
+			Values []struct {
+				ValidTime string
+				Value     *float64
+			}
+		}{}
+		if err := json.Unmarshal(raw, &lRaw); err != nil {
+			continue // skip if not a layer
+		}
+
+		var l GridpointLayer
+		l.Unit = gridpointUnitFromUnitCode(lRaw.UOM)
+		for _, vRaw := range lRaw.Values {
+			if vRaw.Value == nil {
+				continue // skip if null
+			}
+			start, d, err := parseValidTime(vRaw.ValidTime)
+			if err != nil {
+				continue // skip if bad time
+			}
+			l.Values = append(l.Values, GridpointValue{TimeStart: start, Duration: d, Value: *vRaw.Value})
+		}
+		layers[name] = l
+	}
+
+	return layers, nil
+}
+
+// gridpointUnitFromUnitCode returns a unit name given a unit code like
+// "wmoUnit:degC" or "unit:percent".
+func gridpointUnitFromUnitCode(code string) string {
+	if i := strings.Index(code, ":"); i >= 0 {
+		code = code[i+1:]
+	}
+	if u, ok := gridpointUnitCodes[code]; ok {
+		return u
+	}
+	return code
+}
+
+// parseValidTime parses an ISO 8601 time interval in the start/duration form
+// used by the API (e.g. "2019-08-14T11:00:00+00:00/P8DT1H").
+func parseValidTime(s string) (time.Time, time.Duration, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, fmt.Errorf("valid time must be a start and duration separated by a slash: \"%s\"", s)
+	}
+	start, err := time.Parse(time.RFC3339, parts[0])
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	d, err := parseISO8601Duration(parts[1])
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return start, d, nil
+}
+
+// iso8601DurationRegexp matches the subset of ISO 8601 durations used by the
+// API: days, hours, minutes, and seconds.
+var iso8601DurationRegexp = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseISO8601Duration parses an ISO 8601 duration such as "P8DT1H" or "PT6H".
+// Years, months, and weeks are not supported since they are not used by the
+// API and don't have a fixed length.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	m := iso8601DurationRegexp.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("unsupported ISO 8601 duration: \"%s\"", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"strings"
+)
+
+// htmlScriptRegexp matches script and style elements, which encoding/xml can't
+// parse as HTML since their contents aren't escaped.
+var htmlScriptRegexp = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)\s*>`)
+
+// An htmlElement is an element of a loosely parsed HTML document.
+type htmlElement struct {
+	tag      string // lower case
+	attrs    map[string]string
+	children []htmlNode
+}
+
+// An htmlNode is a child of an htmlElement: an element or text.
+type htmlNode struct {
+	elem *htmlElement // nil for text
+	text string
+}
+
+// parseHTML loosely parses an HTML document into a tree rooted at an element
+// with an empty tag. Parsing is best effort: unclosed and mismatched tags are
+// closed, unknown entities are kept as text, and whatever was parsed before an
+// error is returned.
+func parseHTML(page []byte) *htmlElement {
+	page = htmlScriptRegexp.ReplaceAll(page, nil)
+	d := xml.NewDecoder(bytes.NewReader(page))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	root := &htmlElement{}
+	stack := []*htmlElement{root}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break // io.EOF, or a layout encoding/xml can't handle
+		}
+		cur := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &htmlElement{tag: strings.ToLower(t.Name.Local), attrs: make(map[string]string)}
+			for _, a := range t.Attr {
+				e.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			cur.children = append(cur.children, htmlNode{elem: e})
+			stack = append(stack, e)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			cur.children = append(cur.children, htmlNode{text: string(t)})
+		}
+	}
+	return root
+}
+
+// hasClass reports whether the element has a class.
+func (e *htmlElement) hasClass(class string) bool {
+	for _, c := range strings.Fields(e.attrs["class"]) {
+		if strings.EqualFold(c, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// findAll returns the descendants of the element for which match returns
+// true, in document order. Matching elements may be nested, since unclosed
+// tags leave siblings nested within each other.
+func (e *htmlElement) findAll(match func(*htmlElement) bool) []*htmlElement {
+	var found []*htmlElement
+	for _, n := range e.children {
+		if n.elem == nil {
+			continue
+		}
+		if match(n.elem) {
+			found = append(found, n.elem)
+		}
+		found = append(found, n.elem.findAll(match)...)
+	}
+	return found
+}
+
+// find returns the first descendant of the element for which match returns
+// true, or nil.
+func (e *htmlElement) find(match func(*htmlElement) bool) *htmlElement {
+	for _, n := range e.children {
+		if n.elem == nil {
+			continue
+		}
+		if match(n.elem) {
+			return n.elem
+		}
+		if found := n.elem.find(match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// text returns the text of the element and its descendants with whitespace
+// collapsed. Line breaks are treated as spaces.
+func (e *htmlElement) text() string {
+	var b strings.Builder
+	e.writeText(&b)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func (e *htmlElement) writeText(b *strings.Builder) {
+	for _, n := range e.children {
+		if n.elem == nil {
+			b.WriteString(n.text)
+			continue
+		}
+		b.WriteByte(' ')
+		n.elem.writeText(b)
+		b.WriteByte(' ')
+	}
+}
+
+// htmlClass returns a matcher for elements with any of the classes.
+func htmlClass(classes ...string) func(*htmlElement) bool {
+	return func(e *htmlElement) bool {
+		for _, c := range classes {
+			if e.hasClass(c) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// htmlTag returns a matcher for elements with a tag.
+func htmlTag(tag string) func(*htmlElement) bool {
+	return func(e *htmlElement) bool {
+		return e.tag == tag
+	}
+}
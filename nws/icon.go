@@ -0,0 +1,186 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// An Icon represents the conditions encoded in an NWS icon URL.
+//
+// Icons contain one condition, or two conditions when the conditions are
+// expected to change partway through the period. In that case the first
+// condition applies to the first half of the period and the second condition
+// applies to the second half.
+type Icon struct {
+	Set        string // "land" or "marine", empty for legacy icons
+	IsDaytime  bool
+	Conditions []IconCondition
+}
+
+// An IconCondition represents a single condition within an icon and the
+// probability of that condition as a percentage. Probability is zero when no
+// probability is given.
+type IconCondition struct {
+	Code        string // e.g. "sct", "rain", "tsra_hi"
+	Probability int
+}
+
+// ParseIconURL returns an Icon given an icon URL from the NWS API or from one
+// of the legacy weather.gov formats.
+//
+// The following formats are recognized:
+//   https://api.weather.gov/icons/land/day/sct/rain,40?size=medium
+//   https://forecast.weather.gov/newimages/medium/nra40.png
+//   https://forecast.weather.gov/DualImage.php?i=bkn&j=ra&ip=20&jp=30
+func ParseIconURL(urlString string) (Icon, error) {
+	u, err := url.Parse(strings.TrimSpace(urlString))
+	if err != nil {
+		return Icon{}, err
+	}
+
+	if strings.HasSuffix(u.Path, "DualImage.php") {
+		return parseDualImageIconURL(u)
+	}
+	if i := strings.Index(u.Path, "/icons/"); i >= 0 {
+		return parseAPIIconPath(u.Path[i+len("/icons/"):])
+	}
+	if ext := path.Ext(u.Path); ext == ".png" || ext == ".jpg" || ext == ".gif" {
+		return parseLegacyIconPath(u.Path)
+	}
+
+	return Icon{}, fmt.Errorf("unrecognized icon URL: %s", urlString)
+}
+
+// parseAPIIconPath parses the portion of a current API icon URL path
+// following "/icons/" (e.g. "land/day/sct/rain,40").
+func parseAPIIconPath(p string) (Icon, error) {
+	var icon Icon
+
+	tokens := strings.Split(strings.Trim(p, "/"), "/")
+	if len(tokens) < 3 || len(tokens) > 4 {
+		return Icon{}, fmt.Errorf("icon path must contain a set, time of day, and one or two conditions: %s", p)
+	}
+
+	icon.Set = tokens[0]
+	switch tokens[1] {
+	case "day":
+		icon.IsDaytime = true
+	case "night":
+		icon.IsDaytime = false
+	default:
+		return Icon{}, fmt.Errorf("icon time of day must be \"day\" or \"night\": \"%s\"", tokens[1])
+	}
+
+	for _, t := range tokens[2:] {
+		c, err := parseIconCondition(t, ",")
+		if err != nil {
+			return Icon{}, err
+		}
+		icon.Conditions = append(icon.Conditions, c)
+	}
+
+	return icon, nil
+}
+
+// parseLegacyIconPath parses a legacy icon image path (e.g.
+// "/newimages/medium/nra40.png"). Night icons are prefixed with an "n" and
+// probabilities are appended to the condition code.
+func parseLegacyIconPath(p string) (Icon, error) {
+	name := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	if name == "" {
+		return Icon{}, fmt.Errorf("icon file name is empty: %s", p)
+	}
+
+	icon := Icon{IsDaytime: true}
+	if strings.HasPrefix(name, "n") && len(name) > 1 {
+		icon.IsDaytime = false
+		name = name[1:]
+	}
+
+	c, err := parseIconCondition(name, "")
+	if err != nil {
+		return Icon{}, err
+	}
+	icon.Conditions = []IconCondition{c}
+
+	return icon, nil
+}
+
+// parseDualImageIconURL parses a legacy DualImage.php composite icon URL. The
+// query parameters `i` and `j` contain the first and second condition codes
+// and `ip` and `jp` contain their probabilities.
+func parseDualImageIconURL(u *url.URL) (Icon, error) {
+	var err error
+	q := u.Query()
+
+	if q.Get("i") == "" || q.Get("j") == "" {
+		return Icon{}, fmt.Errorf("DualImage icon must have both `i` and `j` parameters: %s", u.String())
+	}
+
+	icon := Icon{IsDaytime: true}
+	for _, k := range []string{"i", "j"} {
+		code := q.Get(k)
+		if strings.HasPrefix(code, "n") && len(code) > 1 {
+			icon.IsDaytime = false
+			code = code[1:]
+		}
+		c := IconCondition{Code: code}
+		if ps := q.Get(k + "p"); ps != "" {
+			if c.Probability, err = strconv.Atoi(ps); err != nil {
+				return Icon{}, fmt.Errorf("icon probability must be an integer: \"%s\"", ps)
+			}
+		}
+		icon.Conditions = append(icon.Conditions, c)
+	}
+
+	return icon, nil
+}
+
+// parseIconCondition parses a single condition token. If sep is empty, any
+// trailing digits are treated as the probability (legacy format); otherwise
+// the probability follows sep (e.g. "rain,40").
+func parseIconCondition(token string, sep string) (IconCondition, error) {
+	var c IconCondition
+	var ps string
+
+	if sep != "" {
+		parts := strings.SplitN(token, sep, 2)
+		c.Code = parts[0]
+		if len(parts) == 2 {
+			ps = parts[1]
+		}
+	} else {
+		c.Code = strings.TrimRight(token, "0123456789")
+		ps = token[len(c.Code):]
+	}
+
+	if c.Code == "" {
+		return IconCondition{}, fmt.Errorf("icon condition code is empty: \"%s\"", token)
+	}
+	if ps != "" {
+		p, err := strconv.Atoi(ps)
+		if err != nil || p < 0 || p > 100 {
+			return IconCondition{}, fmt.Errorf("icon probability must be an integer from 0 to 100: \"%s\"", ps)
+		}
+		c.Probability = p
+	}
+
+	return c, nil
+}
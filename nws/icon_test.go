@@ -0,0 +1,27 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+////////////////////////////////////////////////////////////////////////////////
+// EXAMPLE icon URLs below.
+// - the API has used several formats over the years
+
+// https://api.weather.gov/icons/land/day/few?size=medium
+// https://api.weather.gov/icons/land/night/sct/rain?size=medium
+// https://api.weather.gov/icons/land/day/rain,40/bkn?size=medium
+// https://forecast.weather.gov/newimages/medium/sct.png
+// https://forecast.weather.gov/newimages/medium/nra40.png
+// https://forecast.weather.gov/DualImage.php?i=bkn&j=ra&ip=20&jp=30
+// https://forecast.weather.gov/DualImage.php?i=nbkn&j=nra&jp=40
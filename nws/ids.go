@@ -0,0 +1,83 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Kinds of identifiers validated by this package
+const (
+	IDKindStation = "station"
+	IDKindZone    = "zone"
+	IDKindWFO     = "WFO"
+)
+
+var (
+	// stationIDRegexp matches ICAO identifiers (e.g. "KPDX") and the other
+	// identifiers used by the API for stations that aren't airports, such as
+	// mesonet and CWOP stations, which may be longer. Only the characters are
+	// checked, since the IDs are used in request paths.
+	stationIDRegexp = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+	// zoneIDRegexp matches UGC forecast and county zone codes: a state or
+	// marine area, "Z" or "C", and three digits (e.g. "ORZ006", "ORC051").
+	zoneIDRegexp = regexp.MustCompile(`^[A-Za-z]{2}[ZzCc]\d{3}$`)
+
+	// wfoRegexp matches weather forecast office identifiers (e.g. "PQR"), and
+	// the marine offices whose identifiers contain digits (e.g. "NH1").
+	wfoRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{2}$`)
+)
+
+// An InvalidIDError is returned when an identifier is malformed.
+type InvalidIDError struct {
+	Kind string // IDKindStation, IDKindZone, or IDKindWFO
+	ID   string
+}
+
+// Error implements the error interface.
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("invalid %s ID: \"%s\"", e.Kind, e.ID)
+}
+
+// ValidateStationID returns an *InvalidIDError if id is not a well formed
+// station identifier, such as an ICAO identifier (e.g. "KPDX"). It doesn't
+// check that the station exists.
+func ValidateStationID(id string) error {
+	return validateID(IDKindStation, stationIDRegexp, id)
+}
+
+// ValidateZoneID returns an *InvalidIDError if id is not a well formed UGC
+// forecast or county zone code (e.g. "ORZ006" or "ORC051"). It doesn't check
+// that the zone exists.
+func ValidateZoneID(id string) error {
+	return validateID(IDKindZone, zoneIDRegexp, id)
+}
+
+// ValidateWFO returns an *InvalidIDError if id is not a well formed weather
+// forecast office identifier (e.g. "PQR"). It doesn't check that the office
+// exists; see IsKnownWFO.
+func ValidateWFO(id string) error {
+	return validateID(IDKindWFO, wfoRegexp, id)
+}
+
+// validateID returns an *InvalidIDError if id doesn't match re.
+func validateID(kind string, re *regexp.Regexp, id string) error {
+	if !re.MatchString(id) {
+		return &InvalidIDError{Kind: kind, ID: id}
+	}
+	return nil
+}
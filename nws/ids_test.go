@@ -0,0 +1,43 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import "testing"
+
+func TestValidateStationID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"KPDX", false},
+		{"kpdx", false},
+		{"PDX", false},
+		{"PORO3", false},
+		{"AU125", false},
+		{"E4229", false},
+		{"KPDXTEST12", false},
+		{"", true},
+		{"K PDX", true},
+		{"../KPDX", true},
+		{"KPDX/observations", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if err := ValidateStationID(tt.id); (err != nil) != tt.wantErr {
+				t.Errorf("got %v; want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sources of forecasts and observations
+const (
+	SourceAPI    = "api"    // the NWS API Web Service (api.weather.gov)
+	SourceLegacy = "legacy" // the legacy forecast.weather.gov and w1.weather.gov services
+)
+
+const (
+	defaultLegacyForecastURLString    = "https://forecast.weather.gov/"
+	defaultLegacyObservationURLString = "https://w1.weather.gov/"
+
+	getLegacyForecastEndpointURLString                 = "MapClick.php"
+	getLegacyObservationForStationEndpointURLStringFmt = "xml/current_obs/%s.xml" // station ID
+
+	legacyXMLMIMEType = "application/xml"
+)
+
+// SetLegacyURLStrings sets the URLs of the legacy services used when
+// LegacyFallback is set: the forecast service (forecast.weather.gov) and the
+// current observation service (w1.weather.gov).
+//
+// Each url must begin with `http` (`https` is inherently acceptable) and end
+// with a slash (`/`).
+func (c *Client) SetLegacyURLStrings(forecastURLString string, observationURLString string) error {
+	for _, s := range []string{forecastURLString, observationURLString} {
+		if !strings.HasPrefix(s, "http") {
+			return fmt.Errorf("urlString must begin with `http`: %s", s)
+		}
+		if !strings.HasSuffix(s, "/") {
+			return fmt.Errorf("urlString must end with a slash (`/`): %s", s)
+		}
+	}
+	c.legacyForecastURLString = forecastURLString
+	c.legacyObservationURLString = observationURLString
+	return nil
+}
+
+// legacySemidailyForecast retrieves the semi-daily forecast from the legacy
+// forecast service after the API has failed with apiErr. The returned error
+// includes both failures.
+func (c *Client) legacySemidailyForecast(apiErr error) (*Forecast, error) {
+	f, err := getLegacyForecastForPoint(c.httpClient, c.httpUserAgentString, c.legacyForecastURLString, c.point)
+	if err != nil {
+		return nil, fmt.Errorf("%w (legacy fallback: %s)", apiErr, err)
+	}
+	f.Gridpoint = c.gridpoint
+	f.setPeriodIDs()
+	return f, nil
+}
+
+// legacyLatestObservation retrieves the latest observation for a station from
+// the legacy observation service after the API has failed with apiErr. The
+// returned error includes both failures.
+func (c *Client) legacyLatestObservation(stationID string, apiErr error) (*Observation, error) {
+	o, err := getLegacyObservationForStation(c.httpClient, c.httpUserAgentString, c.legacyObservationURLString, stationID)
+	if err != nil {
+		return nil, fmt.Errorf("%w (legacy fallback: %s)", apiErr, err)
+	}
+	return o, nil
+}
+
+// getLegacyForecastForPoint retrieves the semi-daily forecast for a point from
+// the JSON form of the legacy forecast page.
+func getLegacyForecastForPoint(httpClient *http.Client, httpUserAgentString string, legacyURLString string, point Point) (*Forecast, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		legacyURLString,
+		getLegacyForecastEndpointURLString,
+		url.Values{
+			"lat":      {strconv.FormatFloat(point.Lat, 'f', -1, 64)},
+			"lon":      {strconv.FormatFloat(point.Lon, 'f', -1, 64)},
+			"FcstType": {"json"},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newForecastFromLegacyForecastRespBody(respBody)
+}
+
+// newForecastFromLegacyForecastRespBody returns a Forecast pointer, given a
+// response body from the legacy forecast service. The service provides
+// parallel arrays of period names, start times, and values; each period ends
+// when the next begins, and the last is assumed to be 12 hours long.
+func newForecastFromLegacyForecastRespBody(respBody []byte) (*Forecast, error) {
+	// unmarshal the body into a temporary struct
+	fRaw := struct {
+		CreationDate string
+		Time         struct {
+			StartPeriodName []string
+			StartValidTime  []string
+			TempLabel       []string
+		}
+		Data struct {
+			Temperature []*string
+			PoP         []*string
+			Weather     []string
+			IconLink    []string
+			Text        []string
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &fRaw); err != nil {
+		return nil, err
+	}
+
+	var err error
+	var f Forecast
+	f.Source = SourceLegacy
+	f.TimeRetrieved = time.Now()
+	f.TimeForecast, err = time.Parse(time.RFC3339, fRaw.CreationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(fRaw.Time.StartValidTime)
+	starts := make([]time.Time, n)
+	for i, s := range fRaw.Time.StartValidTime {
+		if starts[i], err = time.Parse(time.RFC3339, s); err != nil {
+			return nil, err
+		}
+	}
+
+	// the values are ignored if missing or malformed
+	at := func(ss []string, i int) string {
+		if i < len(ss) {
+			return ss[i]
+		}
+		return ""
+	}
+	atPtr := func(ss []*string, i int) string {
+		if i < len(ss) && ss[i] != nil {
+			return *ss[i]
+		}
+		return ""
+	}
+	for i, start := range starts {
+		p := Period{
+			Number:           i + 1,
+			Name:             at(fRaw.Time.StartPeriodName, i),
+			TimeStart:        start,
+			TimeEnd:          start.Add(12 * time.Hour),
+			IsDaytime:        at(fRaw.Time.TempLabel, i) == "High",
+			ForecastShort:    at(fRaw.Data.Weather, i),
+			ForecastDetailed: at(fRaw.Data.Text, i),
+		}
+		if i+1 < n {
+			p.TimeEnd = starts[i+1]
+		}
+		if v, err := strconv.ParseFloat(atPtr(fRaw.Data.Temperature, i), 64); err == nil {
+			p.Temperature = ValueUnit{Value: v, Unit: "F"}
+		}
+		if v, err := strconv.ParseFloat(atPtr(fRaw.Data.PoP, i), 64); err == nil {
+			p.PrecipitationProbability = ValueUnit{Value: v, Unit: "percent"}
+		}
+		p.Icon, _ = ParseIconURL(at(fRaw.Data.IconLink, i))
+		p.FirstHalf, p.SecondHalf = newHalfPeriodsFromIcon(p.TimeStart, p.TimeEnd, p.Icon)
+		p.RainfallAmountMin, p.RainfallAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "rain")
+		p.SnowAmountMin, p.SnowAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "snow")
+		p.IceAmountMin, p.IceAmountMax = parsePrecipitationAmount(p.ForecastDetailed, "ice")
+		f.Periods = append(f.Periods, p)
+	}
+
+	return &f, nil
+}
+
+// getLegacyObservationForStation retrieves the latest observation for a
+// station from the legacy current observation service.
+func getLegacyObservationForStation(httpClient *http.Client, httpUserAgentString string, legacyURLString string, stationID string) (*Observation, error) {
+	respBody, err := doAPIRequestAccepting(
+		httpClient,
+		httpUserAgentString,
+		legacyURLString,
+		fmt.Sprintf(getLegacyObservationForStationEndpointURLStringFmt, stationID),
+		nil,
+		legacyXMLMIMEType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newObservationFromLegacyObservationRespBody(respBody)
+}
+
+// newObservationFromLegacyObservationRespBody returns an Observation pointer,
+// given a response body from the legacy current observation service. Values
+// are converted to the units used by the API.
+func newObservationFromLegacyObservationRespBody(respBody []byte) (*Observation, error) {
+	// unmarshal the body into a temporary struct
+	oRaw := struct {
+		StationID        string `xml:"station_id"`
+		ObservationTime  string `xml:"observation_time_rfc822"`
+		TempC            string `xml:"temp_c"`
+		DewpointC        string `xml:"dewpoint_c"`
+		RelativeHumidity string `xml:"relative_humidity"`
+		WindDegrees      string `xml:"wind_degrees"`
+		WindMPH          string `xml:"wind_mph"`
+		WindGustMPH      string `xml:"wind_gust_mph"`
+		PressureMB       string `xml:"pressure_mb"`
+		VisibilityMi     string `xml:"visibility_mi"`
+		WindchillC       string `xml:"windchill_c"`
+		HeatIndexC       string `xml:"heat_index_c"`
+		IconURLBase      string `xml:"icon_url_base"`
+		IconURLName      string `xml:"icon_url_name"`
+		Weather          string `xml:"weather"`
+	}{}
+	if err := xml.Unmarshal(respBody, &oRaw); err != nil {
+		return nil, err
+	}
+
+	var err error
+	var o Observation
+
+	// must have valid station ID and times
+	o.StationID = oRaw.StationID
+	if o.StationID == "" {
+		return nil, fmt.Errorf("station string invalid: \"%s\"", oRaw.StationID)
+	}
+	o.Source = SourceLegacy
+	o.TimeRetrieved = time.Now()
+	o.TimeObserved, err = time.Parse(time.RFC1123Z, oRaw.ObservationTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// ignore any values that are missing or malformed
+	values := []struct {
+		s      string
+		vu     *ValueUnit
+		factor float64
+		unit   string
+	}{
+		{oRaw.TempC, &o.Temperature, 1, "C"},
+		{oRaw.DewpointC, &o.Dewpoint, 1, "C"},
+		{oRaw.RelativeHumidity, &o.RelativeHumidity, 1, "percent"},
+		{oRaw.WindDegrees, &o.WindDirection, 1, "degrees true"},
+		{oRaw.WindMPH, &o.WindSpeed, 0.44704, "m/s"},
+		{oRaw.WindGustMPH, &o.WindGust, 0.44704, "m/s"},
+		{oRaw.PressureMB, &o.BarometricPressure, 100, "Pa"},
+		{oRaw.VisibilityMi, &o.Visibility, 1609.344, "m"},
+		{oRaw.WindchillC, &o.WindChill, 1, "C"},
+		{oRaw.HeatIndexC, &o.HeatIndex, 1, "C"},
+	}
+	for _, v := range values {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v.s), 64); err == nil {
+			*v.vu = ValueUnit{Value: f * v.factor, Unit: v.unit}
+		}
+	}
+	o.Icon, _ = ParseIconURL(strings.TrimSpace(oRaw.IconURLBase) + strings.TrimSpace(oRaw.IconURLName))
+	o.Description = strings.TrimSpace(oRaw.Weather)
+
+	return &o, nil
+}
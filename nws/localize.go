@@ -0,0 +1,85 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"time"
+)
+
+// Location returns the gridpoint's IANA time zone (e.g. America/Los_Angeles)
+// as a *time.Location. The time zone database must be available; see
+// time.LoadLocation.
+func (gp Gridpoint) Location() (*time.Location, error) {
+	if gp.TimeZone == "" {
+		return nil, errors.New("gridpoint has no time zone")
+	}
+	return time.LoadLocation(gp.TimeZone)
+}
+
+// In returns a copy of the period with its times, including those of its
+// halves, in loc.
+func (p Period) In(loc *time.Location) Period {
+	p.TimeStart = p.TimeStart.In(loc)
+	p.TimeEnd = p.TimeEnd.In(loc)
+	if p.FirstHalf != nil {
+		h := *p.FirstHalf
+		h.TimeStart, h.TimeEnd = h.TimeStart.In(loc), h.TimeEnd.In(loc)
+		p.FirstHalf = &h
+	}
+	if p.SecondHalf != nil {
+		h := *p.SecondHalf
+		h.TimeStart, h.TimeEnd = h.TimeStart.In(loc), h.TimeEnd.In(loc)
+		p.SecondHalf = &h
+	}
+	return p
+}
+
+// In returns a copy of the forecast with its times and the times of its
+// periods in loc. The API gives times with fixed UTC offsets, and cached
+// forecasts may be in UTC, so formatting and grouping by day are simplest
+// after re-expressing times in a named location.
+func (f Forecast) In(loc *time.Location) Forecast {
+	f.TimeRetrieved = f.TimeRetrieved.In(loc)
+	f.TimeForecast = f.TimeForecast.In(loc)
+	f.TimeValid = f.TimeValid.In(loc)
+	periods := make([]Period, len(f.Periods))
+	for i, p := range f.Periods {
+		periods[i] = p.In(loc)
+	}
+	f.Periods = periods
+	return f
+}
+
+// Localized returns a copy of the forecast with its times in the time zone of
+// its gridpoint. See Forecast.In.
+func (f Forecast) Localized() (Forecast, error) {
+	loc, err := f.Gridpoint.Location()
+	if err != nil {
+		return Forecast{}, err
+	}
+	return f.In(loc), nil
+}
+
+// DayBoundaryIn returns a DayBoundary that applies boundary in loc, whatever
+// the location of the times it is given. For example, DayBoundaryIn(loc,
+// MidnightDayBoundary) groups periods by local midnight in loc even if their
+// times are in UTC or carry a fixed offset that differs across a daylight
+// saving time change.
+func DayBoundaryIn(loc *time.Location, boundary DayBoundary) DayBoundary {
+	return func(t time.Time) time.Time {
+		return boundary(t.In(loc))
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"time"
+)
+
+// speedsInMetersPerSecond contains the value of one unit of each supported
+// speed unit in meters per second.
+var speedsInMetersPerSecond = map[string]float64{
+	"m/s":  1,
+	"km/h": 1000.0 / 3600,
+	"mph":  1609.344 / 3600,
+	"kt":   1852.0 / 3600,
+}
+
+// LullCriteria describe the wind conditions considered calm enough for an
+// activity such as flying a drone or kite. WindSpeedMax and WindGustMax may use
+// any of the units "mph", "kt", "km/h", or "m/s".
+type LullCriteria struct {
+	WindSpeedMax ValueUnit     // sustained wind must be below this
+	WindGustMax  ValueUnit     // gusts must be below this; empty to ignore gusts
+	MinDuration  time.Duration // windows shorter than this are not returned
+}
+
+// A Lull is a window of time during which the forecast wind satisfies a set of
+// LullCriteria.
+type Lull struct {
+	TimeStart    time.Time
+	TimeEnd      time.Time
+	WindSpeedMax ValueUnit // the highest sustained wind in the window
+	WindGustMax  ValueUnit // the highest gust in the window, empty if none forecast
+}
+
+// Duration returns the length of the lull.
+func (l Lull) Duration() time.Duration {
+	return l.TimeEnd.Sub(l.TimeStart)
+}
+
+// Lulls returns the windows of consecutive periods during which the forecast
+// wind satisfies the criteria, in chronological order. It is intended for use
+// with the hourly forecast.
+//
+// A period without a wind speed ends a window. A period without a gust is
+// judged by its sustained wind alone, since gusts are only forecast when they
+// are notable.
+func (f Forecast) Lulls(criteria LullCriteria) ([]Lull, error) {
+	if _, err := convertSpeed(criteria.WindSpeedMax, "mph"); err != nil {
+		return nil, err
+	}
+	if criteria.WindGustMax.Unit != "" {
+		if _, err := convertSpeed(criteria.WindGustMax, "mph"); err != nil {
+			return nil, err
+		}
+	}
+
+	var lulls []Lull
+	var cur *Lull
+	end := func() {
+		if cur != nil && cur.Duration() >= criteria.MinDuration {
+			lulls = append(lulls, *cur)
+		}
+		cur = nil
+	}
+
+	for _, p := range f.Periods {
+		if !periodIsLull(p, criteria) || (cur != nil && !p.TimeStart.Equal(cur.TimeEnd)) {
+			end()
+		}
+		if !periodIsLull(p, criteria) {
+			continue
+		}
+		if cur == nil {
+			cur = &Lull{TimeStart: p.TimeStart}
+		}
+		cur.TimeEnd = p.TimeEnd
+		if cur.WindSpeedMax.Unit == "" || compareSpeeds(p.WindSpeedMax, cur.WindSpeedMax) > 0 {
+			cur.WindSpeedMax = p.WindSpeedMax
+		}
+		if p.WindGust.Unit != "" && (cur.WindGustMax.Unit == "" || compareSpeeds(p.WindGust, cur.WindGustMax) > 0) {
+			cur.WindGustMax = p.WindGust
+		}
+	}
+	end()
+
+	return lulls, nil
+}
+
+// periodIsLull reports whether a period satisfies the criteria. The criteria
+// must already have been validated.
+func periodIsLull(p Period, criteria LullCriteria) bool {
+	if p.WindSpeedMax.Unit == "" {
+		return false
+	}
+	if c := compareSpeeds(p.WindSpeedMax, criteria.WindSpeedMax); c != -1 {
+		return false
+	}
+	if criteria.WindGustMax.Unit != "" && p.WindGust.Unit != "" {
+		if c := compareSpeeds(p.WindGust, criteria.WindGustMax); c != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// compareSpeeds returns -1 if a is less than b, 0 if they are equal, and 1 if
+// a is greater than b. It returns 2 if either unit is unsupported.
+func compareSpeeds(a ValueUnit, b ValueUnit) int {
+	av, err := convertSpeed(a, "m/s")
+	if err != nil {
+		return 2
+	}
+	bv, err := convertSpeed(b, "m/s")
+	if err != nil {
+		return 2
+	}
+	switch {
+	case av.Value < bv.Value:
+		return -1
+	case av.Value > bv.Value:
+		return 1
+	}
+	return 0
+}
+
+// convertSpeed returns a speed converted to another unit.
+func convertSpeed(vu ValueUnit, unit string) (ValueUnit, error) {
+	from, ok := speedsInMetersPerSecond[vu.Unit]
+	if !ok {
+		return ValueUnit{}, fmt.Errorf("unsupported speed unit: \"%s\"", vu.Unit)
+	}
+	to, ok := speedsInMetersPerSecond[unit]
+	if !ok {
+		return ValueUnit{}, fmt.Errorf("unsupported speed unit: \"%s\"", unit)
+	}
+	return ValueUnit{Value: vu.Value * from / to, Unit: unit}, nil
+}
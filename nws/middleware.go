@@ -0,0 +1,83 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// endpointContextKey is the context key under which doAPIRequest stores the
+// endpoint name of each request.
+type endpointContextKey struct{}
+
+// A Middleware wraps the http.RoundTripper used by a Client. Middleware may
+// observe or modify requests and responses for logging, metrics, tracing, etc.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as
+// http.RoundTrippers when writing Middleware.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use wraps the Client's transport with middleware. The first middleware is
+// the outermost, so it sees requests first and responses last.
+//
+// Requests made while the Client is constructed (gridpoint and station
+// resolution) happen before Use can be called. To observe those, pass an
+// *http.Client with a wrapped Transport to the constructor instead.
+func (c *Client) Use(mw ...Middleware) {
+	rt := c.httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+	c.httpClient.Transport = rt
+}
+
+// EndpointFromRequest returns the name of the NWS API endpoint that a request
+// was made to, with variable path segments replaced by "*" (e.g.
+// "gridpoints/*/*/forecast"). The name is suitable as a low-cardinality metric
+// label. An empty string is returned for requests that were not made to the
+// API, such as alert resources.
+func EndpointFromRequest(req *http.Request) string {
+	name, _ := req.Context().Value(endpointContextKey{}).(string)
+	return name
+}
+
+// withEndpointName returns a copy of req whose context carries the name of the
+// endpoint.
+func withEndpointName(req *http.Request, endpoint string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), endpointContextKey{}, endpointName(endpoint)))
+}
+
+// endpointName returns the name of an endpoint path. Path segments that are
+// not entirely lowercase letters (IDs, WFOs, coordinates) are variable.
+func endpointName(endpoint string) string {
+	segs := strings.Split(strings.Trim(endpoint, "/"), "/")
+	for i, seg := range segs {
+		if seg == "" || strings.IndexFunc(seg, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+			segs[i] = "*"
+		}
+	}
+	return strings.Join(segs, "/")
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// writeExportMsgPack writes records as a MessagePack array of maps, keeping
+// the order of the fields. Missing values are nil and floats are encoded as
+// float32 when that loses nothing.
+//
+// https://github.com/msgpack/msgpack/blob/master/spec.md
+func writeExportMsgPack(w io.Writer, recs [][]exportField) error {
+	e := msgpackEncoder{w: bufio.NewWriter(w)}
+	e.writeArrayHeader(len(recs))
+	for _, rec := range recs {
+		e.writeMapHeader(len(rec))
+		for _, f := range rec {
+			e.writeString(f.name)
+			e.writeValue(exportNormalize(f.value))
+		}
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// A msgpackEncoder encodes the subset of MessagePack needed for export
+// records. The first error is kept and later writes are skipped.
+type msgpackEncoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *msgpackEncoder) write(b ...byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *msgpackEncoder) writeValue(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		e.write(0xc0)
+	case bool:
+		if v {
+			e.write(0xc3)
+		} else {
+			e.write(0xc2)
+		}
+	case int:
+		e.writeInt(int64(v))
+	case float64:
+		e.writeFloat(v)
+	case string:
+		e.writeString(v)
+	default:
+		if e.err == nil {
+			e.err = fmt.Errorf("unsupported export value type: %T", v)
+		}
+	}
+}
+
+func (e *msgpackEncoder) writeInt(v int64) {
+	switch {
+	case v >= 0 && v <= 127:
+		e.write(byte(v))
+	case v >= -32 && v < 0:
+		e.write(byte(0xe0 | (v + 32)))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		e.write(0xd0, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		e.write(0xd1)
+		e.write(binary.BigEndian.AppendUint16(nil, uint16(v))...)
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		e.write(0xd2)
+		e.write(binary.BigEndian.AppendUint32(nil, uint32(v))...)
+	default:
+		e.write(0xd3)
+		e.write(binary.BigEndian.AppendUint64(nil, uint64(v))...)
+	}
+}
+
+func (e *msgpackEncoder) writeFloat(v float64) {
+	if f := float32(v); float64(f) == v {
+		e.write(0xca)
+		e.write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f))...)
+		return
+	}
+	e.write(0xcb)
+	e.write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v))...)
+}
+
+func (e *msgpackEncoder) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.write(byte(0xa0 | n))
+	case n <= math.MaxUint8:
+		e.write(0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.write(0xda)
+		e.write(binary.BigEndian.AppendUint16(nil, uint16(n))...)
+	default:
+		e.write(0xdb)
+		e.write(binary.BigEndian.AppendUint32(nil, uint32(n))...)
+	}
+	e.write([]byte(s)...)
+}
+
+func (e *msgpackEncoder) writeArrayHeader(n int) {
+	e.writeContainerHeader(n, 0x90, 0xdc, 0xdd)
+}
+
+func (e *msgpackEncoder) writeMapHeader(n int) {
+	e.writeContainerHeader(n, 0x80, 0xde, 0xdf)
+}
+
+// writeContainerHeader writes an array or map header using the fix, 16 bit, or
+// 32 bit format.
+func (e *msgpackEncoder) writeContainerHeader(n int, fix byte, b16 byte, b32 byte) {
+	switch {
+	case n < 16:
+		e.write(fix | byte(n))
+	case n <= math.MaxUint16:
+		e.write(b16)
+		e.write(binary.BigEndian.AppendUint16(nil, uint16(n))...)
+	default:
+		e.write(b32)
+		e.write(binary.BigEndian.AppendUint32(nil, uint32(n))...)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMsgpackEncoder(t *testing.T) {
+	// cat returns its arguments joined, for building expected encodings.
+	cat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	str := func(n int) string {
+		return strings.Repeat("a", n)
+	}
+	tests := []struct {
+		name   string
+		encode func(e *msgpackEncoder)
+		want   []byte
+	}{
+		// nil and bool
+		{"nil", func(e *msgpackEncoder) { e.writeValue(nil) }, []byte{0xc0}},
+		{"false", func(e *msgpackEncoder) { e.writeValue(false) }, []byte{0xc2}},
+		{"true", func(e *msgpackEncoder) { e.writeValue(true) }, []byte{0xc3}},
+
+		// int
+		{"positive fixint 0", func(e *msgpackEncoder) { e.writeValue(0) }, []byte{0x00}},
+		{"positive fixint 127", func(e *msgpackEncoder) { e.writeValue(127) }, []byte{0x7f}},
+		{"negative fixint -1", func(e *msgpackEncoder) { e.writeValue(-1) }, []byte{0xff}},
+		{"negative fixint -32", func(e *msgpackEncoder) { e.writeValue(-32) }, []byte{0xe0}},
+		{"int8 -33", func(e *msgpackEncoder) { e.writeValue(-33) }, []byte{0xd0, 0xdf}},
+		{"int8 -128", func(e *msgpackEncoder) { e.writeValue(-128) }, []byte{0xd0, 0x80}},
+		{"int16 128", func(e *msgpackEncoder) { e.writeValue(128) }, []byte{0xd1, 0x00, 0x80}},
+		{"int16 -129", func(e *msgpackEncoder) { e.writeValue(-129) }, []byte{0xd1, 0xff, 0x7f}},
+		{"int32 32768", func(e *msgpackEncoder) { e.writeValue(32768) }, []byte{0xd2, 0x00, 0x00, 0x80, 0x00}},
+		{"int64 2^31", func(e *msgpackEncoder) { e.writeValue(1 << 31) }, []byte{0xd3, 0, 0, 0, 0, 0x80, 0, 0, 0}},
+
+		// float
+		{"float32", func(e *msgpackEncoder) { e.writeValue(1.5) }, []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}},
+		{"float64", func(e *msgpackEncoder) { e.writeValue(0.1) }, []byte{0xcb, 0x3f, 0xb9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a}},
+
+		// str
+		{"empty fixstr", func(e *msgpackEncoder) { e.writeValue("") }, []byte{0xa0}},
+		{"fixstr 31", func(e *msgpackEncoder) { e.writeValue(str(31)) }, cat([]byte{0xbf}, []byte(str(31)))},
+		{"str8 32", func(e *msgpackEncoder) { e.writeValue(str(32)) }, cat([]byte{0xd9, 32}, []byte(str(32)))},
+		{"str8 255", func(e *msgpackEncoder) { e.writeValue(str(255)) }, cat([]byte{0xd9, 0xff}, []byte(str(255)))},
+		{"str16 256", func(e *msgpackEncoder) { e.writeValue(str(256)) }, cat([]byte{0xda, 0x01, 0x00}, []byte(str(256)))},
+		{"str16 65535", func(e *msgpackEncoder) { e.writeValue(str(65535)) }, cat([]byte{0xda, 0xff, 0xff}, []byte(str(65535)))},
+		{"str32 65536", func(e *msgpackEncoder) { e.writeValue(str(65536)) }, cat([]byte{0xdb, 0x00, 0x01, 0x00, 0x00}, []byte(str(65536)))},
+
+		// array and map headers
+		{"fixarray 0", func(e *msgpackEncoder) { e.writeArrayHeader(0) }, []byte{0x90}},
+		{"fixarray 15", func(e *msgpackEncoder) { e.writeArrayHeader(15) }, []byte{0x9f}},
+		{"array16 16", func(e *msgpackEncoder) { e.writeArrayHeader(16) }, []byte{0xdc, 0x00, 0x10}},
+		{"array32 65536", func(e *msgpackEncoder) { e.writeArrayHeader(65536) }, []byte{0xdd, 0x00, 0x01, 0x00, 0x00}},
+		{"fixmap 15", func(e *msgpackEncoder) { e.writeMapHeader(15) }, []byte{0x8f}},
+		{"map16 16", func(e *msgpackEncoder) { e.writeMapHeader(16) }, []byte{0xde, 0x00, 0x10}},
+		{"map16 65535", func(e *msgpackEncoder) { e.writeMapHeader(65535) }, []byte{0xde, 0xff, 0xff}},
+		{"map32 65536", func(e *msgpackEncoder) { e.writeMapHeader(65536) }, []byte{0xdf, 0x00, 0x01, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := msgpackEncoder{w: bufio.NewWriter(&buf)}
+			tt.encode(&e)
+			if e.err != nil {
+				t.Fatal(e.err)
+			}
+			e.w.Flush()
+			if got := buf.Bytes(); !bytes.Equal(got, tt.want) {
+				if len(got) > 16 {
+					got = got[:16]
+				}
+				want := tt.want
+				if len(want) > 16 {
+					want = want[:16]
+				}
+				t.Errorf("got % x (%d bytes); want % x (%d bytes)", got, buf.Len(), want, len(tt.want))
+			}
+		})
+	}
+
+	// unsupported types are an error
+	e := msgpackEncoder{w: bufio.NewWriter(&bytes.Buffer{})}
+	e.writeValue(struct{}{})
+	if e.err == nil {
+		t.Error("unsupported type: got no error")
+	}
+}
+
+func TestWriteExportMsgPack(t *testing.T) {
+	recs := [][]exportField{
+		{{"station", "KPDX"}, {"temperature", 28.5}, {"wind", ""}},
+	}
+	want := []byte{
+		0x91, // array of one record
+		0x83, // map of three fields
+		0xa7, 's', 't', 'a', 't', 'i', 'o', 'n',
+		0xa4, 'K', 'P', 'D', 'X',
+		0xab, 't', 'e', 'm', 'p', 'e', 'r', 'a', 't', 'u', 'r', 'e',
+		0xca, 0x41, 0xe4, 0x00, 0x00, // float32 28.5
+		0xa4, 'w', 'i', 'n', 'd',
+		0xc0, // empty strings are nil
+	}
+	var buf bytes.Buffer
+	if err := writeExportMsgPack(&buf, recs); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got % x; want % x", buf.Bytes(), want)
+	}
+}
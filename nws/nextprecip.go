@@ -0,0 +1,148 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"strings"
+	"time"
+)
+
+// Precipitation types.
+const (
+	PrecipitationRain          = "rain"
+	PrecipitationSnow          = "snow"
+	PrecipitationSleet         = "sleet"
+	PrecipitationFreezingRain  = "freezing rain"
+	PrecipitationMixed         = "mixed"
+	PrecipitationThunderstorms = "thunderstorms"
+)
+
+// precipitationIconTypes maps the precipitation icon condition codes to
+// precipitation types.
+var precipitationIconTypes = map[string]string{
+	"rain":            PrecipitationRain,
+	"rain_showers":    PrecipitationRain,
+	"rain_showers_hi": PrecipitationRain,
+	"tsra":            PrecipitationThunderstorms,
+	"tsra_sct":        PrecipitationThunderstorms,
+	"tsra_hi":         PrecipitationThunderstorms,
+	"snow":            PrecipitationSnow,
+	"blizzard":        PrecipitationSnow,
+	"sleet":           PrecipitationSleet,
+	"fzra":            PrecipitationFreezingRain,
+	"rain_fzra":       PrecipitationFreezingRain,
+	"snow_fzra":       PrecipitationMixed,
+	"rain_snow":       PrecipitationMixed,
+	"rain_sleet":      PrecipitationMixed,
+	"snow_sleet":      PrecipitationMixed,
+}
+
+// A PrecipitationEvent is a run of consecutive periods in which precipitation
+// is expected.
+type PrecipitationEvent struct {
+	TimeStart time.Time
+	TimeEnd   time.Time
+	Type      string // one of the Precipitation constants, or empty if unknown
+
+	// Probability is the highest chance of precipitation during the event,
+	// in percent.
+	Probability int
+
+	// EndsAfterForecast is true if precipitation is still expected at the end
+	// of the forecast, so TimeEnd is only the end of the forecast.
+	EndsAfterForecast bool
+
+	// Derived is true if any part of the event comes from periods derived by
+	// BlendForecasts, so its timing is coarse.
+	Derived bool
+}
+
+// NextPrecipitation returns the next precipitation event in the forecast
+// ending after now: the first period in which the chance of precipitation is
+// at least threshold percent, through the last consecutive such period. If
+// precipitation is expected now, the event has already started. The second
+// return value is false if no precipitation is expected.
+//
+// It is intended for use with the hourly forecast, or with BlendForecasts for
+// a longer horizon. The type is taken from the first period's icon, or from
+// its short forecast if the icon doesn't indicate precipitation.
+func (f Forecast) NextPrecipitation(now time.Time, threshold int) (PrecipitationEvent, bool) {
+	var e PrecipitationEvent
+	var started bool
+
+	for _, p := range f.Periods {
+		if !p.TimeEnd.After(now) {
+			continue
+		}
+		pop := p.precipitationProbability()
+		wet := pop >= threshold && pop > 0
+		if started && (!wet || !p.TimeStart.Equal(e.TimeEnd)) {
+			return e, true
+		}
+		if !wet {
+			continue
+		}
+		if !started {
+			started = true
+			e.TimeStart = p.TimeStart
+			e.Type = periodPrecipitationType(p)
+		}
+		e.TimeEnd = p.TimeEnd
+		if pop > e.Probability {
+			e.Probability = pop
+		}
+		if p.Derived {
+			e.Derived = true
+		}
+	}
+
+	if !started {
+		return PrecipitationEvent{}, false
+	}
+	e.EndsAfterForecast = true
+	return e, true
+}
+
+// periodPrecipitationType returns the type of precipitation forecast for a
+// period, or an empty string if unknown.
+func periodPrecipitationType(p Period) string {
+	var best IconCondition
+	var bestType string
+	for _, c := range p.Icon.Conditions {
+		if t, ok := precipitationIconTypes[c.Code]; ok && (bestType == "" || c.Probability > best.Probability) {
+			best, bestType = c, t
+		}
+	}
+	if bestType != "" {
+		return bestType
+	}
+
+	short := strings.ToLower(p.ForecastShort)
+	switch {
+	case strings.Contains(short, "thunderstorm"):
+		return PrecipitationThunderstorms
+	case strings.Contains(short, "freezing rain") || strings.Contains(short, "freezing drizzle"):
+		return PrecipitationFreezingRain
+	case strings.Contains(short, "wintry mix") || (strings.Contains(short, "rain") && strings.Contains(short, "snow")):
+		return PrecipitationMixed
+	case strings.Contains(short, "sleet"):
+		return PrecipitationSleet
+	case strings.Contains(short, "snow") || strings.Contains(short, "flurries"):
+		return PrecipitationSnow
+	case strings.Contains(short, "rain") || strings.Contains(short, "shower") || strings.Contains(short, "drizzle"):
+		return PrecipitationRain
+	}
+	return ""
+}
@@ -20,19 +20,46 @@
 package nws
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
+	"mime"
 	"net/http"
+	"net/mail"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	defaultAPIURLString   = "https://api.weather.gov/"
 	defaultThrottleString = "5m"
+
+	// maxRespBodyBytes limits the size of response bodies read from the API so
+	// that a corrupted or malicious response can't exhaust memory. The
+	// largest legitimate responses (raw gridpoint data) are a few megabytes.
+	maxRespBodyBytes = 16 << 20
+)
+
+// Errors returned, possibly wrapped, when a 200 response does not contain a
+// document. Some endpoints (notably the legacy alert endpoints) respond this
+// way to bad parameters rather than with an error status.
+var (
+	// ErrEmptyResponse is returned when a response body is empty, or is an
+	// empty JSON document ("null" or "{}").
+	ErrEmptyResponse = errors.New("empty response")
+
+	// ErrExpiredAlert is returned when a response body is a plain text notice
+	// that an alert has expired rather than the alert itself.
+	ErrExpiredAlert = errors.New("alert has expired")
+
+	// ErrUnexpectedContentType is returned when a response is not of the
+	// requested type, such as an HTML outage page served in place of JSON.
+	ErrUnexpectedContentType = errors.New("unexpected content type")
 )
 
 // A Client is used to interact with the NWS API for a specific location on
@@ -54,17 +81,69 @@ type Client struct {
 	// updating the latest observation for any station.
 	ObservationsThrottle time.Duration
 
-	httpClient          *http.Client
-	httpUserAgentString string
-	apiURLString        string
-	point               Point
-	gridpoint           Gridpoint
-	stations            []Station
-	defaultStationID    string
-	alerts              []Alert
-	semidailyForecast   Forecast
-	hourlyForecast      Forecast
-	observations        map[string]ObsTime // key is a station ID
+	// BackfillThrottle represents the minimum time that must elapse between
+	// requests made by Backfill.
+	BackfillThrottle time.Duration
+
+	// URLPolicy determines which hosts the Client may be redirected to or
+	// follow links to. It defaults to *.weather.gov and *.noaa.gov.
+	URLPolicy URLPolicy
+
+	// FetchAlertResources causes UpdateAlerts to also retrieve the resources
+	// (images, audio, etc.) referenced by each alert, and the alert's info
+	// blocks in every language.
+	FetchAlertResources bool
+
+	// ResolveAlertPolygons causes UpdateAlerts to resolve the polygons of
+	// alerts that have only geocodes from the geometry of their zones, so that
+	// CoversPoint works for them. See ResolveAlertPolygonsFromZones.
+	ResolveAlertPolygons bool
+
+	// LegacyFallback causes the forecasts and latest observations to be
+	// retrieved from the legacy forecast.weather.gov and w1.weather.gov
+	// services when the API fails. Data retrieved this way have their Source
+	// set to SourceLegacy and lack some values (e.g. semi-daily forecast
+	// wind). The legacy hourly forecast covers only 48 hours.
+	LegacyFallback bool
+
+	// RefetchStale causes the forecasts and latest observations to be
+	// retrieved again before they are returned if they are stale, as long as
+	// their throttle has elapsed. The stale data are returned if this fails.
+	// See Forecast.IsStale and Observation.IsStale.
+	RefetchStale bool
+
+	// MaxObservationAge is the age beyond which RefetchStale considers an
+	// observation stale. It defaults to DefaultMaxObservationAge.
+	MaxObservationAge time.Duration
+
+	// MaxForecastAge is the age beyond which RefetchStale considers a
+	// forecast stale. It defaults to DefaultMaxForecastAge.
+	MaxForecastAge time.Duration
+
+	// ParseOptions control how forecasts are parsed. Parsing is lenient by
+	// default.
+	ParseOptions ParseOptions
+
+	httpClient                 *http.Client
+	httpUserAgentString        string
+	apiURLString               string
+	legacyForecastURLString    string
+	legacyObservationURLString string
+	point                      Point
+	gridpoint                  Gridpoint
+	adjacentGridpoints         []Gridpoint // from other offices, used as fallbacks
+	stations                   []Station
+	defaultStationID           string
+	alerts                     []Alert
+	alertsValidators           validators // of the last response for alerts
+	semidailyForecast          Forecast
+	hourlyForecast             Forecast
+	mu                         sync.Mutex                // guards the maps below
+	observations               map[string]ObsTime        // key is a station ID
+	alertFeeds                 map[string]alertFeedState // key is an endpoint and query
+	zoneGeometries             map[string][][]Point      // key is a zone ID
+	offices                    map[string]Office         // key is a WFO
+	wfos                       map[string]bool           // nil until RefreshKnownWFOs
 
 	alertsLastRetrived             time.Time
 	semidailyForecastLastRetrieved time.Time
@@ -77,6 +156,63 @@ type ObsTime struct {
 	observationLastRetrieved time.Time
 }
 
+// A ClientConfig identifies the application using a Client. It is used to build
+// a User-Agent that complies with the NWS's request that applications identify
+// themselves and provide contact information.
+type ClientConfig struct {
+	AppName      string // required
+	Version      string
+	ContactEmail string // required
+
+	// StrictTLS requires HTTPS with TLS 1.2 or later for every request. See
+	// StrictTLSTransport.
+	StrictTLS bool
+}
+
+// UserAgent returns a User-Agent string built from the config, in the form
+// "AppName/Version (ContactEmail)". An error is returned if AppName or
+// ContactEmail is missing or ContactEmail is not a valid email address.
+func (cfg ClientConfig) UserAgent() (string, error) {
+	appName := strings.TrimSpace(cfg.AppName)
+	if appName == "" {
+		return "", errors.New("AppName must not be empty")
+	}
+	if strings.ContainsAny(appName, " /()") {
+		return "", fmt.Errorf("AppName must not contain spaces, slashes, or parentheses: \"%s\"", appName)
+	}
+	if strings.TrimSpace(cfg.ContactEmail) == "" {
+		return "", errors.New("ContactEmail must not be empty")
+	}
+	addr, err := mail.ParseAddress(cfg.ContactEmail)
+	if err != nil || addr.Name != "" {
+		return "", fmt.Errorf("ContactEmail must be a bare email address: \"%s\"", cfg.ContactEmail)
+	}
+
+	ua := appName
+	if v := strings.TrimSpace(cfg.Version); v != "" {
+		ua += "/" + v
+	}
+	return fmt.Sprintf("%s (%s)", ua, addr.Address), nil
+}
+
+// NewClientFromConfig creates a new client given a ClientConfig and a WGS 84
+// (EPSG:4326) latitude and longitude. The User-Agent is built from the config.
+func NewClientFromConfig(httpClient *http.Client, config ClientConfig, lat float64, lon float64) (*Client, error) {
+	ua, err := config.UserAgent()
+	if err != nil {
+		return nil, err
+	}
+	if config.StrictTLS {
+		hc := &http.Client{}
+		if httpClient != nil {
+			*hc = *httpClient
+		}
+		hc.Transport = StrictTLSTransport(hc.Transport)
+		httpClient = hc
+	}
+	return NewClientFromCoordinates(httpClient, ua, lat, lon)
+}
+
 // NewClientFromCoordinates creates a new client given a WGS 84 (EPSG:4326)
 // latitude and longitide.
 //
@@ -89,25 +225,22 @@ type ObsTime struct {
 //   (website or email), we can contact you if your string is associated to a
 //   security event. This will be replaced with an API key in the future."
 //   -- https://www.weather.gov/documentation/services-web-api
+//
+// An error is returned if httpUserAgentString is empty. Use
+// NewClientFromConfig to build a compliant User-Agent.
 func NewClientFromCoordinates(httpClient *http.Client, httpUserAgentString string, lat float64, lon float64) (*Client, error) {
-	var err error
-
-	c := &Client{
-		httpClient:          &http.Client{},
-		httpUserAgentString: httpUserAgentString,
-
-		// point Lat and Lon are rounded to four decimal places because the API
-		// requires that requests be made with at most four decimal places. The
-		// API will 301 redirect, but using four in the first place eliminates
-		// those extra requests.
-		point: Point{
-			Lat: math.Round(lat*10000) / 10000,
-			Lon: math.Round(lon*10000) / 10000,
-		},
+	c, err := NewClient(httpClient, httpUserAgentString)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = c.setAPIURLString(defaultAPIURLString); err != nil {
-		return nil, err
+	// point Lat and Lon are rounded to four decimal places because the API
+	// requires that requests be made with at most four decimal places. The
+	// API will 301 redirect, but using four in the first place eliminates
+	// those extra requests.
+	c.point = Point{
+		Lat: math.Round(lat*10000) / 10000,
+		Lon: math.Round(lon*10000) / 10000,
 	}
 
 	if err = c.setGridpointFromPoint(); err != nil {
@@ -122,6 +255,45 @@ func NewClientFromCoordinates(httpClient *http.Client, httpUserAgentString strin
 		return nil, err
 	}
 
+	return c, nil
+}
+
+// NewClient creates a new client that isn't bound to a location, for use with
+// the methods that take a station or zone (e.g.
+// UpdateLatestOservationForStation or ActiveAlertsForZone). The methods for
+// the Client's own location and default station must not be used. See
+// NewClientFromCoordinates regarding httpUserAgentString.
+func NewClient(httpClient *http.Client, httpUserAgentString string) (*Client, error) {
+	var err error
+
+	if strings.TrimSpace(httpUserAgentString) == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+
+	c := &Client{
+		URLPolicy:           NewDefaultURLPolicy(),
+		httpClient:          &http.Client{},
+		httpUserAgentString: httpUserAgentString,
+		observations:        make(map[string]ObsTime),
+	}
+
+	// copy the caller's client so that setting CheckRedirect and using
+	// middleware doesn't affect it
+	if httpClient != nil {
+		hc := *httpClient
+		c.httpClient = &hc
+	}
+	c.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return c.URLPolicy.checkRedirect(req, via)
+	}
+
+	if err = c.setAPIURLString(defaultAPIURLString); err != nil {
+		return nil, err
+	}
+	if err = c.SetLegacyURLStrings(defaultLegacyForecastURLString, defaultLegacyObservationURLString); err != nil {
+		return nil, err
+	}
+
 	defaultThrottle, err := time.ParseDuration(defaultThrottleString)
 	if err != nil {
 		return nil, err
@@ -168,6 +340,9 @@ func (c *Client) DefaultStationID() string {
 
 // SetDefaultStationID changes the default station ID.
 func (c *Client) SetDefaultStationID(id string) error {
+	if err := ValidateStationID(id); err != nil {
+		return err
+	}
 	return c.setDefaultStationID(id)
 }
 
@@ -181,42 +356,117 @@ func (c *Client) Alerts(id string) []Alert {
 //
 // The NWS tends to refer to the semi-daily forecast as simply "forecast."
 func (c *Client) SemidailyForecast() Forecast {
+	c.refetchIfStale(c.semidailyForecast.IsStale(c.maxForecastAge()), c.semidailyForecastLastRetrieved, c.SemidailyForecastThrottle, c.UpdateSemidailyForecast)
 	return c.semidailyForecast
 }
 
 // HourlyForecast returns the last retrieved hourly forcast.
 func (c *Client) HourlyForecast() Forecast {
+	c.refetchIfStale(c.hourlyForecast.IsStale(c.maxForecastAge()), c.hourlyForecastLastRetrieved, c.HourlyForecastThrottle, c.UpdateHourlyForecast)
 	return c.hourlyForecast
 }
 
 // LatestObservationForDefaultStation returns the last retrieved observation
 // for the default station.
 func (c *Client) LatestObservationForDefaultStation() Observation {
+	ot := c.obsTime(c.defaultStationID)
+	c.refetchIfStale(ot.observation.IsStale(c.maxObservationAge()), ot.observationLastRetrieved, c.ObservationsThrottle, c.UpdateLatestObservationForDefaultStation)
 	// return empty observation if station does not exist in obeservations map
-	return c.observations[c.defaultStationID].observation
+	return c.obsTime(c.defaultStationID).observation
 }
 
 // LatestObservationForStation returns the last retrieved observation for a
 // station.
 func (c *Client) LatestObservationForStation(id string) Observation {
+	ot := c.obsTime(id)
+	c.refetchIfStale(ot.observation.IsStale(c.maxObservationAge()), ot.observationLastRetrieved, c.ObservationsThrottle, func() error {
+		return c.UpdateLatestOservationForStation(id)
+	})
 	// return empty observation if station does not exist in obeservations map
-	return c.observations[id].observation
+	return c.obsTime(id).observation
 }
 
-// UpdateAlerts updates the active alerts for this Client.
+// UpdateAlerts updates the active alerts for this Client. Requests are
+// conditional, so if the alerts haven't changed since the last update they
+// are kept without being downloaded again.
 func (c *Client) UpdateAlerts() error {
-	alerts, err := getActiveAlertsForPoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.point)
+	alerts, v, notModified, err := getActiveAlertsForPoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.point, c.alertsValidators)
 	if err != nil {
 		return err
 	}
+	if notModified {
+		c.alertsLastRetrived = time.Now()
+		return nil
+	}
+	if c.FetchAlertResources {
+		for i := range alerts {
+			// resources are optional, so ignore errors
+			_ = c.updateAlertResources(&alerts[i])
+		}
+	}
+	if c.ResolveAlertPolygons {
+		// alerts without polygons are still useful, so ignore errors
+		_ = c.ResolveAlertPolygonsFromZones(alerts)
+	}
 	c.alerts = alerts
+	c.alertsValidators = v
 	c.alertsLastRetrived = time.Now()
 	return nil
 }
 
+// AdjacentGridpoints returns gridpoints near the Client's point that belong to
+// other forecast offices. See FindAdjacentGridpoints.
+func (c *Client) AdjacentGridpoints() []Gridpoint {
+	return c.adjacentGridpoints
+}
+
+// FindAdjacentGridpoints looks for gridpoints belonging to forecast offices
+// other than the Client's own office within radiusMeters of the Client's point.
+// Points near office boundaries sometimes get better (or any) data from an
+// adjacent office.
+//
+// If any are found, forecast updates that fail for the Client's own gridpoint
+// are retried using the adjacent gridpoints, in order of discovery. The
+// Gridpoint of the returned Forecast records which office served it.
+//
+// This makes up to eight requests. An error is returned only if all of them
+// fail.
+func (c *Client) FindAdjacentGridpoints(radiusMeters float64) error {
+	var err error
+	var gps []Gridpoint
+	var ok bool
+
+	seen := map[string]bool{c.gridpoint.WFO: true}
+	for bearing := 0.0; bearing < 360; bearing += 45 {
+		var gp *Gridpoint
+		gp, err = getGridpointForPoint(c.httpClient, c.httpUserAgentString, c.apiURLString, offsetPoint(c.point, bearing, radiusMeters))
+		if err != nil {
+			continue // points offshore may not resolve
+		}
+		ok = true
+		if seen[gp.WFO] {
+			continue
+		}
+		seen[gp.WFO] = true
+		gps = append(gps, *gp)
+	}
+	if !ok {
+		return err
+	}
+
+	c.adjacentGridpoints = gps
+	return nil
+}
+
 // UpdateSemidailyForecast updates the semi-daily forecast for this Client.
 func (c *Client) UpdateSemidailyForecast() error {
-	f, err := getSemidailyForecastForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.gridpoint)
+	f, err := getSemidailyForecastForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.gridpoint, c.ParseOptions)
+	for i := 0; err != nil && i < len(c.adjacentGridpoints); i++ {
+		f, err = getSemidailyForecastForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.adjacentGridpoints[i], c.ParseOptions)
+	}
+	if err != nil && c.LegacyFallback {
+		f, err = c.legacySemidailyForecast(err)
+	}
 	if err != nil {
 		return err
 	}
@@ -227,7 +477,13 @@ func (c *Client) UpdateSemidailyForecast() error {
 
 // UpdateHourlyForecast updates the hourly forecast for this Client.
 func (c *Client) UpdateHourlyForecast() error {
-	f, err := getHourlyForecastForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.gridpoint)
+	f, err := getHourlyForecastForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.gridpoint, c.ParseOptions)
+	for i := 0; err != nil && i < len(c.adjacentGridpoints); i++ {
+		f, err = getHourlyForecastForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.adjacentGridpoints[i], c.ParseOptions)
+	}
+	if err != nil && c.LegacyFallback {
+		f, err = c.legacyHourlyForecast(err)
+	}
 	if err != nil {
 		return err
 	}
@@ -240,30 +496,54 @@ func (c *Client) UpdateHourlyForecast() error {
 // the default station.
 func (c *Client) UpdateLatestObservationForDefaultStation() error {
 	o, err := getLatestObservationForStation(c.httpClient, c.httpUserAgentString, c.apiURLString, c.defaultStationID)
+	if err != nil && c.LegacyFallback {
+		o, err = c.legacyLatestObservation(c.defaultStationID, err)
+	}
 	if err != nil {
 		return err
 	}
-	c.observations[c.defaultStationID] = ObsTime{
+	c.setObsTime(c.defaultStationID, ObsTime{
 		observation:              *o,
 		observationLastRetrieved: o.TimeRetrieved,
-	}
+	})
 	return nil
 }
 
 // UpdateLatestOservationForStation updates the latest observation for
 // a station.
 func (c *Client) UpdateLatestOservationForStation(id string) error {
+	if err := ValidateStationID(id); err != nil {
+		return err
+	}
 	o, err := getLatestObservationForStation(c.httpClient, c.httpUserAgentString, c.apiURLString, id)
+	if err != nil && c.LegacyFallback {
+		o, err = c.legacyLatestObservation(id, err)
+	}
 	if err != nil {
 		return err
 	}
-	c.observations[id] = ObsTime{
+	c.setObsTime(id, ObsTime{
 		observation:              *o,
 		observationLastRetrieved: o.TimeRetrieved,
-	}
+	})
 	return nil
 }
 
+// obsTime returns the latest observation for a station and when it was
+// retrieved, or an empty ObsTime if there is none.
+func (c *Client) obsTime(id string) ObsTime {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.observations[id]
+}
+
+// setObsTime sets the latest observation for a station.
+func (c *Client) setObsTime(id string, ot ObsTime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observations[id] = ot
+}
+
 // AlertsLastRetrieved returns the time that alerts waere last successfuly
 // retrieved.
 func (c *Client) AlertsLastRetrieved(id string) time.Time {
@@ -286,14 +566,14 @@ func (c *Client) HourlyForecastLastRetrieved() time.Time {
 // latesst observation for the default station was last successfuly retrieved.
 func (c *Client) LatestObservationForDefaultStationLastRetrieved() time.Time {
 	// return zero time if station does not exist in obeservations map
-	return c.observations[c.defaultStationID].observationLastRetrieved
+	return c.obsTime(c.defaultStationID).observationLastRetrieved
 }
 
 // LatestObservationForStationLastRetrieved returns the time that the latest
 // observations for the specified station was last successfuly retrieved.
 func (c *Client) LatestObservationForStationLastRetrieved(id string) time.Time {
 	// return zero time if station does not exist in obeservations map
-	return c.observations[id].observationLastRetrieved
+	return c.obsTime(id).observationLastRetrieved
 }
 
 // setAPIURLString sets the URL of the NWS API Web Service.
@@ -301,13 +581,22 @@ func (c *Client) LatestObservationForStationLastRetrieved(id string) time.Time {
 // The url must begin with `http` (`https` is inherently acceptable) and end
 // with a slash (`/`).
 func (c *Client) setAPIURLString(urlString string) error {
+	if err := validateAPIURLString(urlString); err != nil {
+		return err
+	}
+	c.apiURLString = urlString
+	return nil
+}
+
+// validateAPIURLString returns an error if a URL of the NWS API Web Service
+// doesn't begin with `http` and end with a slash (`/`).
+func validateAPIURLString(urlString string) error {
 	if !strings.HasPrefix(urlString, "http") {
 		return fmt.Errorf("urlString must begin with `http`: %s", urlString)
 	}
 	if !strings.HasSuffix(urlString, "/") {
 		return fmt.Errorf("urlString must end with a slash (`/`): %s", urlString)
 	}
-	c.apiURLString = urlString
 	return nil
 }
 
@@ -331,49 +620,205 @@ func (c *Client) setStationsFromGridpont() error {
 	return nil
 }
 
-// setDefaultStationID sets the Client's default station to the first station in
-// its stations slice.
+// setDefaultStationID sets the Client's default station. The Client must have
+// stations.
 func (c *Client) setDefaultStationID(id string) error {
 	if len(c.stations) < 1 {
 		return errors.New("client has no stations")
 	}
-	c.defaultStationID = c.stations[0].ID
+	c.defaultStationID = id
 	return nil
 }
 
+// updateAlertResources retrieves the CAP document for an alert and then each
+// resource that it references. Resources that can't be retrieved are kept,
+// but without data. The alert's info blocks are also taken from the document.
+func (c *Client) updateAlertResources(a *Alert) error {
+	doc, err := getCAPForAlert(c.httpClient, c.httpUserAgentString, c.apiURLString, a.ID)
+	if err != nil {
+		return err
+	}
+	a.Infos = newAlertInfosFromCAP(doc)
+	a.Circles = newCirclesFromCAP(doc)
+	a.Resources = newAlertResourcesFromCAP(doc)
+	for i := range a.Resources {
+		_ = fetchAlertResource(c.httpClient, c.httpUserAgentString, c.URLPolicy, &a.Resources[i])
+	}
+	return nil
+}
+
+// nextEndpointFromRespBody returns the endpoint of the next page of a paginated
+// response, relative to apiURLString. An empty string is returned if there is
+// no next page or if the next page is not on the API.
+func nextEndpointFromRespBody(respBody []byte, apiURLString string) string {
+	pRaw := struct {
+		Pagination struct {
+			Next string
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &pRaw); err != nil {
+		return ""
+	}
+	if !strings.HasPrefix(pRaw.Pagination.Next, apiURLString) {
+		return ""
+	}
+	return strings.TrimPrefix(pRaw.Pagination.Next, apiURLString)
+}
+
 // doAPIRequest both makes a GET request to the specified endpoint and handles
 // non-200 responses. get will only return an *http.Rsponse with a 200 status
 // code.
 func doAPIRequest(httpClient *http.Client, httpUserAgentString string, apiURLString string, endpoint string, query url.Values) ([]byte, error) {
+	return doAPIRequestAccepting(httpClient, httpUserAgentString, apiURLString, endpoint, query, "")
+}
+
+// doAPIRequestAccepting is the same as doAPIRequest, but also sets the Accept
+// header so that a format other than the default GeoJSON may be requested
+// (e.g. "application/cap+xml"). No Accept header is set if accept is empty.
+func doAPIRequestAccepting(httpClient *http.Client, httpUserAgentString string, apiURLString string, endpoint string, query url.Values, accept string) ([]byte, error) {
+	respBody, _, _, err := doConditionalAPIRequest(httpClient, httpUserAgentString, apiURLString, endpoint, query, accept, validators{})
+	return respBody, err
+}
+
+// validators hold the cache validators of a previous response, used to make
+// conditional requests.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// doConditionalAPIRequest is the same as doAPIRequestAccepting, but sends
+// If-None-Match and If-Modified-Since headers built from v. If the server
+// responds 304 Not Modified, notModified is true and no body is returned. The
+// validators of a 200 response are returned for use in the next request.
+func doConditionalAPIRequest(httpClient *http.Client, httpUserAgentString string, apiURLString string, endpoint string, query url.Values, accept string, v validators) (respBody []byte, newV validators, notModified bool, err error) {
+	respBody, header, notModified, err := doAPIRequestWithHeader(httpClient, httpUserAgentString, apiURLString, endpoint, query, accept, v)
+	if err != nil {
+		return nil, validators{}, false, err
+	}
+	if notModified {
+		return nil, v, true, nil
+	}
+	return respBody, newValidators(header), false, nil
+}
+
+// newValidators returns the validators of a response given its header.
+func newValidators(header http.Header) validators {
+	return validators{
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+	}
+}
+
+// doAPIRequestWithHeader makes a conditional request like
+// doConditionalAPIRequest, returning the response header in place of its
+// validators.
+func doAPIRequestWithHeader(httpClient *http.Client, httpUserAgentString string, apiURLString string, endpoint string, query url.Values, accept string, v validators) (respBody []byte, header http.Header, notModified bool, err error) {
 	// build the request
 	req, err := http.NewRequest("GET", apiURLString+endpoint, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
 	}
 	if query != nil {
 		req.URL.RawQuery = query.Encode()
 	}
 	req.Header.Set("User-Agent", httpUserAgentString)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+	req = withEndpointName(req, endpoint)
 
 	// make the request, return error if error
 	// TODO: handle errors like client side timeouts
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
 	}
 	defer resp.Body.Close()
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, resp.Header, true, nil
+	}
+
+	respBody, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
+	}
+	if len(respBody) > maxRespBodyBytes {
+		return nil, nil, false, fmt.Errorf("response body exceeds %d bytes", maxRespBodyBytes)
 	}
 
 	// check status code, return error if not 200
 	// TODO: handle errors like server side timeouts, this is difficult because
 	// the API is so sparsely documented.
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("%s: %s", resp.Status, respBody)
+		if isHTMLContentType(resp.Header.Get("Content-Type")) {
+			return nil, nil, false, fmt.Errorf("%s: HTML error page: %s", resp.Status, endpoint)
+		}
+		return nil, nil, false, fmt.Errorf("%s: %s", resp.Status, respBody)
+	}
+	if err := checkRespBody(respBody); err != nil {
+		return nil, nil, false, fmt.Errorf("%w: %s", err, endpoint)
+	}
+	if err := checkContentType(resp.Header.Get("Content-Type"), accept); err != nil {
+		return nil, nil, false, fmt.Errorf("%w: %s", err, endpoint)
 	}
 
-	return respBody, nil
+	return respBody, resp.Header, false, nil
+}
+
+// checkContentType returns ErrUnexpectedContentType if a response's
+// Content-Type is not the type requested by accept, or JSON if accept is
+// empty. XML may be served as "application/xml" or "text/xml" in place of a
+// more specific XML type. A missing Content-Type is allowed, since some proxies
+// and caches drop it.
+func checkContentType(contentType string, accept string) error {
+	if contentType == "" {
+		return nil
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: \"%s\"", ErrUnexpectedContentType, contentType)
+	}
+	var ok bool
+	switch {
+	case accept == "":
+		ok = mt == "application/json" || strings.HasSuffix(mt, "+json")
+	case accept == "application/xml" || strings.HasSuffix(accept, "+xml"):
+		ok = mt == accept || mt == "application/xml" || mt == "text/xml"
+	default:
+		ok = mt == accept
+	}
+	if !ok {
+		return fmt.Errorf("%w: \"%s\"", ErrUnexpectedContentType, mt)
+	}
+	return nil
+}
+
+// isHTMLContentType reports whether a Content-Type is HTML.
+func isHTMLContentType(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == "text/html" || mt == "application/xhtml+xml"
+}
+
+// checkRespBody returns ErrEmptyResponse or ErrExpiredAlert if a 200 response
+// body is not actually a document. Anything that looks like JSON or XML is
+// left for the caller to parse.
+func checkRespBody(respBody []byte) error {
+	trimmed := strings.TrimSpace(string(respBody))
+	switch {
+	case trimmed == "" || trimmed == "null" || trimmed == "{}":
+		return ErrEmptyResponse
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "<"):
+		return nil
+	case strings.Contains(strings.ToLower(trimmed), "expired"):
+		return ErrExpiredAlert
+	}
+	return nil
 }
@@ -13,3 +13,41 @@
 // limitations under the License.
 
 package nws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient(nil, " "); err == nil {
+		t.Error("empty User-Agent: got nil error")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/alerts/active/zone/ORZ006" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		fmt.Fprint(w, `{"features": [{"properties": {"id": "urn:oid:2.49.0.1.840.0.1", "event": "Heat Advisory"}}]}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.Client(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetAPIURLString(srv.URL + "/"); err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := c.ActiveAlertsForZone("orz006")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Event != "Heat Advisory" {
+		t.Errorf("got %+v; want the Heat Advisory", alerts)
+	}
+}
@@ -18,12 +18,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 )
 
-const getLatestObeservationForStationEndpointURLStringFmt = "stations/%s/observations/latest" // id
+const (
+	getLatestObeservationForStationEndpointURLStringFmt = "stations/%s/observations/latest" // id
+	getObservationsForStationEndpointURLStringFmt       = "stations/%s/observations"        // id
+)
 
 var observationUnitCodes = map[string]string{
 	"unit:degC":           "C",
@@ -34,10 +38,30 @@ var observationUnitCodes = map[string]string{
 	"unit:percent":        "percent",
 }
 
+// Quality control codes of observed values. These are the MADIS data
+// descriptors used by the API.
+const (
+	QCNone           = "Z" // no quality control applied
+	QCCoarse         = "C" // passed coarse checks
+	QCScreened       = "S" // passed screening checks
+	QCVerified       = "V" // passed all checks
+	QCRejected       = "X" // failed coarse checks
+	QCQuestioned     = "Q" // failed a screening or later check
+	QCSubjectiveGood = "G" // judged good by a person
+	QCSubjectiveBad  = "B" // judged bad by a person
+)
+
+// newQCFromRaw returns a quality control code given a qualityControl value from
+// the API, which is either a bare code (e.g. "V") or prefixed (e.g. "qc:V").
+func newQCFromRaw(raw string) string {
+	return strings.TrimPrefix(raw, "qc:")
+}
+
 // A Observation represents the weather at a particular a particular station
 // at a particular point in time returned from the NWS API.
 type Observation struct {
 	StationID string
+	Source    string // SourceAPI or SourceLegacy
 
 	TimeRetrieved time.Time
 	TimeObserved  time.Time
@@ -60,13 +84,34 @@ type Observation struct {
 	HeatIndex                 ValueUnit
 	// CloudLayers
 
-	METAR string // raw METAR string
+	Icon        Icon   // current conditions, if provided
+	Description string // e.g. "Mostly Cloudy", if provided
+	METAR       string // raw METAR string
+
+	ResponseMeta *ResponseMeta // of the API response the observation was parsed from, if any
+}
+
+// WithoutSuspectValues returns a copy of the observation with the values that
+// failed quality control (see ValueUnit.Suspect) removed.
+func (o Observation) WithoutSuspectValues() Observation {
+	for _, vu := range []*ValueUnit{
+		&o.Temperature, &o.Dewpoint, &o.WindDirection, &o.WindSpeed, &o.WindGust,
+		&o.BarometricPressure, &o.SeaLevelPressure, &o.Visibility,
+		&o.TemperatureLast24HoursMin, &o.TemperatureLast24HoursMax,
+		&o.PrecipitationLastHour, &o.PrecipitationLast3Hours, &o.PrecipitationLast6Hours,
+		&o.RelativeHumidity, &o.WindChill, &o.HeatIndex,
+	} {
+		if vu.Suspect() {
+			*vu = ValueUnit{}
+		}
+	}
+	return o
 }
 
 // getLatestObservationForStation retrieves from the NWS API the latest
 // observation from a particular station.
 func getLatestObservationForStation(httpClient *http.Client, httpUserAgentString string, apiURLString string, stationID string) (*Observation, error) {
-	respBody, err := doAPIRequest(
+	respBody, meta, err := doAPIRequestWithMeta(
 		httpClient,
 		httpUserAgentString,
 		apiURLString,
@@ -76,7 +121,63 @@ func getLatestObservationForStation(httpClient *http.Client, httpUserAgentString
 	if err != nil {
 		return nil, err
 	}
-	return newObservationFromStationObservationRespBody(respBody)
+	o, err := newObservationFromStationObservationRespBody(respBody)
+	if err != nil {
+		return nil, err
+	}
+	o.ResponseMeta = meta
+	return o, nil
+}
+
+// getObservationsForStation retrieves from the NWS API the observations from a
+// particular station between two times, in the order returned by the API
+// (newest first).
+func getObservationsForStation(httpClient *http.Client, httpUserAgentString string, apiURLString string, stationID string, start time.Time, end time.Time) ([]Observation, error) {
+	query := url.Values{}
+	query.Add("start", start.UTC().Format(time.RFC3339))
+	query.Add("end", end.UTC().Format(time.RFC3339))
+	respBody, meta, err := doAPIRequestWithMeta(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getObservationsForStationEndpointURLStringFmt, stationID),
+		query,
+	)
+	if err != nil {
+		return nil, err
+	}
+	obs, err := newObservationsFromStationObservationsRespBody(respBody)
+	if err != nil {
+		return nil, err
+	}
+	for i := range obs {
+		obs[i].ResponseMeta = meta
+	}
+	return obs, nil
+}
+
+// newObservationsFromStationObservationsRespBody returns a slice of
+// Observations, given a response body from the NWS API. Invalid observations
+// are skipped.
+func newObservationsFromStationObservationsRespBody(respBody []byte) ([]Observation, error) {
+	// each feature has the same structure as a single observation
+	osRaw := struct {
+		Features []json.RawMessage
+	}{}
+	if err := json.Unmarshal(respBody, &osRaw); err != nil {
+		return nil, err
+	}
+
+	var obs []Observation
+	for _, oRaw := range osRaw.Features {
+		o, err := newObservationFromStationObservationRespBody(oRaw)
+		if err != nil {
+			continue // skip if invalid
+		}
+		obs = append(obs, *o)
+	}
+
+	return obs, nil
 }
 
 // newObservationFromStationObservationRespBody returns an Obsevation pointer,
@@ -93,72 +194,90 @@ func newObservationFromStationObservationRespBody(respBody []byte) (*Observation
 	// unmarshal the body into a temporary struct
 	oRaw := struct {
 		Properties struct {
-			Station     string // URL
-			Timestamp   string // time observed
-			RawMessage  string // raw METAR
-			Temperature struct {
-				Value    string
-				UnitCode string
+			Station         string // URL
+			Timestamp       string // time observed
+			RawMessage      string // raw METAR
+			Icon            string // URL, may be null
+			TextDescription string
+			Temperature     struct {
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			Dewpoint struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			WindDirection struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			WindSpeed struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			WindGust struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			BarometricPressure struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			SeaLevelPressure struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			Visibility struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			MaxTemperatureLast24Hours struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			MinTemperatureLast24Hours struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			PrecipitationLastHour struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			PrecipitationLast3Hours struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			PrecipitationLast6Hours struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			RelativeHumidity struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			WindChill struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 			HeatIndex struct {
-				Value    string
-				UnitCode string
+				Value          json.Number
+				UnitCode       string
+				QualityControl string
 			}
 		}
 	}{}
@@ -178,6 +297,7 @@ func newObservationFromStationObservationRespBody(respBody []byte) (*Observation
 	if o.StationID == "" {
 		return nil, fmt.Errorf("station string invalid: \"%s\"", oRaw.Properties.Station)
 	}
+	o.Source = SourceAPI
 	o.TimeRetrieved = time.Now()
 	o.TimeObserved, err = time.Parse(time.RFC3339, oRaw.Properties.Timestamp)
 	if err != nil {
@@ -185,104 +305,122 @@ func newObservationFromStationObservationRespBody(respBody []byte) (*Observation
 	}
 
 	// ignore any properties that are null, malformed, or have unrecognized units
-	v, err = strconv.ParseFloat(oRaw.Properties.Temperature.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.Temperature.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.Temperature.UnitCode]
 	if uok && err == nil {
 		o.Temperature.Value = v
 		o.Temperature.Unit = u
+		o.Temperature.QC = newQCFromRaw(oRaw.Properties.Temperature.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.Dewpoint.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.Dewpoint.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.Dewpoint.UnitCode]
 	if uok && err == nil {
 		o.Dewpoint.Value = v
 		o.Dewpoint.Unit = u
+		o.Dewpoint.QC = newQCFromRaw(oRaw.Properties.Dewpoint.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.WindDirection.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.WindDirection.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.WindDirection.UnitCode]
 	if uok && err == nil {
 		o.WindDirection.Value = v
 		o.WindDirection.Unit = u
+		o.WindDirection.QC = newQCFromRaw(oRaw.Properties.WindDirection.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.WindSpeed.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.WindSpeed.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.WindSpeed.UnitCode]
 	if uok && err == nil {
 		o.WindSpeed.Value = v
 		o.WindSpeed.Unit = u
+		o.WindSpeed.QC = newQCFromRaw(oRaw.Properties.WindSpeed.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.WindGust.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.WindGust.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.WindGust.UnitCode]
 	if uok && err == nil {
 		o.WindGust.Value = v
 		o.WindGust.Unit = u
+		o.WindGust.QC = newQCFromRaw(oRaw.Properties.WindGust.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.BarometricPressure.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.BarometricPressure.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.BarometricPressure.UnitCode]
 	if uok && err == nil {
 		o.BarometricPressure.Value = v
 		o.BarometricPressure.Unit = u
+		o.BarometricPressure.QC = newQCFromRaw(oRaw.Properties.BarometricPressure.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.SeaLevelPressure.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.SeaLevelPressure.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.SeaLevelPressure.UnitCode]
 	if uok && err == nil {
 		o.SeaLevelPressure.Value = v
 		o.SeaLevelPressure.Unit = u
+		o.SeaLevelPressure.QC = newQCFromRaw(oRaw.Properties.SeaLevelPressure.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.Visibility.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.Visibility.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.Visibility.UnitCode]
 	if uok && err == nil {
 		o.Visibility.Value = v
 		o.Visibility.Unit = u
+		o.Visibility.QC = newQCFromRaw(oRaw.Properties.Visibility.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.MinTemperatureLast24Hours.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.MinTemperatureLast24Hours.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.MinTemperatureLast24Hours.UnitCode]
 	if uok && err == nil {
 		o.TemperatureLast24HoursMin.Value = v
 		o.TemperatureLast24HoursMin.Unit = u
+		o.TemperatureLast24HoursMin.QC = newQCFromRaw(oRaw.Properties.MinTemperatureLast24Hours.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.MaxTemperatureLast24Hours.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.MaxTemperatureLast24Hours.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.MaxTemperatureLast24Hours.UnitCode]
 	if uok && err == nil {
 		o.TemperatureLast24HoursMax.Value = v
 		o.TemperatureLast24HoursMax.Unit = u
+		o.TemperatureLast24HoursMax.QC = newQCFromRaw(oRaw.Properties.MaxTemperatureLast24Hours.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.PrecipitationLastHour.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.PrecipitationLastHour.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.PrecipitationLastHour.UnitCode]
 	if uok && err == nil {
 		o.PrecipitationLastHour.Value = v
 		o.PrecipitationLastHour.Unit = u
+		o.PrecipitationLastHour.QC = newQCFromRaw(oRaw.Properties.PrecipitationLastHour.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.PrecipitationLast3Hours.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.PrecipitationLast3Hours.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.PrecipitationLast3Hours.UnitCode]
 	if uok && err == nil {
 		o.PrecipitationLast3Hours.Value = v
 		o.PrecipitationLast3Hours.Unit = u
+		o.PrecipitationLast3Hours.QC = newQCFromRaw(oRaw.Properties.PrecipitationLast3Hours.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.PrecipitationLast6Hours.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.PrecipitationLast6Hours.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.PrecipitationLast6Hours.UnitCode]
 	if uok && err == nil {
 		o.PrecipitationLast6Hours.Value = v
 		o.PrecipitationLast6Hours.Unit = u
+		o.PrecipitationLast6Hours.QC = newQCFromRaw(oRaw.Properties.PrecipitationLast6Hours.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.RelativeHumidity.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.RelativeHumidity.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.RelativeHumidity.UnitCode]
 	if uok && err == nil {
 		o.RelativeHumidity.Value = v
 		o.RelativeHumidity.Unit = u
+		o.RelativeHumidity.QC = newQCFromRaw(oRaw.Properties.RelativeHumidity.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.WindChill.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.WindChill.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.WindChill.UnitCode]
 	if uok && err == nil {
 		o.WindChill.Value = v
 		o.WindChill.Unit = u
+		o.WindChill.QC = newQCFromRaw(oRaw.Properties.WindChill.QualityControl)
 	}
-	v, err = strconv.ParseFloat(oRaw.Properties.HeatIndex.Value, 64)
+	v, err = strconv.ParseFloat(string(oRaw.Properties.HeatIndex.Value), 64)
 	u, uok = observationUnitCodes[oRaw.Properties.HeatIndex.UnitCode]
 	if uok && err == nil {
 		o.HeatIndex.Value = v
 		o.HeatIndex.Unit = u
+		o.HeatIndex.QC = newQCFromRaw(oRaw.Properties.HeatIndex.QualityControl)
 	}
 
 	o.METAR = oRaw.Properties.RawMessage
+	o.Icon, _ = ParseIconURL(oRaw.Properties.Icon)
+	o.Description = oRaw.Properties.TextDescription
 
 	return &o, nil
 }
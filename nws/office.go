@@ -0,0 +1,181 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const getOfficeEndpointURLStringFmt = "offices/%s" // wfo
+
+// An Office represents a weather forecast office and the area it is
+// responsible for, for showing "your local forecast office" details.
+type Office struct {
+	ID        string // e.g. "PQR"
+	Name      string // e.g. "Portland, OR"
+	Address   OfficeAddress
+	Telephone string
+	Fax       string
+	Email     string
+	URL       string // the office's website
+	Region    string // NWS region (e.g. "wr")
+	ParentID  string // the office's regional headquarters (e.g. "WRH")
+
+	ApprovedObservationStations []string // station IDs (e.g. "KPDX")
+	ResponsibleCounties         []string // county codes (e.g. "ORC051")
+	ResponsibleForecastZones    []string // forecast zone codes (e.g. "ORZ006")
+	ResponsibleFireZones        []string // fire weather zone codes (e.g. "ORZ605")
+}
+
+// An OfficeAddress is the postal address of an Office.
+type OfficeAddress struct {
+	Street     string
+	Locality   string // city
+	Region     string // state
+	PostalCode string
+}
+
+// String returns the address on one line (e.g. "5241 NE 122nd Ave.,
+// Portland, OR 97230-1089"). Missing parts are omitted.
+func (a OfficeAddress) String() string {
+	var parts []string
+	for _, s := range []string{a.Street, a.Locality, strings.TrimSpace(a.Region + " " + a.PostalCode)} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Office returns a weather forecast office (e.g. "PQR"). Offices are
+// retrieved from the NWS API once and then cached for the life of the Client,
+// since they rarely change.
+func (c *Client) Office(wfo string) (Office, error) {
+	if err := ValidateWFO(wfo); err != nil {
+		return Office{}, err
+	}
+	wfo = strings.ToUpper(wfo)
+	c.mu.Lock()
+	cached, ok := c.offices[wfo]
+	c.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	o, err := getOffice(c.httpClient, c.httpUserAgentString, c.apiURLString, wfo)
+	if err != nil {
+		return Office{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.offices == nil {
+		c.offices = make(map[string]Office)
+	}
+	c.offices[wfo] = *o
+	return *o, nil
+}
+
+// LocalOffice returns the weather forecast office responsible for the
+// Client's point.
+func (c *Client) LocalOffice() (Office, error) {
+	return c.Office(c.gridpoint.WFO)
+}
+
+// getOffice retrieves an office from the NWS API.
+func getOffice(httpClient *http.Client, httpUserAgentString string, apiURLString string, wfo string) (*Office, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getOfficeEndpointURLStringFmt, wfo),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newOfficeFromOfficeRespBody(respBody)
+}
+
+// newOfficeFromOfficeRespBody returns an Office pointer, given a response body
+// from the NWS API.
+func newOfficeFromOfficeRespBody(respBody []byte) (*Office, error) {
+	// unmarshal the body into a temporary struct
+	oRaw := struct {
+		ID      string
+		Name    string
+		Address struct {
+			StreetAddress   string
+			AddressLocality string
+			AddressRegion   string
+			PostalCode      string
+		}
+		Telephone                   string
+		FaxNumber                   string
+		Email                       string
+		SameAs                      string // URL
+		NWSRegion                   string
+		ParentOrganization          string   // URL
+		ApprovedObservationStations []string // URLs
+		ResponsibleCounties         []string // URLs
+		ResponsibleForecastZones    []string // URLs
+		ResponsibleFireZones        []string // URLs
+	}{}
+	if err := json.Unmarshal(respBody, &oRaw); err != nil {
+		return nil, err
+	}
+
+	// validate and build returned value, which must have an ID
+	if err := ValidateWFO(oRaw.ID); err != nil {
+		return nil, err
+	}
+	o := Office{
+		ID:   strings.ToUpper(oRaw.ID),
+		Name: strings.TrimSpace(oRaw.Name),
+		Address: OfficeAddress{
+			Street:     strings.TrimSpace(oRaw.Address.StreetAddress),
+			Locality:   strings.TrimSpace(oRaw.Address.AddressLocality),
+			Region:     strings.TrimSpace(oRaw.Address.AddressRegion),
+			PostalCode: strings.TrimSpace(oRaw.Address.PostalCode),
+		},
+		Telephone: strings.TrimSpace(oRaw.Telephone),
+		Fax:       strings.TrimSpace(oRaw.FaxNumber),
+		Email:     strings.TrimSpace(oRaw.Email),
+		URL:       strings.TrimSpace(oRaw.SameAs),
+		Region:    strings.TrimSpace(oRaw.NWSRegion),
+	}
+	if oRaw.ParentOrganization != "" {
+		o.ParentID = zoneIDFromZoneURLString(oRaw.ParentOrganization)
+	}
+	o.ApprovedObservationStations = idsFromURLStrings(oRaw.ApprovedObservationStations)
+	o.ResponsibleCounties = idsFromURLStrings(oRaw.ResponsibleCounties)
+	o.ResponsibleForecastZones = idsFromURLStrings(oRaw.ResponsibleForecastZones)
+	o.ResponsibleFireZones = idsFromURLStrings(oRaw.ResponsibleFireZones)
+
+	return &o, nil
+}
+
+// idsFromURLStrings returns the IDs at the ends of station, zone, or office
+// URLs. URLs without an ID are skipped.
+func idsFromURLStrings(urlStrings []string) []string {
+	var ids []string
+	for _, u := range urlStrings {
+		if id := zoneIDFromZoneURLString(u); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
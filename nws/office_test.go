@@ -0,0 +1,50 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestOfficeConcurrent is meant to be run with -race.
+func TestOfficeConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/ld+json")
+		fmt.Fprint(w, `{"id": "PQR", "name": "Portland, OR"}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client(), httpUserAgentString: "test", apiURLString: srv.URL + "/"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o, err := c.Office("pqr")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if o.ID != "PQR" || o.Name != "Portland, OR" {
+				t.Errorf("got %+v; want PQR", o)
+			}
+		}()
+	}
+	wg.Wait()
+}
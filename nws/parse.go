@@ -0,0 +1,104 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errors recorded in ParseErrors
+var (
+	errUnrecognizedUnit   = errors.New("unrecognized unit")
+	errMalformedWindSpeed = errors.New("malformed wind speed")
+)
+
+// ParseOptions control how responses are parsed.
+//
+// By default parsing is lenient: malformed values are left empty and
+// malformed forecast periods are skipped, so that a change to one field of the
+// API's output doesn't make the rest unavailable. Strict parsing instead
+// fails with a *ParseErrors describing every malformed value, so that users
+// who depend on data quality can detect upstream format changes.
+type ParseOptions struct {
+	Strict bool
+}
+
+// A ParseError describes a malformed value in a response.
+type ParseError struct {
+	Path  string // e.g. "periods[3].windSpeed"
+	Value string // the raw value
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: \"%s\": %s", e.Path, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is returned by strict parsing when one or more values are
+// malformed.
+type ParseErrors struct {
+	Errors []*ParseError
+}
+
+// Error implements the error interface.
+func (pe *ParseErrors) Error() string {
+	msgs := make([]string, len(pe.Errors))
+	for i, e := range pe.Errors {
+		msgs[i] = e.Error()
+	}
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	return fmt.Sprintf("%d values malformed: %s", len(msgs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the individual errors so that errors.Is and errors.As examine
+// each of them.
+func (pe *ParseErrors) Unwrap() []error {
+	errs := make([]error, len(pe.Errors))
+	for i, e := range pe.Errors {
+		errs[i] = e
+	}
+	return errs
+}
+
+// add records a malformed value.
+func (pe *ParseErrors) add(path string, value string, err error) {
+	pe.Errors = append(pe.Errors, &ParseError{Path: path, Value: value, Err: err})
+}
+
+// errOrNil returns pe if strict parsing is enabled and any values were
+// malformed, and nil otherwise.
+func (pe *ParseErrors) errOrNil(opts ParseOptions) error {
+	if !opts.Strict || len(pe.Errors) == 0 {
+		return nil
+	}
+	return pe
+}
+
+// isNullJSON reports whether a raw JSON value is missing or null.
+func isNullJSON(raw []byte) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeriodNames contains the strings used to build relative period names. Replace
+// these to localize period names.
+type PeriodNames struct {
+	Today         string
+	Tonight       string
+	Tomorrow      string
+	TomorrowNight string
+	Overnight     string    // a night period that began before 6 AM
+	NightFmt      string    // e.g. "%s Night", given a weekday name or date
+	Weekdays      [7]string // indexed by time.Weekday
+	DaysAhead     int       // periods up to this many days ahead use weekdays
+	DateFmt       string    // time layout used for periods beyond DaysAhead
+}
+
+// EnglishPeriodNames are the period names used by the NWS.
+var EnglishPeriodNames = PeriodNames{
+	Today:         "Today",
+	Tonight:       "Tonight",
+	Tomorrow:      "Tomorrow",
+	TomorrowNight: "Tomorrow Night",
+	Overnight:     "Overnight",
+	NightFmt:      "%s Night",
+	Weekdays:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	DaysAhead:     6,
+	DateFmt:       "Jan 2",
+}
+
+// RelativeName returns a name for the period relative to a reference time
+// (e.g. "Tonight" or "Tomorrow"), using the day boundaries of loc. If loc is
+// nil the period's own time zone is used. A night period that began before
+// 6 AM on the reference day, as the first period of a forecast retrieved after
+// midnight does, is named Overnight rather than Tonight.
+func (p Period) RelativeName(ref time.Time, loc *time.Location, names PeriodNames) string {
+	if loc == nil {
+		loc = p.TimeStart.Location()
+	}
+	start := p.TimeStart.In(loc)
+	ref = ref.In(loc)
+
+	days := daysBetween(ref, start)
+	switch {
+	case days < 0 && !p.IsDaytime:
+		return names.Overnight
+	case days == 0 && !p.IsDaytime && start.Hour() < 6:
+		return names.Overnight
+	case days == 0 && p.IsDaytime:
+		return names.Today
+	case days == 0:
+		return names.Tonight
+	case days == 1 && p.IsDaytime:
+		return names.Tomorrow
+	case days == 1:
+		return names.TomorrowNight
+	case days > 1 && days <= names.DaysAhead:
+		wd := names.Weekdays[start.Weekday()]
+		if p.IsDaytime {
+			return wd
+		}
+		return fmt.Sprintf(names.NightFmt, wd)
+	default:
+		d := start.Format(names.DateFmt)
+		if p.IsDaytime {
+			return d
+		}
+		return fmt.Sprintf(names.NightFmt, d)
+	}
+}
+
+// WithRelativePeriodNames returns a copy of the forecast with each period's
+// Name replaced by its RelativeName. This is useful when displaying a cached
+// forecast, since names like "Tonight" become incorrect as time passes. Pass
+// f.TimeRetrieved as ref to name periods as of when they were retrieved.
+func (f Forecast) WithRelativePeriodNames(ref time.Time, loc *time.Location, names PeriodNames) Forecast {
+	periods := make([]Period, len(f.Periods))
+	for i, p := range f.Periods {
+		p.Name = p.RelativeName(ref, loc, names)
+		periods[i] = p
+	}
+	f.Periods = periods
+	return f
+}
+
+// daysBetween returns the number of calendar days from a to b, using the
+// location of a.
+func daysBetween(a time.Time, b time.Time) int {
+	b = b.In(a.Location())
+	ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(bd.Sub(ad).Hours() / 24)
+}
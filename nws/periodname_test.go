@@ -0,0 +1,59 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodRelativeName(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day int, hour int) time.Time {
+		return time.Date(2019, time.August, day, hour, 0, 0, 0, loc)
+	}
+	tests := []struct {
+		name      string
+		ref       time.Time
+		start     time.Time
+		isDaytime bool
+		want      string
+	}{
+		{"today", at(30, 10), at(30, 10), true, "Today"},
+		{"tonight", at(30, 10), at(30, 18), false, "Tonight"},
+		{"tomorrow", at(30, 10), at(31, 6), true, "Tomorrow"},
+		{"tomorrow night", at(30, 10), at(31, 18), false, "Tomorrow Night"},
+		{"weekday", at(30, 10), at(32, 6), true, "Sunday"},
+		{"weekday night", at(30, 10), at(32, 18), false, "Sunday Night"},
+		{"date", at(30, 10), at(38, 6), true, "Sep 7"},
+		{"began the previous day", at(31, 2), at(30, 18), false, "Overnight"},
+
+		// a forecast retrieved after midnight starts with a night period
+		{"retrieved after midnight", at(31, 1), at(31, 1), false, "Overnight"},
+		{"after midnight, today", at(31, 1), at(31, 6), true, "Today"},
+		{"after midnight, tonight", at(31, 1), at(31, 18), false, "Tonight"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Period{TimeStart: tt.start, IsDaytime: tt.isDaytime}
+			if got := p.RelativeName(tt.ref, nil, EnglishPeriodNames); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
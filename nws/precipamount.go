@@ -0,0 +1,113 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Detailed forecasts describe expected amounts in sentences such as:
+//   New rainfall amounts between a tenth and quarter of an inch possible.
+//   New rainfall amounts of less than a tenth of an inch possible.
+//   New snow accumulation of 2 to 4 inches possible.
+//   New snow accumulation of around an inch possible.
+//   Little or no snow accumulation expected.
+var (
+	precipAmountRegexps = map[string]*regexp.Regexp{
+		"rain": regexp.MustCompile(`rainfall amounts? (.*?) (?:possible|expected)`),
+		"snow": regexp.MustCompile(`snow(?: and sleet)? accumulations? (.*?) (?:possible|expected)`),
+		"ice":  regexp.MustCompile(`ice accumulations? (.*?) (?:possible|expected)`),
+	}
+	precipLittleOrNoRegexps = map[string]*regexp.Regexp{
+		"rain": regexp.MustCompile(`little or no (?:new )?rainfall`),
+		"snow": regexp.MustCompile(`little or no (?:new )?snow`),
+		"ice":  regexp.MustCompile(`little or no (?:new )?ice`),
+	}
+
+	precipQuantity = `(\d+(?:\.\d+)?|an?|one|two|three quarters|three|four|five|six|seven|eight|nine|ten|tenth|quarter|half)`
+
+	precipBetweenRegexp  = regexp.MustCompile(`between (?:an? )?` + precipQuantity + ` and (?:an? )?` + precipQuantity + ` (of an inch|inch(?:es)?|foot|feet)`)
+	precipToRegexp       = regexp.MustCompile(precipQuantity + ` to ` + precipQuantity + ` (of an inch|inch(?:es)?|foot|feet)`)
+	precipLessThanRegexp = regexp.MustCompile(`(?:less than|up to) (?:an? )?` + precipQuantity + ` (of an inch|inch(?:es)?|foot|feet)`)
+	precipAroundRegexp   = regexp.MustCompile(`(?:around|of) (?:an? )?` + precipQuantity + ` (of an inch|inch(?:es)?|foot|feet)`)
+
+	precipQuantityWords = map[string]float64{
+		"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+		"tenth": 0.1, "quarter": 0.25, "half": 0.5, "three quarters": 0.75,
+	}
+)
+
+// parsePrecipitationAmount returns the minimum and maximum expected amount in
+// inches of a kind of precipitation ("rain", "snow", or "ice") from a detailed
+// forecast. The returned ValueUnits have no unit if no amount is mentioned.
+func parsePrecipitationAmount(detailedForecast string, kind string) (ValueUnit, ValueUnit) {
+	text := strings.ToLower(detailedForecast)
+
+	if precipLittleOrNoRegexps[kind].MatchString(text) {
+		return ValueUnit{Value: 0, Unit: "in"}, ValueUnit{Value: 0, Unit: "in"}
+	}
+	m := precipAmountRegexps[kind].FindStringSubmatch(text)
+	if m == nil {
+		return ValueUnit{}, ValueUnit{}
+	}
+	phrase := m[1]
+
+	var min, max float64
+	var ok bool
+	if sm := precipBetweenRegexp.FindStringSubmatch(phrase); sm != nil {
+		min, max, ok = precipRange(sm[1], sm[2], sm[3])
+	} else if sm := precipToRegexp.FindStringSubmatch(phrase); sm != nil {
+		min, max, ok = precipRange(sm[1], sm[2], sm[3])
+	} else if sm := precipLessThanRegexp.FindStringSubmatch(phrase); sm != nil {
+		min, max, ok = precipRange("0", sm[1], sm[2])
+	} else if sm := precipAroundRegexp.FindStringSubmatch(phrase); sm != nil {
+		min, max, ok = precipRange(sm[1], sm[1], sm[2])
+	}
+	if !ok {
+		return ValueUnit{}, ValueUnit{}
+	}
+
+	return ValueUnit{Value: min, Unit: "in"}, ValueUnit{Value: max, Unit: "in"}
+}
+
+// precipRange converts a pair of quantities and their unit to a range in
+// inches.
+func precipRange(minString string, maxString string, unit string) (float64, float64, bool) {
+	min, ok := precipQuantityValue(minString)
+	if !ok {
+		return 0, 0, false
+	}
+	max, ok := precipQuantityValue(maxString)
+	if !ok {
+		return 0, 0, false
+	}
+	if unit == "foot" || unit == "feet" {
+		min, max = min*12, max*12
+	}
+	return min, max, true
+}
+
+// precipQuantityValue returns the value of a quantity, given as either a number
+// or words.
+func precipQuantityValue(s string) (float64, bool) {
+	if v, ok := precipQuantityWords[s]; ok {
+		return v, true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	getProductsOfTypeEndpointURLStringFmt            = "products/types/%s"              // type code
+	getProductsOfTypeForLocationEndpointURLStringFmt = "products/types/%s/locations/%s" // type code, location id
+	getProductEndpointURLStringFmt                   = "products/%s"                    // id
+)
+
+// A Product represents a text product (e.g. an Area Forecast Discussion or a
+// Tropical Cyclone Public Advisory) returned from the NWS API. Text is empty
+// when the product was retrieved as part of a list.
+type Product struct {
+	ID              string
+	Code            string // e.g. "AFD", "TCP"
+	Name            string
+	WMOCollectiveID string
+	IssuingOffice   string
+	TimeIssued      time.Time
+	Text            string
+}
+
+// getProductsOfType retrieves from the NWS API the list of recent products of
+// a type, newest first. The products do not include their text.
+func getProductsOfType(httpClient *http.Client, httpUserAgentString string, apiURLString string, typeCode string) ([]Product, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getProductsOfTypeEndpointURLStringFmt, typeCode),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newProductsFromProductsRespBody(respBody)
+}
+
+// getProductsOfTypeForLocation retrieves from the NWS API the list of recent
+// products of a type issued for a location, newest first. Locations are
+// usually three letters, such as "PQR" for an office or "PDX" for an airport.
+// The products do not include their text.
+func getProductsOfTypeForLocation(httpClient *http.Client, httpUserAgentString string, apiURLString string, typeCode string, locationID string) ([]Product, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getProductsOfTypeForLocationEndpointURLStringFmt, typeCode, locationID),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newProductsFromProductsRespBody(respBody)
+}
+
+// getProduct retrieves from the NWS API a single product including its text.
+func getProduct(httpClient *http.Client, httpUserAgentString string, apiURLString string, id string) (*Product, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getProductEndpointURLStringFmt, id),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newProductFromProductRespBody(respBody)
+}
+
+// productRaw is the structure of a product in NWS API responses.
+type productRaw struct {
+	ID              string
+	WMOCollectiveID string
+	IssuingOffice   string
+	IssuanceTime    string
+	ProductCode     string
+	ProductName     string
+	ProductText     string
+}
+
+// product returns a Product from the raw structure.
+func (pRaw productRaw) product() Product {
+	p := Product{
+		ID:              pRaw.ID,
+		Code:            pRaw.ProductCode,
+		Name:            pRaw.ProductName,
+		WMOCollectiveID: pRaw.WMOCollectiveID,
+		IssuingOffice:   pRaw.IssuingOffice,
+		Text:            pRaw.ProductText,
+	}
+	p.TimeIssued, _ = time.Parse(time.RFC3339, pRaw.IssuanceTime)
+	return p
+}
+
+// newProductsFromProductsRespBody returns a slice of Products, given a
+// response body from the NWS API.
+func newProductsFromProductsRespBody(respBody []byte) ([]Product, error) {
+	// unmarshal the body into a temporary struct
+	psRaw := struct {
+		Graph []productRaw `json:"@graph"`
+	}{}
+	if err := json.Unmarshal(respBody, &psRaw); err != nil {
+		return nil, err
+	}
+
+	var ps []Product
+	for _, pRaw := range psRaw.Graph {
+		if pRaw.ID == "" {
+			continue // skip if no id
+		}
+		ps = append(ps, pRaw.product())
+	}
+
+	return ps, nil
+}
+
+// newProductFromProductRespBody returns a Product pointer, given a response
+// body from the NWS API.
+func newProductFromProductRespBody(respBody []byte) (*Product, error) {
+	var pRaw productRaw
+	if err := json.Unmarshal(respBody, &pRaw); err != nil {
+		return nil, err
+	}
+	p := pRaw.product()
+	return &p, nil
+}
@@ -0,0 +1,294 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// A Contribution is the part of a sample falling within a bucket when a
+// TimeSeries is resampled.
+type Contribution[T any] struct {
+	Value T
+
+	// Overlap is the time the sample's range overlaps the bucket. It is zero
+	// for instantaneous samples.
+	Overlap time.Duration
+
+	// Fraction is the fraction of the sample's range within the bucket. It
+	// is 1 for instantaneous samples.
+	Fraction float64
+}
+
+// An Aggregator reduces the contributions of the samples falling in a bucket
+// to a single value. It is only called with at least one contribution.
+type Aggregator[T any] func(cs []Contribution[T]) T
+
+// AggregateMean is the mean of the values, weighted by their overlap with the
+// bucket. Instantaneous values are weighted equally. It suits values such as
+// temperature.
+func AggregateMean(cs []Contribution[float64]) float64 {
+	var sum, weight float64
+	for _, c := range cs {
+		w := c.Overlap.Hours()
+		if c.Overlap == 0 {
+			w = 1
+		}
+		sum += c.Value * w
+		weight += w
+	}
+	return sum / weight
+}
+
+// AggregateSum is the sum of the values, each prorated by the fraction of
+// its range within the bucket. It suits amounts such as precipitation.
+func AggregateSum(cs []Contribution[float64]) float64 {
+	var sum float64
+	for _, c := range cs {
+		sum += c.Value * c.Fraction
+	}
+	return sum
+}
+
+// AggregateMin is the least value.
+func AggregateMin(cs []Contribution[float64]) float64 {
+	min := math.Inf(1)
+	for _, c := range cs {
+		min = math.Min(min, c.Value)
+	}
+	return min
+}
+
+// AggregateMax is the greatest value.
+func AggregateMax(cs []Contribution[float64]) float64 {
+	max := math.Inf(-1)
+	for _, c := range cs {
+		max = math.Max(max, c.Value)
+	}
+	return max
+}
+
+// AggregateLast is the value of the last sample in the bucket.
+func AggregateLast[T any](cs []Contribution[T]) T {
+	return cs[len(cs)-1].Value
+}
+
+// ValueUnitAggregator adapts an Aggregator of float64 to ValueUnits. Values
+// are converted to the unit of the first contribution if they are
+// temperatures, speeds, or lengths; values that can't be converted are
+// omitted.
+func ValueUnitAggregator(agg Aggregator[float64]) Aggregator[ValueUnit] {
+	return func(cs []Contribution[ValueUnit]) ValueUnit {
+		unit := cs[0].Value.Unit
+		fcs := make([]Contribution[float64], 0, len(cs))
+		for _, c := range cs {
+			vu, err := convertValueUnit(c.Value, unit)
+			if err != nil {
+				continue
+			}
+			fcs = append(fcs, Contribution[float64]{Value: vu.Value, Overlap: c.Overlap, Fraction: c.Fraction})
+		}
+		return ValueUnit{Value: agg(fcs), Unit: unit}
+	}
+}
+
+// Resample returns the series aggregated into buckets of a fixed interval,
+// such as hourly or daily. Buckets are aligned to local midnight in the
+// location of the first sample's time. Buckets without samples are omitted,
+// leaving gaps; see FillGaps.
+//
+// Intervals that are a whole number of days step by calendar day, so a daily
+// bucket on a day when daylight saving time begins or ends is 23 or 25 hours
+// long. Shorter intervals step by elapsed time; they remain aligned to local
+// hours across a change if they divide an hour.
+//
+// A sample spanning several buckets, such as a six hour gridpoint value being
+// resampled hourly, contributes to each of them.
+func (ts TimeSeries[T]) Resample(interval time.Duration, agg Aggregator[T]) TimeSeries[T] {
+	r := TimeSeries[T]{Interval: interval}
+	if interval <= 0 || len(ts.Samples) == 0 {
+		return r
+	}
+
+	first := ts.Samples[0].Time
+	bStart := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, first.Location())
+	var end time.Time
+	for _, s := range ts.Samples {
+		se := s.TimeEnd()
+		if s.Duration == 0 {
+			se = s.Time.Add(time.Nanosecond) // so that the bucket containing it is included
+		}
+		if se.After(end) {
+			end = se
+		}
+	}
+
+	// samples are sorted by start, so the first sample that can contribute
+	// to a bucket only moves forward
+	var lo int
+	for bStart.Before(end) {
+		bEnd := nextBucketTime(bStart, interval)
+		var cs []Contribution[T]
+		for i := lo; i < len(ts.Samples) && ts.Samples[i].Time.Before(bEnd); i++ {
+			s := ts.Samples[i]
+			if s.Duration == 0 {
+				if !s.Time.Before(bStart) {
+					cs = append(cs, Contribution[T]{Value: s.Value, Fraction: 1})
+				}
+				continue
+			}
+			os, oe := s.Time, s.TimeEnd()
+			if !oe.After(bStart) {
+				continue
+			}
+			if os.Before(bStart) {
+				os = bStart
+			}
+			if oe.After(bEnd) {
+				oe = bEnd
+			}
+			cs = append(cs, Contribution[T]{Value: s.Value, Overlap: oe.Sub(os), Fraction: float64(oe.Sub(os)) / float64(s.Duration)})
+		}
+		for lo < len(ts.Samples) && !ts.Samples[lo].TimeEnd().After(bEnd) && ts.Samples[lo].Time.Before(bEnd) {
+			lo++
+		}
+		if len(cs) > 0 {
+			r.Samples = append(r.Samples, Sample[T]{Time: bStart, Duration: bEnd.Sub(bStart), Value: agg(cs)})
+		}
+		bStart = bEnd
+	}
+	return r
+}
+
+// Gap filling strategies for FillGaps.
+const (
+	GapFillPrevious = "previous" // repeat the previous value
+	GapFillZero     = "zero"     // use the zero value, e.g. no precipitation
+)
+
+// FillGaps returns the series with a sample in each missing interval between
+// its first and last samples, using a strategy. The series must have an
+// Interval, such as one returned by Resample; otherwise it is returned as is.
+// An error is returned if the strategy is not one of the GapFill constants.
+// See Interpolate for linear interpolation.
+func (ts TimeSeries[T]) FillGaps(strategy string) (TimeSeries[T], error) {
+	switch strategy {
+	case GapFillPrevious:
+		return fillGaps(ts, func(prev Sample[T], next Sample[T], t time.Time) T {
+			return prev.Value
+		}), nil
+	case GapFillZero:
+		return fillGaps(ts, func(prev Sample[T], next Sample[T], t time.Time) T {
+			var zero T
+			return zero
+		}), nil
+	}
+	return TimeSeries[T]{}, fmt.Errorf("unknown gap filling strategy: \"%s\"", strategy)
+}
+
+// Interpolate returns the series with a sample in each missing interval
+// between its first and last samples, linearly interpolated between the
+// samples on either side by lerp. See LerpFloat64 and LerpValueUnit.
+func Interpolate[T any](ts TimeSeries[T], lerp func(a T, b T, frac float64) T) TimeSeries[T] {
+	return fillGaps(ts, func(prev Sample[T], next Sample[T], t time.Time) T {
+		frac := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+		return lerp(prev.Value, next.Value, frac)
+	})
+}
+
+// LerpFloat64 linearly interpolates between two values.
+func LerpFloat64(a float64, b float64, frac float64) float64 {
+	return a + (b-a)*frac
+}
+
+// LerpValueUnit linearly interpolates between two values, converting b to
+// a's unit if possible.
+func LerpValueUnit(a ValueUnit, b ValueUnit, frac float64) ValueUnit {
+	if cb, err := convertValueUnit(b, a.Unit); err == nil {
+		b = cb
+	}
+	return ValueUnit{Value: a.Value + (b.Value-a.Value)*frac, Unit: a.Unit}
+}
+
+// fillGaps inserts a sample in each missing interval of a series, with the
+// value given by fill from the samples on either side of the gap.
+func fillGaps[T any](ts TimeSeries[T], fill func(prev Sample[T], next Sample[T], t time.Time) T) TimeSeries[T] {
+	if ts.Interval <= 0 || len(ts.Samples) < 2 {
+		return ts
+	}
+	f := TimeSeries[T]{Interval: ts.Interval, Samples: []Sample[T]{ts.Samples[0]}}
+	for _, next := range ts.Samples[1:] {
+		prev := f.Samples[len(f.Samples)-1]
+		for t := nextBucketTime(prev.Time, ts.Interval); t.Before(next.Time); t = nextBucketTime(t, ts.Interval) {
+			te := nextBucketTime(t, ts.Interval)
+			f.Samples = append(f.Samples, Sample[T]{Time: t, Duration: te.Sub(t), Value: fill(prev, next, t)})
+		}
+		f.Samples = append(f.Samples, next)
+	}
+	return f
+}
+
+// Align returns the samples of a for which b has a value, and b's value at
+// each of those samples' times (see TimeSeries.At), so that the two series
+// may be compared sample by sample. For example, aligning an hourly forecast
+// with observations pairs each forecast hour with the observation in effect at
+// its start.
+func Align[T any, U any](a TimeSeries[T], b TimeSeries[U]) (TimeSeries[T], TimeSeries[U]) {
+	aa := TimeSeries[T]{Interval: a.Interval}
+	ab := TimeSeries[U]{Interval: a.Interval}
+	for _, s := range a.Samples {
+		v, ok := b.At(s.Time)
+		if !ok {
+			continue
+		}
+		aa.Samples = append(aa.Samples, s)
+		ab.Samples = append(ab.Samples, Sample[U]{Time: s.Time, Duration: s.Duration, Value: v})
+	}
+	if len(aa.Samples) != len(a.Samples) {
+		aa.Interval, ab.Interval = 0, 0
+	}
+	return aa, ab
+}
+
+// Align is the same as the Align function, for series of the same type.
+func (ts TimeSeries[T]) Align(other TimeSeries[T]) (TimeSeries[T], TimeSeries[T]) {
+	return Align(ts, other)
+}
+
+// nextBucketTime returns the start of the bucket after the one starting at t.
+// Intervals that are a whole number of days step by calendar day.
+func nextBucketTime(t time.Time, interval time.Duration) time.Time {
+	if interval%(24*time.Hour) == 0 {
+		return t.AddDate(0, 0, int(interval/(24*time.Hour)))
+	}
+	return t.Add(interval)
+}
+
+// convertValueUnit returns a temperature, speed, or length converted to
+// another unit, or the value as is if the units are the same.
+func convertValueUnit(vu ValueUnit, unit string) (ValueUnit, error) {
+	if vu.Unit == unit {
+		return vu, nil
+	}
+	if vu, err := convertTemperature(vu, unit); err == nil {
+		return vu, nil
+	}
+	if vu, err := convertSpeed(vu, unit); err == nil {
+		return vu, nil
+	}
+	return convertLength(vu, unit)
+}
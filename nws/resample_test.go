@@ -0,0 +1,281 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"testing"
+	"time"
+)
+
+// resampleTestLocation observes daylight saving time, which begins on March
+// 10, 2019 and ends on November 3, 2019.
+func resampleTestLocation(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip(err)
+	}
+	return loc
+}
+
+// hourlySeries returns a series of n hourly samples of 1 starting at start.
+func hourlySeries(start time.Time, n int) TimeSeries[float64] {
+	samples := make([]Sample[float64], n)
+	for i := range samples {
+		samples[i] = Sample[float64]{Time: start.Add(time.Duration(i) * time.Hour), Duration: time.Hour, Value: 1}
+	}
+	return NewTimeSeries(samples)
+}
+
+// layerSeries returns the series for gridpoint values given as API valid
+// times, such as "2019-03-10T08:00:00+00:00/PT3H", in loc.
+func layerSeries(t *testing.T, loc *time.Location, values map[string]float64) TimeSeries[float64] {
+	t.Helper()
+	var l GridpointLayer
+	for validTime, v := range values {
+		start, d, err := parseValidTime(validTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Values = append(l.Values, GridpointValue{TimeStart: start.In(loc), Duration: d, Value: v})
+	}
+	return l.Series()
+}
+
+func TestTimeSeriesResampleDaily(t *testing.T) {
+	loc := resampleTestLocation(t)
+	tests := []struct {
+		name string
+		day  time.Time // local midnight of the day before the change
+		want []time.Duration
+	}{
+		{"spring forward", time.Date(2019, 3, 9, 0, 0, 0, 0, loc), []time.Duration{24 * time.Hour, 23 * time.Hour, 24 * time.Hour}},
+		{"fall back", time.Date(2019, 11, 2, 0, 0, 0, 0, loc), []time.Duration{24 * time.Hour, 25 * time.Hour, 24 * time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := tt.day.AddDate(0, 0, 3)
+			r := hourlySeries(tt.day, int(end.Sub(tt.day)/time.Hour)).Resample(24*time.Hour, AggregateSum)
+			if len(r.Samples) != len(tt.want) {
+				t.Fatalf("got %d samples; want %d", len(r.Samples), len(tt.want))
+			}
+			for i, s := range r.Samples {
+				if want := tt.day.AddDate(0, 0, i); !s.Time.Equal(want) {
+					t.Errorf("sample %d: got time %v; want %v", i, s.Time, want)
+				}
+				if s.Duration != tt.want[i] || s.Value != tt.want[i].Hours() {
+					t.Errorf("sample %d: got %v, %v; want %v, %v", i, s.Duration, s.Value, tt.want[i], tt.want[i].Hours())
+				}
+			}
+		})
+	}
+}
+
+func TestTimeSeriesResampleHourly(t *testing.T) {
+	loc := resampleTestLocation(t)
+	tests := []struct {
+		name  string
+		day   time.Time
+		hours int // in the local day
+	}{
+		{"spring forward", time.Date(2019, 3, 10, 0, 0, 0, 0, loc), 23},
+		{"fall back", time.Date(2019, 11, 3, 0, 0, 0, 0, loc), 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// half hourly samples
+			var samples []Sample[float64]
+			for ti := tt.day; ti.Before(tt.day.AddDate(0, 0, 1)); ti = ti.Add(30 * time.Minute) {
+				samples = append(samples, Sample[float64]{Time: ti, Duration: 30 * time.Minute, Value: 1})
+			}
+			r := NewTimeSeries(samples).Resample(time.Hour, AggregateSum)
+			if len(r.Samples) != tt.hours {
+				t.Fatalf("got %d samples; want %d", len(r.Samples), tt.hours)
+			}
+			for i, s := range r.Samples {
+				if s.Time.Minute() != 0 || s.Duration != time.Hour || s.Value != 2 {
+					t.Errorf("sample %d: got %v, %v, %v; want on the hour, 1h0m0s, 2", i, s.Time, s.Duration, s.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeSeriesResampleMixedDurations(t *testing.T) {
+	loc := resampleTestLocation(t)
+
+	// PT1H, PT3H, and PT6H values on the morning daylight saving time
+	// begins, which is at 10:00 UTC
+	ts := layerSeries(t, loc, map[string]float64{
+		"2019-03-10T08:00:00+00:00/PT1H": 1,
+		"2019-03-10T09:00:00+00:00/PT3H": 3,
+		"2019-03-10T12:00:00+00:00/PT6H": 12,
+	})
+	start := time.Date(2019, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		agg  Aggregator[float64]
+		want []float64
+	}{
+		{"sum", AggregateSum, []float64{1, 1, 1, 1, 2, 2, 2, 2, 2, 2}},
+		{"mean", AggregateMean, []float64{1, 3, 3, 3, 12, 12, 12, 12, 12, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ts.Resample(time.Hour, tt.agg)
+			if len(r.Samples) != len(tt.want) {
+				t.Fatalf("got %d samples; want %d", len(r.Samples), len(tt.want))
+			}
+			for i, s := range r.Samples {
+				if want := start.Add(time.Duration(i) * time.Hour); !s.Time.Equal(want) || s.Value != tt.want[i] {
+					t.Errorf("sample %d: got %v at %v; want %v at %v", i, s.Value, s.Time, tt.want[i], want)
+				}
+			}
+		})
+	}
+
+	// a 3 hour interval splits the PT1H, PT3H, and PT6H values unevenly
+	r := ts.Resample(3*time.Hour, AggregateSum)
+	want := []float64{3, 5, 6, 2}
+	if len(r.Samples) != len(want) {
+		t.Fatalf("3h: got %d samples; want %d", len(r.Samples), len(want))
+	}
+	for i, s := range r.Samples {
+		if s.Value != want[i] {
+			t.Errorf("3h sample %d: got %v; want %v", i, s.Value, want[i])
+		}
+	}
+}
+
+func TestTimeSeriesFillGaps(t *testing.T) {
+	loc := resampleTestLocation(t)
+	spring := time.Date(2019, 3, 10, 0, 0, 0, 0, loc)
+	fall := time.Date(2019, 11, 3, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		ts       TimeSeries[float64]
+		strategy string
+		want     []Sample[float64]
+	}{
+		{
+			name: "hourly across spring forward",
+			ts: TimeSeries[float64]{Interval: time.Hour, Samples: []Sample[float64]{
+				{Time: spring.Add(time.Hour), Duration: time.Hour, Value: 5},
+				{Time: spring.Add(4 * time.Hour), Duration: time.Hour, Value: 8},
+			}},
+			strategy: GapFillPrevious,
+			want: []Sample[float64]{
+				{Time: spring.Add(time.Hour), Duration: time.Hour, Value: 5},
+				{Time: spring.Add(2 * time.Hour), Duration: time.Hour, Value: 5},
+				{Time: spring.Add(3 * time.Hour), Duration: time.Hour, Value: 5},
+				{Time: spring.Add(4 * time.Hour), Duration: time.Hour, Value: 8},
+			},
+		},
+		{
+			name: "daily across spring forward",
+			ts: TimeSeries[float64]{Interval: 24 * time.Hour, Samples: []Sample[float64]{
+				{Time: spring.AddDate(0, 0, -1), Duration: 24 * time.Hour, Value: 5},
+				{Time: spring.AddDate(0, 0, 1), Duration: 24 * time.Hour, Value: 8},
+			}},
+			strategy: GapFillZero,
+			want: []Sample[float64]{
+				{Time: spring.AddDate(0, 0, -1), Duration: 24 * time.Hour, Value: 5},
+				{Time: spring, Duration: 23 * time.Hour, Value: 0},
+				{Time: spring.AddDate(0, 0, 1), Duration: 24 * time.Hour, Value: 8},
+			},
+		},
+		{
+			name: "daily across fall back",
+			ts: TimeSeries[float64]{Interval: 24 * time.Hour, Samples: []Sample[float64]{
+				{Time: fall.AddDate(0, 0, -1), Duration: 24 * time.Hour, Value: 5},
+				{Time: fall.AddDate(0, 0, 1), Duration: 24 * time.Hour, Value: 8},
+			}},
+			strategy: GapFillPrevious,
+			want: []Sample[float64]{
+				{Time: fall.AddDate(0, 0, -1), Duration: 24 * time.Hour, Value: 5},
+				{Time: fall, Duration: 25 * time.Hour, Value: 5},
+				{Time: fall.AddDate(0, 0, 1), Duration: 24 * time.Hour, Value: 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ts.FillGaps(tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Samples) != len(tt.want) {
+				t.Fatalf("got %d samples; want %d", len(got.Samples), len(tt.want))
+			}
+			for i, s := range got.Samples {
+				w := tt.want[i]
+				if !s.Time.Equal(w.Time) || s.Duration != w.Duration || s.Value != w.Value {
+					t.Errorf("sample %d: got %v; want %v", i, s, w)
+				}
+			}
+		})
+	}
+
+	if _, err := tests[0].ts.FillGaps("linear"); err == nil {
+		t.Error("unknown strategy: got no error")
+	}
+}
+
+func TestAlign(t *testing.T) {
+	loc := resampleTestLocation(t)
+
+	// hourly values through the repeated hour when daylight saving time
+	// ends, at 08:00 and 09:00 UTC, and observations every 30 minutes
+	// starting an hour before them
+	fall := time.Date(2019, 11, 3, 0, 0, 0, 0, loc)
+	hourly := hourlySeries(fall, 4)
+	var obs []Sample[float64]
+	for i, m := range []int{0, 30, 60, 90, 120, 150} {
+		obs = append(obs, Sample[float64]{Time: fall.Add(time.Duration(m) * time.Minute), Value: float64(i)})
+	}
+	observations := NewTimeSeries(obs)
+	observations.Samples = append([]Sample[float64]{{Time: fall.Add(-time.Hour), Value: -1}}, observations.Samples...)
+
+	tests := []struct {
+		name     string
+		a        TimeSeries[float64]
+		b        TimeSeries[float64]
+		wantLen  int
+		wantB    []float64
+		interval time.Duration
+	}{
+		{"forecast with observations", hourly, observations, 4, []float64{0, 2, 4, 5}, time.Hour},
+		{"observations with forecast", observations, hourly, 6, []float64{1, 1, 1, 1, 1, 1}, 0},
+		{"forecast with itself", hourly, hourly, 4, []float64{1, 1, 1, 1}, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aa, ab := Align(tt.a, tt.b)
+			if len(aa.Samples) != tt.wantLen || len(ab.Samples) != tt.wantLen {
+				t.Fatalf("got %d and %d samples; want %d", len(aa.Samples), len(ab.Samples), tt.wantLen)
+			}
+			for i := range ab.Samples {
+				if !aa.Samples[i].Time.Equal(ab.Samples[i].Time) || ab.Samples[i].Value != tt.wantB[i] {
+					t.Errorf("sample %d: got %v at %v; want %v at %v", i, ab.Samples[i].Value, ab.Samples[i].Time, tt.wantB[i], aa.Samples[i].Time)
+				}
+			}
+			if aa.Interval != tt.interval || ab.Interval != tt.interval {
+				t.Errorf("got intervals %v and %v; want %v", aa.Interval, ab.Interval, tt.interval)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxResourceBytes limits the size of a single alert resource.
+const maxResourceBytes = 8 << 20
+
+// allowedResourceMIMETypePrefixes are the content types that alert resources
+// may have.
+var allowedResourceMIMETypePrefixes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"text/plain",
+	"application/pdf",
+}
+
+// An AlertResource represents a CAP resource (image, audio, etc.) referenced by
+// an alert.
+type AlertResource struct {
+	Description string
+	MIMEType    string
+	Size        int    // approximate size in bytes, zero if not given
+	URI         string // empty if the resource was only provided inline
+	Digest      string // SHA-1 hash, if given
+
+	Data []byte // nil unless retrieved or provided inline
+}
+
+// newAlertResourcesFromCAP returns the resources referenced by the info block
+// of a CAP document in the default language.
+func newAlertResourcesFromCAP(doc *capAlertRaw) []AlertResource {
+	var rs []AlertResource
+	i := doc.defaultInfoIndex()
+	if i < 0 {
+		return nil
+	}
+	for _, rRaw := range doc.Info[i].Resources {
+		r := AlertResource{
+			Description: rRaw.ResourceDesc,
+			MIMEType:    strings.TrimSpace(rRaw.MIMEType),
+			URI:         strings.TrimSpace(rRaw.URI),
+			Digest:      strings.TrimSpace(rRaw.Digest),
+		}
+		r.Size, _ = strconv.Atoi(strings.TrimSpace(rRaw.Size))
+		// inline data that can't be decoded is ignored; the URI may still work
+		_ = decodeAlertResourceDerefURI(&r, rRaw.DerefURI)
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// decodeAlertResourceDerefURI decodes the base64 inline data of a resource
+// (the CAP derefUri element), subject to a size limit and MIME type
+// validation. The data is stored in the resource. Nothing is done if
+// derefURI is empty.
+func decodeAlertResourceDerefURI(r *AlertResource, derefURI string) error {
+	derefURI = strings.Join(strings.Fields(derefURI), "") // may be wrapped
+	if derefURI == "" {
+		return nil
+	}
+	if !isAllowedResourceMIMEType(r.MIMEType) {
+		return fmt.Errorf("resource MIME type is not allowed: %s", r.MIMEType)
+	}
+	if base64.StdEncoding.DecodedLen(len(derefURI)) > maxResourceBytes+2 {
+		return fmt.Errorf("resource exceeds %d bytes", maxResourceBytes)
+	}
+	data, err := base64.StdEncoding.DecodeString(derefURI)
+	if err != nil {
+		return fmt.Errorf("resource has invalid inline data: %s", err)
+	}
+	if len(data) > maxResourceBytes {
+		return fmt.Errorf("resource exceeds %d bytes", maxResourceBytes)
+	}
+	r.Data = data
+	return nil
+}
+
+// AlertResources retrieves the CAP document for an alert and returns the
+// resources that it references, without retrieving their data. Resources
+// provided inline are decoded. Use FetchAlertResource to retrieve the data
+// for the others. Unlike FetchAlertResources, this may be used for any alert.
+func (c *Client) AlertResources(id string) ([]AlertResource, error) {
+	doc, err := getCAPForAlert(c.httpClient, c.httpUserAgentString, c.apiURLString, id)
+	if err != nil {
+		return nil, err
+	}
+	return newAlertResourcesFromCAP(doc), nil
+}
+
+// FetchAlertResource retrieves the data for a resource, subject to the
+// Client's URLPolicy, an 8 MiB size limit, and MIME type validation: only
+// images, audio, video, plain text, and PDFs are retrieved, and the type
+// served must match the declared type. The data is stored in the resource.
+// Nothing is done if the resource already has data.
+func (c *Client) FetchAlertResource(r *AlertResource) error {
+	return fetchAlertResource(c.httpClient, c.httpUserAgentString, c.URLPolicy, r)
+}
+
+// IsImage reports whether the resource is an image.
+func (r AlertResource) IsImage() bool {
+	return strings.HasPrefix(strings.ToLower(r.MIMEType), "image/")
+}
+
+// IsAudio reports whether the resource is audio.
+func (r AlertResource) IsAudio() bool {
+	return strings.HasPrefix(strings.ToLower(r.MIMEType), "audio/")
+}
+
+// fetchAlertResource retrieves the data for a resource, subject to a URL
+// policy, a size limit, and content type validation. The data is stored in the
+// resource. Nothing is done if the resource already has data.
+func fetchAlertResource(httpClient *http.Client, httpUserAgentString string, policy URLPolicy, r *AlertResource) error {
+	if r.Data != nil {
+		return nil
+	}
+	if r.URI == "" {
+		return fmt.Errorf("resource has no URI: %s", r.Description)
+	}
+	u, err := policy.Check(r.URI)
+	if err != nil {
+		return err
+	}
+	if r.MIMEType != "" && !isAllowedResourceMIMEType(r.MIMEType) {
+		return fmt.Errorf("resource MIME type is not allowed: %s", r.MIMEType)
+	}
+	if r.Size > maxResourceBytes {
+		return fmt.Errorf("resource size %d exceeds %d bytes", r.Size, maxResourceBytes)
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", httpUserAgentString)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("%s: %s", resp.Status, u.String())
+	}
+
+	// the content type must be allowed and agree with the declared type
+	ct, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("resource has invalid content type: %s", err)
+	}
+	if !isAllowedResourceMIMEType(ct) {
+		return fmt.Errorf("resource content type is not allowed: %s", ct)
+	}
+	if r.MIMEType != "" && !strings.EqualFold(ct, r.MIMEType) {
+		return fmt.Errorf("resource content type \"%s\" does not match declared type \"%s\"", ct, r.MIMEType)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResourceBytes+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxResourceBytes {
+		return fmt.Errorf("resource exceeds %d bytes", maxResourceBytes)
+	}
+
+	r.Data = data
+	return nil
+}
+
+// isAllowedResourceMIMEType reports whether resources of a MIME type may be
+// retrieved.
+func isAllowedResourceMIMEType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	for _, prefix := range allowedResourceMIMETypePrefixes {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	return false
+}
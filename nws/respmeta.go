@@ -0,0 +1,86 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// A ResponseMeta holds metadata about the NWS API response that a result was
+// parsed from. The correlation and request IDs identify the request to NOAA
+// when reporting a problem with the API.
+type ResponseMeta struct {
+	CorrelationID string // X-Correlation-ID
+	RequestID     string // X-Request-ID
+	ServerID      string // X-Server-ID
+	Server        string
+
+	// TimeGenerated is when the API generated the response (generatedAt).
+	// Only some endpoints (e.g. forecasts) provide it.
+	TimeGenerated time.Time
+
+	Date         time.Time
+	LastModified time.Time
+	Expires      time.Time
+	CacheControl string
+	ETag         string
+}
+
+// doAPIRequestWithMeta is the same as doAPIRequest, but also returns the
+// response's metadata.
+func doAPIRequestWithMeta(httpClient *http.Client, httpUserAgentString string, apiURLString string, endpoint string, query url.Values) ([]byte, *ResponseMeta, error) {
+	respBody, header, _, err := doAPIRequestWithHeader(httpClient, httpUserAgentString, apiURLString, endpoint, query, "", validators{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return respBody, newResponseMeta(header, respBody), nil
+}
+
+// newResponseMeta returns the metadata of a response given its header and
+// body. Headers that are missing or malformed are left empty.
+func newResponseMeta(header http.Header, respBody []byte) *ResponseMeta {
+	m := &ResponseMeta{
+		CorrelationID: header.Get("X-Correlation-Id"),
+		RequestID:     header.Get("X-Request-Id"),
+		ServerID:      header.Get("X-Server-Id"),
+		Server:        header.Get("Server"),
+		CacheControl:  header.Get("Cache-Control"),
+		ETag:          header.Get("ETag"),
+	}
+	m.Date, _ = http.ParseTime(header.Get("Date"))
+	m.LastModified, _ = http.ParseTime(header.Get("Last-Modified"))
+	m.Expires, _ = http.ParseTime(header.Get("Expires"))
+
+	// generatedAt is a property of GeoJSON responses and a top level member of
+	// JSON-LD responses
+	genRaw := struct {
+		GeneratedAt string
+		Properties  struct {
+			GeneratedAt string
+		}
+	}{}
+	if json.Unmarshal(respBody, &genRaw) == nil {
+		s := genRaw.Properties.GeneratedAt
+		if s == "" {
+			s = genRaw.GeneratedAt
+		}
+		m.TimeGenerated, _ = time.Parse(time.RFC3339, s)
+	}
+
+	return m
+}
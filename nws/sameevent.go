@@ -0,0 +1,162 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"sort"
+	"strings"
+)
+
+// SAME event categories
+const (
+	SAMECategoryAdministrative = "administrative" // tests and network messages
+	SAMECategoryCivil          = "civil"          // civil authority and law enforcement
+	SAMECategoryConvective     = "convective"     // thunderstorms and tornadoes
+	SAMECategoryFire           = "fire"
+	SAMECategoryFlood          = "flood"
+	SAMECategoryGeophysical    = "geophysical" // earthquakes, tsunamis, volcanoes, avalanches
+	SAMECategoryMarine         = "marine"      // marine and coastal
+	SAMECategoryTropical       = "tropical"
+	SAMECategoryWind           = "wind" // wind and dust
+	SAMECategoryWinter         = "winter"
+	SAMECategoryOther          = "other"
+)
+
+// SAME event types, in order of increasing rank
+const (
+	SAMETypeTest      = "test"
+	SAMETypeStatement = "statement" // including advisories and messages
+	SAMETypeWatch     = "watch"
+	SAMETypeWarning   = "warning"
+	SAMETypeEmergency = "emergency"
+)
+
+// sameTypeRanks are the ranks of the SAME event types.
+var sameTypeRanks = map[string]int{
+	SAMETypeTest:      0,
+	SAMETypeStatement: 1,
+	SAMETypeWatch:     2,
+	SAMETypeWarning:   3,
+	SAMETypeEmergency: 4,
+}
+
+// A SAMEEvent describes a SAME (EAS) event code, such as "TOR" for a tornado
+// warning. Rank orders events by how urgently they should be presented (e.g.
+// whether to sound a siren): emergencies rank above warnings, which rank
+// above watches, statements, and tests.
+type SAMEEvent struct {
+	Code     string // e.g. "TOR"
+	Name     string // e.g. "Tornado Warning"
+	Category string // one of SAMECategoryAdministrative etc.
+	Type     string // one of SAMETypeTest etc.
+	Rank     int
+}
+
+// sameEvents are the SAME event codes defined in 47 CFR 11.31, plus the snow
+// squall warning used by the NWS, keyed by code.
+var sameEvents = map[string]SAMEEvent{}
+
+func init() {
+	for _, e := range []SAMEEvent{
+		{Code: "EAN", Name: "Emergency Action Notification", Category: SAMECategoryCivil, Type: SAMETypeEmergency},
+		{Code: "NPT", Name: "National Periodic Test", Category: SAMECategoryAdministrative, Type: SAMETypeTest},
+		{Code: "RMT", Name: "Required Monthly Test", Category: SAMECategoryAdministrative, Type: SAMETypeTest},
+		{Code: "RWT", Name: "Required Weekly Test", Category: SAMECategoryAdministrative, Type: SAMETypeTest},
+		{Code: "DMO", Name: "Practice/Demo Warning", Category: SAMECategoryAdministrative, Type: SAMETypeTest},
+		{Code: "ADR", Name: "Administrative Message", Category: SAMECategoryAdministrative, Type: SAMETypeStatement},
+		{Code: "NMN", Name: "Network Message Notification", Category: SAMECategoryAdministrative, Type: SAMETypeStatement},
+		{Code: "AVA", Name: "Avalanche Watch", Category: SAMECategoryGeophysical, Type: SAMETypeWatch},
+		{Code: "AVW", Name: "Avalanche Warning", Category: SAMECategoryGeophysical, Type: SAMETypeWarning},
+		{Code: "BLU", Name: "Blue Alert", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "BZW", Name: "Blizzard Warning", Category: SAMECategoryWinter, Type: SAMETypeWarning},
+		{Code: "CAE", Name: "Child Abduction Emergency", Category: SAMECategoryCivil, Type: SAMETypeEmergency},
+		{Code: "CDW", Name: "Civil Danger Warning", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "CEM", Name: "Civil Emergency Message", Category: SAMECategoryCivil, Type: SAMETypeEmergency},
+		{Code: "CFA", Name: "Coastal Flood Watch", Category: SAMECategoryMarine, Type: SAMETypeWatch},
+		{Code: "CFW", Name: "Coastal Flood Warning", Category: SAMECategoryMarine, Type: SAMETypeWarning},
+		{Code: "DSW", Name: "Dust Storm Warning", Category: SAMECategoryWind, Type: SAMETypeWarning},
+		{Code: "EQW", Name: "Earthquake Warning", Category: SAMECategoryGeophysical, Type: SAMETypeWarning},
+		{Code: "EVI", Name: "Evacuation Immediate", Category: SAMECategoryCivil, Type: SAMETypeEmergency},
+		{Code: "EWW", Name: "Extreme Wind Warning", Category: SAMECategoryWind, Type: SAMETypeWarning},
+		{Code: "FFA", Name: "Flash Flood Watch", Category: SAMECategoryFlood, Type: SAMETypeWatch},
+		{Code: "FFS", Name: "Flash Flood Statement", Category: SAMECategoryFlood, Type: SAMETypeStatement},
+		{Code: "FFW", Name: "Flash Flood Warning", Category: SAMECategoryFlood, Type: SAMETypeWarning},
+		{Code: "FLA", Name: "Flood Watch", Category: SAMECategoryFlood, Type: SAMETypeWatch},
+		{Code: "FLS", Name: "Flood Statement", Category: SAMECategoryFlood, Type: SAMETypeStatement},
+		{Code: "FLW", Name: "Flood Warning", Category: SAMECategoryFlood, Type: SAMETypeWarning},
+		{Code: "FRW", Name: "Fire Warning", Category: SAMECategoryFire, Type: SAMETypeWarning},
+		{Code: "HLS", Name: "Hurricane Statement", Category: SAMECategoryTropical, Type: SAMETypeStatement},
+		{Code: "HMW", Name: "Hazardous Materials Warning", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "HUA", Name: "Hurricane Watch", Category: SAMECategoryTropical, Type: SAMETypeWatch},
+		{Code: "HUW", Name: "Hurricane Warning", Category: SAMECategoryTropical, Type: SAMETypeWarning},
+		{Code: "HWA", Name: "High Wind Watch", Category: SAMECategoryWind, Type: SAMETypeWatch},
+		{Code: "HWW", Name: "High Wind Warning", Category: SAMECategoryWind, Type: SAMETypeWarning},
+		{Code: "LAE", Name: "Local Area Emergency", Category: SAMECategoryCivil, Type: SAMETypeEmergency},
+		{Code: "LEW", Name: "Law Enforcement Warning", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "NUW", Name: "Nuclear Power Plant Warning", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "RHW", Name: "Radiological Hazard Warning", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "SMW", Name: "Special Marine Warning", Category: SAMECategoryMarine, Type: SAMETypeWarning},
+		{Code: "SPS", Name: "Special Weather Statement", Category: SAMECategoryOther, Type: SAMETypeStatement},
+		{Code: "SPW", Name: "Shelter in Place Warning", Category: SAMECategoryCivil, Type: SAMETypeWarning},
+		{Code: "SQW", Name: "Snow Squall Warning", Category: SAMECategoryWinter, Type: SAMETypeWarning},
+		{Code: "SSA", Name: "Storm Surge Watch", Category: SAMECategoryTropical, Type: SAMETypeWatch},
+		{Code: "SSW", Name: "Storm Surge Warning", Category: SAMECategoryTropical, Type: SAMETypeWarning},
+		{Code: "SVA", Name: "Severe Thunderstorm Watch", Category: SAMECategoryConvective, Type: SAMETypeWatch},
+		{Code: "SVR", Name: "Severe Thunderstorm Warning", Category: SAMECategoryConvective, Type: SAMETypeWarning},
+		{Code: "SVS", Name: "Severe Weather Statement", Category: SAMECategoryConvective, Type: SAMETypeStatement},
+		{Code: "TOA", Name: "Tornado Watch", Category: SAMECategoryConvective, Type: SAMETypeWatch},
+		{Code: "TOE", Name: "911 Telephone Outage Emergency", Category: SAMECategoryCivil, Type: SAMETypeEmergency},
+		{Code: "TOR", Name: "Tornado Warning", Category: SAMECategoryConvective, Type: SAMETypeWarning},
+		{Code: "TRA", Name: "Tropical Storm Watch", Category: SAMECategoryTropical, Type: SAMETypeWatch},
+		{Code: "TRW", Name: "Tropical Storm Warning", Category: SAMECategoryTropical, Type: SAMETypeWarning},
+		{Code: "TSA", Name: "Tsunami Watch", Category: SAMECategoryGeophysical, Type: SAMETypeWatch},
+		{Code: "TSW", Name: "Tsunami Warning", Category: SAMECategoryGeophysical, Type: SAMETypeWarning},
+		{Code: "VOW", Name: "Volcano Warning", Category: SAMECategoryGeophysical, Type: SAMETypeWarning},
+		{Code: "WSA", Name: "Winter Storm Watch", Category: SAMECategoryWinter, Type: SAMETypeWatch},
+		{Code: "WSW", Name: "Winter Storm Warning", Category: SAMECategoryWinter, Type: SAMETypeWarning},
+	} {
+		e.Rank = sameTypeRanks[e.Type]
+		sameEvents[e.Code] = e
+	}
+}
+
+// LookupSAMEEvent returns the SAMEEvent for a code (e.g. "TOR"). The second
+// return value is false if the code is unknown.
+func LookupSAMEEvent(code string) (SAMEEvent, bool) {
+	e, ok := sameEvents[strings.ToUpper(strings.TrimSpace(code))]
+	return e, ok
+}
+
+// SAMEEvents returns every known SAMEEvent, highest rank first and then by
+// code.
+func SAMEEvents() []SAMEEvent {
+	es := make([]SAMEEvent, 0, len(sameEvents))
+	for _, e := range sameEvents {
+		es = append(es, e)
+	}
+	sort.Slice(es, func(i, j int) bool {
+		if es[i].Rank != es[j].Rank {
+			return es[i].Rank > es[j].Rank
+		}
+		return es[i].Code < es[j].Code
+	})
+	return es
+}
+
+// SAMEEvent returns the SAMEEvent for the alert's EventCode. The second return
+// value is false if the alert has no event code or it is unknown.
+func (a Alert) SAMEEvent() (SAMEEvent, bool) {
+	return LookupSAMEEvent(a.EventCode)
+}
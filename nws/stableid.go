@@ -0,0 +1,74 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PeriodID returns the stable ID of a period of a forecast: a hash of the
+// forecast's source gridpoint, the period's name and time range, and the time
+// the forecast was issued. The same period fetched again before the forecast
+// is updated has the same ID, so it may be referenced without relying on its
+// position in Periods.
+//
+// Periods are given their IDs when the forecast is retrieved; PeriodID is
+// only needed for forecasts built by hand.
+func PeriodID(f Forecast, p Period) string {
+	return stableID(
+		"period",
+		f.Gridpoint.String(),
+		p.Name,
+		p.TimeStart.UTC().Format(time.RFC3339),
+		p.TimeEnd.UTC().Format(time.RFC3339),
+		f.TimeForecast.UTC().Format(time.RFC3339),
+		fmt.Sprint(p.Derived),
+	)
+}
+
+// StableID returns a stable ID for the alert. It is the alert's ID if it has
+// one, which the NWS assigns when the alert is issued. Otherwise, it is a hash
+// of the sender, event, effective and expiration times, and the time the
+// alert was sent.
+func (a Alert) StableID() string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return stableID(
+		"alert",
+		a.SenderID,
+		a.Event,
+		a.TimeEffective.UTC().Format(time.RFC3339),
+		a.TimeExpires.UTC().Format(time.RFC3339),
+		a.TimeSent.UTC().Format(time.RFC3339),
+	)
+}
+
+// setPeriodIDs sets the ID of each period of a forecast.
+func (f *Forecast) setPeriodIDs() {
+	for i := range f.Periods {
+		f.Periods[i].ID = PeriodID(*f, f.Periods[i])
+	}
+}
+
+// stableID returns a short hex encoded hash of parts.
+func stableID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
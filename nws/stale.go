@@ -0,0 +1,86 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import "time"
+
+// DefaultMaxObservationAge is the age beyond which a Client with RefetchStale
+// set considers an observation stale, if MaxObservationAge is zero. Most
+// stations report hourly.
+const DefaultMaxObservationAge = 90 * time.Minute
+
+// DefaultMaxForecastAge is the age beyond which a Client with RefetchStale set
+// considers a forecast stale, if MaxForecastAge is zero. Forecasts are
+// routinely issued twice a day and updated in between as needed.
+const DefaultMaxForecastAge = 6 * time.Hour
+
+// IsStale reports whether the observation was made more than maxAge ago. An
+// observation without an observed time is always stale.
+func (o Observation) IsStale(maxAge time.Duration) bool {
+	return o.isStaleAt(time.Now(), maxAge)
+}
+
+// isStaleAt reports whether the observation was made more than maxAge before
+// t.
+func (o Observation) isStaleAt(t time.Time, maxAge time.Duration) bool {
+	return o.TimeObserved.IsZero() || t.Sub(o.TimeObserved) > maxAge
+}
+
+// IsStale reports whether the forecast's first period has ended, or whether
+// the forecast was made more than maxAge ago. The forecast's age is ignored if
+// maxAge is zero or the time it was made is unknown. A forecast without
+// periods is always stale.
+func (f Forecast) IsStale(maxAge time.Duration) bool {
+	return f.isStaleAt(time.Now(), maxAge)
+}
+
+// isStaleAt reports whether the forecast is stale at t.
+func (f Forecast) isStaleAt(t time.Time, maxAge time.Duration) bool {
+	if len(f.Periods) < 1 || !t.Before(f.Periods[0].TimeEnd) {
+		return true
+	}
+	return maxAge > 0 && !f.TimeForecast.IsZero() && t.Sub(f.TimeForecast) > maxAge
+}
+
+// refetchIfStale calls update if RefetchStale is set, stale is true, and
+// throttle has elapsed since lastRetrieved. Errors are ignored since the
+// stale data are returned regardless.
+func (c *Client) refetchIfStale(stale bool, lastRetrieved time.Time, throttle time.Duration, update func() error) {
+	if !c.RefetchStale || !stale {
+		return
+	}
+	if !lastRetrieved.IsZero() && time.Since(lastRetrieved) < throttle {
+		return
+	}
+	_ = update()
+}
+
+// maxForecastAge returns MaxForecastAge, or DefaultMaxForecastAge if it is
+// zero.
+func (c *Client) maxForecastAge() time.Duration {
+	if c.MaxForecastAge > 0 {
+		return c.MaxForecastAge
+	}
+	return DefaultMaxForecastAge
+}
+
+// maxObservationAge returns MaxObservationAge, or DefaultMaxObservationAge if
+// it is zero.
+func (c *Client) maxObservationAge() time.Duration {
+	if c.MaxObservationAge > 0 {
+		return c.MaxObservationAge
+	}
+	return DefaultMaxObservationAge
+}
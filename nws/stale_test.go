@@ -0,0 +1,79 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForecastIsStaleAt(t *testing.T) {
+	made := time.Date(2019, 8, 30, 21, 0, 0, 0, time.UTC)
+	f := Forecast{
+		TimeForecast:  made,
+		TimeValid:     made,
+		ValidDuration: 7 * 24 * time.Hour,
+		Periods: []Period{
+			{TimeStart: made, TimeEnd: made.Add(4 * time.Hour)},
+			{TimeStart: made.Add(4 * time.Hour), TimeEnd: made.Add(16 * time.Hour)},
+		},
+	}
+	unmade := f
+	unmade.TimeForecast = time.Time{}
+
+	tests := []struct {
+		name   string
+		f      Forecast
+		t      time.Time
+		maxAge time.Duration
+		want   bool
+	}{
+		{"during first period", f, made.Add(time.Hour), 6 * time.Hour, false},
+		{"first period ended", f, made.Add(4 * time.Hour), 6 * time.Hour, true},
+		{"first period ended within valid times", f, made.Add(5 * time.Hour), 24 * time.Hour, true},
+		{"older than max age", f, made.Add(3 * time.Hour), 2 * time.Hour, true},
+		{"max age zero", f, made.Add(3 * time.Hour), 0, false},
+		{"time made unknown", unmade, made.Add(3 * time.Hour), time.Hour, false},
+		{"no periods", Forecast{TimeForecast: made}, made, 6 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.isStaleAt(tt.t, tt.maxAge); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObservationIsStaleAt(t *testing.T) {
+	observed := time.Date(2019, 8, 30, 20, 53, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		o    Observation
+		t    time.Time
+		want bool
+	}{
+		{"recent", Observation{TimeObserved: observed}, observed.Add(time.Hour), false},
+		{"old", Observation{TimeObserved: observed}, observed.Add(2 * time.Hour), true},
+		{"time observed unknown", Observation{}, observed, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.isStaleAt(tt.t, DefaultMaxObservationAge); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -59,7 +59,7 @@ func newStationsFromStationsRespBody(respBody []byte) ([]Station, error) {
 	stnsRaw := struct {
 		Features []struct {
 			Geometry struct {
-				Coordinates []string // lon, lat (annoying)
+				Coordinates []json.Number // lon, lat (annoying)
 			}
 			Properties struct {
 				StationIdentifier string // callsign
@@ -83,8 +83,8 @@ func newStationsFromStationsRespBody(respBody []byte) ([]Station, error) {
 			Name: sRaw.Properties.Name,
 		}
 		if len(sRaw.Geometry.Coordinates) == 2 {
-			s.Point.Lat, _ = strconv.ParseFloat(sRaw.Geometry.Coordinates[1], 64)
-			s.Point.Lon, _ = strconv.ParseFloat(sRaw.Geometry.Coordinates[0], 64)
+			s.Point.Lat, _ = strconv.ParseFloat(string(sRaw.Geometry.Coordinates[1]), 64)
+			s.Point.Lon, _ = strconv.ParseFloat(string(sRaw.Geometry.Coordinates[0]), 64)
 		}
 		stns = append(stns, s)
 	}
@@ -0,0 +1,166 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"time"
+)
+
+// lengthsInMeters contains the value of one unit of each supported length unit
+// in meters.
+var lengthsInMeters = map[string]float64{
+	"m":  1,
+	"cm": 0.01,
+	"mm": 0.001,
+	"in": 0.0254,
+}
+
+// stormLayers names the gridpoint layer holding forecast amounts of each kind
+// of precipitation.
+var stormLayers = map[string]string{
+	"rain": "quantitativePrecipitation",
+	"snow": "snowfallAmount",
+	"ice":  "iceAccumulation",
+}
+
+// StormProgress compares the precipitation observed so far during a storm
+// with the total forecast for it. Amounts are in inches.
+type StormProgress struct {
+	Kind         string // "rain", "snow", or "ice"
+	TimeStart    time.Time
+	TimeObserved time.Time // time of the latest observation counted
+	Observed     ValueUnit
+	ForecastMin  ValueUnit // no unit if no total was forecast
+	ForecastMax  ValueUnit // no unit if no total was forecast
+}
+
+// String returns a human readable summary of the progress (e.g. `4.2" of
+// forecast 8–12" so far`).
+func (p StormProgress) String() string {
+	obs := fmt.Sprintf("%.1f\"", p.Observed.Value)
+	switch {
+	case p.ForecastMax.Unit == "":
+		return obs + " so far"
+	case p.ForecastMin.Value == p.ForecastMax.Value:
+		return fmt.Sprintf("%s of forecast %g\" so far", obs, p.ForecastMax.Value)
+	}
+	return fmt.Sprintf("%s of forecast %g–%g\" so far", obs, p.ForecastMin.Value, p.ForecastMax.Value)
+}
+
+// StormTotal returns the minimum and maximum total amount in inches of a kind
+// of precipitation ("rain", "snow", or "ice") forecast in the detailed
+// forecasts of the periods overlapping start and end. It is intended for use
+// with the semidaily forecast. The returned ValueUnits have no unit if no
+// period mentions an amount.
+func (f Forecast) StormTotal(kind string, start time.Time, end time.Time) (ValueUnit, ValueUnit) {
+	var min, max ValueUnit
+	for _, p := range f.PeriodsBetween(start, end) {
+		var pMin, pMax ValueUnit
+		switch kind {
+		case "rain":
+			pMin, pMax = p.RainfallAmountMin, p.RainfallAmountMax
+		case "snow":
+			pMin, pMax = p.SnowAmountMin, p.SnowAmountMax
+		case "ice":
+			pMin, pMax = p.IceAmountMin, p.IceAmountMax
+		}
+		if pMax.Unit == "" {
+			continue
+		}
+		min = ValueUnit{Value: min.Value + pMin.Value, Unit: "in"}
+		max = ValueUnit{Value: max.Value + pMax.Value, Unit: "in"}
+	}
+	return min, max
+}
+
+// Total returns the total in inches of a gridpoint layer of amounts, such
+// as "quantitativePrecipitation" or "snowfallAmount", between start and end.
+// Values only partly within the window are prorated. A layer without values
+// totals zero.
+func (l GridpointLayer) Total(start time.Time, end time.Time) (ValueUnit, error) {
+	if len(l.Values) == 0 {
+		return ValueUnit{Value: 0, Unit: "in"}, nil
+	}
+	return convertLength(ValueUnit{Value: SumBetween(l.Series(), start, end), Unit: l.Unit}, "in")
+}
+
+// GridpointStormTotal retrieves the raw gridpoint forecast for the Client's
+// point and returns the total amount in inches of a kind of precipitation
+// ("rain", "snow", or "ice") forecast between start and end. Rain is the total
+// liquid precipitation, including any that falls as snow or ice.
+func (c *Client) GridpointStormTotal(kind string, start time.Time, end time.Time) (ValueUnit, error) {
+	name, ok := stormLayers[kind]
+	if !ok {
+		return ValueUnit{}, fmt.Errorf("unsupported precipitation kind: \"%s\"", kind)
+	}
+	layers, err := getGridpointLayersForGridpoint(c.httpClient, c.httpUserAgentString, c.apiURLString, c.gridpoint, []string{name})
+	if err != nil {
+		return ValueUnit{}, err
+	}
+	l, ok := layers[name]
+	if !ok {
+		return ValueUnit{}, fmt.Errorf("gridpoint has no \"%s\" layer", name)
+	}
+	return l.Total(start, end)
+}
+
+// ObservedPrecipitation returns the liquid precipitation in inches reported
+// by a station's hourly observations between start and end, and the time of
+// the latest observation counted.
+//
+// Routine reports give the precipitation since the previous routine report,
+// shortly before the hour; special reports in between give a partial amount.
+// The largest amount reported in each hour is counted.
+func ObservedPrecipitation(observations []Observation, start time.Time, end time.Time) (ValueUnit, time.Time) {
+	ts := ObservationSeries(observations, func(o Observation) ValueUnit { return o.PrecipitationLastHour })
+
+	// routine reports are made around ten minutes before the hour
+	hourly := make(map[int64]float64)
+	var latest time.Time
+	for _, s := range ts.Samples {
+		if !s.Time.After(start) || s.Time.After(end) {
+			continue
+		}
+		in, err := convertLength(s.Value, "in")
+		if err != nil {
+			continue
+		}
+		h := s.Time.Add(10 * time.Minute).Truncate(time.Hour).Unix()
+		if in.Value > hourly[h] {
+			hourly[h] = in.Value
+		}
+		latest = s.Time
+	}
+
+	var total float64
+	for _, v := range hourly {
+		total += v
+	}
+	return ValueUnit{Value: total, Unit: "in"}, latest
+}
+
+// convertLength returns a length converted to another unit.
+func convertLength(vu ValueUnit, unit string) (ValueUnit, error) {
+	from, ok := lengthsInMeters[vu.Unit]
+	if !ok {
+		return ValueUnit{}, fmt.Errorf("unsupported length unit: \"%s\"", vu.Unit)
+	}
+	to, ok := lengthsInMeters[unit]
+	if !ok {
+		return ValueUnit{}, fmt.Errorf("unsupported length unit: \"%s\"", unit)
+	}
+	return ValueUnit{Value: vu.Value * from / to, Unit: unit}, nil
+}
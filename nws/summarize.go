@@ -0,0 +1,158 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultSummaryTemplate is the template Summarize uses for each period (e.g.
+// "Today: Sunny 86°F").
+const DefaultSummaryTemplate = `{{label .}}: {{.ForecastShort}} {{degrees .Temperature}}`
+
+// SummaryFuncs are the functions available to summary templates. label returns
+// the period's name, or its start time (e.g. "3PM") if it has none. degrees
+// formats a temperature rounded to a whole degree (e.g. "86°F"). format formats
+// a value with the Summarizer's DisplayPolicy (e.g. "10 mph"). pop returns the
+// probability of precipitation as a percentage, from the forecast or its icon.
+var SummaryFuncs = template.FuncMap{
+	"label":   summaryLabel,
+	"degrees": summaryDegrees,
+	"format":  DefaultDisplayPolicy.Format,
+	"pop":     func(p Period) int { return p.precipitationProbability() },
+}
+
+var defaultSummaryTemplate = template.Must(NewSummaryTemplate(DefaultSummaryTemplate))
+
+// NewSummaryTemplate parses a template for a Summarizer. The template is
+// executed with a Period and may use SummaryFuncs.
+func NewSummaryTemplate(text string) (*template.Template, error) {
+	return template.New("summary").Funcs(SummaryFuncs).Parse(text)
+}
+
+// A Summarizer produces compact, human-readable summaries of forecasts, such
+// as for SMS messages and notifications.
+type Summarizer struct {
+	// Template is executed for each period; see NewSummaryTemplate.
+	// DefaultSummaryTemplate is used if nil.
+	Template *template.Template
+
+	// Separator separates periods. "; " if empty.
+	Separator string
+
+	// MaxLength is the maximum length of a summary in characters (runes), or
+	// zero for no limit. Periods that don't fit are left out; if the first
+	// doesn't fit, it is truncated and ends with "…".
+	MaxLength int
+
+	// Time is the time from which periods are summarized: periods that end
+	// before it are skipped. The current time is used if zero.
+	Time time.Time
+
+	// DisplayPolicy is used by the format template function.
+	// DefaultDisplayPolicy is used if nil.
+	DisplayPolicy *DisplayPolicy
+}
+
+// Summarize returns a summary of the next n periods of a forecast using the
+// default Summarizer (e.g. "Today: Sunny 86°F; Tonight: Clear 60°F").
+func Summarize(f *Forecast, n int) string {
+	s, _ := Summarizer{}.Summarize(f, n)
+	return s
+}
+
+// Summarize returns a summary of the next n periods of a forecast. An error is
+// returned only if the template fails.
+func (s Summarizer) Summarize(f *Forecast, n int) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+	tmpl := s.Template
+	if tmpl == nil {
+		tmpl = defaultSummaryTemplate
+	}
+	if s.DisplayPolicy != nil {
+		var err error
+		if tmpl, err = tmpl.Clone(); err != nil {
+			return "", err
+		}
+		tmpl.Funcs(template.FuncMap{"format": s.DisplayPolicy.Format})
+	}
+	sep := s.Separator
+	if sep == "" {
+		sep = "; "
+	}
+	ref := s.Time
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+
+	var parts []string
+	var b bytes.Buffer
+	for _, p := range f.Periods {
+		if len(parts) >= n {
+			break
+		}
+		if !p.TimeEnd.IsZero() && !p.TimeEnd.After(ref) {
+			continue // already over
+		}
+		b.Reset()
+		if err := tmpl.Execute(&b, p); err != nil {
+			return "", err
+		}
+		parts = append(parts, strings.Join(strings.Fields(b.String()), " "))
+	}
+
+	summary := strings.Join(parts, sep)
+	if s.MaxLength <= 0 || len([]rune(summary)) <= s.MaxLength {
+		return summary, nil
+	}
+	for len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+		if summary = strings.Join(parts, sep); len([]rune(summary)) <= s.MaxLength {
+			return summary, nil
+		}
+	}
+	if s.MaxLength < 2 {
+		return "", nil
+	}
+	return string([]rune(summary)[:s.MaxLength-1]) + "…", nil
+}
+
+// summaryLabel returns a period's name, or its start time if it has none, as
+// in hourly forecasts.
+func summaryLabel(p Period) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if p.TimeStart.IsZero() {
+		return ""
+	}
+	return p.TimeStart.Format("3PM")
+}
+
+// summaryDegrees returns a temperature rounded to a whole degree (e.g.
+// "86°F"), or an empty string if it has no unit.
+func summaryDegrees(vu ValueUnit) string {
+	if vu.Unit != "F" && vu.Unit != "C" {
+		return ""
+	}
+	return strconv.FormatFloat(math.Round(vu.Value), 'f', 0, 64) + "°" + vu.Unit
+}
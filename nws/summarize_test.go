@@ -0,0 +1,55 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"testing"
+)
+
+func TestSummarizerDisplayPolicy(t *testing.T) {
+	f := &Forecast{Periods: []Period{
+		{Name: "Today", WindSpeedMax: ValueUnit{Value: 12, Unit: "mph"}},
+	}}
+	tmpl, err := NewSummaryTemplate(`{{label .}}: wind {{format .WindSpeedMax}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		policy *DisplayPolicy
+		want   string
+	}{
+		{"default", nil, "Today: wind 10 mph"},
+		{"exact", &DisplayPolicy{}, "Today: wind 12 mph"},
+		{"custom", &DisplayPolicy{Increments: map[string]float64{"mph": 0.5}}, "Today: wind 12.0 mph"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Summarizer{Template: tmpl, DisplayPolicy: tt.policy}
+			got, err := s.Summarize(f, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+
+	// the template's own functions are unchanged
+	if got, _ := (Summarizer{Template: tmpl}).Summarize(f, 1); got != tests[0].want {
+		t.Errorf("after custom policies: got %q; want %q", got, tests[0].want)
+	}
+}
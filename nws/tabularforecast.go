@@ -0,0 +1,231 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const htmlMIMEType = "text/html"
+
+// tabularForecastHourLabelRegexp matches the label of the hour row of a
+// tabular forecast, which gives the time zone (e.g. "Hour (PDT)").
+var tabularForecastHourLabelRegexp = regexp.MustCompile(`(?i)^hour\s*\(\s*([a-z]+)\s*\)`)
+
+// ParseTabularForecastHTML returns the hourly forecast shown on the "Tabular
+// Forecast" form of a forecast.weather.gov forecast page (MapClick.php with
+// FcstType=digital), as an alternative to the NWS API's hourly forecast.
+//
+// Each hour becomes a period with its temperature, dewpoint, relative
+// humidity, sky cover, precipitation probability, and wind, where given. The
+// table gives dates without years, so the year is chosen to put each date
+// nearest to ref, usually the time the page was retrieved. Rows that are
+// missing or can't be parsed are skipped, and ErrEmptyResponse is returned if
+// no hours are found. IsDaytime is not set, since the page doesn't give it.
+func ParseTabularForecastHTML(page []byte, ref time.Time) (*Forecast, error) {
+	doc := parseHTML(page)
+
+	f := Forecast{Source: SourceLegacy, TimeRetrieved: time.Now()}
+	f.TimeForecast = parseLegacyForecastLastUpdate(doc.text())
+
+	// The table is split into blocks of (usually) 24 hours, each starting
+	// with a date row. Rows are identified by the label in their first cell.
+	var block map[string][]string
+	flush := func() {
+		f.Periods = append(f.Periods, newPeriodsFromTabularForecastBlock(block, ref)...)
+		block = nil
+	}
+	for _, tr := range doc.findAll(htmlTag("tr")) {
+		var cells []string
+		for _, n := range tr.children {
+			if n.elem != nil && (n.elem.tag == "td" || n.elem.tag == "th") {
+				cells = append(cells, n.elem.text())
+			}
+		}
+		if len(cells) < 2 {
+			continue
+		}
+		label := strings.ToLower(cells[0])
+		if strings.HasPrefix(label, "date") {
+			flush()
+			block = make(map[string][]string)
+		}
+		if block == nil {
+			continue
+		}
+		if m := tabularForecastHourLabelRegexp.FindStringSubmatch(cells[0]); m != nil {
+			block["tz"] = []string{strings.ToUpper(m[1])}
+			label = "hour"
+		}
+		block[label] = cells[1:]
+	}
+	flush()
+
+	if len(f.Periods) < 1 {
+		return nil, ErrEmptyResponse
+	}
+	for i := range f.Periods {
+		f.Periods[i].Number = i + 1
+	}
+	return &f, nil
+}
+
+// newPeriodsFromTabularForecastBlock returns a period for each hour of a block
+// of a tabular forecast, given its rows keyed by lowercased label.
+func newPeriodsFromTabularForecastBlock(block map[string][]string, ref time.Time) []Period {
+	if block == nil {
+		return nil
+	}
+	row := func(prefix string) []string {
+		for label, cells := range block {
+			if strings.HasPrefix(label, prefix) {
+				return cells
+			}
+		}
+		return nil
+	}
+	at := func(cells []string, i int) string {
+		if i < len(cells) {
+			return cells[i]
+		}
+		return ""
+	}
+	number := func(cells []string, i int) (float64, bool) {
+		v, err := strconv.ParseFloat(at(cells, i), 64)
+		return v, err == nil
+	}
+
+	loc := time.UTC
+	if tz := row("tz"); len(tz) > 0 {
+		if offset, ok := usTimeZoneOffsets[tz[0]]; ok {
+			loc = time.FixedZone(tz[0], offset*60*60)
+		}
+	}
+	dates, hours := row("date"), row("hour")
+	temperatures, dewpoints := row("temperature"), row("dewpoint")
+	humidities, skyCovers, pops := row("relative humidity"), row("sky cover"), row("precipitation potential")
+	winds, directions, gusts := row("surface wind"), row("wind dir"), row("gust")
+
+	var periods []Period
+	var day time.Time
+	prevHour := -1
+	for i, hs := range hours {
+		hour, err := strconv.Atoi(hs)
+		if err != nil || hour < 0 || hour > 23 {
+			continue
+		}
+		if d, ok := parseTabularForecastDate(at(dates, i), ref, loc); ok {
+			day = d
+		} else if !day.IsZero() && hour < prevHour {
+			day = day.AddDate(0, 0, 1)
+		}
+		prevHour = hour
+		if day.IsZero() {
+			continue // no date yet
+		}
+
+		start := day.Add(time.Duration(hour) * time.Hour)
+		p := Period{TimeStart: start, TimeEnd: start.Add(time.Hour)}
+		if v, ok := number(temperatures, i); ok {
+			p.Temperature = ValueUnit{Value: v, Unit: "F"}
+		}
+		if v, ok := number(dewpoints, i); ok {
+			// hourly forecasts from the API give dewpoints in Celsius
+			p.Dewpoint = ValueUnit{Value: math.Round((v-32)*5/9*10) / 10, Unit: "C"}
+		}
+		if v, ok := number(humidities, i); ok {
+			p.RelativeHumidity = ValueUnit{Value: v, Unit: "percent"}
+		}
+		if v, ok := number(skyCovers, i); ok {
+			p.SkyCover = ValueUnit{Value: v, Unit: "percent"}
+		}
+		if v, ok := number(pops, i); ok {
+			p.PrecipitationProbability = ValueUnit{Value: v, Unit: "percent"}
+		}
+		if v, ok := number(winds, i); ok {
+			p.WindSpeedMin = ValueUnit{Value: v, Unit: "mph"}
+			p.WindSpeedMax = p.WindSpeedMin
+		}
+		if v, ok := number(gusts, i); ok {
+			p.WindGust = ValueUnit{Value: v, Unit: "mph"}
+		}
+		p.WindDirection = strings.ToUpper(at(directions, i))
+		periods = append(periods, p)
+	}
+	return periods
+}
+
+// parseTabularForecastDate returns midnight of a tabular forecast date (e.g.
+// "08/30") in loc, in the year that puts it nearest to ref.
+func parseTabularForecastDate(s string, ref time.Time, loc *time.Location) (time.Time, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+	month, err1 := strconv.Atoi(parts[0])
+	day, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	var best time.Time
+	for _, year := range []int{ref.Year() - 1, ref.Year(), ref.Year() + 1} {
+		t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+		if best.IsZero() || math.Abs(float64(t.Sub(ref))) < math.Abs(float64(best.Sub(ref))) {
+			best = t
+		}
+	}
+	return best, true
+}
+
+// legacyHourlyForecast retrieves the hourly forecast from the tabular form of
+// the legacy forecast page after the API has failed with apiErr. The returned
+// error includes both failures.
+func (c *Client) legacyHourlyForecast(apiErr error) (*Forecast, error) {
+	f, err := getLegacyTabularForecastForPoint(c.httpClient, c.httpUserAgentString, c.legacyForecastURLString, c.point)
+	if err != nil {
+		return nil, fmt.Errorf("%w (legacy fallback: %s)", apiErr, err)
+	}
+	f.Gridpoint = c.gridpoint
+	f.setPeriodIDs()
+	return f, nil
+}
+
+// getLegacyTabularForecastForPoint retrieves the hourly forecast for a point
+// from the tabular form of the legacy forecast page. The page shows 48 hours.
+func getLegacyTabularForecastForPoint(httpClient *http.Client, httpUserAgentString string, legacyURLString string, point Point) (*Forecast, error) {
+	respBody, err := doAPIRequestAccepting(
+		httpClient,
+		httpUserAgentString,
+		legacyURLString,
+		getLegacyForecastEndpointURLString,
+		url.Values{
+			"lat":      {strconv.FormatFloat(point.Lat, 'f', -1, 64)},
+			"lon":      {strconv.FormatFloat(point.Lon, 'f', -1, 64)},
+			"FcstType": {"digital"},
+		},
+		htmlMIMEType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTabularForecastHTML(respBody, time.Now())
+}
@@ -0,0 +1,382 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tafProductType is the product type code of TAFs.
+const tafProductType = "TAF"
+
+// maxTAFProducts is the number of recent TAF products retrieved by TAFs, about
+// a day of scheduled TAFs.
+const maxTAFProducts = 4
+
+// TAF change group types.
+const (
+	TAFChangeBase      = ""      // the initial conditions
+	TAFChangeFrom      = "FM"    // conditions change completely at TimeStart
+	TAFChangeTempo     = "TEMPO" // temporary fluctuations between TimeStart and TimeEnd
+	TAFChangeBecmg     = "BECMG" // conditions change gradually between TimeStart and TimeEnd
+	TAFChangeProb      = "PROB"  // conditions with a Probability between TimeStart and TimeEnd
+	TAFChangeProbTempo = "PROB TEMPO"
+)
+
+// A TAF represents a decoded Terminal Aerodrome Forecast.
+type TAF struct {
+	StationID  string
+	Amended    bool
+	Corrected  bool
+	TimeIssued time.Time
+	TimeStart  time.Time
+	TimeEnd    time.Time
+	Groups     []TAFGroup // the base group followed by change groups
+
+	Raw string
+}
+
+// A TAFGroup represents the base forecast or a single change group within a
+// TAF. Elements that are not forecast in a group are empty.
+type TAFGroup struct {
+	Change      string // one of the TAFChange constants
+	Probability int    // PROB groups only
+	TimeStart   time.Time
+	TimeEnd     time.Time // zero for FM groups, which last until the next FM group
+
+	WindDirection         ValueUnit // empty when variable
+	WindDirectionVariable bool
+	WindSpeed             ValueUnit
+	WindGust              ValueUnit
+
+	Visibility            ValueUnit
+	VisibilityGreaterThan bool // e.g. "P6SM"
+
+	Weather     []string // e.g. "-RA", "VCSH", "TSRA"; "NSW" for no significant weather
+	CloudLayers []CloudLayer
+
+	WindShear string // e.g. "WS020/27045KT"
+}
+
+// A CloudLayer represents a single cloud layer in a TAF or METAR.
+type CloudLayer struct {
+	Cover string    // "SKC", "FEW", "SCT", "BKN", "OVC", or "VV" (vertical visibility)
+	Base  ValueUnit // height above ground in ft, empty for SKC
+	Type  string    // "CB", "TCU", or empty
+}
+
+var (
+	tafIssuedRegexp      = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	tafValidRegexp       = regexp.MustCompile(`^(\d{2})(\d{2})/(\d{2})(\d{2})$`)
+	tafFromRegexp        = regexp.MustCompile(`^FM(\d{2})(\d{2})(\d{2})$`)
+	tafProbRegexp        = regexp.MustCompile(`^PROB(\d{2})$`)
+	tafWindRegexp        = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS)$`)
+	tafVisSMRegexp       = regexp.MustCompile(`^(P)?(\d+)?(?:(\d)/(\d{1,2}))?SM$`)
+	tafVisMetersRegexp   = regexp.MustCompile(`^\d{4}$`)
+	tafCloudRegexp       = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3})(CB|TCU)?$`)
+	tafWindShearRegexp   = regexp.MustCompile(`^WS\d{3}/\d{5}KT$`)
+	tafWeatherRegexp     = regexp.MustCompile(`^(?:[-+]|VC)?(?:MI|PR|BC|DR|BL|SH|TS|FZ)?(?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*$`)
+	tafWholeNumberRegexp = regexp.MustCompile(`^\d$`)
+)
+
+// TAFs retrieves the recent TAFs for the default station, newest first. Not
+// all stations issue TAFs.
+func (c *Client) TAFs() ([]TAF, error) {
+	return getTAFsForStation(c.httpClient, c.httpUserAgentString, c.apiURLString, c.defaultStationID)
+}
+
+// getTAFsForStation retrieves from the NWS API the recent TAFs for a station,
+// newest first. TAFs that can't be decoded are skipped.
+//
+// The API's stations/{id}/tafs list doesn't include the text of the TAFs, so
+// they are taken from the TAF text products for the station's location
+// instead.
+func getTAFsForStation(httpClient *http.Client, httpUserAgentString string, apiURLString string, stationID string) ([]TAF, error) {
+	ps, err := getProductsOfTypeForLocation(httpClient, httpUserAgentString, apiURLString, tafProductType, tafLocationID(stationID))
+	if err != nil {
+		return nil, err
+	}
+	if len(ps) > maxTAFProducts {
+		ps = ps[:maxTAFProducts]
+	}
+
+	var tafs []TAF
+	for _, p := range ps {
+		pp, err := getProduct(httpClient, httpUserAgentString, apiURLString, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		ref := pp.TimeIssued
+		if ref.IsZero() {
+			ref = time.Now()
+		}
+		tafs = append(tafs, newTAFsFromProductText(pp.Text, stationID, ref)...)
+	}
+
+	return tafs, nil
+}
+
+// tafLocationID returns the product location ID for a station, which is the
+// station ID without its ICAO region prefix (e.g. "PDX" for "KPDX").
+func tafLocationID(stationID string) string {
+	stationID = strings.ToUpper(stationID)
+	if len(stationID) == 4 {
+		return stationID[1:]
+	}
+	return stationID
+}
+
+// newTAFsFromProductText returns the TAFs for a station in the text of a TAF
+// product, in the order they appear. A product contains a WMO header followed
+// by one or more TAFs, each ending with "=" and possibly wrapped over several
+// lines. TAFs that can't be decoded are skipped.
+func newTAFsFromProductText(text string, stationID string, ref time.Time) []TAF {
+	stationID = strings.ToUpper(stationID)
+
+	var tafs []TAF
+	for _, chunk := range strings.Split(text, "=") {
+		tokens := strings.Fields(chunk)
+
+		// find the station and issue time, then include any preceding
+		// "TAF", "AMD", and "COR"
+		start := -1
+		for i := 0; i+1 < len(tokens); i++ {
+			if tokens[i] == stationID && tafIssuedRegexp.MatchString(tokens[i+1]) {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			continue // skip if no TAF for the station
+		}
+		for start > 0 && (tokens[start-1] == "TAF" || tokens[start-1] == "AMD" || tokens[start-1] == "COR") {
+			start--
+		}
+
+		t, err := ParseTAF(strings.Join(tokens[start:], " "), ref)
+		if err != nil {
+			continue // skip if invalid
+		}
+		tafs = append(tafs, t)
+	}
+
+	return tafs
+}
+
+// ParseTAF decodes the text of a TAF. Since TAFs contain only the day of the
+// month, ref must be a time within a couple of weeks of issuance (e.g. the
+// time the TAF was retrieved) and is used to determine the month and year.
+//
+// Unrecognized groups (remarks, temperature forecasts, etc.) are ignored.
+func ParseTAF(raw string, ref time.Time) (TAF, error) {
+	t := TAF{Raw: raw}
+	tokens := strings.Fields(strings.TrimRight(strings.TrimSpace(raw), "="))
+
+	// header: [TAF] [AMD|COR] CCCC DDHHMMZ DDHH/DDHH
+	if len(tokens) > 0 && tokens[0] == "TAF" {
+		tokens = tokens[1:]
+	}
+	for len(tokens) > 0 && (tokens[0] == "AMD" || tokens[0] == "COR") {
+		t.Amended = t.Amended || tokens[0] == "AMD"
+		t.Corrected = t.Corrected || tokens[0] == "COR"
+		tokens = tokens[1:]
+	}
+	if len(tokens) < 3 {
+		return TAF{}, errors.New("TAF must contain a station, issue time, and valid period")
+	}
+	t.StationID = tokens[0]
+	m := tafIssuedRegexp.FindStringSubmatch(tokens[1])
+	if m == nil {
+		return TAF{}, fmt.Errorf("TAF issue time must be in the form DDHHMMZ: \"%s\"", tokens[1])
+	}
+	t.TimeIssued = tafTime(ref, m[1], m[2], m[3])
+	start, end, ok := parseTAFValidPeriod(t.TimeIssued, tokens[2])
+	if !ok {
+		return TAF{}, fmt.Errorf("TAF valid period must be in the form DDHH/DDHH: \"%s\"", tokens[2])
+	}
+	t.TimeStart, t.TimeEnd = start, end
+	tokens = tokens[3:]
+
+	g := TAFGroup{Change: TAFChangeBase, TimeStart: t.TimeStart, TimeEnd: t.TimeEnd}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		if tok == "RMK" {
+			break
+		}
+		if tok == "NIL" || tok == "CNL" {
+			return TAF{}, fmt.Errorf("TAF is %s", tok)
+		}
+
+		// a new change group ends the current group
+		var next *TAFGroup
+		switch {
+		case tafFromRegexp.MatchString(tok):
+			m := tafFromRegexp.FindStringSubmatch(tok)
+			next = &TAFGroup{Change: TAFChangeFrom, TimeStart: tafTime(t.TimeIssued, m[1], m[2], m[3])}
+		case tok == "TEMPO" || tok == "BECMG":
+			next = &TAFGroup{Change: tok}
+		case tafProbRegexp.MatchString(tok):
+			p, _ := strconv.Atoi(tok[len("PROB"):])
+			next = &TAFGroup{Change: TAFChangeProb, Probability: p}
+			if i+1 < len(tokens) && tokens[i+1] == "TEMPO" {
+				next.Change = TAFChangeProbTempo
+				i++
+			}
+		}
+		if next != nil {
+			if next.Change != TAFChangeFrom && i+1 < len(tokens) {
+				if s, e, ok := parseTAFValidPeriod(t.TimeIssued, tokens[i+1]); ok {
+					next.TimeStart, next.TimeEnd = s, e
+					i++
+				}
+			}
+			t.Groups = append(t.Groups, g)
+			g = *next
+			continue
+		}
+
+		// a visibility may be a whole number followed by a fraction
+		if tafWholeNumberRegexp.MatchString(tok) && i+1 < len(tokens) && tafVisSMRegexp.MatchString(tokens[i+1]) {
+			tok += " " + tokens[i+1]
+			i++
+		}
+		parseTAFElement(&g, tok)
+	}
+	t.Groups = append(t.Groups, g)
+
+	return t, nil
+}
+
+// parseTAFElement decodes a single forecast element into a group. Unrecognized
+// elements are ignored.
+func parseTAFElement(g *TAFGroup, tok string) {
+	switch {
+	case tafWindRegexp.MatchString(tok):
+		m := tafWindRegexp.FindStringSubmatch(tok)
+		unit := "kt"
+		if m[4] == "MPS" {
+			unit = "m/s"
+		}
+		if m[1] == "VRB" {
+			g.WindDirectionVariable = true
+		} else {
+			d, _ := strconv.Atoi(m[1])
+			g.WindDirection = ValueUnit{Value: float64(d), Unit: "degrees true"}
+		}
+		s, _ := strconv.Atoi(m[2])
+		g.WindSpeed = ValueUnit{Value: float64(s), Unit: unit}
+		if m[3] != "" {
+			gu, _ := strconv.Atoi(m[3])
+			g.WindGust = ValueUnit{Value: float64(gu), Unit: unit}
+		}
+	case strings.HasSuffix(tok, "SM"):
+		parts := strings.Fields(tok)
+		var v float64
+		for _, p := range parts {
+			m := tafVisSMRegexp.FindStringSubmatch(p)
+			if m == nil {
+				if n, err := strconv.Atoi(p); err == nil {
+					v += float64(n)
+				}
+				continue
+			}
+			if m[1] == "P" {
+				g.VisibilityGreaterThan = true
+			}
+			if m[2] != "" {
+				n, _ := strconv.Atoi(m[2])
+				v += float64(n)
+			}
+			if m[3] != "" {
+				num, _ := strconv.Atoi(m[3])
+				den, _ := strconv.Atoi(m[4])
+				if den != 0 {
+					v += float64(num) / float64(den)
+				}
+			}
+		}
+		g.Visibility = ValueUnit{Value: v, Unit: "mi"}
+	case tafVisMetersRegexp.MatchString(tok):
+		v, _ := strconv.Atoi(tok)
+		g.Visibility = ValueUnit{Value: float64(v), Unit: "m"}
+		g.VisibilityGreaterThan = v == 9999
+	case tafCloudRegexp.MatchString(tok):
+		m := tafCloudRegexp.FindStringSubmatch(tok)
+		h, _ := strconv.Atoi(m[2])
+		g.CloudLayers = append(g.CloudLayers, CloudLayer{
+			Cover: m[1],
+			Base:  ValueUnit{Value: float64(h * 100), Unit: "ft"},
+			Type:  m[3],
+		})
+	case tok == "SKC" || tok == "CLR" || tok == "NSC":
+		g.CloudLayers = append(g.CloudLayers, CloudLayer{Cover: "SKC"})
+	case tafWindShearRegexp.MatchString(tok):
+		g.WindShear = tok
+	case tok == "NSW" || (tok != "" && tafWeatherRegexp.MatchString(tok)):
+		g.Weather = append(g.Weather, tok)
+	}
+}
+
+// parseTAFValidPeriod parses a DDHH/DDHH valid period relative to the time a
+// TAF was issued.
+func parseTAFValidPeriod(issued time.Time, s string) (time.Time, time.Time, bool) {
+	m := tafValidRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return tafTime(issued, m[1], m[2], "00"), tafTime(issued, m[3], m[4], "00"), true
+}
+
+// tafTime returns the UTC time nearest ref with the given day of the month,
+// hour, and minute. An hour of 24 is the end of the day.
+func tafTime(ref time.Time, day string, hour string, minute string) time.Time {
+	d, _ := strconv.Atoi(day)
+	h, _ := strconv.Atoi(hour)
+	mi, _ := strconv.Atoi(minute)
+
+	ref = ref.UTC()
+	var best time.Time
+	for _, mo := range []int{-1, 0, 1} {
+		first := time.Date(ref.Year(), ref.Month()+time.Month(mo), 1, 0, 0, 0, 0, time.UTC)
+		if d > daysIn(first) {
+			continue
+		}
+		t := first.AddDate(0, 0, d-1).Add(time.Duration(h)*time.Hour + time.Duration(mi)*time.Minute)
+		if best.IsZero() || absDuration(t.Sub(ref)) < absDuration(best.Sub(ref)) {
+			best = t
+		}
+	}
+	return best
+}
+
+// daysIn returns the number of days in the month containing t.
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// absDuration returns the absolute value of d.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"math"
+	"time"
+)
+
+// compassPointNames are the sixteen compass points used in forecasts,
+// clockwise from north.
+var compassPointNames = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// A TimelineEntry is one step of a timeline built by BlendObservation: the
+// current conditions or an hour of the forecast.
+type TimelineEntry struct {
+	TimeStart time.Time
+	TimeEnd   time.Time
+
+	// Observed is true if the entry's values come from an observation.
+	// Values the observation lacks (e.g. PrecipitationProbability) are
+	// filled in from the forecast period it overlaps, if any.
+	Observed bool
+
+	Temperature              ValueUnit
+	Dewpoint                 ValueUnit
+	RelativeHumidity         ValueUnit
+	WindSpeed                ValueUnit
+	WindGust                 ValueUnit
+	WindDirection            string // compass point (e.g. "NW")
+	PrecipitationProbability ValueUnit
+	Icon                     Icon
+	ShortForecast            string
+}
+
+// BlendObservation returns a timeline of the current conditions followed by
+// the hours of an hourly forecast, for "now plus the next hours" displays.
+//
+// Forecast periods that ended before the observation was made are dropped.
+// If the observation was made during the first remaining period, that
+// period's entry is replaced with the observed values; otherwise the
+// observation is placed in an entry of its own ahead of the forecast, ending
+// when the forecast begins. Observed values that failed quality control are
+// not used, and the forecast's values are kept where the observation has
+// none. Observed values are converted to the units of the forecast where
+// possible. If the observation has no TimeObserved, only the forecast is
+// returned.
+func BlendObservation(o Observation, hourly Forecast) []TimelineEntry {
+	periods := hourly.Periods
+	for len(periods) > 0 && !o.TimeObserved.IsZero() && !periods[0].TimeEnd.After(o.TimeObserved) {
+		periods = periods[1:]
+	}
+
+	var entries []TimelineEntry
+	for _, p := range periods {
+		entries = append(entries, newTimelineEntryFromPeriod(p))
+	}
+	if o.TimeObserved.IsZero() {
+		return entries
+	}
+
+	if len(entries) > 0 && !o.TimeObserved.Before(entries[0].TimeStart) {
+		entries[0] = blendTimelineEntry(entries[0], o)
+		return entries
+	}
+	e := TimelineEntry{TimeStart: o.TimeObserved, TimeEnd: o.TimeObserved}
+	if len(entries) > 0 {
+		e.TimeEnd = entries[0].TimeStart
+		e.Temperature = ValueUnit{Unit: entries[0].Temperature.Unit}
+		e.WindSpeed = ValueUnit{Unit: entries[0].WindSpeed.Unit}
+	}
+	return append([]TimelineEntry{blendTimelineEntry(e, o)}, entries...)
+}
+
+// newTimelineEntryFromPeriod returns a TimelineEntry for a forecast period.
+func newTimelineEntryFromPeriod(p Period) TimelineEntry {
+	return TimelineEntry{
+		TimeStart:                p.TimeStart,
+		TimeEnd:                  p.TimeEnd,
+		Temperature:              p.Temperature,
+		Dewpoint:                 p.Dewpoint,
+		RelativeHumidity:         p.RelativeHumidity,
+		WindSpeed:                p.WindSpeedMax,
+		WindGust:                 p.WindGust,
+		WindDirection:            p.WindDirection,
+		PrecipitationProbability: p.PrecipitationProbability,
+		Icon:                     p.Icon,
+		ShortForecast:            p.ForecastShort,
+	}
+}
+
+// blendTimelineEntry returns an entry with the observed values of o replacing
+// its own. The units of the entry's values, if any, are kept.
+func blendTimelineEntry(e TimelineEntry, o Observation) TimelineEntry {
+	o = o.WithoutSuspectValues()
+	e.Observed = true
+
+	if o.Temperature.Unit != "" {
+		e.Temperature = blendValueUnit(o.Temperature, e.Temperature.Unit, convertTemperature)
+	}
+	if o.Dewpoint.Unit != "" {
+		e.Dewpoint = blendValueUnit(o.Dewpoint, e.Dewpoint.Unit, convertTemperature)
+	}
+	if o.RelativeHumidity.Unit != "" {
+		e.RelativeHumidity = o.RelativeHumidity
+	}
+	if o.WindSpeed.Unit != "" {
+		e.WindSpeed = blendValueUnit(o.WindSpeed, e.WindSpeed.Unit, convertSpeed)
+		e.WindGust = ValueUnit{} // a calm or steady observation has no gust
+		if e.WindSpeed.Value == 0 {
+			e.WindDirection = ""
+		}
+	}
+	if o.WindGust.Unit != "" {
+		e.WindGust = blendValueUnit(o.WindGust, e.WindSpeed.Unit, convertSpeed)
+	}
+	if o.WindDirection.Unit != "" && (o.WindSpeed.Unit == "" || o.WindSpeed.Value > 0) {
+		e.WindDirection = compassPointName(o.WindDirection.Value)
+	}
+	if len(o.Icon.Conditions) > 0 || o.Description != "" {
+		e.Icon = o.Icon
+		e.ShortForecast = o.Description
+	}
+	return e
+}
+
+// blendValueUnit returns an observed value converted to unit, or unconverted
+// if unit is empty or the conversion isn't supported.
+func blendValueUnit(vu ValueUnit, unit string, convert func(ValueUnit, string) (ValueUnit, error)) ValueUnit {
+	if unit == "" {
+		return vu
+	}
+	converted, err := convert(vu, unit)
+	if err != nil {
+		return vu
+	}
+	return converted
+}
+
+// compassPointName returns the nearest of the sixteen compass points to a
+// direction in degrees.
+func compassPointName(deg float64) string {
+	i := int(math.Floor(normalizeDegrees(deg)/22.5+0.5)) % len(compassPointNames)
+	return compassPointNames[i]
+}
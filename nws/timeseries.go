@@ -0,0 +1,241 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"io"
+	"sort"
+	"time"
+)
+
+// A Sample is a single value in a TimeSeries, valid for Duration starting at
+// Time. Duration is zero for instantaneous values such as observations.
+type Sample[T any] struct {
+	Time     time.Time
+	Duration time.Duration
+	Value    T
+}
+
+// TimeEnd returns the end of the time range for which the value is valid.
+func (s Sample[T]) TimeEnd() time.Time {
+	return s.Time.Add(s.Duration)
+}
+
+// A TimeSeries is a sequence of timestamped values in chronological order.
+// Gridpoint layers, observation histories, forecasts, and values derived from
+// them may all be represented as a TimeSeries, so that they can be sliced,
+// combined, and exported the same way.
+type TimeSeries[T any] struct {
+	// Interval is the nominal time between samples, or zero if the samples
+	// are irregular.
+	Interval time.Duration
+
+	Samples []Sample[T]
+}
+
+// NewTimeSeries returns a TimeSeries of samples sorted into chronological
+// order. Interval is set if every sample has the same, nonzero duration and
+// follows the previous sample by that duration.
+func NewTimeSeries[T any](samples []Sample[T]) TimeSeries[T] {
+	ss := append([]Sample[T](nil), samples...)
+	sort.SliceStable(ss, func(i, j int) bool { return ss[i].Time.Before(ss[j].Time) })
+
+	ts := TimeSeries[T]{Samples: ss}
+	if len(ss) > 0 && ss[0].Duration > 0 {
+		ts.Interval = ss[0].Duration
+		for i, s := range ss {
+			if s.Duration != ts.Interval || (i > 0 && !s.Time.Equal(ss[i-1].TimeEnd())) {
+				ts.Interval = 0
+				break
+			}
+		}
+	}
+	return ts
+}
+
+// Len returns the number of samples.
+func (ts TimeSeries[T]) Len() int {
+	return len(ts.Samples)
+}
+
+// Values returns the values of the samples, in order.
+func (ts TimeSeries[T]) Values() []T {
+	vs := make([]T, len(ts.Samples))
+	for i, s := range ts.Samples {
+		vs[i] = s.Value
+	}
+	return vs
+}
+
+// TimeStart returns the start of the first sample, or the zero time if there
+// are none.
+func (ts TimeSeries[T]) TimeStart() time.Time {
+	if len(ts.Samples) == 0 {
+		return time.Time{}
+	}
+	return ts.Samples[0].Time
+}
+
+// TimeEnd returns the end of the last sample, or the zero time if there are
+// none.
+func (ts TimeSeries[T]) TimeEnd() time.Time {
+	if len(ts.Samples) == 0 {
+		return time.Time{}
+	}
+	return ts.Samples[len(ts.Samples)-1].TimeEnd()
+}
+
+// Between returns the samples overlapping start and end. An instantaneous
+// sample overlaps if it is at or after start and before end.
+func (ts TimeSeries[T]) Between(start time.Time, end time.Time) TimeSeries[T] {
+	b := TimeSeries[T]{Interval: ts.Interval}
+	for _, s := range ts.Samples {
+		if s.Time.Before(end) && (s.TimeEnd().After(start) || (s.Duration == 0 && !s.Time.Before(start))) {
+			b.Samples = append(b.Samples, s)
+		}
+	}
+	return b
+}
+
+// At returns the value of the sample valid at t: the last sample whose time
+// range contains t, or for instantaneous samples, the last sample at or before
+// t. The second return value is false if there is none.
+func (ts TimeSeries[T]) At(t time.Time) (T, bool) {
+	i := sort.Search(len(ts.Samples), func(i int) bool { return ts.Samples[i].Time.After(t) })
+	if i == 0 {
+		var zero T
+		return zero, false
+	}
+	s := ts.Samples[i-1]
+	if s.Duration > 0 && !t.Before(s.TimeEnd()) {
+		var zero T
+		return zero, false
+	}
+	return s.Value, true
+}
+
+// MapSeries returns a TimeSeries of the values derived from each sample of ts
+// by f, such as a heat index derived from temperatures. Samples for which f
+// returns an error are omitted.
+func MapSeries[T any, U any](ts TimeSeries[T], f func(T) (U, error)) TimeSeries[U] {
+	m := TimeSeries[U]{Interval: ts.Interval}
+	for _, s := range ts.Samples {
+		v, err := f(s.Value)
+		if err != nil {
+			continue
+		}
+		m.Samples = append(m.Samples, Sample[U]{Time: s.Time, Duration: s.Duration, Value: v})
+	}
+	if len(m.Samples) != len(ts.Samples) {
+		m.Interval = 0
+	}
+	return m
+}
+
+// SumBetween returns the sum of the values of a series of amounts (e.g.
+// precipitation) between start and end. Samples only partly within the window
+// are prorated; instantaneous samples are counted if within the window.
+func SumBetween(ts TimeSeries[float64], start time.Time, end time.Time) float64 {
+	var total float64
+	for _, s := range ts.Between(start, end).Samples {
+		if s.Duration <= 0 {
+			total += s.Value
+			continue
+		}
+		os, oe := s.Time, s.TimeEnd()
+		if os.Before(start) {
+			os = start
+		}
+		if oe.After(end) {
+			oe = end
+		}
+		total += s.Value * float64(oe.Sub(os)) / float64(s.Duration)
+	}
+	return total
+}
+
+// Series returns the layer as a TimeSeries of values in the layer's unit.
+func (l GridpointLayer) Series() TimeSeries[float64] {
+	samples := make([]Sample[float64], len(l.Values))
+	for i, v := range l.Values {
+		samples[i] = Sample[float64]{Time: v.TimeStart, Duration: v.Duration, Value: v.Value}
+	}
+	return NewTimeSeries(samples)
+}
+
+// Series returns a value of each period of the forecast, selected by value
+// (e.g. func(p Period) ValueUnit { return p.Temperature }), as a TimeSeries.
+// Periods for which the value has no unit are omitted.
+func (f Forecast) Series(value func(Period) ValueUnit) TimeSeries[ValueUnit] {
+	var samples []Sample[ValueUnit]
+	for _, p := range f.Periods {
+		if vu := value(p); vu.Unit != "" {
+			samples = append(samples, Sample[ValueUnit]{Time: p.TimeStart, Duration: p.TimeEnd.Sub(p.TimeStart), Value: vu})
+		}
+	}
+	return NewTimeSeries(samples)
+}
+
+// ObservationSeries returns a value of each observation, selected by value
+// (e.g. func(o Observation) ValueUnit { return o.Temperature }), as a
+// TimeSeries of instantaneous samples. Observations for which the value has no
+// unit are omitted.
+func ObservationSeries(obs []Observation, value func(Observation) ValueUnit) TimeSeries[ValueUnit] {
+	var samples []Sample[ValueUnit]
+	for _, o := range obs {
+		if vu := value(o); vu.Unit != "" {
+			samples = append(samples, Sample[ValueUnit]{Time: o.TimeObserved, Value: vu})
+		}
+	}
+	return NewTimeSeries(samples)
+}
+
+// ExportSeriesCSV writes a TimeSeries as CSV with a header row. The columns
+// are:
+//
+//   start, end, value, unit
+//
+// Times are ISO 8601 (RFC 3339). The end is empty for instantaneous samples.
+func ExportSeriesCSV(w io.Writer, ts TimeSeries[ValueUnit]) error {
+	return writeExportCSV(w, seriesExportHeader, seriesExportRecords(ts))
+}
+
+// ExportSeriesJSON writes a TimeSeries as a JSON array of objects with the
+// same fields as ExportSeriesCSV. Missing values are null.
+func ExportSeriesJSON(w io.Writer, ts TimeSeries[ValueUnit]) error {
+	return writeExportJSON(w, seriesExportRecords(ts))
+}
+
+// seriesExportHeader is the header row written by ExportSeriesCSV, naming the
+// fields of the records returned by seriesExportRecords.
+var seriesExportHeader = []string{"start", "end", "value", "unit"}
+
+// seriesExportRecords returns the export records for a TimeSeries.
+func seriesExportRecords(ts TimeSeries[ValueUnit]) [][]exportField {
+	var recs [][]exportField
+	for _, s := range ts.Samples {
+		var end interface{}
+		if s.Duration > 0 {
+			end = s.TimeEnd()
+		}
+		recs = append(recs, []exportField{
+			{"start", s.Time},
+			{"end", end},
+			{"value", exportValue(s.Value)},
+			{"unit", s.Value.Unit},
+		})
+	}
+	return recs
+}
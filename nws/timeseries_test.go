@@ -0,0 +1,63 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestExportSeriesCSV(t *testing.T) {
+	start := time.Date(2019, 8, 30, 21, 0, 0, 0, time.UTC)
+	ts := NewTimeSeries([]Sample[ValueUnit]{
+		{Time: start, Duration: time.Hour, Value: ValueUnit{Value: 84, Unit: "F"}},
+		{Time: start.Add(time.Hour), Value: ValueUnit{Value: 29, Unit: "C"}},
+	})
+	tests := []struct {
+		name string
+		ts   TimeSeries[ValueUnit]
+		want string
+	}{
+		{"empty", TimeSeries[ValueUnit]{}, "start,end,value,unit\n"},
+		{"samples", ts, "start,end,value,unit\n" +
+			"2019-08-30T21:00:00Z,2019-08-30T22:00:00Z,84,F\n" +
+			"2019-08-30T22:00:00Z,,29,C\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := ExportSeriesCSV(&b, tt.ts); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+
+	// the header must name the fields of the records
+	for _, rec := range seriesExportRecords(ts) {
+		if got := exportHeader(rec); len(got) != len(seriesExportHeader) {
+			t.Fatalf("got fields %v; want %v", got, seriesExportHeader)
+		} else {
+			for i := range got {
+				if got[i] != seriesExportHeader[i] {
+					t.Errorf("got field %d %q; want %q", i, got[i], seriesExportHeader[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// StrictTLSTransport returns a RoundTripper that refuses requests other than
+// HTTPS and, if base is an *http.Transport (or nil, for
+// http.DefaultTransport), requires TLS 1.2 or later with certificate
+// verification. Other RoundTrippers are wrapped as is, since their TLS
+// settings can't be changed.
+func StrictTLSTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t, ok := base.(*http.Transport); ok {
+		t = t.Clone()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		if t.TLSClientConfig.MinVersion < tls.VersionTLS12 {
+			t.TLSClientConfig.MinVersion = tls.VersionTLS12
+		}
+		t.TLSClientConfig.InsecureSkipVerify = false
+		base = t
+	}
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Scheme != "https" {
+			return nil, fmt.Errorf("strict TLS requires https: \"%s\"", req.URL)
+		}
+		return base.RoundTrip(req)
+	})
+}
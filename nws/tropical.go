@@ -0,0 +1,240 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tropical cyclone product codes.
+const (
+	TropicalCyclonePublicAdvisory   = "TCP"
+	TropicalCycloneForecastAdvisory = "TCM"
+)
+
+// A TropicalCycloneAdvisory represents the structured content of a Tropical
+// Cyclone Public Advisory (TCP) or Forecast/Advisory (TCM). Elements that
+// could not be found in the text are empty.
+type TropicalCycloneAdvisory struct {
+	ProductID      string
+	ProductCode    string // TropicalCyclonePublicAdvisory or TropicalCycloneForecastAdvisory
+	TimeIssued     time.Time
+	StormName      string // e.g. "Hurricane Dorian"; public advisories only
+	StormID        string // ATCF identifier, e.g. "AL052019"
+	AdvisoryNumber string // e.g. "33" or "33A"
+
+	Position           Point
+	MaxSustainedWind   ValueUnit // mph in public advisories, kt in forecast/advisories
+	WindGust           ValueUnit // forecast/advisories only
+	MinCentralPressure ValueUnit // mb
+	MovementDirection  ValueUnit // degrees true, empty if stationary
+	MovementSpeed      ValueUnit
+
+	WatchesWarnings []TropicalWatchWarning
+
+	Text string
+}
+
+// A TropicalWatchWarning represents a tropical watch or warning and the
+// coastal breakpoints or areas it covers (e.g. "Jupiter Inlet to Ponte Vedra
+// Beach").
+type TropicalWatchWarning struct {
+	Type  string // e.g. "Hurricane Warning", "Tropical Storm Watch"
+	Areas []string
+}
+
+var (
+	tcStormRegexp        = regexp.MustCompile(`(?im)^(.+?) (?:Intermediate |Special )?Advisory Number\s+(\w+)`)
+	tcStormIDRegexp      = regexp.MustCompile(`\b((?:AL|EP|CP|WP)\d{6})\b`)
+	tcWatchWarningRegexp = regexp.MustCompile(`(?i)^An? (.+?) (?:is|are) in effect for\.\.\.$`)
+
+	tcpPositionRegexp = regexp.MustCompile(`(?i)LOCATION\.\.\.(\d+\.\d+)([NS])\s+(\d+\.\d+)([EW])`)
+	tcpWindRegexp     = regexp.MustCompile(`(?i)MAXIMUM SUSTAINED WINDS\.\.\.(\d+) MPH`)
+	tcpPressureRegexp = regexp.MustCompile(`(?i)MINIMUM CENTRAL PRESSURE\.\.\.(\d+) MB`)
+	tcpMovementRegexp = regexp.MustCompile(`(?i)PRESENT MOVEMENT\.\.\..*? OR (\d+) DEGREES AT (\d+) MPH`)
+
+	tcmPositionRegexp = regexp.MustCompile(`(?i)CENTER LOCATED NEAR\s+(\d+\.\d+)([NS])\s+(\d+\.\d+)([EW])`)
+	tcmWindRegexp     = regexp.MustCompile(`(?i)MAX SUSTAINED WINDS\s+(\d+) KT WITH GUSTS TO\s+(\d+) KT`)
+	tcmPressureRegexp = regexp.MustCompile(`(?i)ESTIMATED MINIMUM CENTRAL PRESSURE\s+(\d+) MB`)
+	tcmMovementRegexp = regexp.MustCompile(`(?i)PRESENT MOVEMENT TOWARD THE .*? OR\s+(\d+) DEGREES AT\s+(\d+) KT`)
+)
+
+// TropicalCycloneAdvisories retrieves and parses up to limit of the most
+// recent tropical cyclone products of a type (TropicalCyclonePublicAdvisory or
+// TropicalCycloneForecastAdvisory) for all basins, newest first. Products that
+// can't be parsed are skipped.
+func (c *Client) TropicalCycloneAdvisories(productCode string, limit int) ([]TropicalCycloneAdvisory, error) {
+	if productCode != TropicalCyclonePublicAdvisory && productCode != TropicalCycloneForecastAdvisory {
+		return nil, fmt.Errorf("product code must be %s or %s: \"%s\"", TropicalCyclonePublicAdvisory, TropicalCycloneForecastAdvisory, productCode)
+	}
+
+	ps, err := getProductsOfType(c.httpClient, c.httpUserAgentString, c.apiURLString, productCode)
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && len(ps) > limit {
+		ps = ps[:limit]
+	}
+
+	var tcas []TropicalCycloneAdvisory
+	for _, p := range ps {
+		full, err := getProduct(c.httpClient, c.httpUserAgentString, c.apiURLString, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		tca, err := ParseTropicalCycloneAdvisory(*full)
+		if err != nil {
+			continue // skip if invalid
+		}
+		tcas = append(tcas, tca)
+	}
+
+	return tcas, nil
+}
+
+// ParseTropicalCycloneAdvisory returns the structured content of a TCP or TCM
+// product. An error is returned only if the storm position can't be found.
+func ParseTropicalCycloneAdvisory(p Product) (TropicalCycloneAdvisory, error) {
+	tca := TropicalCycloneAdvisory{
+		ProductID:   p.ID,
+		ProductCode: p.Code,
+		TimeIssued:  p.TimeIssued,
+		Text:        p.Text,
+	}
+
+	if m := tcStormIDRegexp.FindStringSubmatch(p.Text); m != nil {
+		tca.StormID = m[1]
+	}
+	if m := tcStormRegexp.FindStringSubmatch(p.Text); m != nil {
+		if p.Code == TropicalCyclonePublicAdvisory {
+			tca.StormName = strings.TrimSpace(m[1])
+		}
+		tca.AdvisoryNumber = m[2]
+	}
+
+	var ok bool
+	switch p.Code {
+	case TropicalCyclonePublicAdvisory:
+		tca.Position, ok = parseTropicalPosition(tcpPositionRegexp, p.Text)
+		if m := tcpWindRegexp.FindStringSubmatch(p.Text); m != nil {
+			tca.MaxSustainedWind = tropicalValueUnit(m[1], "mph")
+		}
+		if m := tcpPressureRegexp.FindStringSubmatch(p.Text); m != nil {
+			tca.MinCentralPressure = tropicalValueUnit(m[1], "mb")
+		}
+		if m := tcpMovementRegexp.FindStringSubmatch(p.Text); m != nil {
+			tca.MovementDirection = tropicalValueUnit(m[1], "degrees true")
+			tca.MovementSpeed = tropicalValueUnit(m[2], "mph")
+		}
+	case TropicalCycloneForecastAdvisory:
+		tca.Position, ok = parseTropicalPosition(tcmPositionRegexp, p.Text)
+		if m := tcmWindRegexp.FindStringSubmatch(p.Text); m != nil {
+			tca.MaxSustainedWind = tropicalValueUnit(m[1], "kt")
+			tca.WindGust = tropicalValueUnit(m[2], "kt")
+		}
+		if m := tcmPressureRegexp.FindStringSubmatch(p.Text); m != nil {
+			tca.MinCentralPressure = tropicalValueUnit(m[1], "mb")
+		}
+		if m := tcmMovementRegexp.FindStringSubmatch(p.Text); m != nil {
+			tca.MovementDirection = tropicalValueUnit(m[1], "degrees true")
+			tca.MovementSpeed = tropicalValueUnit(m[2], "kt")
+		}
+	default:
+		return TropicalCycloneAdvisory{}, fmt.Errorf("product code must be %s or %s: \"%s\"", TropicalCyclonePublicAdvisory, TropicalCycloneForecastAdvisory, p.Code)
+	}
+	if !ok {
+		return TropicalCycloneAdvisory{}, errors.New("storm position not found")
+	}
+
+	tca.WatchesWarnings = parseTropicalWatchesWarnings(p.Text)
+
+	return tca, nil
+}
+
+// parseTropicalPosition finds a position like "25.4N 71.1W" using a regexp
+// with latitude, hemisphere, longitude, and hemisphere groups.
+func parseTropicalPosition(re *regexp.Regexp, text string) (Point, bool) {
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return Point{}, false
+	}
+	lat, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return Point{}, false
+	}
+	lon, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return Point{}, false
+	}
+	if strings.EqualFold(m[2], "S") {
+		lat = -lat
+	}
+	if strings.EqualFold(m[4], "W") {
+		lon = -lon
+	}
+	return Point{Lat: lat, Lon: lon}, true
+}
+
+// parseTropicalWatchesWarnings returns the watches and warnings listed in an
+// advisory. Each begins with a line like "A Hurricane Warning is in effect
+// for..." followed by one line per area, each starting with "* ".
+func parseTropicalWatchesWarnings(text string) []TropicalWatchWarning {
+	var wws []TropicalWatchWarning
+	var cur *TropicalWatchWarning
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if m := tcWatchWarningRegexp.FindStringSubmatch(line); m != nil {
+			wws = append(wws, TropicalWatchWarning{Type: titleCase(m[1])})
+			cur = &wws[len(wws)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if strings.HasPrefix(line, "* ") {
+			cur.Areas = append(cur.Areas, strings.TrimSpace(line[2:]))
+			continue
+		}
+		cur = nil
+	}
+
+	return wws
+}
+
+// tropicalValueUnit returns a ValueUnit from an integer string matched by one
+// of the advisory regexps.
+func tropicalValueUnit(s string, unit string) ValueUnit {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return ValueUnit{}
+	}
+	return ValueUnit{Value: float64(v), Unit: unit}
+}
+
+// titleCase returns s with the first letter of each word upper case and the
+// rest lower case (e.g. "HURRICANE WARNING" becomes "Hurricane Warning").
+func titleCase(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ugcCodeRegexp matches a UGC code with its state and type, optionally
+	// followed by the end of a range (e.g. "ORZ049" or "ORZ503>506").
+	ugcCodeRegexp = regexp.MustCompile(`^([A-Z]{2}[ZC])(\d{3}|ALL)(?:>(\d{3}))?$`)
+
+	// ugcNumberRegexp matches a UGC code's number alone, optionally followed
+	// by the end of a range (e.g. "050" or "503>506").
+	ugcNumberRegexp = regexp.MustCompile(`^(\d{3})(?:>(\d{3}))?$`)
+
+	// ugcExpirationRegexp matches the expiration time (DDHHMM) that ends a
+	// UGC string.
+	ugcExpirationRegexp = regexp.MustCompile(`^\d{6}$`)
+)
+
+// ExpandUGC expands a UGC string as it appears in products, such as
+// "ORZ049-050-502-503>506-WAZ021-040300-", into individual codes:
+//
+//   ORZ049 ORZ050 ORZ502 ORZ503 ORZ504 ORZ505 ORZ506 WAZ021
+//
+// A number without a state and type takes those of the code before it, and
+// ">" joins the ends of an inclusive range. The expiration time and line
+// breaks are ignored. Codes for all zones in a state (e.g. "ORZALL") are kept
+// as is.
+func ExpandUGC(s string) ([]string, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, s)
+
+	var codes []string
+	var prefix string
+	for _, tok := range strings.Split(s, "-") {
+		var first, last string
+		if m := ugcCodeRegexp.FindStringSubmatch(tok); m != nil {
+			prefix, first, last = m[1], m[2], m[3]
+		} else if m := ugcNumberRegexp.FindStringSubmatch(tok); m != nil && prefix != "" {
+			first, last = m[1], m[2]
+		} else if tok == "" || ugcExpirationRegexp.MatchString(tok) {
+			continue
+		} else {
+			return nil, fmt.Errorf("invalid UGC: \"%s\" in \"%s\"", tok, s)
+		}
+
+		if first == "ALL" || last == "" {
+			codes = append(codes, prefix+first)
+			continue
+		}
+		from, _ := strconv.Atoi(first)
+		to, _ := strconv.Atoi(last)
+		if to < from {
+			return nil, fmt.Errorf("invalid UGC range: \"%s\" in \"%s\"", tok, s)
+		}
+		for n := from; n <= to; n++ {
+			codes = append(codes, fmt.Sprintf("%s%03d", prefix, n))
+		}
+	}
+	return codes, nil
+}
+
+// expandUGCGeocodes returns UGC geocode values split into individual codes
+// (see splitGeocodes) with any compressed UGC strings expanded. Values that
+// can't be expanded are kept as is.
+func expandUGCGeocodes(values []string) []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, v := range splitGeocodes(values) {
+		expanded := []string{v}
+		if strings.ContainsAny(v, "->") {
+			if ugcs, err := ExpandUGC(v); err == nil {
+				expanded = ugcs
+			}
+		}
+		for _, code := range expanded {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes
+}
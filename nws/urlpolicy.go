@@ -0,0 +1,104 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultURLPolicyHosts are the hosts (and their subdomains) that a Client will
+// follow links to by default.
+var defaultURLPolicyHosts = []string{"weather.gov", "noaa.gov"}
+
+// A URLPolicy determines which URLs found in API responses (alert documents,
+// resources, redirects) a Client may automatically fetch.
+type URLPolicy struct {
+	// AllowedHosts contains host names that may be fetched. A host name also
+	// allows all of its subdomains (e.g. "weather.gov" allows
+	// "api.weather.gov").
+	AllowedHosts []string
+
+	// UpgradeHTTP causes `http` URLs to be fetched using `https` instead.
+	// When false, `http` URLs are rejected.
+	UpgradeHTTP bool
+}
+
+// NewDefaultURLPolicy returns a URLPolicy that allows *.weather.gov and
+// *.noaa.gov and upgrades `http` URLs to `https`.
+func NewDefaultURLPolicy() URLPolicy {
+	return URLPolicy{
+		AllowedHosts: append([]string(nil), defaultURLPolicyHosts...),
+		UpgradeHTTP:  true,
+	}
+}
+
+// Check validates a URL against the policy and returns the URL that should be
+// fetched in its place, which may have been upgraded to `https`.
+func (p URLPolicy) Check(urlString string) (*url.URL, error) {
+	u, err := url.Parse(urlString)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.check(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// check validates u against the policy, upgrading its scheme in place.
+func (p URLPolicy) check(u *url.URL) error {
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+	case "http":
+		if !p.UpgradeHTTP {
+			return fmt.Errorf("URL must use `https`: %s", u.String())
+		}
+		u.Scheme = "https"
+		if u.Port() == "80" {
+			u.Host = u.Hostname()
+		}
+	default:
+		return fmt.Errorf("URL scheme must be `https`: %s", u.String())
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return errors.New("URL has no host")
+	}
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "."))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("URL host is not allowed: %s", host)
+}
+
+// checkRedirect is used as an http.Client's CheckRedirect function. Redirects
+// to the host of the original request (e.g. the API adjusting point precision)
+// are always followed; all others must satisfy the policy.
+func (p URLPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if len(via) > 0 && req.URL.Host == via[0].URL.Host && req.URL.Scheme == via[0].URL.Scheme {
+		return nil
+	}
+	return p.check(req.URL)
+}
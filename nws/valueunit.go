@@ -18,4 +18,17 @@ package nws
 type ValueUnit struct {
 	Value float64
 	Unit  string
+	QC    string `json:",omitempty"` // quality control code, observations only; see QCVerified etc.
+}
+
+// Convert returns the value converted to another unit of temperature ("F" or
+// "C"), speed, or length, or the value as is if the units are the same.
+func (vu ValueUnit) Convert(unit string) (ValueUnit, error) {
+	return convertValueUnit(vu, unit)
+}
+
+// Suspect reports whether the value failed quality control and should not be
+// trusted. Values without a quality control code are not suspect.
+func (vu ValueUnit) Suspect() bool {
+	return vu.QC == QCRejected || vu.QC == QCQuestioned || vu.QC == QCSubjectiveBad
 }
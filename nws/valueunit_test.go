@@ -13,3 +13,39 @@
 // limitations under the License.
 
 package nws
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValueUnitConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		vu      ValueUnit
+		unit    string
+		want    float64
+		wantErr bool
+	}{
+		{"same unit", ValueUnit{Value: 101325, Unit: "Pa"}, "Pa", 101325, false},
+		{"temperature", ValueUnit{Value: 212, Unit: "F"}, "C", 100, false},
+		{"speed", ValueUnit{Value: 36, Unit: "km/h"}, "m/s", 10, false},
+		{"knots", ValueUnit{Value: 10, Unit: "kt"}, "m/s", 5.144444, false},
+		{"length", ValueUnit{Value: 1, Unit: "in"}, "mm", 25.4, false},
+		{"incompatible", ValueUnit{Value: 10, Unit: "m/s"}, "C", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.vu.Convert(tt.unit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v; want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Unit != tt.unit || math.Abs(got.Value-tt.want) > 1e-6 {
+				t.Errorf("got %v; want %v %s", got, tt.want, tt.unit)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ventilation schedule actions.
+const (
+	VentilationOpen  = "open"
+	VentilationClose = "close"
+)
+
+// DefaultVentilationCriteria keep a home between 65 and 75 °F without letting
+// in humid air or rain.
+var DefaultVentilationCriteria = VentilationCriteria{
+	IndoorMin:                   ValueUnit{Value: 65, Unit: "F"},
+	IndoorMax:                   ValueUnit{Value: 75, Unit: "F"},
+	RelativeHumidityMax:         ValueUnit{Value: 70, Unit: "percent"},
+	DewpointMax:                 ValueUnit{Value: 60, Unit: "F"},
+	PrecipitationProbabilityMax: 40,
+	MinDuration:                 time.Hour,
+}
+
+// VentilationCriteria describe when outdoor air may be let in to keep a home
+// within a target indoor temperature band. An hour is suitable if the outdoor
+// temperature is within the band, the humidity and dew point are below their
+// maximums, and the chance of precipitation is below
+// PrecipitationProbabilityMax percent.
+type VentilationCriteria struct {
+	IndoorMin                   ValueUnit     // "F" or "C"
+	IndoorMax                   ValueUnit     // "F" or "C"
+	RelativeHumidityMax         ValueUnit     // "percent"; empty to ignore humidity
+	DewpointMax                 ValueUnit     // "F" or "C"; empty to ignore dew point
+	PrecipitationProbabilityMax int           // percent
+	MinDuration                 time.Duration // windows shorter than this are not returned
+}
+
+// A VentilationWindow is a window of time during which outdoor air satisfies
+// a set of VentilationCriteria, such as for opening windows or running a
+// whole-house fan.
+type VentilationWindow struct {
+	TimeStart      time.Time
+	TimeEnd        time.Time
+	TemperatureMin ValueUnit // the lowest outdoor temperature in the window
+	TemperatureMax ValueUnit // the highest outdoor temperature in the window
+}
+
+// Duration returns the length of the window.
+func (w VentilationWindow) Duration() time.Duration {
+	return w.TimeEnd.Sub(w.TimeStart)
+}
+
+// A VentilationEvent is a scheduled action for home automation.
+type VentilationEvent struct {
+	Time   time.Time
+	Action string // VentilationOpen or VentilationClose
+}
+
+// VentilationWindows returns the windows of consecutive periods during which
+// outdoor air satisfies the criteria, in chronological order. It is intended
+// for use with the hourly forecast.
+//
+// A period without a temperature ends a window. A period without a humidity
+// or dew point is judged by the remaining criteria, since older versions of
+// the API don't provide them.
+func (f Forecast) VentilationWindows(criteria VentilationCriteria) ([]VentilationWindow, error) {
+	unit := criteria.IndoorMin.Unit
+	indoorMax, err := convertTemperature(criteria.IndoorMax, unit)
+	if err != nil {
+		return nil, err
+	}
+	if criteria.RelativeHumidityMax.Unit != "" && criteria.RelativeHumidityMax.Unit != "percent" {
+		return nil, fmt.Errorf("unsupported relative humidity unit: \"%s\"", criteria.RelativeHumidityMax.Unit)
+	}
+	var dewpointMax ValueUnit
+	if criteria.DewpointMax.Unit != "" {
+		if dewpointMax, err = convertTemperature(criteria.DewpointMax, unit); err != nil {
+			return nil, err
+		}
+	}
+
+	suitable := func(p Period) bool {
+		if p.Temperature.Unit == "" {
+			return false
+		}
+		t, err := convertTemperature(p.Temperature, unit)
+		if err != nil || t.Value < criteria.IndoorMin.Value || t.Value > indoorMax.Value {
+			return false
+		}
+		if criteria.RelativeHumidityMax.Unit != "" && p.RelativeHumidity.Unit != "" && p.RelativeHumidity.Value >= criteria.RelativeHumidityMax.Value {
+			return false
+		}
+		if dewpointMax.Unit != "" && p.Dewpoint.Unit != "" {
+			if td, err := convertTemperature(p.Dewpoint, unit); err != nil || td.Value >= dewpointMax.Value {
+				return false
+			}
+		}
+		return p.precipitationProbability() < criteria.PrecipitationProbabilityMax
+	}
+
+	var windows []VentilationWindow
+	var cur *VentilationWindow
+	end := func() {
+		if cur != nil && cur.Duration() >= criteria.MinDuration {
+			windows = append(windows, *cur)
+		}
+		cur = nil
+	}
+
+	for _, p := range f.Periods {
+		ok := suitable(p)
+		if !ok || (cur != nil && !p.TimeStart.Equal(cur.TimeEnd)) {
+			end()
+		}
+		if !ok {
+			continue
+		}
+		t, _ := convertTemperature(p.Temperature, unit)
+		if cur == nil {
+			cur = &VentilationWindow{TimeStart: p.TimeStart, TemperatureMin: t, TemperatureMax: t}
+		}
+		cur.TimeEnd = p.TimeEnd
+		if t.Value < cur.TemperatureMin.Value {
+			cur.TemperatureMin = t
+		}
+		if t.Value > cur.TemperatureMax.Value {
+			cur.TemperatureMax = t
+		}
+	}
+	end()
+
+	return windows, nil
+}
+
+// VentilationSchedule returns an open event at the start and a close event at
+// the end of each window, in chronological order.
+func VentilationSchedule(windows []VentilationWindow) []VentilationEvent {
+	var events []VentilationEvent
+	for _, w := range windows {
+		events = append(events,
+			VentilationEvent{Time: w.TimeStart, Action: VentilationOpen},
+			VentilationEvent{Time: w.TimeEnd, Action: VentilationClose},
+		)
+	}
+	return events
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// VerificationOptions configure VerifyForecasts.
+type VerificationOptions struct {
+	// PrecipitationThreshold is the probability of precipitation, in percent,
+	// at or above which a period is considered to forecast precipitation.
+	PrecipitationThreshold int
+
+	// LeadTimeStep is the width of the lead time buckets that scores are
+	// grouped into.
+	LeadTimeStep time.Duration
+}
+
+// DefaultVerificationOptions suit hourly forecasts.
+var DefaultVerificationOptions = VerificationOptions{
+	PrecipitationThreshold: 50,
+	LeadTimeStep:           time.Hour,
+}
+
+// A LeadTimeScore holds forecast error metrics for periods whose start was
+// between LeadTime and LeadTime plus the lead time step after the forecast
+// was issued.
+type LeadTimeScore struct {
+	LeadTime time.Duration
+
+	// Temperature errors, in degrees F, for periods with both a forecast and
+	// an observed temperature.
+	TemperatureCount int
+	TemperatureMAE   float64 // mean absolute error
+	TemperatureBias  float64 // mean of forecast minus observed
+
+	// Precipitation contingency counts for periods with an observed one hour
+	// precipitation amount.
+	PrecipitationHits             int // forecast and observed
+	PrecipitationMisses           int // observed but not forecast
+	PrecipitationFalseAlarms      int // forecast but not observed
+	PrecipitationCorrectNegatives int // neither forecast nor observed
+}
+
+// POD returns the probability of detection of precipitation, or NaN if
+// precipitation was never observed.
+func (s LeadTimeScore) POD() float64 {
+	n := s.PrecipitationHits + s.PrecipitationMisses
+	if n == 0 {
+		return math.NaN()
+	}
+	return float64(s.PrecipitationHits) / float64(n)
+}
+
+// FAR returns the false alarm ratio of precipitation, or NaN if precipitation
+// was never forecast.
+func (s LeadTimeScore) FAR() float64 {
+	n := s.PrecipitationHits + s.PrecipitationFalseAlarms
+	if n == 0 {
+		return math.NaN()
+	}
+	return float64(s.PrecipitationFalseAlarms) / float64(n)
+}
+
+// VerifyForecasts aligns the periods of past forecasts, typically hourly
+// forecasts retrieved over time, with observations and scores them by lead
+// time. Each period is compared with the last observation taken during it.
+// Lead time is measured from the forecast's TimeForecast, or TimeRetrieved
+// if that is zero. Scores are ordered by lead time.
+func VerifyForecasts(forecasts []Forecast, observations []Observation, opts VerificationOptions) []LeadTimeScore {
+	if opts.LeadTimeStep <= 0 {
+		opts.LeadTimeStep = DefaultVerificationOptions.LeadTimeStep
+	}
+
+	obs := append([]Observation(nil), observations...)
+	sort.Slice(obs, func(i, j int) bool { return obs[i].TimeObserved.Before(obs[j].TimeObserved) })
+
+	type sums struct {
+		score         LeadTimeScore
+		absErr, sumErr float64
+	}
+	buckets := make(map[time.Duration]*sums)
+
+	for _, f := range forecasts {
+		issued := f.TimeForecast
+		if issued.IsZero() {
+			issued = f.TimeRetrieved
+		}
+		for _, p := range f.Periods {
+			if p.Derived || p.TimeStart.Before(issued) {
+				continue
+			}
+			o, ok := lastObservationDuring(obs, p.TimeStart, p.TimeEnd)
+			if !ok {
+				continue
+			}
+			lead := p.TimeStart.Sub(issued).Truncate(opts.LeadTimeStep)
+			b, ok := buckets[lead]
+			if !ok {
+				b = &sums{score: LeadTimeScore{LeadTime: lead}}
+				buckets[lead] = b
+			}
+
+			ft, ferr := convertTemperature(p.Temperature, "F")
+			ot, oerr := convertTemperature(o.Temperature, "F")
+			if ferr == nil && oerr == nil {
+				b.score.TemperatureCount++
+				b.absErr += math.Abs(ft.Value - ot.Value)
+				b.sumErr += ft.Value - ot.Value
+			}
+
+			if o.PrecipitationLastHour.Unit != "" {
+				forecast := p.precipitationProbability() >= opts.PrecipitationThreshold
+				observed := o.PrecipitationLastHour.Value > 0
+				switch {
+				case forecast && observed:
+					b.score.PrecipitationHits++
+				case observed:
+					b.score.PrecipitationMisses++
+				case forecast:
+					b.score.PrecipitationFalseAlarms++
+				default:
+					b.score.PrecipitationCorrectNegatives++
+				}
+			}
+		}
+	}
+
+	var scores []LeadTimeScore
+	for _, b := range buckets {
+		if n := float64(b.score.TemperatureCount); n > 0 {
+			b.score.TemperatureMAE = b.absErr / n
+			b.score.TemperatureBias = b.sumErr / n
+		}
+		scores = append(scores, b.score)
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].LeadTime < scores[j].LeadTime })
+	return scores
+}
+
+// lastObservationDuring returns the last of obs, which must be sorted, taken
+// at or after start and before end.
+func lastObservationDuring(obs []Observation, start time.Time, end time.Time) (Observation, bool) {
+	i := sort.Search(len(obs), func(i int) bool { return !obs[i].TimeObserved.Before(end) })
+	if i == 0 || obs[i-1].TimeObserved.Before(start) {
+		return Observation{}, false
+	}
+	return obs[i-1], true
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vtecTimeLayout is the layout of VTEC times. A time of all zeros means that
+// the time is unspecified.
+const (
+	vtecTimeLayout      = "060102T1504Z"
+	vtecUnspecifiedTime = "000000T0000Z"
+)
+
+var (
+	// VTECProductClasses are defined in NWS Directive 10-1703.
+	VTECProductClasses = map[string]string{
+		"O": "Operational",
+		"T": "Test",
+		"E": "Experimental",
+		"X": "Experimental VTEC in an operational product",
+	}
+
+	// VTECActions are defined in NWS Directive 10-1703.
+	VTECActions = map[string]string{
+		"NEW": "New event",
+		"CON": "Event continued",
+		"EXT": "Event extended (time)",
+		"EXA": "Event extended (area)",
+		"EXB": "Event extended (both time and area)",
+		"UPG": "Event upgraded",
+		"CAN": "Event cancelled",
+		"EXP": "Event expiring",
+		"COR": "Correction",
+		"ROU": "Routine",
+	}
+
+	// VTECSignificances are defined in NWS Directive 10-1703.
+	VTECSignificances = map[string]string{
+		"W": "Warning",
+		"A": "Watch",
+		"Y": "Advisory",
+		"S": "Statement",
+		"F": "Forecast",
+		"O": "Outlook",
+		"N": "Synopsis",
+	}
+)
+
+// A VTEC is a parsed P-VTEC (Valid Time Event Code) string, such as
+// "/O.CON.KSEW.WS.W.0007.000000T0000Z-170304T0800Z/", which identifies the
+// event that an alert is about and what the alert does to it.
+type VTEC struct {
+	ProductClass        string // must be a key in VTECProductClasses
+	Action              string // must be a key in VTECActions
+	Office              string // e.g. "KSEW"
+	Phenomenon          string // e.g. "WS" (winter storm)
+	Significance        string // must be a key in VTECSignificances
+	EventTrackingNumber int
+
+	TimeBegin time.Time // zero if the event is already in progress
+	TimeEnd   time.Time // zero if the event continues until further notice
+}
+
+// ParseVTEC parses a P-VTEC string. The slashes around it are optional.
+// Hydrologic (H-VTEC) strings are not supported.
+func ParseVTEC(s string) (VTEC, error) {
+	fields := strings.Split(strings.Trim(strings.TrimSpace(s), "/"), ".")
+	if len(fields) != 7 {
+		return VTEC{}, fmt.Errorf("VTEC must have seven fields: \"%s\"", s)
+	}
+
+	var v VTEC
+	v.ProductClass = fields[0]
+	if _, ok := VTECProductClasses[v.ProductClass]; !ok {
+		return VTEC{}, fmt.Errorf("invalid VTEC product class: \"%s\"", s)
+	}
+	v.Action = fields[1]
+	if _, ok := VTECActions[v.Action]; !ok {
+		return VTEC{}, fmt.Errorf("invalid VTEC action: \"%s\"", s)
+	}
+	v.Office = fields[2]
+	if len(v.Office) != 4 {
+		return VTEC{}, fmt.Errorf("invalid VTEC office: \"%s\"", s)
+	}
+	v.Phenomenon = fields[3]
+	if len(v.Phenomenon) != 2 {
+		return VTEC{}, fmt.Errorf("invalid VTEC phenomenon: \"%s\"", s)
+	}
+	v.Significance = fields[4]
+	if _, ok := VTECSignificances[v.Significance]; !ok {
+		return VTEC{}, fmt.Errorf("invalid VTEC significance: \"%s\"", s)
+	}
+	etn, err := strconv.Atoi(fields[5])
+	if err != nil || len(fields[5]) != 4 {
+		return VTEC{}, fmt.Errorf("invalid VTEC event tracking number: \"%s\"", s)
+	}
+	v.EventTrackingNumber = etn
+
+	times := strings.Split(fields[6], "-")
+	if len(times) != 2 {
+		return VTEC{}, fmt.Errorf("VTEC times must be separated by a hyphen: \"%s\"", s)
+	}
+	if v.TimeBegin, err = parseVTECTime(times[0]); err != nil {
+		return VTEC{}, err
+	}
+	if v.TimeEnd, err = parseVTECTime(times[1]); err != nil {
+		return VTEC{}, err
+	}
+	return v, nil
+}
+
+// parseVTECTime parses a VTEC time, returning a zero time if it is
+// unspecified.
+func parseVTECTime(s string) (time.Time, error) {
+	if s == vtecUnspecifiedTime {
+		return time.Time{}, nil
+	}
+	return time.Parse(vtecTimeLayout, s)
+}
+
+// EventID returns the office, phenomenon, significance, and event tracking
+// number (e.g. "KSEW.WS.W.0007"), which together identify an event across the
+// alerts issued about it. Event tracking numbers restart each year.
+func (v VTEC) EventID() string {
+	return fmt.Sprintf("%s.%s.%s.%04d", v.Office, v.Phenomenon, v.Significance, v.EventTrackingNumber)
+}
+
+// String returns the VTEC in its original form.
+func (v VTEC) String() string {
+	return fmt.Sprintf("/%s.%s.%s.%s.%s.%04d.%s-%s/",
+		v.ProductClass, v.Action, v.Office, v.Phenomenon, v.Significance, v.EventTrackingNumber,
+		formatVTECTime(v.TimeBegin), formatVTECTime(v.TimeEnd))
+}
+
+// formatVTECTime formats a VTEC time, which is all zeros if t is zero.
+func formatVTECTime(t time.Time) string {
+	if t.IsZero() {
+		return vtecUnspecifiedTime
+	}
+	return t.UTC().Format(vtecTimeLayout)
+}
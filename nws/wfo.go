@@ -0,0 +1,157 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const getOpenAPIEndpointURLString = "openapi.json"
+
+// defaultWFOs are the weather forecast offices known to the API, from the
+// NWSForecastOfficeId enumeration of its OpenAPI document. The API has no
+// endpoint listing the offices themselves. See Client.RefreshKnownWFOs.
+var defaultWFOs = []string{
+	// Eastern Region
+	"AKQ", "ALY", "BGM", "BOX", "BTV", "BUF", "CAE", "CAR", "CHS", "CLE",
+	"CTP", "GSP", "GYX", "ILM", "ILN", "LWX", "MHX", "OKX", "PBZ", "PHI",
+	"RAH", "RLX", "RNK",
+	// Southern Region, including Puerto Rico
+	"ABQ", "AMA", "BMX", "BRO", "CRP", "EPZ", "EWX", "FFC", "FWD", "HGX",
+	"HUN", "JAN", "JAX", "KEY", "LCH", "LIX", "LUB", "LZK", "MAF", "MEG",
+	"MFL", "MLB", "MOB", "MRX", "OHX", "OUN", "SHV", "SJT", "SJU", "TAE",
+	"TBW", "TSA",
+	// Central Region
+	"ABR", "APX", "ARX", "BIS", "BOU", "CYS", "DDC", "DLH", "DMX", "DTX",
+	"DVN", "EAX", "FGF", "FSD", "GID", "GJT", "GLD", "GRB", "GRR", "ICT",
+	"ILX", "IND", "IWX", "JKL", "LBF", "LMK", "LOT", "LSX", "MKX", "MPX",
+	"MQT", "OAX", "PAH", "PUB", "RIW", "SGF", "TOP", "UNR",
+	// Western Region
+	"BOI", "BYZ", "EKA", "FGZ", "GGW", "HNX", "LKN", "LOX", "MFR", "MSO",
+	"MTR", "OTX", "PDT", "PIH", "PQR", "PSR", "REV", "SEW", "SGX", "SLC",
+	"STO", "TFX", "TWC", "VEF",
+	// Alaska Region
+	"AER", "AFC", "AFG", "AJK", "ALU",
+	// Pacific Region, including Guam and American Samoa
+	"GUM", "HPA", "HFO", "PPG", "STU",
+	// National Hurricane Center and Ocean Prediction Center (marine)
+	"NH1", "NH2", "ONA", "ONP",
+}
+
+// defaultWFOSet contains defaultWFOs.
+var defaultWFOSet = newWFOSet(defaultWFOs)
+
+// newWFOSet returns a set of uppercased weather forecast office identifiers.
+func newWFOSet(wfos []string) map[string]bool {
+	set := make(map[string]bool, len(wfos))
+	for _, wfo := range wfos {
+		set[strings.ToUpper(wfo)] = true
+	}
+	return set
+}
+
+// IsKnownWFO reports whether id is one of the weather forecast offices known
+// to this package, including those outside the contiguous United States and
+// the marine offices. Unlike ValidateWFO, which checks only the form of an
+// identifier, it rejects offices that don't exist. Case is ignored.
+func IsKnownWFO(id string) bool {
+	return defaultWFOSet[strings.ToUpper(id)]
+}
+
+// IsKnownWFO is the same as the IsKnownWFO function, but uses the offices
+// retrieved by RefreshKnownWFOs if they have been.
+func (c *Client) IsKnownWFO(id string) bool {
+	return c.knownWFOSet()[strings.ToUpper(id)]
+}
+
+// KnownWFOs returns the weather forecast offices known to the Client, sorted.
+// These are the offices retrieved by RefreshKnownWFOs, or those known to this
+// package if they haven't been.
+func (c *Client) KnownWFOs() []string {
+	set := c.knownWFOSet()
+	wfos := make([]string, 0, len(set))
+	for wfo := range set {
+		wfos = append(wfos, wfo)
+	}
+	sort.Strings(wfos)
+	return wfos
+}
+
+// RefreshKnownWFOs retrieves the weather forecast offices currently known to
+// the API from its OpenAPI document, for use by IsKnownWFO and KnownWFOs.
+func (c *Client) RefreshKnownWFOs() error {
+	wfos, err := getKnownWFOs(c.httpClient, c.httpUserAgentString, c.apiURLString)
+	if err != nil {
+		return err
+	}
+	set := newWFOSet(wfos)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.wfos = set
+	return nil
+}
+
+// knownWFOSet returns the offices retrieved by RefreshKnownWFOs, or those
+// known to this package if they haven't been. It takes c.mu to read c.wfos.
+// The set is replaced rather than modified on refresh, so callers may read the
+// returned set after the lock is released.
+func (c *Client) knownWFOSet() map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wfos == nil {
+		return defaultWFOSet
+	}
+	return c.wfos
+}
+
+// getKnownWFOs retrieves the weather forecast offices known to the API.
+func getKnownWFOs(httpClient *http.Client, httpUserAgentString string, apiURLString string) ([]string, error) {
+	respBody, err := doAPIRequest(httpClient, httpUserAgentString, apiURLString, getOpenAPIEndpointURLString, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newWFOsFromOpenAPIRespBody(respBody)
+}
+
+// newWFOsFromOpenAPIRespBody returns the weather forecast offices enumerated
+// by the NWSForecastOfficeId schema of the API's OpenAPI document. Malformed
+// identifiers are skipped.
+func newWFOsFromOpenAPIRespBody(respBody []byte) ([]string, error) {
+	specRaw := struct {
+		Components struct {
+			Schemas map[string]struct {
+				Enum []string
+			}
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &specRaw); err != nil {
+		return nil, err
+	}
+
+	var wfos []string
+	for _, wfo := range specRaw.Components.Schemas["NWSForecastOfficeId"].Enum {
+		if ValidateWFO(wfo) == nil {
+			wfos = append(wfos, strings.ToUpper(wfo))
+		}
+	}
+	if len(wfos) == 0 {
+		return nil, errors.New("OpenAPI document does not enumerate any forecast offices")
+	}
+	return wfos, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestRefreshKnownWFOsConcurrent is meant to be run with -race.
+func TestRefreshKnownWFOsConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"components": {"schemas": {"NWSForecastOfficeId": {"enum": ["PQR", "SEW"]}}}}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client(), httpUserAgentString: "test", apiURLString: srv.URL + "/"}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := c.RefreshKnownWFOs(); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			c.IsKnownWFO("PQR")
+			c.KnownWFOs()
+		}()
+	}
+	wg.Wait()
+	if got := c.KnownWFOs(); len(got) != 2 || got[0] != "PQR" || got[1] != "SEW" {
+		t.Errorf("got %v; want [PQR SEW]", got)
+	}
+}
+
+// TestNonCONUSOffices checks that the offices outside the contiguous United
+// States are known and that their icons and wind speeds, which may use the
+// marine icon set and knots, are parsed.
+func TestNonCONUSOffices(t *testing.T) {
+	tests := []struct {
+		name      string
+		wfo       string
+		iconURL   string
+		windSpeed string
+		wantSet   string
+		wantCode  string
+		wantWind  ValueUnit // maximum
+	}{
+		{"Puerto Rico", "SJU", "https://api.weather.gov/icons/land/night/tsra_hi,40?size=medium", "10 mph", "land", "tsra_hi", ValueUnit{Value: 10, Unit: "mph"}},
+		{"Alaska", "AFC", "https://api.weather.gov/icons/land/day/snow,60?size=medium", "15 to 25 mph", "land", "snow", ValueUnit{Value: 25, Unit: "mph"}},
+		{"Hawaii", "HFO", "https://api.weather.gov/icons/land/day/wind_few?size=medium", "15 to 20 mph", "land", "wind_few", ValueUnit{Value: 20, Unit: "mph"}},
+		{"Guam", "GUM", "https://api.weather.gov/icons/land/day/tropical_storm?size=medium", "25 to 35 mph", "land", "tropical_storm", ValueUnit{Value: 35, Unit: "mph"}},
+		{"American Samoa", "PPG", "https://api.weather.gov/icons/land/night/rain_showers,30?size=medium", "5 mph", "land", "rain_showers", ValueUnit{Value: 5, Unit: "mph"}},
+		{"National Hurricane Center", "NH1", "https://api.weather.gov/icons/marine/day/hurricane?size=medium", "25 to 30 kt", "marine", "hurricane", ValueUnit{Value: 30, Unit: "kt"}},
+		{"Ocean Prediction Center", "ONA", "https://api.weather.gov/icons/marine/night/rain?size=medium", "10 to 15 knots", "marine", "rain", ValueUnit{Value: 15, Unit: "kt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWFO(tt.wfo); err != nil {
+				t.Errorf("ValidateWFO(%s): %v", tt.wfo, err)
+			}
+			if !IsKnownWFO(tt.wfo) {
+				t.Errorf("IsKnownWFO(%s): got false", tt.wfo)
+			}
+			icon, err := ParseIconURL(tt.iconURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if icon.Set != tt.wantSet || len(icon.Conditions) != 1 || icon.Conditions[0].Code != tt.wantCode {
+				t.Errorf("got icon %+v; want set %s and condition %s", icon, tt.wantSet, tt.wantCode)
+			}
+			if _, max := parseWindSpeedRange(tt.windSpeed); max != tt.wantWind {
+				t.Errorf("got wind %+v; want %+v", max, tt.wantWind)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// compassPoints contains the direction in degrees of each of the sixteen
+// compass points used in forecasts.
+var compassPoints = map[string]float64{
+	"N": 0, "NNE": 22.5, "NE": 45, "ENE": 67.5,
+	"E": 90, "ESE": 112.5, "SE": 135, "SSE": 157.5,
+	"S": 180, "SSW": 202.5, "SW": 225, "WSW": 247.5,
+	"W": 270, "WNW": 292.5, "NW": 315, "NNW": 337.5,
+}
+
+// WindShiftTypes
+const (
+	WindVeering = "veering" // clockwise (e.g. S to W)
+	WindBacking = "backing" // counterclockwise (e.g. W to S)
+)
+
+// WindShiftCriteria describe the wind shifts to detect.
+type WindShiftCriteria struct {
+	MinChange float64       // degrees the direction must turn through
+	Window    time.Duration // within this length of time
+
+	// MinSpeed may be used to ignore light winds, whose direction is often
+	// meaningless. It may use any of the units "mph", "kt", "km/h", or
+	// "m/s", and is ignored if empty.
+	MinSpeed ValueUnit
+}
+
+// A WindShift is a change in forecast wind direction.
+type WindShift struct {
+	TimeStart      time.Time // start of the period before the shift
+	TimeEnd        time.Time // start of the period after the shift
+	DirectionStart string
+	DirectionEnd   string
+	Change         float64 // degrees turned through; positive when veering
+	Type           string  // WindVeering or WindBacking
+}
+
+// WindShifts returns the shifts in wind direction that satisfy the criteria,
+// in chronological order. It is intended for use with the hourly forecast.
+//
+// The direction is followed from period to period along the shorter way
+// around the compass, so a wind that veers steadily from S through W to N has
+// turned through 180 degrees rather than none. Shifts do not overlap. Periods
+// without a compass direction (e.g. variable winds) or below MinSpeed are
+// skipped.
+func (f Forecast) WindShifts(criteria WindShiftCriteria) ([]WindShift, error) {
+	if criteria.MinChange <= 0 || criteria.Window <= 0 {
+		return nil, errors.New("MinChange and Window must be positive")
+	}
+	if criteria.MinSpeed.Unit != "" {
+		if _, err := convertSpeed(criteria.MinSpeed, "mph"); err != nil {
+			return nil, err
+		}
+	}
+
+	type point struct {
+		p       Period
+		heading float64 // unwrapped, so it may be outside [0, 360)
+	}
+	var pts []point
+	for _, p := range f.Periods {
+		deg, ok := compassDegrees(p.WindDirection)
+		if !ok {
+			continue
+		}
+		if criteria.MinSpeed.Unit != "" {
+			speed := p.WindSpeedMax
+			if speed.Unit == "" {
+				speed = p.WindSpeedMin
+			}
+			if c := compareSpeeds(speed, criteria.MinSpeed); c == -1 || c == 2 {
+				continue
+			}
+		}
+		if len(pts) > 0 {
+			prev := pts[len(pts)-1].heading
+			deg = prev + angleDifference(math.Mod(prev, 360), deg)
+		}
+		pts = append(pts, point{p: p, heading: deg})
+	}
+
+	var shifts []WindShift
+	for i := 0; i < len(pts); i++ {
+		for j := i + 1; j < len(pts) && pts[j].p.TimeStart.Sub(pts[i].p.TimeStart) <= criteria.Window; j++ {
+			change := pts[j].heading - pts[i].heading
+			if math.Abs(change) < criteria.MinChange {
+				continue
+			}
+			s := WindShift{
+				TimeStart:      pts[i].p.TimeStart,
+				TimeEnd:        pts[j].p.TimeStart,
+				DirectionStart: pts[i].p.WindDirection,
+				DirectionEnd:   pts[j].p.WindDirection,
+				Change:         change,
+				Type:           WindVeering,
+			}
+			if change < 0 {
+				s.Type = WindBacking
+			}
+			shifts = append(shifts, s)
+			i = j - 1 // continue from the end of this shift
+			break
+		}
+	}
+
+	return shifts, nil
+}
+
+// WindDirectionAt returns the forecast wind direction in degrees at a time,
+// interpolated along the shorter way around the compass between the middles
+// of the periods on either side. Before the middle of the first period and
+// after the middle of the last, the direction of that period is returned.
+// false is returned if there is no direction at or around the time.
+func (f Forecast) WindDirectionAt(t time.Time) (float64, bool) {
+	var prevT, prevEnd time.Time
+	var prevDeg float64
+	havePrev := false
+	for _, p := range f.Periods {
+		deg, ok := compassDegrees(p.WindDirection)
+		if !ok {
+			continue
+		}
+		mid := p.TimeStart.Add(p.TimeEnd.Sub(p.TimeStart) / 2)
+		if !t.After(mid) {
+			if !havePrev {
+				if t.Before(p.TimeStart) {
+					return 0, false
+				}
+				return deg, true
+			}
+			frac := float64(t.Sub(prevT)) / float64(mid.Sub(prevT))
+			return normalizeDegrees(prevDeg + frac*angleDifference(prevDeg, deg)), true
+		}
+		prevT, prevEnd, prevDeg, havePrev = mid, p.TimeEnd, deg, true
+	}
+	if havePrev && t.Before(prevEnd) {
+		return prevDeg, true // in the second half of the last period
+	}
+	return 0, false
+}
+
+// compassDegrees returns the direction in degrees of a compass point (e.g.
+// "NW").
+func compassDegrees(dir string) (float64, bool) {
+	deg, ok := compassPoints[strings.ToUpper(strings.TrimSpace(dir))]
+	return deg, ok
+}
+
+// angleDifference returns the signed change in degrees from one direction to
+// another the shorter way around, in (-180, 180]. Positive is clockwise.
+func angleDifference(from float64, to float64) float64 {
+	d := math.Mod(to-from, 360)
+	switch {
+	case d > 180:
+		d -= 360
+	case d <= -180:
+		d += 360
+	}
+	return d
+}
+
+// normalizeDegrees returns a direction in [0, 360).
+func normalizeDegrees(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestForecastWindDirectionAt(t *testing.T) {
+	start := time.Date(2019, 8, 30, 12, 0, 0, 0, time.UTC)
+	period := func(h int, dir string) Period {
+		return Period{
+			TimeStart:     start.Add(time.Duration(h) * time.Hour),
+			TimeEnd:       start.Add(time.Duration(h+1) * time.Hour),
+			WindDirection: dir,
+		}
+	}
+	f := Forecast{Periods: []Period{
+		period(0, "NNW"),
+		period(1, "NNE"),
+		period(2, "VRB"),
+		period(3, "E"),
+	}}
+
+	tests := []struct {
+		name   string
+		t      time.Duration // after start
+		want   float64
+		wantOK bool
+	}{
+		{"before the first period", -time.Minute, 0, false},
+		{"first half of the first period", 15 * time.Minute, 337.5, true},
+		{"across north", 60 * time.Minute, 0, true},
+		{"second half of a period", 75 * time.Minute, 11.25, true},
+		{"at a middle", 90 * time.Minute, 22.5, true},
+		{"across a period without a direction", 150 * time.Minute, 56.25, true},
+		{"second half of the last period", 225 * time.Minute, 90, true},
+		{"after the last period", 4 * time.Hour, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := f.WindDirectionAt(start.Add(tt.t))
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const getZoneEndpointURLStringFmt = "zones/%s/%s" // type, id
+
+// maxZoneGeometryVertices limits the total number of vertices accepted in a
+// zone's geometry. Zones follow coastlines and rivers, so they have many more
+// than alert polygons.
+const maxZoneGeometryVertices = 500000
+
+// ZoneGeometry returns the polygons (outer rings) of a forecast or county zone
+// (e.g. "ORZ006" or "ORC051"). Geometries are retrieved from the NWS API once
+// and then cached for the life of the Client, since zones rarely change.
+func (c *Client) ZoneGeometry(id string) ([][]Point, error) {
+	if err := ValidateZoneID(id); err != nil {
+		return nil, err
+	}
+	id = strings.ToUpper(id)
+	c.mu.Lock()
+	polys, ok := c.zoneGeometries[id]
+	c.mu.Unlock()
+	if ok {
+		return polys, nil
+	}
+	polys, err := getZoneGeometry(c.httpClient, c.httpUserAgentString, c.apiURLString, zoneTypeForZoneID(id), id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.zoneGeometries == nil {
+		c.zoneGeometries = make(map[string][][]Point)
+	}
+	c.zoneGeometries[id] = polys
+	return polys, nil
+}
+
+// ResolveAlertPolygonsFromZones sets the polygons of alerts that have none to
+// the geometry of their UGC zones (or affected zones, if they have no UGC
+// codes), so that CoversPoint works for alerts that only have geocodes. Such
+// alerts have PolygonsFromZones set. Alerts that already have polygons are
+// left alone.
+//
+// All alerts are resolved even if some zones can't be retrieved; the first
+// error is returned and the alerts with zones that failed are left without
+// polygons.
+func (c *Client) ResolveAlertPolygonsFromZones(alerts []Alert) error {
+	var firstErr error
+	for i := range alerts {
+		a := &alerts[i]
+		if len(a.Polygons) > 0 {
+			continue
+		}
+		zones := a.UGCCodes
+		if len(zones) < 1 {
+			zones = a.AffectedZones
+		}
+		var polys [][]Point
+		var err error
+		for _, z := range zones {
+			var zPolys [][]Point
+			if zPolys, err = c.ZoneGeometry(z); err != nil {
+				break
+			}
+			polys = append(polys, zPolys...)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if len(polys) > 0 {
+			a.Polygons = polys
+			a.PolygonsFromZones = true
+		}
+	}
+	return firstErr
+}
+
+// zoneTypeForZoneID returns the NWS API zone type for a UGC code: "county" for
+// county codes (e.g. "ORC051") and "forecast" for zone codes (e.g. "ORZ006").
+func zoneTypeForZoneID(id string) string {
+	if len(id) > 2 && (id[2] == 'C' || id[2] == 'c') {
+		return "county"
+	}
+	return "forecast"
+}
+
+// getZoneGeometry retrieves from the NWS API the geometry of a zone of a given
+// type ("forecast", "county", etc.).
+func getZoneGeometry(httpClient *http.Client, httpUserAgentString string, apiURLString string, zoneType string, zoneID string) ([][]Point, error) {
+	respBody, err := doAPIRequest(
+		httpClient,
+		httpUserAgentString,
+		apiURLString,
+		fmt.Sprintf(getZoneEndpointURLStringFmt, zoneType, zoneID),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newZoneGeometryFromZoneRespBody(respBody)
+}
+
+// newZoneGeometryFromZoneRespBody returns the polygons of a zone, given a
+// response body from the NWS API. Polygons within a GeometryCollection are
+// included.
+func newZoneGeometryFromZoneRespBody(respBody []byte) ([][]Point, error) {
+	zRaw := struct {
+		Geometry *geoJSONGeometry
+	}{}
+	if err := json.Unmarshal(respBody, &zRaw); err != nil {
+		return nil, err
+	}
+	if zRaw.Geometry == nil {
+		return nil, ErrEmptyResponse
+	}
+
+	geometries := []geoJSONGeometry{*zRaw.Geometry}
+	if zRaw.Geometry.Type == "GeometryCollection" {
+		geometries = zRaw.Geometry.Geometries
+	}
+	var polys [][]Point
+	for _, g := range geometries {
+		if g.Type != "Polygon" && g.Type != "MultiPolygon" {
+			continue
+		}
+		gPolys, err := newPolygonsFromGeoJSONGeometryLimited(g.Type, g.Coordinates, maxZoneGeometryVertices)
+		if err != nil {
+			return nil, err
+		}
+		polys = append(polys, gPolys...)
+	}
+	if len(polys) < 1 {
+		return nil, ErrEmptyResponse
+	}
+	return polys, nil
+}
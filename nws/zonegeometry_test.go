@@ -0,0 +1,50 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestZoneGeometryConcurrent is meant to be run with -race.
+func TestZoneGeometryConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/geo+json")
+		fmt.Fprint(w, `{"geometry": {"type": "Polygon", "coordinates": [[[-122.0, 45.0], [-121.0, 45.0], [-121.0, 46.0], [-122.0, 45.0]]]}}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client(), httpUserAgentString: "test", apiURLString: srv.URL + "/"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			polys, err := c.ZoneGeometry("ORZ006")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(polys) != 1 || len(polys[0]) != 4 {
+				t.Errorf("got %v; want one polygon of 4 points", polys)
+			}
+		}()
+	}
+	wg.Wait()
+}
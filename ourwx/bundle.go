@@ -0,0 +1,147 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A Bundle holds data from several sources retrieved together, such as for a
+// briefing. Any source that failed or ran out of time is nil (or empty).
+type Bundle struct {
+	Forecast          *nws.Forecast
+	HourlyForecast    *nws.Forecast
+	CurrentConditions *nws.Observation
+	ActiveAlerts      []nws.Alert
+}
+
+// BundleOptions configures Client.Bundle.
+type BundleOptions struct {
+	// Sources lists the capability names of the sources to retrieve. All
+	// sources are retrieved if it is empty.
+	Sources []string
+
+	// Deadline is the total time budget for the bundle. Zero means no budget
+	// beyond the context's own deadline.
+	Deadline time.Duration
+
+	// SourceTimeouts limits individual sources, keyed by capability name.
+	// Sources without an entry are only limited by Deadline.
+	SourceTimeouts map[string]time.Duration
+}
+
+// bundleResult is the result of retrieving one source for a Bundle.
+type bundleResult struct {
+	source string
+	err    error
+	set    func(b *Bundle)
+}
+
+// Bundle retrieves data from several sources concurrently, returning whatever
+// was retrieved before the deadline. A single slow source doesn't hold up the
+// rest; its request continues in the background and its data are cached for
+// the next call.
+//
+// The returned Bundle is never nil. If any sources are missing, the returned
+// error is a *MultiError with an entry for each, keyed by capability name, in
+// the order the sources were requested.
+func (c *Client) Bundle(ctx context.Context, opts BundleOptions) (*Bundle, error) {
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	fetchers := map[string]func() (func(b *Bundle), error){
+		CapabilityForecast: func() (func(b *Bundle), error) {
+			f, err := c.Forecast()
+			return func(b *Bundle) { b.Forecast = &f }, err
+		},
+		CapabilityHourlyForecast: func() (func(b *Bundle), error) {
+			f, err := c.HourlyForecast()
+			return func(b *Bundle) { b.HourlyForecast = &f }, err
+		},
+		CapabilityCurrentConditions: func() (func(b *Bundle), error) {
+			o, err := c.CurrentConditions()
+			return func(b *Bundle) { b.CurrentConditions = &o }, err
+		},
+		CapabilityActiveAlerts: func() (func(b *Bundle), error) {
+			as, err := c.ActiveAlerts()
+			return func(b *Bundle) { b.ActiveAlerts = as }, err
+		},
+	}
+
+	sources := opts.Sources
+	if len(sources) == 0 {
+		sources = []string{CapabilityForecast, CapabilityHourlyForecast, CapabilityCurrentConditions, CapabilityActiveAlerts}
+	}
+
+	b := &Bundle{}
+	errs := make(map[string]error)
+	results := make(chan bundleResult, len(sources))
+	pending := make(map[string]bool)
+
+	for _, source := range sources {
+		fetch, ok := fetchers[source]
+		if !ok {
+			errs[source] = fmt.Errorf("unknown source: %s", source)
+			continue
+		}
+		pending[source] = true
+
+		go func(source string, fetch func() (func(b *Bundle), error)) {
+			sctx := ctx
+			if d, ok := opts.SourceTimeouts[source]; ok && d > 0 {
+				var cancel context.CancelFunc
+				sctx, cancel = context.WithTimeout(ctx, d)
+				defer cancel()
+			}
+
+			// fetch can't be cancelled, so race it against the context
+			done := make(chan bundleResult, 1)
+			go func() {
+				set, err := fetch()
+				done <- bundleResult{source: source, err: err, set: set}
+			}()
+			select {
+			case r := <-done:
+				results <- r
+			case <-sctx.Done():
+				results <- bundleResult{source: source, err: sctx.Err()}
+			}
+		}(source, fetch)
+	}
+
+	for len(pending) > 0 {
+		r := <-results
+		delete(pending, r.source)
+		if r.err != nil {
+			errs[r.source] = r.err
+			continue
+		}
+		r.set(b)
+	}
+
+	merr := &MultiError{}
+	for _, source := range sources {
+		merr.add(source, errs[source])
+		delete(errs, source) // a source requested twice is reported once
+	}
+	return b, merr.errOrNil()
+}
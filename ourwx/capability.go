@@ -0,0 +1,112 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CapabilityKind describes what a capability provides.
+type CapabilityKind string
+
+// CapabilityKinds
+const (
+	CapabilitySource CapabilityKind = "source" // provides data
+	CapabilityStore  CapabilityKind = "store"  // persists data
+)
+
+// Capability names for the data sources built into a Client.
+const (
+	CapabilityForecast          = "nws.forecast"
+	CapabilityHourlyForecast    = "nws.forecast.hourly"
+	CapabilityCurrentConditions = "nws.observation"
+	CapabilityActiveAlerts      = "nws.alerts"
+)
+
+// A Capability describes a feature that a Client is configured with and
+// whether it is currently working.
+type Capability struct {
+	Name string
+	Kind CapabilityKind
+
+	// Healthy is false if the most recent attempt to use the capability
+	// failed. Capabilities that have not been used yet are healthy.
+	Healthy     bool
+	LastSuccess time.Time
+	LastFailure time.Time
+	LastError   error
+}
+
+// capabilities tracks the capabilities of a Client and their health.
+type capabilities struct {
+	mu   sync.Mutex
+	caps map[string]*Capability
+}
+
+// add adds a capability if it doesn't already exist.
+func (cs *capabilities) add(name string, kind CapabilityKind) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.caps == nil {
+		cs.caps = make(map[string]*Capability)
+	}
+	if _, ok := cs.caps[name]; !ok {
+		cs.caps[name] = &Capability{Name: name, Kind: kind, Healthy: true}
+	}
+}
+
+// record records the result of using a capability.
+func (cs *capabilities) record(name string, err error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	c, ok := cs.caps[name]
+	if !ok {
+		return
+	}
+	if err != nil {
+		c.Healthy = false
+		c.LastFailure = time.Now()
+		c.LastError = err
+		return
+	}
+	c.Healthy = true
+	c.LastSuccess = time.Now()
+}
+
+// list returns copies of all capabilities, sorted by kind and then name.
+func (cs *capabilities) list() []Capability {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var l []Capability
+	for _, c := range cs.caps {
+		l = append(l, *c)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Kind != l[j].Kind {
+			return l[i].Kind < l[j].Kind
+		}
+		return l[i].Name < l[j].Name
+	})
+	return l
+}
+
+// Capabilities returns the data sources and stores that the Client is
+// configured with, along with their health. Applications can use this to adapt
+// their features.
+func (c *Client) Capabilities() []Capability {
+	return c.caps.list()
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mikecamilleri/our-data/mock"
+	"github.com/mikecamilleri/our-data/nws"
+	"github.com/mikecamilleri/our-data/ourwx"
+)
+
+func ExampleNewClient() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	httpClient := srv.Client()
+	c, err := ourwx.NewClient(httpClient, "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	o, err := c.CurrentConditions()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(o.StationID, o.Temperature.Value, o.Temperature.Unit)
+	// Output:
+	// KPDX 28.3 C
+}
+
+func ExampleClient_Bundle() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	httpClient := srv.Client()
+	c, err := ourwx.NewClient(httpClient, "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// the forecast is unavailable in this example, so the bundle is partial
+	b, err := c.Bundle(context.Background(), ourwx.BundleOptions{
+		Sources:  []string{ourwx.CapabilityForecast, ourwx.CapabilityCurrentConditions},
+		Deadline: 5 * time.Second,
+	})
+	fmt.Println("have current conditions:", b.CurrentConditions != nil)
+	fmt.Println("have forecast:", b.Forecast != nil)
+
+	var merr *ourwx.MultiError
+	if errors.As(err, &merr) {
+		fmt.Println("failed:", merr.Components())
+	}
+	// Output:
+	// have current conditions: true
+	// have forecast: false
+	// failed: [nws.forecast]
+}
+
+func ExampleFileStore() {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("testdata"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	dir, err := ioutil.TempDir("", "ourwx-example")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := ourwx.NewFileStore(dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	c, err := ourwx.NewClient(srv.Client(), "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	c.SetStore(s)
+
+	// retrieved data are recorded in the store
+	if _, err := c.CurrentConditions(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	obs, err := s.Observations(time.Time{}, time.Time{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, o := range obs {
+		fmt.Println(o.StationID, o.Temperature.Value, o.Temperature.Unit)
+	}
+	// Output:
+	// KPDX 28.3 C
+}
+
+func ExampleSQLStore() {
+	// open the database with a driver of your choosing, imported for its
+	// side effects (e.g. _ "github.com/mattn/go-sqlite3")
+	db, err := sql.Open("sqlite3", "history.db")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer db.Close()
+
+	s, err := ourwx.NewSQLStore(db)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	c, err := ourwx.NewClient(&http.Client{}, "example/1.0 (you@example.com)", 45.458, -122.6636)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	c.SetStore(s)
+
+	// after polling for a while, score the recorded hourly forecasts against
+	// the recorded observations
+	end := time.Now()
+	scores, err := ourwx.VerifyHistory(s, end.AddDate(0, 0, -30), end, nws.DefaultVerificationOptions)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, sc := range scores {
+		fmt.Printf("%v ahead: temperature off by %.1f F on average\n", sc.LeadTime, sc.TemperatureMAE)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A ZoneHazard aggregates the alerts for one event type (e.g. "Heat
+// Advisory") in one UGC zone or county.
+type ZoneHazard struct {
+	Zone     string
+	Event    string
+	Count    int           // number of distinct alerts
+	Duration time.Duration // total time in effect
+}
+
+// AlertHeatmap aggregates the alerts recorded in a Store between start and
+// end. See AggregateAlerts.
+func AlertHeatmap(s Store, start, end time.Time) ([]ZoneHazard, error) {
+	as, err := s.Alerts(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return AggregateAlerts(as, start, end), nil
+}
+
+// AggregateAlerts returns the number of alerts and the time they were in
+// effect between start and end, per zone and event type, ordered by zone and
+// then event. Each alert counts toward every zone it covers.
+//
+// Alerts may be repeated, as they are in a Store; each is counted once. An
+// alert is in effect from its effective time until it ends or expires, or
+// until it is replaced by an alert referencing it. Cancellations are not
+// counted.
+func AggregateAlerts(alerts []nws.Alert, start, end time.Time) []ZoneHazard {
+	latest := make(map[string]nws.Alert)
+	var ids []string
+	for _, a := range alerts {
+		if _, ok := latest[a.ID]; !ok {
+			ids = append(ids, a.ID)
+		}
+		latest[a.ID] = a
+	}
+
+	replaced := make(map[string]time.Time)
+	for _, a := range latest {
+		for _, ref := range a.References {
+			if t, ok := replaced[ref]; !ok || a.TimeSent.Before(t) {
+				replaced[ref] = a.TimeSent
+			}
+		}
+	}
+
+	type key struct{ zone, event string }
+	hazards := make(map[key]*ZoneHazard)
+	for _, id := range ids {
+		a := latest[id]
+		if a.MessageType == "Cancel" {
+			continue
+		}
+		as, ae := a.EffectiveRange()
+		if t, ok := replaced[a.ID]; ok && !t.IsZero() && t.Before(ae) {
+			ae = t
+		}
+		if as.Before(start) {
+			as = start
+		}
+		if !end.IsZero() && ae.After(end) {
+			ae = end
+		}
+		if !ae.After(as) {
+			continue
+		}
+
+		for _, zone := range alertZones(a) {
+			k := key{zone, a.Event}
+			h, ok := hazards[k]
+			if !ok {
+				h = &ZoneHazard{Zone: zone, Event: a.Event}
+				hazards[k] = h
+			}
+			h.Count++
+			h.Duration += ae.Sub(as)
+		}
+	}
+
+	zhs := make([]ZoneHazard, 0, len(hazards))
+	for _, h := range hazards {
+		zhs = append(zhs, *h)
+	}
+	sort.Slice(zhs, func(i, j int) bool {
+		if zhs[i].Zone != zhs[j].Zone {
+			return zhs[i].Zone < zhs[j].Zone
+		}
+		return zhs[i].Event < zhs[j].Event
+	})
+	return zhs
+}
+
+// WriteHeatmapGeoJSON writes aggregated alerts as a GeoJSON FeatureCollection
+// with one feature per zone, ready for a choropleth. Each feature's properties
+// are the zone, the total number of alerts and hours in effect, the event with
+// the most hours ("dominantEvent"), and the count and hours of each event.
+//
+// geometries maps zone IDs to GeoJSON geometry objects. Zones without one have
+// a null geometry so they can be joined with zone boundaries elsewhere.
+func WriteHeatmapGeoJSON(w io.Writer, zhs []ZoneHazard, geometries map[string]json.RawMessage) error {
+	type eventProps struct {
+		Count int     `json:"count"`
+		Hours float64 `json:"hours"`
+	}
+	type feature struct {
+		Type       string          `json:"type"`
+		Geometry   json.RawMessage `json:"geometry"`
+		Properties struct {
+			Zone          string                `json:"zone"`
+			Count         int                   `json:"count"`
+			Hours         float64               `json:"hours"`
+			DominantEvent string                `json:"dominantEvent"`
+			Events        map[string]eventProps `json:"events"`
+		} `json:"properties"`
+	}
+
+	var features []*feature
+	byZone := make(map[string]*feature)
+	for _, zh := range zhs {
+		f, ok := byZone[zh.Zone]
+		if !ok {
+			f = &feature{Type: "Feature", Geometry: json.RawMessage("null")}
+			if g, ok := geometries[zh.Zone]; ok && len(g) > 0 {
+				f.Geometry = g
+			}
+			f.Properties.Zone = zh.Zone
+			f.Properties.Events = make(map[string]eventProps)
+			byZone[zh.Zone] = f
+			features = append(features, f)
+		}
+		hours := zh.Duration.Hours()
+		f.Properties.Count += zh.Count
+		f.Properties.Hours += hours
+		f.Properties.Events[zh.Event] = eventProps{Count: zh.Count, Hours: hours}
+		if dom, ok := f.Properties.Events[f.Properties.DominantEvent]; !ok || hours > dom.Hours {
+			f.Properties.DominantEvent = zh.Event
+		}
+	}
+
+	fc := struct {
+		Type     string     `json:"type"`
+		Features []*feature `json:"features"`
+	}{Type: "FeatureCollection", Features: features}
+	if fc.Features == nil {
+		fc.Features = []*feature{}
+	}
+	return json.NewEncoder(w).Encode(fc)
+}
+
+// alertZones returns the UGC zones and counties an alert covers, without
+// duplicates.
+func alertZones(a nws.Alert) []string {
+	var zones []string
+	seen := make(map[string]bool)
+	for _, z := range append(append([]string{}, a.UGCCodes...), a.AffectedZones...) {
+		if !seen[z] {
+			seen[z] = true
+			zones = append(zones, z)
+		}
+	}
+	return zones
+}
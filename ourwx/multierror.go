@@ -0,0 +1,111 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A ComponentError is the failure of one component (usually a source, keyed
+// by capability name) of an aggregate operation.
+type ComponentError struct {
+	Component string
+	Err       error
+}
+
+// Error implements the error interface.
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Component, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// A MultiError is returned alongside partial results by operations that
+// aggregate several components. Components not listed succeeded.
+//
+// Use errors.As to retrieve a MultiError and Failed to check a particular
+// component:
+//   b, err := c.Bundle(ctx, opts)
+//   var merr *ourwx.MultiError
+//   if errors.As(err, &merr) && merr.Failed(ourwx.CapabilityForecast) != nil {
+//   	// b.Forecast is nil
+//   }
+type MultiError struct {
+	Errors []*ComponentError
+}
+
+// Error implements the error interface.
+func (m *MultiError) Error() string {
+	msgs := make([]string, len(m.Errors))
+	for i, e := range m.Errors {
+		msgs[i] = e.Error()
+	}
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	return fmt.Sprintf("%d components failed: %s", len(msgs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the component errors so that errors.Is and errors.As examine
+// each of them.
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.Errors))
+	for i, e := range m.Errors {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Failed returns the error for a component, or nil if the component did not
+// fail.
+func (m *MultiError) Failed(component string) error {
+	for _, e := range m.Errors {
+		if e.Component == component {
+			return e.Err
+		}
+	}
+	return nil
+}
+
+// Components returns the names of the components that failed, in the order
+// they were recorded.
+func (m *MultiError) Components() []string {
+	cs := make([]string, len(m.Errors))
+	for i, e := range m.Errors {
+		cs[i] = e.Component
+	}
+	return cs
+}
+
+// add records the failure of a component. A nil err is ignored.
+func (m *MultiError) add(component string, err error) {
+	if err == nil {
+		return
+	}
+	m.Errors = append(m.Errors, &ComponentError{Component: component, Err: err})
+}
+
+// errOrNil returns m as an error, or nil if no components failed. This avoids
+// returning a non-nil error interface holding an empty MultiError.
+func (m *MultiError) errOrNil() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
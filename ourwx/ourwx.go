@@ -0,0 +1,183 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ourwx implements a single client for retrieving weather data for a
+// location on Earth. It combines the lower level packages in this repository
+// so that callers don't need to stitch them together. Data are retrieved as
+// needed and cached according to each source's throttle.
+package ourwx
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A Client retrieves weather data for a specific location on Earth.
+type Client struct {
+	nwsClient *nws.Client
+	caps      capabilities
+
+	// each source has its own lock so that a request abandoned by Bundle can
+	// finish in the background without racing later calls
+	forecastMu          sync.Mutex
+	hourlyForecastMu    sync.Mutex
+	currentConditionsMu sync.Mutex
+	activeAlertsMu      sync.Mutex
+
+	storeMu sync.Mutex
+	store   Store
+}
+
+// NewClient creates a new Client given a WGS 84 (EPSG:4326) latitude and
+// longitude. Gridpoint, zone, and station resolution happen here, so this
+// makes several requests.
+//
+// See nws.NewClientFromCoordinates for details about httpUserAgentString.
+func NewClient(httpClient *http.Client, httpUserAgentString string, lat float64, lon float64) (*Client, error) {
+	nc, err := nws.NewClientFromCoordinates(httpClient, httpUserAgentString, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{nwsClient: nc}
+	c.caps.add(CapabilityForecast, CapabilitySource)
+	c.caps.add(CapabilityHourlyForecast, CapabilitySource)
+	c.caps.add(CapabilityCurrentConditions, CapabilitySource)
+	c.caps.add(CapabilityActiveAlerts, CapabilitySource)
+	return c, nil
+}
+
+// NWS returns the underlying NWS client, which may be used to change throttles
+// and the default station, or to access data not exposed here.
+func (c *Client) NWS() *nws.Client {
+	return c.nwsClient
+}
+
+// Point returns the point for this Client.
+func (c *Client) Point() nws.Point {
+	return c.nwsClient.Point()
+}
+
+// Forecast returns the semi-daily forecast, retrieving it first if it is older
+// than the NWS client's SemidailyForecastThrottle.
+func (c *Client) Forecast() (nws.Forecast, error) {
+	c.forecastMu.Lock()
+	defer c.forecastMu.Unlock()
+	if isExpired(c.nwsClient.SemidailyForecastLastRetrieved(), c.nwsClient.SemidailyForecastThrottle) {
+		err := c.nwsClient.UpdateSemidailyForecast()
+		c.caps.record(CapabilityForecast, err)
+		if err != nil {
+			return nws.Forecast{}, err
+		}
+		c.saveForecast(ForecastKindSemidaily, c.nwsClient.SemidailyForecast())
+	}
+	return c.nwsClient.SemidailyForecast(), nil
+}
+
+// HourlyForecast returns the hourly forecast, retrieving it first if it is
+// older than the NWS client's HourlyForecastThrottle.
+func (c *Client) HourlyForecast() (nws.Forecast, error) {
+	c.hourlyForecastMu.Lock()
+	defer c.hourlyForecastMu.Unlock()
+	if isExpired(c.nwsClient.HourlyForecastLastRetrieved(), c.nwsClient.HourlyForecastThrottle) {
+		err := c.nwsClient.UpdateHourlyForecast()
+		c.caps.record(CapabilityHourlyForecast, err)
+		if err != nil {
+			return nws.Forecast{}, err
+		}
+		c.saveForecast(ForecastKindHourly, c.nwsClient.HourlyForecast())
+	}
+	return c.nwsClient.HourlyForecast(), nil
+}
+
+// BlendedHourlyForecast returns the hourly forecast extended to the end of the
+// semi-daily forecast with derived hourly periods. See nws.BlendForecasts.
+func (c *Client) BlendedHourlyForecast() (nws.Forecast, error) {
+	hourly, err := c.HourlyForecast()
+	if err != nil {
+		return nws.Forecast{}, err
+	}
+	semidaily, err := c.Forecast()
+	if err != nil {
+		return nws.Forecast{}, err
+	}
+	return nws.BlendForecasts(hourly, semidaily), nil
+}
+
+// Timeline returns the current conditions followed by the hours of the hourly
+// forecast, for dashboards. See nws.BlendObservation.
+func (c *Client) Timeline() ([]nws.TimelineEntry, error) {
+	hourly, err := c.HourlyForecast()
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.CurrentConditions()
+	if err != nil {
+		return nil, err
+	}
+	return nws.BlendObservation(o, hourly), nil
+}
+
+// NextPrecipitation returns the next precipitation event within the blended
+// hourly forecast that ends after now. The second return value is false
+// if no precipitation is expected. See nws.Forecast.NextPrecipitation.
+func (c *Client) NextPrecipitation(now time.Time, threshold int) (nws.PrecipitationEvent, bool, error) {
+	f, err := c.BlendedHourlyForecast()
+	if err != nil {
+		return nws.PrecipitationEvent{}, false, err
+	}
+	e, ok := f.NextPrecipitation(now, threshold)
+	return e, ok, nil
+}
+
+// CurrentConditions returns the latest observation from the default station,
+// retrieving it first if it is older than the NWS client's
+// ObservationsThrottle.
+func (c *Client) CurrentConditions() (nws.Observation, error) {
+	c.currentConditionsMu.Lock()
+	defer c.currentConditionsMu.Unlock()
+	if isExpired(c.nwsClient.LatestObservationForDefaultStationLastRetrieved(), c.nwsClient.ObservationsThrottle) {
+		err := c.nwsClient.UpdateLatestObservationForDefaultStation()
+		c.caps.record(CapabilityCurrentConditions, err)
+		if err != nil {
+			return nws.Observation{}, err
+		}
+		c.saveObservation(c.nwsClient.LatestObservationForDefaultStation())
+	}
+	return c.nwsClient.LatestObservationForDefaultStation(), nil
+}
+
+// ActiveAlerts returns the alerts active for the point, retrieving them first
+// if they are older than the NWS client's AlertsThrottle.
+func (c *Client) ActiveAlerts() ([]nws.Alert, error) {
+	c.activeAlertsMu.Lock()
+	defer c.activeAlertsMu.Unlock()
+	if isExpired(c.nwsClient.AlertsLastRetrieved(""), c.nwsClient.AlertsThrottle) {
+		err := c.nwsClient.UpdateAlerts()
+		c.caps.record(CapabilityActiveAlerts, err)
+		if err != nil {
+			return nil, err
+		}
+		c.saveAlerts(c.nwsClient.Alerts(""))
+	}
+	return c.nwsClient.Alerts(""), nil
+}
+
+// isExpired reports whether data last retrieved at t should be retrieved again
+// given a throttle.
+func isExpired(t time.Time, throttle time.Duration) bool {
+	return t.IsZero() || time.Since(t) >= throttle
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"net/http"
+
+	"github.com/mikecamilleri/our-data/geocode"
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// GridpointForPlace resolves a place name (e.g. "Portland, OR", or a zip code
+// with geocode.ZipCity) using a geocoder, then retrieves the NWS gridpoint
+// containing it. The gridpoint's ForecastZone, County, and CountyFIPS identify
+// the place's alerts, so users may configure alerts by name. The place is also
+// returned, since the geocoder's match may not be the one intended.
+//
+// See nws.GridpointForCoordinates for details about apiURLString.
+func GridpointForPlace(httpClient *http.Client, httpUserAgentString string, apiURLString string, g geocode.Geocoder, name string) (nws.Gridpoint, geocode.Place, error) {
+	p, err := geocode.First(g, name)
+	if err != nil {
+		return nws.Gridpoint{}, geocode.Place{}, err
+	}
+	gp, err := nws.GridpointForCoordinates(httpClient, httpUserAgentString, apiURLString, p.Lat, p.Lon)
+	if err != nil {
+		return nws.Gridpoint{}, geocode.Place{}, err
+	}
+	return gp, p, nil
+}
+
+// NewClientForPlace creates a new Client for a place name (e.g. "Portland,
+// OR") by resolving it to a point with a geocoder, then calling NewClient. The
+// place is also returned, since the geocoder's match may not be the one
+// intended.
+func NewClientForPlace(httpClient *http.Client, httpUserAgentString string, g geocode.Geocoder, name string) (*Client, geocode.Place, error) {
+	p, err := geocode.First(g, name)
+	if err != nil {
+		return nil, geocode.Place{}, err
+	}
+	c, err := NewClient(httpClient, httpUserAgentString, p.Lat, p.Lon)
+	if err != nil {
+		return nil, geocode.Place{}, err
+	}
+	return c, p, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"testing"
+
+	"github.com/mikecamilleri/our-data/geocode"
+	"github.com/mikecamilleri/our-data/mock"
+)
+
+// fakeGeocoder resolves queries from a map.
+type fakeGeocoder map[string]geocode.Place
+
+func (g fakeGeocoder) Geocode(query string) ([]geocode.Place, error) {
+	p, ok := g[query]
+	if !ok {
+		return nil, geocode.ErrNoMatch
+	}
+	return []geocode.Place{p}, nil
+}
+
+func TestNewClientForPlace(t *testing.T) {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("../mock/testdata"); err != nil {
+		t.Fatal(err)
+	}
+	g := fakeGeocoder{
+		"Portland, OR": {Name: "Portland, Multnomah County, Oregon", Lat: 45.458, Lon: -122.6636},
+		"Nowhere":      {Name: "Nowhere", Lat: 10, Lon: 10},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{"found", "Portland, OR", false},
+		{"no match", "Atlantis", true},
+		{"no gridpoint", "Nowhere", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, p, err := NewClientForPlace(srv.Client(), "test/1.0 (test@example.com)", g, tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v; want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if p != g[tt.query] {
+				t.Errorf("got place %+v; want %+v", p, g[tt.query])
+			}
+			if got := c.Point(); got.Lat != p.Lat || got.Lon != p.Lon {
+				t.Errorf("got point %+v; want %v,%v", got, p.Lat, p.Lon)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A PollingProfile describes how a long running program polls for data.
+type PollingProfile struct {
+	// Interval is the time between wake windows. All sources are retrieved
+	// together in each window so that a device can sleep in between.
+	Interval time.Duration
+
+	// Sources lists the capability names of the sources retrieved in each
+	// window.
+	Sources []string
+
+	// Deadline limits each wake window. Sources that don't finish in time
+	// are retried in the next window. Zero means no limit.
+	Deadline time.Duration
+}
+
+// LowPowerProfile suits battery or solar powered field devices. It wakes once
+// an hour and omits the semi-daily forecast, since the hourly forecast covers
+// the same days in more detail.
+var LowPowerProfile = PollingProfile{
+	Interval: time.Hour,
+	Sources: []string{
+		CapabilityCurrentConditions,
+		CapabilityActiveAlerts,
+		CapabilityHourlyForecast,
+	},
+	Deadline: 30 * time.Second,
+}
+
+// EstimatedRequestsPerDay returns the number of API requests that polling
+// with the profile makes in a day when all goes well. Failed requests may be
+// retried against adjacent gridpoints, which adds to this.
+func (p PollingProfile) EstimatedRequestsPerDay() int {
+	if p.Interval <= 0 {
+		return 0
+	}
+	wakes := int((24*time.Hour + p.Interval - 1) / p.Interval)
+	return wakes * len(p.Sources)
+}
+
+// SetPollingProfile sets the throttles of the underlying NWS client so that
+// every source in the profile is retrieved again in each wake window, and
+// only then. Each throttle is set while holding the lock of its source, so
+// this may be called while other goroutines retrieve data, but it waits for
+// any retrieval in progress. No throttles are set if a source is unknown.
+func (c *Client) SetPollingProfile(p PollingProfile) error {
+	if p.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	type throttle struct {
+		mu *sync.Mutex
+		d  *time.Duration
+	}
+	var throttles []throttle
+	for _, s := range p.Sources {
+		switch s {
+		case CapabilityForecast:
+			throttles = append(throttles, throttle{&c.forecastMu, &c.nwsClient.SemidailyForecastThrottle})
+		case CapabilityHourlyForecast:
+			throttles = append(throttles, throttle{&c.hourlyForecastMu, &c.nwsClient.HourlyForecastThrottle})
+		case CapabilityCurrentConditions:
+			throttles = append(throttles, throttle{&c.currentConditionsMu, &c.nwsClient.ObservationsThrottle})
+		case CapabilityActiveAlerts:
+			throttles = append(throttles, throttle{&c.activeAlertsMu, &c.nwsClient.AlertsThrottle})
+		default:
+			return fmt.Errorf("unknown source: \"%s\"", s)
+		}
+	}
+	// a little less than the interval, so that a window that wakes slightly
+	// early still retrieves fresh data
+	d := p.Interval - p.Interval/10
+	for _, t := range throttles {
+		t.mu.Lock()
+		*t.d = d
+		t.mu.Unlock()
+	}
+	return nil
+}
+
+// Wake retrieves every source in a profile together, as a single wake window.
+// See Bundle for the returned values.
+func (c *Client) Wake(ctx context.Context, p PollingProfile) (*Bundle, error) {
+	return c.Bundle(ctx, BundleOptions{Sources: p.Sources, Deadline: p.Deadline})
+}
+
+// Poll sets the polling profile and then calls Wake once per interval until
+// the context is done, passing each result to handle. It returns the
+// context's error.
+func (c *Client) Poll(ctx context.Context, p PollingProfile, handle func(*Bundle, error)) error {
+	if err := c.SetPollingProfile(p); err != nil {
+		return err
+	}
+	tick := time.NewTicker(p.Interval)
+	defer tick.Stop()
+	for {
+		handle(c.Wake(ctx, p))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
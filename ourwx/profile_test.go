@@ -0,0 +1,80 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikecamilleri/our-data/mock"
+)
+
+func TestSetPollingProfile(t *testing.T) {
+	srv := mock.NewServer()
+	defer srv.Close()
+	if err := srv.LoadDir("../mock/testdata"); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(srv.Client(), "test/1.0 (test@example.com)", 45.458, -122.6636)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := c.NWS().SemidailyForecastThrottle
+
+	// an unknown source sets nothing
+	p := PollingProfile{Interval: time.Hour, Sources: []string{CapabilityHourlyForecast, "tides"}}
+	if err := c.SetPollingProfile(p); err == nil {
+		t.Error("unknown source: got no error")
+	}
+	if got := c.NWS().HourlyForecastThrottle; got != before {
+		t.Errorf("unknown source: hourly throttle changed to %v", got)
+	}
+	if err := c.SetPollingProfile(PollingProfile{}); err == nil {
+		t.Error("zero interval: got no error")
+	}
+
+	// setting the profile while sources are retrieved doesn't race (run
+	// with -race)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.Forecast()
+		c.CurrentConditions()
+	}()
+	go func() {
+		defer wg.Done()
+		if err := c.SetPollingProfile(LowPowerProfile); err != nil {
+			t.Error(err)
+		}
+		if err := c.SetPollingProfile(PollingProfile{Interval: time.Hour, Sources: []string{CapabilityForecast}}); err != nil {
+			t.Error(err)
+		}
+	}()
+	wg.Wait()
+
+	want := 54 * time.Minute
+	for name, got := range map[string]time.Duration{
+		"forecast":           c.NWS().SemidailyForecastThrottle,
+		"hourly forecast":    c.NWS().HourlyForecastThrottle,
+		"current conditions": c.NWS().ObservationsThrottle,
+		"active alerts":      c.NWS().AlertsThrottle,
+	} {
+		if got != want {
+			t.Errorf("%s throttle: got %v; want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A Recording holds the observations and alerts for a location over a period
+// of time so that they can be replayed later. Recordings marshal to JSON.
+type Recording struct {
+	Start        time.Time
+	End          time.Time
+	Observations []nws.Observation
+	Alerts       []nws.Alert
+}
+
+// NewRecordingFromBackfill returns a Recording of the data in a Backfill,
+// ending at end.
+func NewRecordingFromBackfill(b *nws.Backfill, end time.Time) Recording {
+	r := Recording{Start: b.Since, End: end, Observations: b.Observations}
+	for _, lc := range b.AlertLifecycles {
+		r.Alerts = append(r.Alerts, lc...)
+	}
+	return r
+}
+
+// Record retrieves the observations and alerts since a time and returns them
+// as a Recording. See nws.Client.Backfill.
+func (c *Client) Record(since time.Time) (Recording, error) {
+	b, err := c.nwsClient.Backfill(since)
+	if err != nil {
+		return Recording{}, err
+	}
+	return NewRecordingFromBackfill(b, time.Now()), nil
+}
+
+// A ReplayEvent is a single event during a replay: either a new observation or
+// a change to the set of active alerts.
+type ReplayEvent struct {
+	Time         time.Time // the original time of the event
+	Observation  *nws.Observation
+	AlertChanges []nws.AlertChange
+}
+
+// ReplayOptions configure Replay.
+type ReplayOptions struct {
+	// Speed is how many times faster than real time events are delivered
+	// (e.g. 60 replays an hour in a minute). Zero delivers events without
+	// waiting.
+	Speed float64
+}
+
+// Replay delivers the events in a Recording to handle in chronological order,
+// spaced according to opts, so that integrations can be exercised with a
+// recorded day of severe weather. Alerts become active when sent and inactive
+// when they expire or are replaced; changes are reported as by
+// nws.DiffAlertsAt, so expirations close out their lifecycles.
+//
+// Replay stops and returns the error if handle returns an error or the
+// context is done.
+func Replay(ctx context.Context, rec Recording, opts ReplayOptions, handle func(ReplayEvent) error) error {
+	var events []ReplayEvent
+	for i := range rec.Observations {
+		o := rec.Observations[i]
+		events = append(events, ReplayEvent{Time: o.TimeObserved, Observation: &o})
+	}
+
+	// the active set of alerts can only change when an alert is sent or
+	// expires
+	var alertTimes []time.Time
+	for _, a := range rec.Alerts {
+		alertTimes = append(alertTimes, a.TimeSent)
+		if !a.TimeExpires.IsZero() && (rec.End.IsZero() || a.TimeExpires.Before(rec.End)) {
+			alertTimes = append(alertTimes, a.TimeExpires)
+		}
+	}
+	sort.Slice(alertTimes, func(i, j int) bool { return alertTimes[i].Before(alertTimes[j]) })
+	var active []nws.Alert
+	for i, t := range alertTimes {
+		if i > 0 && t.Equal(alertTimes[i-1]) {
+			continue
+		}
+		next := activeAlertsAt(rec.Alerts, t)
+		if changes := nws.DiffAlertsAt(active, next, t); len(changes) > 0 {
+			events = append(events, ReplayEvent{Time: t, AlertChanges: changes})
+		}
+		active = next
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+	var prev time.Time
+	for _, e := range events {
+		if opts.Speed > 0 && !prev.IsZero() {
+			d := time.Duration(float64(e.Time.Sub(prev)) / opts.Speed)
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := handle(e); err != nil {
+			return err
+		}
+		prev = e.Time
+	}
+
+	return nil
+}
+
+// activeAlertsAt returns the alerts that were active at t: sent at or before
+// t, not expired, and not replaced by another alert sent at or before t.
+func activeAlertsAt(alerts []nws.Alert, t time.Time) []nws.Alert {
+	replaced := make(map[string]bool)
+	for _, a := range alerts {
+		if !a.TimeSent.After(t) {
+			for _, ref := range a.References {
+				replaced[ref] = true
+			}
+		}
+	}
+
+	var active []nws.Alert
+	seen := make(map[string]bool)
+	for _, a := range alerts {
+		if seen[a.ID] || replaced[a.ID] || a.TimeSent.After(t) {
+			continue
+		}
+		if !a.TimeExpires.IsZero() && !a.TimeExpires.After(t) {
+			continue
+		}
+		seen[a.ID] = true
+		active = append(active, a)
+	}
+	return active
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A SQLStore is a Store that keeps records as JSON in a table of a SQL
+// database, which suits histories too large to scan from a file.
+//
+// The caller opens the database with a driver of their choosing, so this
+// package doesn't depend on one. The schema is written for SQLite.
+type SQLStore struct {
+	db *sql.DB
+}
+
+const (
+	sqlStoreObservationKind    = "observation"
+	sqlStoreAlertKind          = "alert"
+	sqlStoreForecastKindPrefix = "forecast-"
+)
+
+// sqlStoreSchema creates the records table if it does not exist. Records are
+// returned in order of retrieved and then id, which is the insertion order.
+var sqlStoreSchema = []string{
+	`CREATE TABLE IF NOT EXISTS ourwx_records (
+		id INTEGER PRIMARY KEY,
+		kind TEXT NOT NULL,
+		retrieved INTEGER NOT NULL,
+		record TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS ourwx_records_kind_retrieved
+		ON ourwx_records (kind, retrieved)`,
+}
+
+// NewSQLStore returns a SQLStore that stores records in db, creating its
+// table if it does not exist.
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	if db == nil {
+		return nil, errors.New("db must not be nil")
+	}
+	for _, stmt := range sqlStoreSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// SaveForecast implements Store.
+func (s *SQLStore) SaveForecast(kind string, f nws.Forecast) error {
+	if _, err := fileStoreForecastsName(kind); err != nil {
+		return err
+	}
+	return s.insert(s.db, sqlStoreForecastKindPrefix+kind, f.TimeRetrieved, f)
+}
+
+// SaveObservation implements Store.
+func (s *SQLStore) SaveObservation(o nws.Observation) error {
+	return s.insert(s.db, sqlStoreObservationKind, o.TimeRetrieved, o)
+}
+
+// SaveAlerts implements Store. Each alert is a record; either all of them are
+// saved or none are.
+func (s *SQLStore) SaveAlerts(as []nws.Alert) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, a := range as {
+		if err := s.insert(tx, sqlStoreAlertKind, a.TimeRetrieved, a); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Forecasts implements Store.
+func (s *SQLStore) Forecasts(kind string, start, end time.Time) ([]nws.Forecast, error) {
+	if _, err := fileStoreForecastsName(kind); err != nil {
+		return nil, err
+	}
+	var fs []nws.Forecast
+	err := s.query(sqlStoreForecastKindPrefix+kind, start, end, func(b []byte) error {
+		var f nws.Forecast
+		if err := json.Unmarshal(b, &f); err != nil {
+			return err
+		}
+		fs = append(fs, f)
+		return nil
+	})
+	return fs, err
+}
+
+// Observations implements Store.
+func (s *SQLStore) Observations(start, end time.Time) ([]nws.Observation, error) {
+	var obs []nws.Observation
+	err := s.query(sqlStoreObservationKind, start, end, func(b []byte) error {
+		var o nws.Observation
+		if err := json.Unmarshal(b, &o); err != nil {
+			return err
+		}
+		obs = append(obs, o)
+		return nil
+	})
+	return obs, err
+}
+
+// Alerts implements Store.
+func (s *SQLStore) Alerts(start, end time.Time) ([]nws.Alert, error) {
+	var as []nws.Alert
+	err := s.query(sqlStoreAlertKind, start, end, func(b []byte) error {
+		var a nws.Alert
+		if err := json.Unmarshal(b, &a); err != nil {
+			return err
+		}
+		as = append(as, a)
+		return nil
+	})
+	return as, err
+}
+
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx.
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insert inserts a record. Retrieval times are stored as Unix nanoseconds so
+// that they compare correctly as integers.
+func (s *SQLStore) insert(e sqlExecer, kind string, retrieved time.Time, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec(
+		"INSERT INTO ourwx_records (kind, retrieved, record) VALUES (?, ?, ?)",
+		kind, retrieved.UnixNano(), string(b),
+	)
+	return err
+}
+
+// query calls decode for each record of a kind retrieved between start and
+// end, inclusive, oldest first. A zero start or end is unbounded.
+func (s *SQLStore) query(kind string, start, end time.Time, decode func([]byte) error) error {
+	lo, hi := int64(math.MinInt64), int64(math.MaxInt64)
+	if !start.IsZero() {
+		lo = start.UnixNano()
+	}
+	if !end.IsZero() {
+		hi = end.UnixNano()
+	}
+	rows, err := s.db.Query(
+		"SELECT record FROM ourwx_records WHERE kind = ? AND retrieved BETWEEN ? AND ? ORDER BY retrieved, id",
+		kind, lo, hi,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var record string
+		if err := rows.Scan(&record); err != nil {
+			return err
+		}
+		if err := decode([]byte(record)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// recordsDriver is a database/sql driver that understands only the statements
+// SQLStore issues, keeping ourwx_records in memory.
+type recordsDriver struct {
+	mu   sync.Mutex
+	rows []recordsRow
+}
+
+type recordsRow struct {
+	id        int64
+	kind      string
+	retrieved int64
+	record    string
+}
+
+func (d *recordsDriver) Open(string) (driver.Conn, error) { return recordsConn{d}, nil }
+
+type recordsConn struct{ d *recordsDriver }
+
+func (c recordsConn) Prepare(query string) (driver.Stmt, error) {
+	return recordsStmt{c.d, query}, nil
+}
+func (c recordsConn) Close() error              { return nil }
+func (c recordsConn) Begin() (driver.Tx, error) { return recordsTx{}, nil }
+
+type recordsTx struct{}
+
+func (recordsTx) Commit() error   { return nil }
+func (recordsTx) Rollback() error { return nil }
+
+type recordsStmt struct {
+	d     *recordsDriver
+	query string
+}
+
+func (s recordsStmt) Close() error  { return nil }
+func (s recordsStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s recordsStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(s.query, "CREATE ") {
+		return driver.RowsAffected(0), nil
+	}
+	if !strings.HasPrefix(s.query, "INSERT ") {
+		return nil, errors.New("unexpected statement: " + s.query)
+	}
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.rows = append(s.d.rows, recordsRow{
+		id:        int64(len(s.d.rows) + 1),
+		kind:      args[0].(string),
+		retrieved: args[1].(int64),
+		record:    args[2].(string),
+	})
+	return driver.RowsAffected(1), nil
+}
+
+func (s recordsStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.query, "SELECT ") {
+		return nil, errors.New("unexpected query: " + s.query)
+	}
+	kind, lo, hi := args[0].(string), args[1].(int64), args[2].(int64)
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	var rows []recordsRow
+	for _, r := range s.d.rows {
+		if r.kind == kind && r.retrieved >= lo && r.retrieved <= hi {
+			rows = append(rows, r)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].retrieved < rows[j].retrieved })
+	return &recordsRows{rows: rows}, nil
+}
+
+type recordsRows struct{ rows []recordsRow }
+
+func (r *recordsRows) Columns() []string { return []string{"record"} }
+func (r *recordsRows) Close() error      { return nil }
+
+func (r *recordsRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.rows[0].record
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("ourwx-records", &recordsDriver{})
+}
+
+func TestSQLStore(t *testing.T) {
+	db, err := sql.Open("ourwx-records", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err := NewSQLStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+	for _, d := range []time.Duration{2 * time.Hour, 0, time.Hour} {
+		if err := s.SaveObservation(nws.Observation{TimeRetrieved: t0.Add(d)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SaveForecast(ForecastKindHourly, nws.Forecast{TimeRetrieved: t0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveForecast("weekly", nws.Forecast{TimeRetrieved: t0}); err == nil {
+		t.Error("SaveForecast with an unknown kind: got nil error")
+	}
+	if err := s.SaveAlerts([]nws.Alert{{ID: "a", TimeRetrieved: t0}, {ID: "b", TimeRetrieved: t0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	obs, err := s.Observations(t0, t0.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obs) != 2 || !obs[0].TimeRetrieved.Equal(t0) || !obs[1].TimeRetrieved.Equal(t0.Add(time.Hour)) {
+		t.Errorf("Observations: got %v", obs)
+	}
+	if obs, _ := s.Observations(time.Time{}, time.Time{}); len(obs) != 3 {
+		t.Errorf("unbounded Observations: got %d; want 3", len(obs))
+	}
+
+	if fs, _ := s.Forecasts(ForecastKindHourly, t0, t0); len(fs) != 1 {
+		t.Errorf("hourly Forecasts: got %d; want 1", len(fs))
+	}
+	if fs, _ := s.Forecasts(ForecastKindSemidaily, t0, t0); len(fs) != 0 {
+		t.Errorf("semidaily Forecasts: got %d; want 0", len(fs))
+	}
+
+	as, err := s.Alerts(t0, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 2 || as[0].ID != "a" || as[1].ID != "b" {
+		t.Errorf("Alerts: got %v", as)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// CapabilityHistoryStore is the capability name of the history store set
+// with SetStore.
+const CapabilityHistoryStore = "store.history"
+
+// Forecast kinds
+const (
+	ForecastKindSemidaily = "semidaily"
+	ForecastKindHourly    = "hourly"
+)
+
+// A Store records fetched forecasts, observations, and alerts so that they can
+// be analyzed later, for example to compare forecasts with what was observed.
+// Records are keyed by their TimeRetrieved.
+type Store interface {
+	SaveForecast(kind string, f nws.Forecast) error
+	SaveObservation(o nws.Observation) error
+	SaveAlerts(as []nws.Alert) error
+
+	// Forecasts, Observations, and Alerts return the records retrieved
+	// between start and end (inclusive), oldest first. An alert that was
+	// active for several retrievals is returned once for each.
+	Forecasts(kind string, start, end time.Time) ([]nws.Forecast, error)
+	Observations(start, end time.Time) ([]nws.Observation, error)
+	Alerts(start, end time.Time) ([]nws.Alert, error)
+}
+
+// SetStore sets a Store in which every forecast, observation, and alert
+// retrieved by the Client is recorded. Failures to record are reported by
+// Capabilities under CapabilityHistoryStore rather than returned, so that a
+// broken store doesn't interrupt the data itself. A nil Store stops recording.
+func (c *Client) SetStore(s Store) {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	c.store = s
+	if s != nil {
+		c.caps.add(CapabilityHistoryStore, CapabilityStore)
+	}
+}
+
+// saveForecast records a newly retrieved forecast if there is a store.
+func (c *Client) saveForecast(kind string, f nws.Forecast) {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	if c.store != nil {
+		c.caps.record(CapabilityHistoryStore, c.store.SaveForecast(kind, f))
+	}
+}
+
+// saveObservation records a newly retrieved observation if there is a store.
+func (c *Client) saveObservation(o nws.Observation) {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	if c.store != nil {
+		c.caps.record(CapabilityHistoryStore, c.store.SaveObservation(o))
+	}
+}
+
+// saveAlerts records newly retrieved alerts if there is a store.
+func (c *Client) saveAlerts(as []nws.Alert) {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	if c.store != nil {
+		c.caps.record(CapabilityHistoryStore, c.store.SaveAlerts(as))
+	}
+}
+
+// A FileStore is a Store that appends records as JSON, one per line, to a
+// file per kind of record in a directory. Every query reads a whole file, so
+// SQLStore is a better fit for large histories.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+const (
+	fileStoreObservationsName = "observations.jsonl"
+	fileStoreAlertsName       = "alerts.jsonl"
+	fileStoreForecastsNameFmt = "forecasts-%s.jsonl" // kind
+)
+
+// NewFileStore returns a FileStore that stores records in dir, creating it if
+// it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("dir must not be empty")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// SaveForecast implements Store.
+func (s *FileStore) SaveForecast(kind string, f nws.Forecast) error {
+	name, err := fileStoreForecastsName(kind)
+	if err != nil {
+		return err
+	}
+	return s.append(name, f)
+}
+
+// SaveObservation implements Store.
+func (s *FileStore) SaveObservation(o nws.Observation) error {
+	return s.append(fileStoreObservationsName, o)
+}
+
+// SaveAlerts implements Store. Each alert is a record.
+func (s *FileStore) SaveAlerts(as []nws.Alert) error {
+	for _, a := range as {
+		if err := s.append(fileStoreAlertsName, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Forecasts implements Store.
+func (s *FileStore) Forecasts(kind string, start, end time.Time) ([]nws.Forecast, error) {
+	name, err := fileStoreForecastsName(kind)
+	if err != nil {
+		return nil, err
+	}
+	var fs []nws.Forecast
+	err = s.read(name, func(d *json.Decoder) error {
+		var f nws.Forecast
+		if err := d.Decode(&f); err != nil {
+			return err
+		}
+		if inRange(f.TimeRetrieved, start, end) {
+			fs = append(fs, f)
+		}
+		return nil
+	})
+	return fs, err
+}
+
+// Observations implements Store.
+func (s *FileStore) Observations(start, end time.Time) ([]nws.Observation, error) {
+	var obs []nws.Observation
+	err := s.read(fileStoreObservationsName, func(d *json.Decoder) error {
+		var o nws.Observation
+		if err := d.Decode(&o); err != nil {
+			return err
+		}
+		if inRange(o.TimeRetrieved, start, end) {
+			obs = append(obs, o)
+		}
+		return nil
+	})
+	return obs, err
+}
+
+// Alerts implements Store.
+func (s *FileStore) Alerts(start, end time.Time) ([]nws.Alert, error) {
+	var as []nws.Alert
+	err := s.read(fileStoreAlertsName, func(d *json.Decoder) error {
+		var a nws.Alert
+		if err := d.Decode(&a); err != nil {
+			return err
+		}
+		if inRange(a.TimeRetrieved, start, end) {
+			as = append(as, a)
+		}
+		return nil
+	})
+	return as, err
+}
+
+// append appends a record to a file.
+func (s *FileStore) append(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(s.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// read calls decode for each record in a file. A missing file has no records.
+// Records are appended in the order they are retrieved, so they are read
+// oldest first.
+func (s *FileStore) read(name string, decode func(*json.Decoder) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	for {
+		if err := decode(d); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// fileStoreForecastsName returns the file name for a kind of forecast.
+func fileStoreForecastsName(kind string) (string, error) {
+	if kind != ForecastKindSemidaily && kind != ForecastKindHourly {
+		return "", fmt.Errorf("unknown forecast kind: \"%s\"", kind)
+	}
+	return fmt.Sprintf(fileStoreForecastsNameFmt, kind), nil
+}
+
+// inRange reports whether t is between start and end, inclusive. A zero start
+// or end is unbounded.
+func inRange(t, start, end time.Time) bool {
+	return (start.IsZero() || !t.Before(start)) && (end.IsZero() || !t.After(end))
+}
+
+// VerifyHistory scores the hourly forecasts recorded in a Store between start
+// and end against the observations recorded through end. See
+// nws.VerifyForecasts.
+func VerifyHistory(s Store, start, end time.Time, opts nws.VerificationOptions) ([]nws.LeadTimeScore, error) {
+	fs, err := s.Forecasts(ForecastKindHourly, start, end)
+	if err != nil {
+		return nil, err
+	}
+	obs, err := s.Observations(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return nws.VerifyForecasts(fs, obs, opts), nil
+}
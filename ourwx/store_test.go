@@ -0,0 +1,54 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// rangeStore is a Store that records the ranges it is queried for.
+type rangeStore struct {
+	Store
+	forecastsStart, forecastsEnd       time.Time
+	observationsStart, observationsEnd time.Time
+}
+
+func (s *rangeStore) Forecasts(kind string, start, end time.Time) ([]nws.Forecast, error) {
+	s.forecastsStart, s.forecastsEnd = start, end
+	return nil, nil
+}
+
+func (s *rangeStore) Observations(start, end time.Time) ([]nws.Observation, error) {
+	s.observationsStart, s.observationsEnd = start, end
+	return nil, nil
+}
+
+func TestVerifyHistoryRange(t *testing.T) {
+	start := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	s := &rangeStore{}
+	if _, err := VerifyHistory(s, start, end, nws.VerificationOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !s.forecastsStart.Equal(start) || !s.forecastsEnd.Equal(end) {
+		t.Errorf("forecasts: got %v to %v; want %v to %v", s.forecastsStart, s.forecastsEnd, start, end)
+	}
+	if !s.observationsStart.Equal(start) || !s.observationsEnd.Equal(end) {
+		t.Errorf("observations: got %v to %v; want %v to %v", s.observationsStart, s.observationsEnd, start, end)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// defaultSnowRatio is the typical ratio of snowfall to its liquid equivalent.
+const defaultSnowRatio = 10
+
+// A StormTracker tracks observed precipitation during a storm against the
+// total forecast when tracking began.
+type StormTracker struct {
+	Kind      string // "rain", "snow", or "ice"
+	TimeStart time.Time
+	TimeEnd   time.Time
+
+	// SnowRatio converts observed liquid precipitation to snowfall, since
+	// stations don't report snowfall. It is 10 by default.
+	SnowRatio float64
+
+	ForecastMin nws.ValueUnit // inches
+	ForecastMax nws.ValueUnit // inches
+}
+
+// TrackStorm begins tracking a storm expected between start and end. The
+// forecast total is taken from the amounts in the semidaily forecast or, if
+// none are mentioned, from the raw gridpoint forecast. Since forecasts only
+// cover the time remaining, TrackStorm should be called before the storm
+// begins.
+func (c *Client) TrackStorm(kind string, start time.Time, end time.Time) (*StormTracker, error) {
+	f, err := c.Forecast()
+	if err != nil {
+		return nil, err
+	}
+	t := &StormTracker{Kind: kind, TimeStart: start, TimeEnd: end, SnowRatio: defaultSnowRatio}
+	t.ForecastMin, t.ForecastMax = f.StormTotal(kind, start, end)
+	if t.ForecastMax.Unit == "" {
+		total, err := c.nwsClient.GridpointStormTotal(kind, start, end)
+		if err != nil {
+			return nil, err
+		}
+		t.ForecastMin, t.ForecastMax = total, total
+	}
+	return t, nil
+}
+
+// Progress returns the precipitation observed so far, as recorded in a
+// Store, against the forecast total. Snow and ice are estimated from the
+// liquid precipitation observed; ice accumulates roughly one to one.
+func (t *StormTracker) Progress(store Store, now time.Time) (nws.StormProgress, error) {
+	end := t.TimeEnd
+	if now.Before(end) {
+		end = now
+	}
+	obs, err := store.Observations(t.TimeStart, end)
+	if err != nil {
+		return nws.StormProgress{}, err
+	}
+	observed, latest := nws.ObservedPrecipitation(obs, t.TimeStart, end)
+	if t.Kind == "snow" {
+		ratio := t.SnowRatio
+		if ratio <= 0 {
+			ratio = defaultSnowRatio
+		}
+		observed.Value *= ratio
+	}
+	return nws.StormProgress{
+		Kind:         t.Kind,
+		TimeStart:    t.TimeStart,
+		TimeObserved: latest,
+		Observed:     observed,
+		ForecastMin:  t.ForecastMin,
+		ForecastMax:  t.ForecastMax,
+	}, nil
+}
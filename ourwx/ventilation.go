@@ -0,0 +1,52 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ourwx
+
+import (
+	"github.com/mikecamilleri/our-data/airnow"
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// categoryMinAQI is the lowest AQI in each AirNow category, indexed by
+// category number.
+var categoryMinAQI = []int{0, 0, 51, 101, 151, 201, 301}
+
+// ExcludeUnhealthyAir returns the ventilation windows falling on days for which
+// no pollutant's forecast AQI exceeds maxAQI, so that windows are not opened
+// when AirNow forecasts poor air quality. Forecasts giving only a category are
+// judged by the lowest AQI in that category. Days without a forecast are
+// assumed to be healthy.
+func ExcludeUnhealthyAir(windows []nws.VentilationWindow, forecasts []airnow.Forecast, maxAQI int) []nws.VentilationWindow {
+	unhealthy := make(map[string]bool)
+	for _, f := range forecasts {
+		aqi := f.AQI
+		if aqi < 0 && f.Category.Number > 0 && f.Category.Number < len(categoryMinAQI) {
+			aqi = categoryMinAQI[f.Category.Number]
+		}
+		if aqi > maxAQI {
+			unhealthy[f.Date.Format("2006-01-02")] = true
+		}
+	}
+
+	var healthy []nws.VentilationWindow
+	for _, w := range windows {
+		// forecast times are local, as are AirNow's dates
+		if unhealthy[w.TimeStart.Format("2006-01-02")] || unhealthy[w.TimeEnd.Add(-1).Format("2006-01-02")] {
+			continue
+		}
+		healthy = append(healthy, w)
+	}
+	return healthy
+}
@@ -0,0 +1,233 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rediscache implements an nws.ResponseCache backed by Redis, so that
+// several processes can share API responses.
+package rediscache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDialTimeout = 5 * time.Second
+	defaultIOTimeout   = 5 * time.Second
+
+	// maxBulkBytes limits the size of a value read from the server. A
+	// cached response is a body, which is limited to 16 MiB like NWS API
+	// response bodies, after a few headers, which are allowed 64 KiB.
+	maxBulkBytes = 16<<20 + 64<<10
+)
+
+// Options configure a Cache.
+type Options struct {
+	Password  string // sent with AUTH if not empty
+	DB        int    // selected with SELECT if not zero
+	KeyPrefix string // prepended to every key
+	Timeout   time.Duration
+}
+
+// A Cache stores values in Redis. It holds a single connection, which is
+// re-established after an error. It is safe for concurrent use.
+type Cache struct {
+	addr string
+	opts Options
+
+	mu   sync.Mutex
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// New returns a Cache for the Redis server at addr (e.g. "localhost:6379").
+// The connection is made on first use.
+func New(addr string, opts Options) (*Cache, error) {
+	if addr == "" {
+		return nil, errors.New("addr must not be empty")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultIOTimeout
+	}
+	return &Cache{addr: addr, opts: opts}, nil
+}
+
+// Get implements nws.ResponseCache.
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	v, err := c.do("GET", c.opts.KeyPrefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if v == nil {
+		return nil, false, nil
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected reply to GET: %v", v)
+	}
+	return b, true, nil
+}
+
+// Set implements nws.ResponseCache.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) error {
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		return errors.New("ttl must be at least one millisecond")
+	}
+	v, err := c.do("SET", c.opts.KeyPrefix+key, string(value), "PX", strconv.FormatInt(ms, 10))
+	if err != nil {
+		return err
+	}
+	if v != "OK" {
+		return fmt.Errorf("unexpected reply to SET: %v", v)
+	}
+	return nil
+}
+
+// Close closes the connection, if any.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rw = nil, nil
+	return err
+}
+
+// do sends a command and returns its reply: a string for a simple string, an
+// int64 for an integer, a []byte for a bulk string, or nil for a null bulk
+// string. Error replies are returned as errors. The connection is closed after
+// any other error so that the next command starts afresh.
+func (c *Cache) do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	v, err := c.roundTrip(args)
+	if err != nil {
+		if _, ok := err.(redisError); !ok {
+			c.conn.Close()
+			c.conn, c.rw = nil, nil
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// connect dials the server and authenticates and selects a database if
+// configured.
+func (c *Cache) connect() error {
+	conn, err := net.DialTimeout("tcp", c.addr, defaultDialTimeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	var setup [][]string
+	if c.opts.Password != "" {
+		setup = append(setup, []string{"AUTH", c.opts.Password})
+	}
+	if c.opts.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.opts.DB)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(args); err != nil {
+			conn.Close()
+			c.conn, c.rw = nil, nil
+			return err
+		}
+	}
+	return nil
+}
+
+// roundTrip writes a command as a RESP array of bulk strings and reads the
+// reply.
+func (c *Cache) roundTrip(args []string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(c.opts.Timeout))
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(c.rw.Reader)
+}
+
+// A redisError is an error reply from the server. The connection is still
+// usable after one.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readReply reads a single RESP reply that is not an array.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if n > maxBulkBytes {
+			return nil, fmt.Errorf("reply exceeds %d bytes", maxBulkBytes)
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+	return nil, fmt.Errorf("unsupported reply: %q", line)
+}
+
+// readLine reads a line terminated by CRLF, without the terminator.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed reply: %q", line)
+	}
+	return line[:len(line)-2], nil
+}
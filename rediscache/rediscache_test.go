@@ -0,0 +1,320 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rediscache
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikecamilleri/our-data/nws"
+)
+
+// A fakeServer speaks enough RESP to stand in for Redis: AUTH, SELECT, GET
+// and SET. Keys are stored per database, and TTLs are recorded but not
+// enforced.
+type fakeServer struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string][]byte // keyed by "db:key"
+	ttls     map[string]string
+	commands []string // command names in the order received
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{
+		ln:       ln,
+		password: password,
+		data:     map[string][]byte{},
+		ttls:     map[string]string{},
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+	db := "0"
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, args[0])
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			if args[1] == s.password {
+				authed = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			db = args[1]
+			reply = "+OK\r\n"
+		case args[0] == "GET" && strings.HasSuffix(args[1], "wrongtype"):
+			reply = "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+		case args[0] == "GET":
+			if v, ok := s.data[db+":"+args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			s.data[db+":"+args[1]] = []byte(args[2])
+			s.ttls[db+":"+args[1]] = strings.Join(args[3:], " ")
+			reply = "+OK\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a RESP array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("not an array: %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		v, err := readReply(r)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("not a bulk string: %v", v)
+		}
+		args[i] = string(b)
+	}
+	return args, nil
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		password     string
+		opts         Options
+		wantCommands []string // received for the first Get
+		wantErr      string   // from the first Get
+	}{
+		{"no setup", "", Options{}, []string{"GET"}, ""},
+		{"auth", "secret", Options{Password: "secret"}, []string{"AUTH", "GET"}, ""},
+		{"auth and select", "secret", Options{Password: "secret", DB: 2}, []string{"AUTH", "SELECT", "GET"}, ""},
+		{"select", "", Options{DB: 2}, []string{"SELECT", "GET"}, ""},
+		{"wrong password", "secret", Options{Password: "wrong"}, []string{"AUTH"}, "redis: WRONGPASS invalid password"},
+		{"no password", "secret", Options{}, []string{"GET"}, "redis: NOAUTH Authentication required."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t, tt.password)
+			c, err := New(s.ln.Addr().String(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			_, ok, err := c.Get("missing")
+			s.mu.Lock()
+			commands := s.commands
+			s.mu.Unlock()
+			if strings.Join(commands, " ") != strings.Join(tt.wantCommands, " ") {
+				t.Errorf("got commands %q; want %q", commands, tt.wantCommands)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got error %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || ok {
+				t.Fatalf("Get(missing): got %v, %v; want a miss", ok, err)
+			}
+
+			if err := c.Set("key", []byte("value"), 1500*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			v, ok, err := c.Get("key")
+			if err != nil || !ok || string(v) != "value" {
+				t.Errorf("Get(key): got %q, %v, %v; want \"value\"", v, ok, err)
+			}
+			s.mu.Lock()
+			db := strconv.Itoa(tt.opts.DB)
+			ttl := s.ttls[db+":key"]
+			s.mu.Unlock()
+			if ttl != "PX 1500" {
+				t.Errorf("got TTL arguments %q; want \"PX 1500\"", ttl)
+			}
+		})
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	if _, err := New("", Options{}); err == nil {
+		t.Error("New with empty addr: got no error")
+	}
+
+	s := newFakeServer(t, "")
+	c, _ := New(s.ln.Addr().String(), Options{KeyPrefix: "p:"})
+	defer c.Close()
+
+	// an error reply leaves the connection usable
+	if _, _, err := c.Get("wrongtype"); err == nil || !strings.HasPrefix(err.Error(), "redis: WRONGTYPE") {
+		t.Errorf("got error %v; want WRONGTYPE", err)
+	}
+	if err := c.Set("key", []byte("value"), time.Second); err != nil {
+		t.Errorf("Set after error reply: %v", err)
+	}
+	if err := c.Set("key", []byte("value"), 0); err == nil {
+		t.Error("Set with zero TTL: got no error")
+	}
+	s.mu.Lock()
+	_, ok := s.data["0:p:key"]
+	s.mu.Unlock()
+	if !ok {
+		t.Error("key not stored with prefix")
+	}
+
+	// a lost connection is re-established on the next command
+	c.mu.Lock()
+	c.conn.Close()
+	c.mu.Unlock()
+	if _, _, err := c.Get("key"); err == nil {
+		t.Error("Get on closed connection: got no error")
+	}
+	if _, ok, err := c.Get("key"); err != nil || !ok {
+		t.Errorf("Get after reconnect: got %v, %v", ok, err)
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    interface{}
+		wantErr bool
+	}{
+		{"simple string", "+OK\r\n", "OK", false},
+		{"integer", ":42\r\n", int64(42), false},
+		{"bulk string", "$5\r\nhello\r\n", []byte("hello"), false},
+		{"empty bulk string", "$0\r\n\r\n", []byte{}, false},
+		{"nil bulk string", "$-1\r\n", nil, false},
+		{"error", "-ERR bad\r\n", nil, true},
+		{"too large", fmt.Sprintf("$%d\r\n", maxBulkBytes+1), nil, true},
+		{"truncated", "$5\r\nhel", nil, true},
+		{"no CR", "+OK\n", nil, true},
+		{"array", "*1\r\n$1\r\na\r\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.in)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v; want error: %v", err, tt.wantErr)
+			}
+			if b, ok := tt.want.([]byte); ok {
+				if gb, ok := got.([]byte); !ok || !bytes.Equal(gb, b) {
+					t.Errorf("got %q; want %q", got, b)
+				}
+			} else if got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCacheEntry stores a response through nws.CacheMiddleware and reads it
+// back. The body is as large as the NWS package allows, so the entry, which
+// also holds headers, is larger than the body limit.
+func TestCacheEntry(t *testing.T) {
+	s := newFakeServer(t, "")
+	c, _ := New(s.ln.Addr().String(), Options{})
+	defer c.Close()
+
+	body := strings.Repeat(" ", 16<<20)
+	var requests int
+	next := nws.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: 200,
+			Header: http.Header{
+				"Content-Type":  {"application/geo+json"},
+				"Cache-Control": {"public, max-age=60"},
+				"Etag":          {`"abc"`},
+			},
+			Body:    ioutil.NopCloser(strings.NewReader(body)),
+			Request: req,
+		}, nil
+	})
+	rt := nws.CacheMiddleware(c, time.Minute)(next)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "https://api.weather.gov/points/45,-122", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := ioutil.ReadAll(resp.Body)
+		if len(got) != len(body) || resp.Header.Get("ETag") != `"abc"` {
+			t.Errorf("request %d: got %d bytes, ETag %q", i+1, len(got), resp.Header.Get("ETag"))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests; want 1", requests)
+	}
+}
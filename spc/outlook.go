@@ -0,0 +1,304 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	getOutlookProductFmt = "products/outlook/day%dotlk_%s.nolyr.geojson" // day, kind
+
+	// outlookTimeLayout is the layout of times in outlook properties, which
+	// are UTC.
+	outlookTimeLayout = "200601021504"
+)
+
+// Outlook kinds. Days 1 and 2 have categorical, tornado, wind, and hail
+// outlooks. Day 3 has categorical and total severe probability outlooks.
+const (
+	OutlookCategorical   = "cat"
+	OutlookTornado       = "torn"
+	OutlookWind          = "wind"
+	OutlookHail          = "hail"
+	OutlookProbabilistic = "prob"
+)
+
+// Categorical risk labels, from least to most severe.
+const (
+	RiskThunderstorm = "TSTM"
+	RiskMarginal     = "MRGL"
+	RiskSlight       = "SLGT"
+	RiskEnhanced     = "ENH"
+	RiskModerate     = "MDT"
+	RiskHigh         = "HIGH"
+)
+
+// significantLabel labels the hatched area of a probabilistic outlook in which
+// significant severe weather is possible.
+const significantLabel = "SIGN"
+
+// outlookKinds lists the kinds of outlook issued for each day.
+var outlookKinds = map[int][]string{
+	1: {OutlookCategorical, OutlookTornado, OutlookWind, OutlookHail},
+	2: {OutlookCategorical, OutlookTornado, OutlookWind, OutlookHail},
+	3: {OutlookCategorical, OutlookProbabilistic},
+}
+
+// An Outlook is a single convective outlook.
+type Outlook struct {
+	Day         int
+	Kind        string // one of the Outlook constants
+	TimeIssued  time.Time
+	TimeValid   time.Time
+	TimeExpires time.Time
+	Areas       []Area // in the order published, least severe first
+}
+
+// An Area is one risk area of an outlook.
+type Area struct {
+	// Label is a categorical risk label (e.g. RiskSlight) for categorical
+	// outlooks. For probabilistic outlooks, it is the probability as a
+	// fraction (e.g. "0.15"), or "SIGN" for the hatched area in which
+	// significant severe weather is possible.
+	Label       string
+	Description string // e.g. "Slight Risk"
+
+	// Rank orders areas of the same outlook by severity.
+	Rank int
+
+	// Probability is the probability of severe weather within 25 miles of
+	// a point, in percent. It is zero for categorical outlooks and for the
+	// significant area.
+	Probability int
+
+	// Significant is true for the hatched area of a probabilistic outlook.
+	Significant bool
+
+	polygons [][][]point
+}
+
+// point is a GeoJSON position.
+type point struct {
+	Lat float64
+	Lon float64
+}
+
+// Outlook retrieves the current outlook of a kind for day 1, 2, or 3.
+func (c *Client) Outlook(day int, kind string) (*Outlook, error) {
+	kinds, ok := outlookKinds[day]
+	if !ok {
+		return nil, fmt.Errorf("day must be 1, 2, or 3: %d", day)
+	}
+	var valid bool
+	for _, k := range kinds {
+		valid = valid || k == kind
+	}
+	if !valid {
+		return nil, fmt.Errorf("no \"%s\" outlook for day %d", kind, day)
+	}
+
+	respBody, err := doRequest(c.httpClient, c.httpUserAgentString, c.baseURLString, fmt.Sprintf(getOutlookProductFmt, day, kind))
+	if err != nil {
+		return nil, err
+	}
+	o, err := newOutlookFromRespBody(respBody)
+	if err != nil {
+		return nil, err
+	}
+	o.Day = day
+	o.Kind = kind
+	return o, nil
+}
+
+// AreasContaining returns the areas of the outlook containing a WGS 84
+// (EPSG:4326) latitude and longitude, least severe first.
+func (o Outlook) AreasContaining(lat float64, lon float64) []Area {
+	var areas []Area
+	for _, a := range o.Areas {
+		if a.Contains(lat, lon) {
+			areas = append(areas, a)
+		}
+	}
+	return areas
+}
+
+// RiskAt returns the most severe area containing a latitude and longitude,
+// excluding the significant area of a probabilistic outlook. The second
+// return value is false if the point is outside every area.
+func (o Outlook) RiskAt(lat float64, lon float64) (Area, bool) {
+	var risk Area
+	var found bool
+	for _, a := range o.AreasContaining(lat, lon) {
+		if a.Significant {
+			continue
+		}
+		if !found || a.Rank > risk.Rank {
+			risk, found = a, true
+		}
+	}
+	return risk, found
+}
+
+// SignificantAt reports whether a latitude and longitude is within the
+// significant severe area of a probabilistic outlook.
+func (o Outlook) SignificantAt(lat float64, lon float64) bool {
+	for _, a := range o.AreasContaining(lat, lon) {
+		if a.Significant {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the area contains a WGS 84 (EPSG:4326) latitude and
+// longitude. Holes in the area's polygons are respected.
+func (a Area) Contains(lat float64, lon float64) bool {
+	p := point{Lat: lat, Lon: lon}
+	for _, poly := range a.polygons {
+		// with the even-odd rule, a point within a hole crosses the outer
+		// ring and the hole, so is outside
+		in := false
+		for _, ring := range poly {
+			if ringContainsPoint(ring, p) {
+				in = !in
+			}
+		}
+		if in {
+			return true
+		}
+	}
+	return false
+}
+
+// newOutlookFromRespBody returns an Outlook pointer, given a GeoJSON response
+// body from the SPC. Areas without geometry, such as placeholders for risk
+// categories not forecast, are skipped.
+func newOutlookFromRespBody(respBody []byte) (*Outlook, error) {
+	oRaw := struct {
+		Features []struct {
+			Geometry *struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties struct {
+				DN     json.Number
+				Valid  string `json:"VALID"`
+				Expire string `json:"EXPIRE"`
+				Issue  string `json:"ISSUE"`
+				Label  string `json:"LABEL"`
+				Label2 string `json:"LABEL2"`
+			}
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &oRaw); err != nil {
+		return nil, err
+	}
+
+	var o Outlook
+	for _, fRaw := range oRaw.Features {
+		props := fRaw.Properties
+		if o.TimeIssued.IsZero() {
+			o.TimeIssued, _ = time.Parse(outlookTimeLayout, props.Issue)
+			o.TimeValid, _ = time.Parse(outlookTimeLayout, props.Valid)
+			o.TimeExpires, _ = time.Parse(outlookTimeLayout, props.Expire)
+		}
+		if fRaw.Geometry == nil {
+			continue
+		}
+
+		a := Area{
+			Label:       strings.TrimSpace(props.Label),
+			Description: strings.TrimSpace(props.Label2),
+		}
+		rank, err := strconv.ParseFloat(string(props.DN), 64)
+		if err != nil {
+			return nil, fmt.Errorf("DN must be a number: \"%s\"", props.DN)
+		}
+		a.Rank = int(rank)
+		if a.Label == significantLabel {
+			a.Significant = true
+		} else if v, err := strconv.ParseFloat(a.Label, 64); err == nil {
+			a.Probability = int(v*100 + 0.5)
+		}
+		if a.polygons, err = newPolygonsFromGeoJSONGeometry(fRaw.Geometry.Type, fRaw.Geometry.Coordinates); err != nil {
+			return nil, err
+		}
+		o.Areas = append(o.Areas, a)
+	}
+
+	return &o, nil
+}
+
+// newPolygonsFromGeoJSONGeometry returns the rings of each polygon in a
+// GeoJSON Polygon or MultiPolygon geometry.
+func newPolygonsFromGeoJSONGeometry(geometryType string, coordinates json.RawMessage) ([][][]point, error) {
+	// GeoJSON coordinates are lon, lat
+	var multi [][][][]float64
+	switch geometryType {
+	case "Polygon":
+		var poly [][][]float64
+		if err := json.Unmarshal(coordinates, &poly); err != nil {
+			return nil, err
+		}
+		multi = append(multi, poly)
+	case "MultiPolygon":
+		if err := json.Unmarshal(coordinates, &multi); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("geometry type must be Polygon or MultiPolygon: \"%s\"", geometryType)
+	}
+
+	var polys [][][]point
+	for _, polyRaw := range multi {
+		var poly [][]point
+		for _, ringRaw := range polyRaw {
+			var ring []point
+			for _, c := range ringRaw {
+				if len(c) < 2 {
+					continue // skip malformed positions
+				}
+				ring = append(ring, point{Lat: c[1], Lon: c[0]})
+			}
+			if len(ring) >= 3 {
+				poly = append(poly, ring)
+			}
+		}
+		if len(poly) > 0 {
+			polys = append(polys, poly)
+		}
+	}
+	return polys, nil
+}
+
+// ringContainsPoint reports whether a point lies inside a ring using the
+// even-odd (ray casting) rule. Latitude and longitude are treated as planar
+// coordinates.
+func ringContainsPoint(ring []point, p point) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			in = !in
+		}
+	}
+	return in
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const alertsFeed = "products/alerts.json"
+
+// alertSerialRegexp matches the serial number line in an alert message.
+var alertSerialRegexp = regexp.MustCompile(`(?m)^Serial Number: (\d+)`)
+
+// alertTitleRegexp matches the first ALERT, WATCH, WARNING, etc. line in an
+// alert message, which describes it (e.g. "WATCH: Geomagnetic Storm Category
+// G2 Predicted").
+var alertTitleRegexp = regexp.MustCompile(`(?m)^((?:ALERT|WATCH|WARNING|EXTENDED WARNING|SUMMARY|CANCEL [A-Z]+): .+)$`)
+
+// An Alert represents an alert, watch, warning, or summary issued by SWPC.
+type Alert struct {
+	ProductID    string // e.g. "K05A" (K-index of 5 alert), "A20F" (geomagnetic watch)
+	SerialNumber string
+	Title        string // e.g. "WATCH: Geomagnetic Storm Category G2 Predicted"
+	TimeIssued   time.Time
+	Message      string
+}
+
+// Alerts retrieves the alerts, watches, and warnings issued by SWPC in the
+// past several days, newest first.
+func (c *Client) Alerts() ([]Alert, error) {
+	return getAlerts(c.httpClient, c.httpUserAgentString, c.baseURLString)
+}
+
+// getAlerts retrieves the alerts feed.
+func getAlerts(httpClient *http.Client, httpUserAgentString string, baseURLString string) ([]Alert, error) {
+	respBody, err := doRequest(httpClient, httpUserAgentString, baseURLString, alertsFeed)
+	if err != nil {
+		return nil, err
+	}
+	return newAlertsFromRespBody(respBody)
+}
+
+// newAlertsFromRespBody returns a slice of Alerts, given a response body.
+func newAlertsFromRespBody(respBody []byte) ([]Alert, error) {
+	asRaw := []struct {
+		ProductID     string `json:"product_id"`
+		IssueDatetime string `json:"issue_datetime"`
+		Message       string
+	}{}
+	if err := json.Unmarshal(respBody, &asRaw); err != nil {
+		return nil, err
+	}
+
+	var as []Alert
+	for _, aRaw := range asRaw {
+		a := Alert{ProductID: aRaw.ProductID, Message: aRaw.Message}
+		var err error
+		if a.TimeIssued, err = parseTime(aRaw.IssueDatetime); err != nil {
+			continue // skip if bad time
+		}
+		if m := alertSerialRegexp.FindStringSubmatch(aRaw.Message); m != nil {
+			a.SerialNumber = m[1]
+		}
+		if m := alertTitleRegexp.FindStringSubmatch(aRaw.Message); m != nil {
+			a.Title = m[1]
+		}
+		as = append(as, a)
+	}
+
+	return as, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const planetaryKIndexFeed = "products/noaa-planetary-k-index.json"
+
+// A KIndex represents the planetary K-index (Kp) for a three hour period
+// starting at Time. Kp ranges from 0 (quiet) to 9 (extreme storm); values of
+// 5 and above correspond to the G1 to G5 geomagnetic storm scales.
+type KIndex struct {
+	Time         time.Time
+	Kp           float64
+	ARunning     int
+	StationCount int
+}
+
+// GeomagneticScale returns the NOAA G-scale level (0 through 5) corresponding
+// to the K-index.
+func (k KIndex) GeomagneticScale() int {
+	switch {
+	case k.Kp >= 9:
+		return 5
+	case k.Kp >= 5:
+		return int(k.Kp) - 4
+	}
+	return 0
+}
+
+// PlanetaryKIndex retrieves the planetary K-index for the past week, oldest
+// first.
+func (c *Client) PlanetaryKIndex() ([]KIndex, error) {
+	return getPlanetaryKIndex(c.httpClient, c.httpUserAgentString, c.baseURLString)
+}
+
+// getPlanetaryKIndex retrieves the planetary K-index feed.
+func getPlanetaryKIndex(httpClient *http.Client, httpUserAgentString string, baseURLString string) ([]KIndex, error) {
+	respBody, err := doRequest(httpClient, httpUserAgentString, baseURLString, planetaryKIndexFeed)
+	if err != nil {
+		return nil, err
+	}
+	return newKIndexesFromRespBody(respBody)
+}
+
+// newKIndexesFromRespBody returns a slice of KIndexes, given a response body.
+// The feed is a table whose first row contains the column names and whose
+// values are all strings. Invalid rows are skipped.
+func newKIndexesFromRespBody(respBody []byte) ([]KIndex, error) {
+	var rows [][]string
+	if err := json.Unmarshal(respBody, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) < 1 {
+		return nil, nil
+	}
+
+	cols := make(map[string]int)
+	for i, name := range rows[0] {
+		cols[name] = i
+	}
+	field := func(row []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+
+	var ks []KIndex
+	for _, row := range rows[1:] {
+		var k KIndex
+		var err error
+		if k.Time, err = parseTime(field(row, "time_tag")); err != nil {
+			continue // skip if bad time
+		}
+		if k.Kp, err = strconv.ParseFloat(field(row, "Kp"), 64); err != nil {
+			continue // skip if no Kp
+		}
+		k.ARunning, _ = strconv.Atoi(field(row, "a_running"))
+		k.StationCount, _ = strconv.Atoi(field(row, "station_count"))
+		ks = append(ks, k)
+	}
+
+	return ks, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const scalesFeed = "products/noaa-scales.json"
+
+// Scales represents the NOAA space weather scales for a day: radio blackouts
+// (R), solar radiation storms (S), and geomagnetic storms (G).
+//
+// For observed days the levels are the maximum reached. For forecast days the
+// levels are empty and the probabilities are given instead.
+type Scales struct {
+	Time     time.Time
+	Forecast bool
+
+	RadioBlackout          Scale
+	SolarRadiationStorm    Scale
+	GeomagneticStorm       Scale
+	RadioBlackoutMinorProb int // percent chance of R1-R2, forecast days only
+	RadioBlackoutMajorProb int // percent chance of R3 or greater, forecast days only
+	SolarRadiationProb     int // percent chance of S1 or greater, forecast days only
+}
+
+// A Scale is a single NOAA space weather scale level, such as "G2" ("Moderate").
+type Scale struct {
+	Level int    // 0 (none) through 5 (extreme)
+	Text  string // e.g. "none", "minor", "moderate"
+}
+
+// Scales retrieves the space weather scales for the previous day, the current
+// day, and the next three days (forecast), in chronological order.
+func (c *Client) Scales() ([]Scales, error) {
+	return getScales(c.httpClient, c.httpUserAgentString, c.baseURLString)
+}
+
+// getScales retrieves the scales feed.
+func getScales(httpClient *http.Client, httpUserAgentString string, baseURLString string) ([]Scales, error) {
+	respBody, err := doRequest(httpClient, httpUserAgentString, baseURLString, scalesFeed)
+	if err != nil {
+		return nil, err
+	}
+	return newScalesFromRespBody(respBody)
+}
+
+// newScalesFromRespBody returns a slice of Scales, given a response body. The
+// feed is an object keyed by day offset ("-1", "0", "1", ...); positive
+// offsets are forecasts.
+func newScalesFromRespBody(respBody []byte) ([]Scales, error) {
+	type scaleRaw struct {
+		Scale     *string
+		Text      *string
+		Prob      *string
+		MinorProb *string
+		MajorProb *string
+	}
+	ssRaw := make(map[string]struct {
+		DateStamp string
+		TimeStamp string
+		R         scaleRaw
+		S         scaleRaw
+		G         scaleRaw
+	})
+	if err := json.Unmarshal(respBody, &ssRaw); err != nil {
+		return nil, err
+	}
+
+	offsets := make([]int, 0, len(ssRaw))
+	byOffset := make(map[int]string)
+	for k := range ssRaw {
+		o, err := strconv.Atoi(k)
+		if err != nil {
+			continue // skip if not a day offset
+		}
+		offsets = append(offsets, o)
+		byOffset[o] = k
+	}
+	sort.Ints(offsets)
+
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	num := func(s *string) int {
+		n, _ := strconv.Atoi(str(s))
+		return n
+	}
+
+	var ss []Scales
+	for _, o := range offsets {
+		sRaw := ssRaw[byOffset[o]]
+		var s Scales
+		var err error
+		if s.Time, err = time.Parse(swpcTimeLayout, sRaw.DateStamp+" "+sRaw.TimeStamp); err != nil {
+			continue // skip if bad time
+		}
+		s.Forecast = o > 0
+		s.RadioBlackout = Scale{Level: num(sRaw.R.Scale), Text: str(sRaw.R.Text)}
+		s.SolarRadiationStorm = Scale{Level: num(sRaw.S.Scale), Text: str(sRaw.S.Text)}
+		s.GeomagneticStorm = Scale{Level: num(sRaw.G.Scale), Text: str(sRaw.G.Text)}
+		s.RadioBlackoutMinorProb = num(sRaw.R.MinorProb)
+		s.RadioBlackoutMajorProb = num(sRaw.R.MajorProb)
+		s.SolarRadiationProb = num(sRaw.S.Prob)
+		ss = append(ss, s)
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package swpc implements a client for retrieving space weather data from the
+// JSON feeds published by the NOAA Space Weather Prediction Center.
+package swpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURLString = "https://services.swpc.noaa.gov/"
+
+	// maxRespBodyBytes limits the size of response bodies. The feeds used
+	// here are well under a megabyte.
+	maxRespBodyBytes = 4 << 20
+
+	// swpcTimeLayout is the layout of times in the feeds, which are UTC.
+	swpcTimeLayout = "2006-01-02 15:04:05"
+)
+
+// A Client is used to retrieve data from the SWPC feeds.
+type Client struct {
+	httpClient          *http.Client
+	httpUserAgentString string
+	baseURLString       string
+}
+
+// NewClient returns a new Client. An httpUserAgentString identifying your
+// application is required.
+func NewClient(httpClient *http.Client, httpUserAgentString string) (*Client, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if httpUserAgentString == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	return &Client{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		baseURLString:       defaultBaseURLString,
+	}, nil
+}
+
+// SetBaseURLString sets the base URL for the feeds. It must end with a slash.
+func (c *Client) SetBaseURLString(urlString string) error {
+	if !strings.HasSuffix(urlString, "/") {
+		return errors.New("base URL must end with a slash")
+	}
+	c.baseURLString = urlString
+	return nil
+}
+
+// doRequest makes a GET request for a feed and returns the body of a 200
+// response.
+func doRequest(httpClient *http.Client, httpUserAgentString string, baseURLString string, feed string) ([]byte, error) {
+	req, err := http.NewRequest("GET", baseURLString+feed, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpUserAgentString)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody) > maxRespBodyBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxRespBodyBytes)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, respBody)
+	}
+
+	return respBody, nil
+}
+
+// parseTime parses a feed time, which may have fractional seconds.
+func parseTime(s string) (time.Time, error) {
+	if i := strings.Index(s, "."); i >= 0 {
+		s = s[:i]
+	}
+	return time.Parse(swpcTimeLayout, s)
+}
@@ -0,0 +1,311 @@
+// Copyright 2019 Michael Camilleri <mike@mikecamilleri.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tides implements a client for the NOAA CO-OPS Data API, which
+// provides observed water levels, tide predictions, and currents for stations
+// around the coasts of the United States.
+package tides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAPIURLString = "https://api.tidesandcurrents.noaa.gov/api/prod/datagetter"
+
+	// maxRespBodyBytes limits the size of response bodies. A month of six
+	// minute water levels is under a megabyte.
+	maxRespBodyBytes = 8 << 20
+
+	// requestTimeLayout and respTimeLayout are the layouts of times in
+	// requests and responses. All times are UTC.
+	requestTimeLayout = "20060102 15:04"
+	respTimeLayout    = "2006-01-02 15:04"
+)
+
+// Products
+const (
+	ProductWaterLevel  = "water_level" // observed water levels, six minute intervals
+	ProductPredictions = "predictions" // predicted tides
+	ProductCurrents    = "currents"    // observed currents
+)
+
+// Datums that water levels and predictions may be referenced to.
+const (
+	DatumMLLW = "MLLW" // mean lower low water, used on nautical charts
+	DatumMLW  = "MLW"
+	DatumMSL  = "MSL"
+	DatumMHW  = "MHW"
+	DatumMHHW = "MHHW"
+	DatumNAVD = "NAVD" // North American Vertical Datum of 1988
+	DatumSTND = "STND" // station datum
+)
+
+// Intervals for tide predictions.
+const (
+	IntervalDefault = "" // six minutes
+	IntervalHourly  = "h"
+	IntervalHighLow = "hilo" // high and low tides only
+)
+
+// Units
+const (
+	UnitsEnglish = "english" // feet and knots
+	UnitsMetric  = "metric"  // meters and centimeters per second
+)
+
+// A Client is used to retrieve data from the CO-OPS Data API.
+type Client struct {
+	httpClient          *http.Client
+	httpUserAgentString string
+	apiURLString        string
+}
+
+// A Request describes the data to retrieve from a station.
+type Request struct {
+	StationID string // e.g. "9447130" (Seattle)
+	Product   string // one of the Product constants
+	Datum     string // one of the Datum constants; required for water levels and predictions
+	Interval  string // one of the Interval constants; predictions only
+	Units     string // one of the Units constants; UnitsEnglish if empty
+	Start     time.Time
+	End       time.Time
+}
+
+// A Series is the data returned for a Request.
+type Series struct {
+	StationID   string
+	StationName string // empty for predictions
+	Product     string
+	Datum       string
+	Unit        string // unit of Value: "ft", "m", "kn", or "cm/s"
+	Values      []Value
+}
+
+// A Value is a single value in a Series.
+type Value struct {
+	Time  time.Time
+	Value float64 // water level, or current speed
+
+	Type      string  // "H" or "L" for high and low tide predictions
+	Direction float64 // currents only, degrees true
+	Quality   string  // "p" (preliminary) or "v" (verified), observations only
+}
+
+// NewClient returns a new Client. An httpUserAgentString identifying your
+// application is required; it is also sent as the API's application
+// parameter.
+func NewClient(httpClient *http.Client, httpUserAgentString string) (*Client, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+	if httpUserAgentString == "" {
+		return nil, errors.New("httpUserAgentString must not be empty")
+	}
+	return &Client{
+		httpClient:          httpClient,
+		httpUserAgentString: httpUserAgentString,
+		apiURLString:        defaultAPIURLString,
+	}, nil
+}
+
+// SetAPIURLString sets the URL of the API's data getter.
+func (c *Client) SetAPIURLString(urlString string) error {
+	if _, err := url.Parse(urlString); err != nil {
+		return err
+	}
+	c.apiURLString = urlString
+	return nil
+}
+
+// Get retrieves the data described by a Request.
+func (c *Client) Get(r Request) (*Series, error) {
+	query, err := r.query()
+	if err != nil {
+		return nil, err
+	}
+	query.Set("application", c.httpUserAgentString)
+
+	respBody, err := doAPIRequest(c.httpClient, c.httpUserAgentString, c.apiURLString, query)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSeriesFromRespBody(respBody, r.Product)
+	if err != nil {
+		return nil, err
+	}
+	s.StationID = r.StationID
+	s.Product = r.Product
+	s.Datum = r.Datum
+	s.Unit = unitFor(r.Product, r.Units)
+	return s, nil
+}
+
+// query validates a Request and returns its query parameters.
+func (r Request) query() (url.Values, error) {
+	if r.StationID == "" {
+		return nil, errors.New("station ID must not be empty")
+	}
+	switch r.Product {
+	case ProductWaterLevel, ProductPredictions:
+		if r.Datum == "" {
+			return nil, fmt.Errorf("datum is required for %s", r.Product)
+		}
+	case ProductCurrents:
+	default:
+		return nil, fmt.Errorf("unsupported product: \"%s\"", r.Product)
+	}
+	if r.Interval != "" && r.Product != ProductPredictions {
+		return nil, errors.New("interval is only supported for predictions")
+	}
+	if r.Start.IsZero() || !r.End.After(r.Start) {
+		return nil, errors.New("end must be after start")
+	}
+	units := r.Units
+	if units == "" {
+		units = UnitsEnglish
+	}
+	if units != UnitsEnglish && units != UnitsMetric {
+		return nil, fmt.Errorf("units must be \"%s\" or \"%s\": \"%s\"", UnitsEnglish, UnitsMetric, units)
+	}
+
+	q := url.Values{}
+	q.Set("station", r.StationID)
+	q.Set("product", r.Product)
+	if r.Datum != "" {
+		q.Set("datum", r.Datum)
+	}
+	if r.Interval != "" {
+		q.Set("interval", r.Interval)
+	}
+	q.Set("units", units)
+	q.Set("time_zone", "gmt")
+	q.Set("format", "json")
+	q.Set("begin_date", r.Start.UTC().Format(requestTimeLayout))
+	q.Set("end_date", r.End.UTC().Format(requestTimeLayout))
+	return q, nil
+}
+
+// unitFor returns the unit of values for a product and system of units.
+func unitFor(product string, units string) string {
+	metric := units == UnitsMetric
+	switch {
+	case product == ProductCurrents && metric:
+		return "cm/s"
+	case product == ProductCurrents:
+		return "kn"
+	case metric:
+		return "m"
+	}
+	return "ft"
+}
+
+// newSeriesFromRespBody returns a Series pointer, given a response body from
+// the API. The API reports errors, such as a station without the requested
+// product, in the body of 200 responses.
+func newSeriesFromRespBody(respBody []byte, product string) (*Series, error) {
+	// unmarshal the body into a temporary struct; values are all strings
+	type valueRaw struct {
+		T    string
+		V    string
+		S    string
+		D    string
+		Q    string
+		Type string
+	}
+	sRaw := struct {
+		Metadata struct {
+			ID   string
+			Name string
+		}
+		Data        []valueRaw
+		Predictions []valueRaw
+		Error       *struct {
+			Message string
+		}
+	}{}
+	if err := json.Unmarshal(respBody, &sRaw); err != nil {
+		return nil, err
+	}
+	if sRaw.Error != nil {
+		return nil, errors.New(strings.TrimSpace(sRaw.Error.Message))
+	}
+
+	vsRaw := sRaw.Data
+	if product == ProductPredictions {
+		vsRaw = sRaw.Predictions
+	}
+
+	s := Series{StationName: sRaw.Metadata.Name}
+	for _, vRaw := range vsRaw {
+		var v Value
+		var err error
+		if v.Time, err = time.Parse(respTimeLayout, vRaw.T); err != nil {
+			continue // skip if bad time
+		}
+		// currents give speed in "s"; elsewhere "s" is the standard deviation
+		vs := vRaw.V
+		if product == ProductCurrents {
+			vs = vRaw.S
+			v.Direction, _ = strconv.ParseFloat(vRaw.D, 64)
+		}
+		if v.Value, err = strconv.ParseFloat(vs, 64); err != nil {
+			continue // skip if missing
+		}
+		v.Type = vRaw.Type
+		v.Quality = vRaw.Q
+		s.Values = append(s.Values, v)
+	}
+
+	return &s, nil
+}
+
+// doAPIRequest makes a GET request to the API and returns the body of a 200
+// response.
+func doAPIRequest(httpClient *http.Client, httpUserAgentString string, apiURLString string, query url.Values) ([]byte, error) {
+	req, err := http.NewRequest("GET", apiURLString, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("User-Agent", httpUserAgentString)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody) > maxRespBodyBytes {
+		return nil, fmt.Errorf("response body exceeds %d bytes", maxRespBodyBytes)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: %s", resp.Status, respBody)
+	}
+
+	return respBody, nil
+}